@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// runtimeStats is the JSON body of /debug/runtime.
+type runtimeStats struct {
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	HeapInuseBytes uint64 `json:"heap_inuse_bytes"`
+	HeapObjects    uint64 `json:"heap_objects"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+	LastGC         string `json:"last_gc,omitempty"`
+	GCPauseTotalNs uint64 `json:"gc_pause_total_ns"`
+	GoVersion      string `json:"go_version"`
+	NumCPU         int    `json:"num_cpu"`
+	GOMAXPROCS     int    `json:"gomaxprocs"`
+}
+
+// newAdminMux returns the handlers for operators: the net/http/pprof profiles under
+// /debug/pprof/, a JSON snapshot of goroutine and heap statistics at /debug/runtime, the
+// log level at /debug/loglevel, API key usage at /debug/apikeys and the load of the analysis
+// queue at /debug/queue.
+func newAdminMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", handleRuntimeStats)
+	mux.HandleFunc("/debug/loglevel", handleLogLevel)
+	mux.HandleFunc("/debug/apikeys", handleAPIKeyUsage)
+	mux.HandleFunc("/debug/queue", handleQueueStats)
+	return mux
+}
+
+func handleRuntimeStats(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := runtimeStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapInuseBytes: mem.HeapInuse,
+		HeapObjects:    mem.HeapObjects,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+		GCPauseTotalNs: mem.PauseTotalNs,
+		GoVersion:      runtime.Version(),
+		NumCPU:         runtime.NumCPU(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC)).UTC().Format(time.RFC3339)
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func handleQueueStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := analysisQueue.Stats(r.Context())
+	if err != nil {
+		http.Error(w, "Could not read queue stats: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// serveAdmin runs the admin server on addr. It is meant for a loopback or otherwise
+// private address, since profiles expose internals of the running process.
+func serveAdmin(addr string) {
+	slog.Info("Admin server starting...", "addr", addr)
+	if err := http.ListenAndServe(addr, newAdminMux()); err != nil {
+		slog.Error("Admin server stopped", "error", err)
+	}
+}
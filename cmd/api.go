@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"web-analyzer/internal/jobqueue"
+	"web-analyzer/pkg/analyzer"
+)
+
+// apiAnalyzeRequest is the JSON body accepted by POST /api/v1/analyze.
+type apiAnalyzeRequest struct {
+	URL       string            `json:"url"`
+	Workers   int               `json:"workers,omitempty"`
+	Refresh   bool              `json:"refresh,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Proxy     string            `json:"proxy,omitempty"`
+	BasicAuth *apiBasicAuth     `json:"basic_auth,omitempty"`
+	Cookie    string            `json:"cookie,omitempty"`
+	Cookies   map[string]string `json:"cookies,omitempty"`
+	// Snapshot, if set, analyzes the page's snapshot in the Wayback Machine: "latest" or the one
+	// closest to a timestamp such as "20190615".
+	Snapshot string `json:"snapshot,omitempty"`
+	// Email, if set, receives the HTML report once the analysis succeeds.
+	Email string `json:"email,omitempty"`
+	// WebhookURL, if set, is POSTed the outcome of the analysis, failed or not.
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+type apiBasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// apiAnalyzeResponse is the JSON body returned by POST /api/v1/analyze.
+type apiAnalyzeResponse struct {
+	XMLName xml.Name                 `json:"-" xml:"analysis"`
+	Result  *analyzer.AnalysisResult `json:"result" xml:"result"`
+	Cached  bool                     `json:"cached" xml:"cached,attr"`
+}
+
+// apiError is the JSON body returned for every failed API request. Code is a stable,
+// machine-readable identifier (one of the apiCode constants); Error is meant for humans and
+// may change wording.
+type apiError struct {
+	XMLName xml.Name `json:"-" xml:"error"`
+	Error   string   `json:"error" xml:"message"`
+	Code    string   `json:"code" xml:"code,attr"`
+	// URL is the URL that failed to be analyzed, when the request got far enough to name one.
+	URL string `json:"url,omitempty" xml:"url,attr,omitempty"`
+	// UpstreamStatus is the status code returned by the analyzed page, for apiCodeUpstreamStatus.
+	UpstreamStatus int `json:"upstream_status,omitempty" xml:"upstream_status,attr,omitempty"`
+	// Fields lists every invalid field of the request, when the request failed validation.
+	Fields []fieldError `json:"fields,omitempty" xml:"field,omitempty"`
+}
+
+// Error codes returned in apiError.Code.
+const (
+	apiCodeMethodNotAllowed  = "method_not_allowed"
+	apiCodeInvalidJSON       = "invalid_json"
+	apiCodeMissingURL        = "missing_url"
+	apiCodeInvalidURL        = "invalid_url"
+	apiCodeInvalidWorkers    = "invalid_workers"
+	apiCodeInvalidHeader     = "invalid_header"
+	apiCodeInvalidProxy      = "invalid_proxy"
+	apiCodeInvalidCookie     = "invalid_cookie"
+	apiCodeInvalidEmail      = "invalid_email"
+	apiCodeInvalidWebhookURL = "invalid_webhook_url"
+	apiCodeInvalidSnapshot   = "invalid_snapshot"
+	apiCodeTooManyURLs       = "too_many_urls"
+
+	apiCodeCanceled         = "canceled"
+	apiCodeTimeout          = "timeout"
+	apiCodeBlockedAddress   = "blocked_address"
+	apiCodePageTooLarge     = "page_too_large"
+	apiCodeDNSFailure       = "dns_failure"
+	apiCodeTLSError         = "tls_error"
+	apiCodeNotHTML          = "not_html"
+	apiCodeNoSnapshot       = "no_snapshot"
+	apiCodeRedirectLoop     = "redirect_loop"
+	apiCodeTooManyRedirects = "too_many_redirects"
+	apiCodeUpstreamStatus   = "upstream_status"
+	apiCodeAnalysisFailed   = "analysis_failed"
+	apiCodeQueueFull        = "queue_full"
+)
+
+// statusClientClosedRequest is the non-standard status logged when the client goes away
+// before the analysis finishes.
+const statusClientClosedRequest = 499
+
+// handleAPIAnalyze runs an analysis described by a JSON request body and returns the result
+// as JSON. Headers, User-Agent, proxy and credentials given here apply to this analysis only,
+// on top of the server-wide settings.
+func handleAPIAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+
+	var body apiAnalyzeRequest
+	if !decodeJSONBody(w, r, &body) {
+		return
+	}
+
+	req, invalid := body.analysisRequest()
+	body.checkDelivery(&invalid)
+	if len(invalid) > 0 {
+		writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(body.URL))
+		return
+	}
+
+	logger := slog.Default()
+	result, cached, err := runAnalysis(r.Context(), logger, req)
+	if body.WebhookURL != "" {
+		notifyWebhookInBackground(r.Context(), body.WebhookURL, "api", body.URL, result, cached, err)
+	}
+	if err != nil {
+		status, apiErr := apiAnalysisError(body.URL, err)
+		writeAPIResponse(w, r, status, apiErr)
+		return
+	}
+	if body.Email != "" {
+		emailReportInBackground(r.Context(), body.Email, body.URL, result)
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "junit":
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		if err := analyzer.WriteJUnitXML(w, body.URL, result); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write JUnit report", "error", err)
+		}
+		return
+	case "sarif":
+		w.Header().Set("Content-Type", "application/sarif+json")
+		if err := analyzer.WriteSARIF(w, body.URL, result); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write SARIF log", "error", err)
+		}
+		return
+	}
+	writeAPIResponse(w, r, http.StatusOK, apiAnalyzeResponse{Result: result, Cached: cached})
+}
+
+// analysisRequest checks body and turns it into the analysis it asks for: the server-wide
+// settings with the request's overrides. Every invalid field is reported, not just the first.
+// Email and WebhookURL are checked by checkDelivery.
+func (body apiAnalyzeRequest) analysisRequest() (analysisRequest, fieldErrors) {
+	var invalid fieldErrors
+	checkPageURL(&invalid, "url", body.URL)
+	req := analysisRequest{URL: body.URL, Options: analysisOptions, Refresh: body.Refresh}
+	if body.Workers < 0 {
+		invalid.add("workers", apiCodeInvalidWorkers, "must not be negative")
+	} else if body.Workers != 0 {
+		req.Options.Workers = analyzer.BoundedWorkers(body.Workers)
+	}
+	if body.UserAgent != "" {
+		if err := analyzer.ValidateHeader("User-Agent", body.UserAgent); err != nil {
+			invalid.add("user_agent", apiCodeInvalidHeader, "is invalid: "+err.Error())
+		}
+		req.Options.UserAgent = body.UserAgent
+		req.Custom = true
+	}
+	if len(body.Headers) > 0 {
+		headers := analysisOptions.Headers.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		for name, value := range body.Headers {
+			if err := analyzer.ValidateHeader(name, value); err != nil {
+				invalid.add("headers."+name, apiCodeInvalidHeader, "is invalid: "+err.Error())
+				continue
+			}
+			headers.Set(name, value)
+		}
+		req.Options.Headers = headers
+		req.Custom = true
+	}
+
+	if body.Proxy != "" {
+		proxy, err := analyzer.ParseProxyURL(body.Proxy)
+		if err != nil {
+			invalid.add("proxy", apiCodeInvalidProxy, "is invalid: "+err.Error())
+		}
+		req.Options.Proxy = proxy
+		req.Custom = true
+	}
+
+	if body.BasicAuth != nil {
+		req.Options.BasicAuth = &analyzer.BasicAuth{Username: body.BasicAuth.Username, Password: body.BasicAuth.Password}
+		req.Custom = true
+	}
+	if body.Cookie != "" {
+		if _, err := http.ParseCookie(body.Cookie); err != nil {
+			invalid.add("cookie", apiCodeInvalidCookie, "is invalid: "+err.Error())
+		}
+		req.Options.Cookie = body.Cookie
+		req.Custom = true
+	}
+
+	for name, value := range body.Cookies {
+		cookie := &http.Cookie{Name: name, Value: value}
+		if err := cookie.Valid(); err != nil {
+			invalid.add("cookies."+name, apiCodeInvalidCookie, "is invalid: "+err.Error())
+			continue
+		}
+		req.Options.Cookies = append(req.Options.Cookies, cookie)
+		req.Custom = true
+	}
+
+	if body.Snapshot != "" {
+		checkSnapshot(&invalid, "snapshot", body.Snapshot)
+		req.Options.Snapshot = body.Snapshot
+		req.Custom = true
+	}
+
+	return req, invalid
+}
+
+// checkDelivery adds a problem to errs for each invalid field saying where to deliver the
+// result: the webhook URL and email address.
+func (body apiAnalyzeRequest) checkDelivery(errs *fieldErrors) {
+	if body.WebhookURL != "" && !validPageURL(body.WebhookURL) {
+		errs.add("webhook_url", apiCodeInvalidWebhookURL, "must be an absolute http or https URL")
+	}
+	if body.Email != "" {
+		if _, err := reportMailer.checkRecipient(body.Email); err != nil {
+			errs.add("email", apiCodeInvalidEmail, "is invalid: "+err.Error())
+		}
+	}
+}
+
+// validPageURL reports whether pageURL is an absolute http or https URL.
+func validPageURL(pageURL string) bool {
+	u, err := url.ParseRequestURI(pageURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// apiAnalysisError maps a failed analysis of pageURL to the HTTP status and error body
+// returned to API clients.
+func apiAnalysisError(pageURL string, err error) (int, apiError) {
+	var queuedErr *queuedAnalysisError
+	if errors.As(err, &queuedErr) {
+		apiErr := queuedErr.apiErr
+		apiErr.URL = pageURL
+		return queuedErr.status, apiErr
+	}
+
+	apiErr := apiError{Error: analysisErrorMessage(err), URL: pageURL}
+	var statusErr *analyzer.HTTPStatusError
+	status := http.StatusBadGateway
+	switch {
+	case errors.Is(err, jobqueue.ErrFull):
+		apiErr.Code, status = apiCodeQueueFull, http.StatusTooManyRequests
+	case errors.Is(err, context.Canceled):
+		apiErr.Code, status = apiCodeCanceled, statusClientClosedRequest
+	case errors.Is(err, analyzer.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		apiErr.Code, status = apiCodeTimeout, http.StatusGatewayTimeout
+	case errors.Is(err, analyzer.ErrBlockedAddress):
+		apiErr.Code, status = apiCodeBlockedAddress, http.StatusForbidden
+	case errors.Is(err, analyzer.ErrPageTooLarge):
+		apiErr.Code, status = apiCodePageTooLarge, http.StatusUnprocessableEntity
+	case errors.Is(err, analyzer.ErrDNSFailure):
+		apiErr.Code = apiCodeDNSFailure
+	case errors.Is(err, analyzer.ErrTLS):
+		apiErr.Code = apiCodeTLSError
+	case errors.Is(err, analyzer.ErrNotHTML):
+		apiErr.Code, status = apiCodeNotHTML, http.StatusUnprocessableEntity
+	case errors.Is(err, analyzer.ErrNoSnapshot):
+		apiErr.Code, status = apiCodeNoSnapshot, http.StatusUnprocessableEntity
+	case errors.Is(err, analyzer.ErrRedirectLoop):
+		apiErr.Code = apiCodeRedirectLoop
+	case errors.Is(err, analyzer.ErrTooManyRedirects):
+		apiErr.Code = apiCodeTooManyRedirects
+	case errors.As(err, &statusErr):
+		apiErr.Code, apiErr.UpstreamStatus = apiCodeUpstreamStatus, statusErr.Code
+	default:
+		apiErr.Code = apiCodeAnalysisFailed
+	}
+	return status, apiErr
+}
+
+// writeAPIResponse writes v as XML when the client asks for it with ?format=xml or an Accept
+// header preferring application/xml or text/xml, and as JSON otherwise.
+func writeAPIResponse(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if !wantsXML(r) {
+		writeJSON(w, status, v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write XML response", "error", err)
+	}
+}
+
+func wantsXML(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		// JUnit reports are XML, so failures that produce no report are XML too.
+		return format == "xml" || format == "junit"
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		switch strings.TrimSpace(mediaType) {
+		case "application/xml", "text/xml":
+			return true
+		case "application/json":
+			return false
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
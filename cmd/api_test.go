@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"web-analyzer/internal/jobqueue"
+	"web-analyzer/pkg/analyzer"
+)
+
+func TestAPIAnalysisError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "Queue Full", err: jobqueue.ErrFull, wantStatus: http.StatusTooManyRequests, wantCode: apiCodeQueueFull},
+		{name: "Canceled", err: context.Canceled, wantStatus: statusClientClosedRequest, wantCode: apiCodeCanceled},
+		{name: "Timeout", err: analyzer.ErrTimeout, wantStatus: http.StatusGatewayTimeout, wantCode: apiCodeTimeout},
+		{name: "Deadline Exceeded", err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout, wantCode: apiCodeTimeout},
+		{name: "Blocked Address", err: analyzer.ErrBlockedAddress, wantStatus: http.StatusForbidden, wantCode: apiCodeBlockedAddress},
+		{name: "Page Too Large", err: analyzer.ErrPageTooLarge, wantStatus: http.StatusUnprocessableEntity, wantCode: apiCodePageTooLarge},
+		{name: "DNS Failure", err: analyzer.ErrDNSFailure, wantStatus: http.StatusBadGateway, wantCode: apiCodeDNSFailure},
+		{name: "TLS Error", err: analyzer.ErrTLS, wantStatus: http.StatusBadGateway, wantCode: apiCodeTLSError},
+		{name: "Not HTML", err: analyzer.ErrNotHTML, wantStatus: http.StatusUnprocessableEntity, wantCode: apiCodeNotHTML},
+		{name: "No Snapshot", err: analyzer.ErrNoSnapshot, wantStatus: http.StatusUnprocessableEntity, wantCode: apiCodeNoSnapshot},
+		{name: "Redirect Loop", err: analyzer.ErrRedirectLoop, wantStatus: http.StatusBadGateway, wantCode: apiCodeRedirectLoop},
+		{name: "Too Many Redirects", err: analyzer.ErrTooManyRedirects, wantStatus: http.StatusBadGateway, wantCode: apiCodeTooManyRedirects},
+		{name: "Upstream Status", err: &analyzer.HTTPStatusError{Code: http.StatusNotFound}, wantStatus: http.StatusBadGateway, wantCode: apiCodeUpstreamStatus},
+		{name: "Other Error", err: errors.New("boom"), wantStatus: http.StatusBadGateway, wantCode: apiCodeAnalysisFailed},
+		{
+			name:       "Queued Analysis Error",
+			err:        &queuedAnalysisError{status: http.StatusForbidden, apiErr: apiError{Error: "blocked", Code: apiCodeBlockedAddress}},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apiCodeBlockedAddress,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, apiErr := apiAnalysisError("https://example.com", fmt.Errorf("analyzing page: %w", tc.err))
+			if status != tc.wantStatus {
+				t.Errorf("Expected status %d, but got %d", tc.wantStatus, status)
+			}
+			if apiErr.Code != tc.wantCode {
+				t.Errorf("Expected code %q, but got %q", tc.wantCode, apiErr.Code)
+			}
+			if apiErr.URL != "https://example.com" {
+				t.Errorf("Expected the error to name the URL, but got %q", apiErr.URL)
+			}
+		})
+	}
+}
+
+func TestAPIAnalysisError_UpstreamStatus(t *testing.T) {
+	_, apiErr := apiAnalysisError("https://example.com", &analyzer.HTTPStatusError{Code: http.StatusServiceUnavailable})
+	if apiErr.UpstreamStatus != http.StatusServiceUnavailable {
+		t.Errorf("Expected upstream status %d, but got %d", http.StatusServiceUnavailable, apiErr.UpstreamStatus)
+	}
+}
+
+func TestAPIAnalyzeRequest_Custom(t *testing.T) {
+	oldWaybackURL := analysisOptions.WaybackURL
+	analysisOptions.WaybackURL = "https://archive.example/wayback/available"
+	t.Cleanup(func() { analysisOptions.WaybackURL = oldWaybackURL })
+
+	testCases := []struct {
+		name       string
+		body       apiAnalyzeRequest
+		wantCustom bool
+	}{
+		{name: "Plain", body: apiAnalyzeRequest{}, wantCustom: false},
+		{name: "Workers", body: apiAnalyzeRequest{Workers: 5}, wantCustom: false},
+		{name: "Refresh", body: apiAnalyzeRequest{Refresh: true}, wantCustom: false},
+		{name: "Headers", body: apiAnalyzeRequest{Headers: map[string]string{"Accept-Language": "de"}}, wantCustom: true},
+		{name: "User Agent", body: apiAnalyzeRequest{UserAgent: "test-bot/1.0"}, wantCustom: true},
+		{name: "Proxy", body: apiAnalyzeRequest{Proxy: "http://proxy.example:3128"}, wantCustom: true},
+		{name: "Basic Auth", body: apiAnalyzeRequest{BasicAuth: &apiBasicAuth{Username: "user", Password: "secret"}}, wantCustom: true},
+		{name: "Cookie", body: apiAnalyzeRequest{Cookie: "session=abc"}, wantCustom: true},
+		{name: "Cookies", body: apiAnalyzeRequest{Cookies: map[string]string{"session": "abc"}}, wantCustom: true},
+		{name: "Snapshot", body: apiAnalyzeRequest{Snapshot: analyzer.SnapshotLatest}, wantCustom: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.body.URL = "https://example.com"
+			req, invalid := tc.body.analysisRequest()
+			if len(invalid) > 0 {
+				t.Fatalf("Expected a valid request, but got %v", invalid)
+			}
+			if req.Custom != tc.wantCustom {
+				t.Errorf("Expected Custom to be %v, but got %v", tc.wantCustom, req.Custom)
+			}
+		})
+	}
+}
+
+func TestAPIAnalyzeRequest_InvalidFields(t *testing.T) {
+	testCases := []struct {
+		name      string
+		body      apiAnalyzeRequest
+		wantField string
+		wantCode  string
+	}{
+		{name: "Missing URL", body: apiAnalyzeRequest{}, wantField: "url", wantCode: apiCodeMissingURL},
+		{name: "Invalid URL", body: apiAnalyzeRequest{URL: "ftp://example.com"}, wantField: "url", wantCode: apiCodeInvalidURL},
+		{name: "Negative Workers", body: apiAnalyzeRequest{URL: "https://example.com", Workers: -1}, wantField: "workers", wantCode: apiCodeInvalidWorkers},
+		{name: "Invalid Header", body: apiAnalyzeRequest{URL: "https://example.com", Headers: map[string]string{"X-Bad": "a\nb"}}, wantField: "headers.X-Bad", wantCode: apiCodeInvalidHeader},
+		{name: "Invalid Proxy", body: apiAnalyzeRequest{URL: "https://example.com", Proxy: "ftp://proxy.example"}, wantField: "proxy", wantCode: apiCodeInvalidProxy},
+		{name: "Invalid Snapshot", body: apiAnalyzeRequest{URL: "https://example.com", Snapshot: "yesterday"}, wantField: "snapshot", wantCode: apiCodeInvalidSnapshot},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, invalid := tc.body.analysisRequest()
+			if len(invalid) != 1 {
+				t.Fatalf("Expected 1 invalid field, but got %v", invalid)
+			}
+			if invalid[0].Field != tc.wantField || invalid[0].Code != tc.wantCode {
+				t.Errorf("Expected %s to be invalid with %q, but got %s with %q", tc.wantField, tc.wantCode, invalid[0].Field, invalid[0].Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"web-analyzer/internal/apikey"
+)
+
+// apiCodeMissingAPIKey is returned when -require-api-key is set and an API request has no key.
+const apiCodeMissingAPIKey = "missing_api_key"
+
+// requireAPIKey refuses requests to the JSON API under /api/ that do not carry one of apiKeys.
+// The UI and the API documentation stay open, since browsers cannot send the key header.
+func requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isAPIRequest(r) && !isAPIDocs(r) {
+			secret := r.Header.Get(apiKeyHeader)
+			if secret == "" {
+				rejectRequest(w, r, http.StatusUnauthorized, apiCodeMissingAPIKey, "an API key is required in the "+apiKeyHeader+" header")
+				return
+			}
+			if _, ok := apiKeys.Lookup(secret); !ok {
+				rejectRequest(w, r, http.StatusUnauthorized, apiCodeInvalidAPIKey, "unknown API key")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAPIRequest reports whether r is for the JSON API.
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
+
+// isAPIDocs reports whether r is for the OpenAPI document or its Swagger UI.
+func isAPIDocs(r *http.Request) bool {
+	return r.URL.Path == apiPrefix+"/openapi.json" || r.URL.Path == apiPrefix+"/docs"
+}
+
+// apiKeyUsageBody is the JSON body of /debug/apikeys.
+type apiKeyUsageBody struct {
+	Keys []apikey.Usage `json:"keys"`
+}
+
+// handleAPIKeyUsage serves /debug/apikeys on the admin server: the quota and usage of every
+// API key, by name. Secrets are never included.
+func handleAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, apiKeyUsageBody{Keys: apiKeys.Usage()})
+}
+
+// loadAPIKeys parses the keys given in entries and, if path is set, those in the file at path.
+func loadAPIKeys(entries []string, path string, defaultQuota int) (*apikey.Registry, error) {
+	keys := make([]apikey.Key, 0, len(entries))
+	for _, entry := range entries {
+		key, err := apikey.Parse(entry, defaultQuota)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if path != "" {
+		fileKeys, err := apikey.LoadFile(path, defaultQuota)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return apikey.New(keys)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// badgeMaxAge is how long browsers and proxies may cache a badge.
+const badgeMaxAge = 5 * 60
+
+// badgeColors maps each grade to the shields.io color of its badge.
+var badgeColors = map[string]string{
+	"A": "#4c1",
+	"B": "#97ca00",
+	"C": "#dfb317",
+	"D": "#fe7d37",
+	"F": "#e05d44",
+}
+
+// badgeUnknownColor is used when no analysis of the URL is known.
+const badgeUnknownColor = "#9f9f9f"
+
+var badgeTmpl = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Value}}">
+<title>{{.Label}}: {{.Value}}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="20" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.ValueWidth}}" height="20" fill="{{.Color}}"/><rect width="{{.Width}}" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="14">{{.Label}}</text><text x="{{.ValueX}}" y="14">{{.Value}}</text>
+</g>
+</svg>
+`))
+
+type badgeData struct {
+	Label, Value, Color           string
+	Width, LabelWidth, ValueWidth int
+	LabelX, ValueX                float64
+}
+
+// newBadge lays out a two-part badge, estimating text widths from the character count.
+func newBadge(label, value, color string) badgeData {
+	const charWidth, padding = 7, 10
+	b := badgeData{
+		Label:      label,
+		Value:      value,
+		Color:      color,
+		LabelWidth: utf8.RuneCountInString(label)*charWidth + padding,
+		ValueWidth: utf8.RuneCountInString(value)*charWidth + padding,
+	}
+	b.Width = b.LabelWidth + b.ValueWidth
+	b.LabelX = float64(b.LabelWidth) / 2
+	b.ValueX = float64(b.LabelWidth) + float64(b.ValueWidth)/2
+	return b
+}
+
+// handleBadge serves GET /badge?url=... as an SVG shield showing the grade and score of the
+// latest analysis of url. It never starts an analysis; URLs without a known result get an
+// "unknown" badge.
+func handleBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	pageURL := r.URL.Query().Get("url")
+	if pageURL == "" {
+		clientError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	result, ok := resultCache.Stale(pageURL)
+	if !ok {
+		// Results saved by other instances sharing the store count as well.
+		if recs, err := savedResults.ListByURL(r.Context(), pageURL, 1); err != nil {
+			slog.WarnContext(r.Context(), "Could not look up saved results", "url", pageURL, "error", err)
+		} else if len(recs) > 0 {
+			result, ok = recs[0].Result, true
+		}
+	}
+
+	badge := newBadge("web analyzer", "unknown", badgeUnknownColor)
+	if ok && result.Grade != "" {
+		badge = newBadge("web analyzer", fmt.Sprintf("%s %d", result.Grade, result.Score), badgeColors[result.Grade])
+		w.Header().Set("Last-Modified", result.AnalyzedAt.Format(http.TimeFormat))
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(badgeMaxAge))
+	if err := badgeTmpl.Execute(w, badge); err != nil {
+		serverError(w, err)
+	}
+}
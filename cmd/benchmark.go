@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// maxBenchmarkCompetitors is the most competitors a benchmark compares the page with. A
+// benchmark counts as one analysis against the rate limits, so this bounds what it costs.
+const maxBenchmarkCompetitors = 5
+
+var benchmarkTmpl *template.Template // parsed by loadTemplates
+
+type benchmarkData struct {
+	URL         string
+	Competitors string
+	Benchmark   *analyzer.Benchmark
+	// Error is why the page itself could not be analyzed.
+	Error string
+	// FieldErrors maps the form fields that failed validation to their problem.
+	FieldErrors map[string]string
+}
+
+// apiBenchmarkRequest is the JSON body accepted by POST /api/v1/benchmark.
+type apiBenchmarkRequest struct {
+	URL         string   `json:"url"`
+	Competitors []string `json:"competitors"`
+	Refresh     bool     `json:"refresh,omitempty"`
+}
+
+// checkCompetitors adds a problem to errs for each invalid competitor URL, and if there are
+// none or too many of them.
+func checkCompetitors(errs *fieldErrors, field string, competitors []string) {
+	switch {
+	case len(competitors) == 0:
+		errs.add(field, apiCodeMissingURL, "must list at least one URL")
+	case len(competitors) > maxBenchmarkCompetitors:
+		errs.add(field, apiCodeTooManyURLs, fmt.Sprintf("must list at most %d URLs", maxBenchmarkCompetitors))
+	default:
+		for i, competitor := range competitors {
+			checkPageURL(errs, fmt.Sprintf("%s[%d]", field, i), competitor)
+		}
+	}
+}
+
+// runBenchmark analyzes pageURL and its competitors side by side and compares them. It fails
+// with the page's error if the page itself cannot be analyzed; a competitor that cannot be
+// is listed with its error instead.
+func runBenchmark(r *http.Request, pageURL string, competitors []string, refresh bool) (*analyzer.Benchmark, error) {
+	sides := analyzeSideBySide(r, append([]string{pageURL}, competitors...), refresh)
+	if sides[0].err != nil {
+		return nil, sides[0].err
+	}
+	pages := make([]analyzer.BenchmarkPage, len(sides))
+	for i, side := range sides {
+		if side.err != nil {
+			pages[i] = analyzer.BenchmarkPage{URL: side.URL, Error: side.Error}
+		} else {
+			pages[i] = analyzer.NewBenchmarkPage(side.URL, side.Results)
+		}
+	}
+	return analyzer.NewBenchmark(pages[0], pages[1:]), nil
+}
+
+// writeBenchmarkCSV writes b as a CSV attachment.
+func writeBenchmarkCSV(w http.ResponseWriter, r *http.Request, b *analyzer.Benchmark) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="benchmark.csv"`)
+	if err := analyzer.WriteBenchmarkCSV(w, b); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write benchmark CSV", "error", err)
+	}
+}
+
+// handleBenchmark serves the benchmark page: GET shows the form and POST compares the url form
+// field with the competitors, one URL per line, in a table, or as CSV with format=csv.
+func handleBenchmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if !parseForm(w, r) {
+		return
+	}
+	data := benchmarkData{URL: r.FormValue("url"), Competitors: r.FormValue("competitors")}
+	if r.Method == http.MethodPost {
+		competitors := strings.Fields(data.Competitors)
+		var invalid fieldErrors
+		checkPageURL(&invalid, "url", data.URL)
+		checkCompetitors(&invalid, "competitors", competitors)
+		if len(invalid) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			data.FieldErrors = invalid.byField()
+		} else if b, err := runBenchmark(r, data.URL, competitors, r.FormValue("refresh") != ""); err != nil {
+			data.Error = analysisErrorMessage(err)
+		} else if r.FormValue("format") == "csv" {
+			writeBenchmarkCSV(w, r, b)
+			return
+		} else {
+			data.Benchmark = b
+		}
+	}
+
+	if err := benchmarkTmpl.Execute(w, data); err != nil {
+		serverError(w, err)
+	}
+}
+
+// handleAPIBenchmark compares the page of a JSON request body with its competitors.
+func handleAPIBenchmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+
+	var body apiBenchmarkRequest
+	if !decodeJSONBody(w, r, &body) {
+		return
+	}
+	var invalid fieldErrors
+	checkPageURL(&invalid, "url", body.URL)
+	checkCompetitors(&invalid, "competitors", body.Competitors)
+	if len(invalid) > 0 {
+		writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(body.URL))
+		return
+	}
+
+	b, err := runBenchmark(r, body.URL, body.Competitors, body.Refresh)
+	if err != nil {
+		status, apiErr := apiAnalysisError(body.URL, err)
+		writeAPIResponse(w, r, status, apiErr)
+		return
+	}
+	if r.URL.Query().Get("format") == "csv" {
+		writeBenchmarkCSV(w, r, b)
+		return
+	}
+	writeAPIResponse(w, r, http.StatusOK, b)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+var compareTmpl *template.Template // parsed by loadTemplates
+
+// compareSide is one column of the comparison page.
+type compareSide struct {
+	URL     string
+	Results *analyzer.AnalysisResult
+	Cached  bool
+	Error   string
+	err     error
+}
+
+type compareData struct {
+	Sides []compareSide
+	// FieldErrors maps the form fields that failed validation to their problem.
+	FieldErrors map[string]string
+}
+
+// apiCompareRequest is the JSON body accepted by POST /api/v1/compare.
+type apiCompareRequest struct {
+	URLs    []string `json:"urls"`
+	Refresh bool     `json:"refresh,omitempty"`
+}
+
+// apiCompareResponse is the body returned by POST /api/v1/compare, with the results in the
+// order of the requested URLs.
+type apiCompareResponse struct {
+	XMLName xml.Name             `json:"-" xml:"comparison"`
+	Results []apiAnalyzeResponse `json:"results" xml:"analysis"`
+}
+
+// analyzeSideBySide analyzes pageURLs concurrently with the server-wide settings. A failed
+// analysis does not stop the others; its side carries the error instead.
+func analyzeSideBySide(r *http.Request, pageURLs []string, refresh bool) []compareSide {
+	logger := slog.Default()
+	sides := make([]compareSide, len(pageURLs))
+	var wg sync.WaitGroup
+	for i, pageURL := range pageURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			side := compareSide{URL: pageURL}
+			side.Results, side.Cached, side.err = runAnalysis(r.Context(), logger, analysisRequest{
+				URL:     pageURL,
+				Options: analysisOptions,
+				Refresh: refresh,
+			})
+			if side.err != nil {
+				side.Error = analysisErrorMessage(side.err)
+			}
+			sides[i] = side
+		}()
+	}
+	wg.Wait()
+	return sides
+}
+
+// handleCompare serves the side-by-side comparison page: GET shows the form and POST
+// analyzes the url and competitor form fields and shows their results in adjacent columns.
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if !parseForm(w, r) {
+		return
+	}
+	data := compareData{Sides: []compareSide{{URL: r.FormValue("url")}, {URL: r.FormValue("competitor")}}}
+	if r.Method == http.MethodPost {
+		var invalid fieldErrors
+		checkPageURL(&invalid, "url", data.Sides[0].URL)
+		checkPageURL(&invalid, "competitor", data.Sides[1].URL)
+		if len(invalid) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			data.FieldErrors = invalid.byField()
+		} else {
+			data.Sides = analyzeSideBySide(r, []string{data.Sides[0].URL, data.Sides[1].URL}, r.FormValue("refresh") != "")
+		}
+	}
+
+	if err := compareTmpl.Execute(w, data); err != nil {
+		serverError(w, err)
+	}
+}
+
+// handleAPICompare analyzes the two URLs of a JSON request body side by side. If either
+// analysis fails, the request fails with that URL's error.
+func handleAPICompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+
+	var body apiCompareRequest
+	if !decodeJSONBody(w, r, &body) {
+		return
+	}
+	if len(body.URLs) != 2 {
+		writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: "urls must list exactly two URLs", Code: apiCodeMissingURL})
+		return
+	}
+	var invalid fieldErrors
+	for i, pageURL := range body.URLs {
+		checkPageURL(&invalid, fmt.Sprintf("urls[%d]", i), pageURL)
+	}
+	if len(invalid) > 0 {
+		writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(""))
+		return
+	}
+
+	var resp apiCompareResponse
+	for _, side := range analyzeSideBySide(r, body.URLs, body.Refresh) {
+		if side.err != nil {
+			status, apiErr := apiAnalysisError(side.URL, side.err)
+			writeAPIResponse(w, r, status, apiErr)
+			return
+		}
+		resp.Results = append(resp.Results, apiAnalyzeResponse{Result: side.Results, Cached: side.Cached})
+	}
+	writeAPIResponse(w, r, http.StatusOK, resp)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-analyzer/internal/crawl"
+	"web-analyzer/internal/jobqueue"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Error codes returned by the /api/v1/crawls endpoints.
+const (
+	apiCodeInvalidMaxPages = "invalid_max_pages"
+	apiCodeInvalidMaxDepth = "invalid_max_depth"
+	apiCodeInvalidScope    = "invalid_scope"
+	apiCodeInvalidPattern  = "invalid_pattern"
+	apiCodeTooManyCrawls   = "too_many_crawls"
+	apiCodeCrawlNotFound   = "crawl_not_found"
+)
+
+// crawlQueueRetry is how long a crawl waits before offering a page to a full analysis queue
+// again.
+const crawlQueueRetry = 2 * time.Second
+
+// crawls runs the site crawls; main creates it once the analysis queue is open.
+var crawls *crawl.Crawler
+
+// crawlMaxPages is the most pages a crawl may analyze, and the default for one that does
+// not say.
+var crawlMaxPages = 100
+
+// crawlIgnoreRobots makes crawls disregard robots.txt; main sets it from -crawl-ignore-robots.
+var crawlIgnoreRobots bool
+
+// analyzeCrawlPage analyzes a page found by a crawl with the server-wide settings. The
+// analysis goes through the analysis queue like any other, so with a shared queue the pages
+// of a crawl are spread over every instance. Pages wait for room in a full queue rather
+// than failing.
+func analyzeCrawlPage(ctx context.Context, pageURL string) (*analyzer.AnalysisResult, error) {
+	ctx = withRequestID(ctx, newRequestID())
+	for {
+		result, _, err := runAnalysis(ctx, slog.Default(), analysisRequest{URL: pageURL, Options: analysisOptions})
+		if !errors.Is(err, jobqueue.ErrFull) {
+			if err != nil {
+				return nil, errors.New(analysisErrorMessage(err))
+			}
+			return result, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.New(analysisErrorMessage(ctx.Err()))
+		case <-time.After(crawlQueueRetry):
+		}
+	}
+}
+
+// listCrawlSitemap reads the sitemap of a sitemap crawl with the server-wide settings.
+func listCrawlSitemap(ctx context.Context, sitemapURL string, limit int) ([]string, error) {
+	urls, err := analyzer.SitemapURLs(ctx, slog.Default(), sitemapURL, limit, analysisOptions)
+	switch {
+	case errors.Is(err, analyzer.ErrNotSitemap):
+		return nil, errors.New("The URL does not point to a sitemap.")
+	case err != nil:
+		return nil, errors.New(analysisErrorMessage(err))
+	}
+	return urls, nil
+}
+
+// fetchCrawlRobots fetches the robots.txt a crawl honors for pageURL.
+func fetchCrawlRobots(ctx context.Context, pageURL string) crawl.Robots {
+	return analyzer.FetchRobots(ctx, slog.Default(), pageURL, analysisOptions)
+}
+
+// apiCrawlRequest is the JSON body accepted by POST /api/v1/crawls. It gives either the URL
+// to start crawling from or the sitemap whose pages to audit.
+type apiCrawlRequest struct {
+	URL     string `json:"url,omitempty"`
+	Sitemap string `json:"sitemap,omitempty"`
+	// MaxPages bounds the pages analyzed; it defaults to, and may not exceed, -crawl-max-pages.
+	MaxPages int `json:"max_pages,omitempty"`
+	// MaxDepth bounds the links followed from the start page; 0 means no bound.
+	MaxDepth int `json:"max_depth,omitempty"`
+	// Scope is "host" (the default) or "domain", see crawl.Options.Scope.
+	Scope string `json:"scope,omitempty"`
+	// Include and Exclude are regular expressions matched against the URLs found.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// compilePatterns compiles the patterns given in field, adding a problem to errs for each
+// that is not a valid regular expression.
+func compilePatterns(errs *fieldErrors, field string, patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs.add(field+"."+strconv.Itoa(i), apiCodeInvalidPattern, "is invalid: "+err.Error())
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// apiCrawlsResponse is the body returned by GET /api/v1/crawls.
+type apiCrawlsResponse struct {
+	XMLName xml.Name      `json:"-" xml:"crawls"`
+	Crawls  []crawl.Crawl `json:"crawls" xml:"crawl"`
+}
+
+// handleAPICrawls lists the crawls (GET) or starts a new one (POST).
+func handleAPICrawls(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAPIResponse(w, r, http.StatusOK, apiCrawlsResponse{Crawls: crawls.List()})
+	case http.MethodPost:
+		var body apiCrawlRequest
+		if !decodeJSONBody(w, r, &body) {
+			return
+		}
+		var invalid fieldErrors
+		target := body.URL
+		switch {
+		case body.Sitemap == "":
+			checkPageURL(&invalid, "url", body.URL)
+		case body.URL != "":
+			invalid.add("sitemap", apiCodeInvalidURL, "cannot be given together with url")
+		default:
+			target = body.Sitemap
+			checkPageURL(&invalid, "sitemap", body.Sitemap)
+		}
+		if body.MaxPages < 0 || body.MaxPages > crawlMaxPages {
+			invalid.add("max_pages", apiCodeInvalidMaxPages, "must be between 1 and "+strconv.Itoa(crawlMaxPages))
+		}
+		if body.MaxDepth < 0 {
+			invalid.add("max_depth", apiCodeInvalidMaxDepth, "must not be negative")
+		}
+		if body.Scope != "" && body.Scope != crawl.ScopeHost && body.Scope != crawl.ScopeDomain {
+			invalid.add("scope", apiCodeInvalidScope, `must be "host" or "domain"`)
+		}
+		include := compilePatterns(&invalid, "include", body.Include)
+		exclude := compilePatterns(&invalid, "exclude", body.Exclude)
+		if len(invalid) > 0 {
+			writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(target))
+			return
+		}
+		if body.MaxPages == 0 {
+			body.MaxPages = crawlMaxPages
+		}
+		started, err := crawls.Start(target, crawl.Options{
+			MaxPages:     body.MaxPages,
+			MaxDepth:     body.MaxDepth,
+			Scope:        body.Scope,
+			Include:      include,
+			Exclude:      exclude,
+			Sitemap:      body.Sitemap != "",
+			IgnoreRobots: crawlIgnoreRobots,
+		})
+		if errors.Is(err, crawl.ErrTooManyCrawls) {
+			writeAPIResponse(w, r, http.StatusConflict, apiError{Error: err.Error(), Code: apiCodeTooManyCrawls, URL: target})
+			return
+		} else if err != nil {
+			writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: err.Error(), Code: apiCodeInvalidURL, URL: target})
+			return
+		}
+		w.Header().Set("Location", apiPrefix+"/crawls/"+started.ID)
+		writeAPIResponse(w, r, http.StatusAccepted, started)
+	default:
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+	}
+}
+
+// handleAPICrawl returns (GET) or cancels and removes (DELETE) the crawl /api/v1/crawls/{id}.
+func handleAPICrawl(w http.ResponseWriter, r *http.Request) {
+	_, id, _ := strings.Cut(r.URL.Path, "/crawls/")
+	switch r.Method {
+	case http.MethodGet:
+		c, ok := crawls.Get(id)
+		if !ok {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "crawl not found", Code: apiCodeCrawlNotFound})
+			return
+		}
+		writeAPIResponse(w, r, http.StatusOK, c)
+	case http.MethodDelete:
+		if !crawls.Remove(id) {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "crawl not found", Code: apiCodeCrawlNotFound})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+	}
+}
+
+// lookupCrawl returns the crawl a GET of /api/v1/crawls/{id}/... is about. If there is
+// none, or the method is not GET, it answers the request and returns false.
+func lookupCrawl(w http.ResponseWriter, r *http.Request) (crawl.Crawl, bool) {
+	if r.Method != http.MethodGet {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return crawl.Crawl{}, false
+	}
+	c, ok := crawls.Get(r.PathValue("id"))
+	if !ok {
+		writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "crawl not found", Code: apiCodeCrawlNotFound})
+	}
+	return c, ok
+}
+
+// handleAPICrawlReport returns the site report of the crawl /api/v1/crawls/{id}/report.
+func handleAPICrawlReport(w http.ResponseWriter, r *http.Request) {
+	if c, ok := lookupCrawl(w, r); ok {
+		writeAPIResponse(w, r, http.StatusOK, crawl.Summarize(c))
+	}
+}
+
+// handleAPICrawlBrokenLinks returns the broken internal links of the crawl
+// /api/v1/crawls/{id}/broken-links with the pages referencing each.
+func handleAPICrawlBrokenLinks(w http.ResponseWriter, r *http.Request) {
+	if c, ok := lookupCrawl(w, r); ok {
+		writeAPIResponse(w, r, http.StatusOK, crawl.FindBrokenLinks(c))
+	}
+}
+
+// handleAPICrawlClickDepth returns the click depths of the pages of the crawl
+// /api/v1/crawls/{id}/click-depth.
+func handleAPICrawlClickDepth(w http.ResponseWriter, r *http.Request) {
+	if c, ok := lookupCrawl(w, r); ok {
+		writeAPIResponse(w, r, http.StatusOK, crawl.ClickDepths(c))
+	}
+}
+
+// handleAPICrawlSitemap serves a sitemap.xml of the indexable pages of the crawl
+// /api/v1/crawls/{id}/sitemap.xml as an attachment.
+func handleAPICrawlSitemap(w http.ResponseWriter, r *http.Request) {
+	c, ok := lookupCrawl(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="sitemap.xml"`)
+	if err := crawl.WriteSitemap(w, c); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write sitemap", "error", err)
+	}
+}
+
+// handleAPICrawlGraph returns the internal link graph of the crawl
+// /api/v1/crawls/{id}/graph, in the DOT language with ?format=dot.
+func handleAPICrawlGraph(w http.ResponseWriter, r *http.Request) {
+	c, ok := lookupCrawl(w, r)
+	if !ok {
+		return
+	}
+	graph := crawl.BuildGraph(c)
+	if r.URL.Query().Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		if err := crawl.WriteDOT(w, graph); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write DOT graph", "error", err)
+		}
+		return
+	}
+	writeAPIResponse(w, r, http.StatusOK, graph)
+}
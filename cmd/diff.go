@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"web-analyzer/internal/store"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Error codes returned by GET /api/v1/diff, in addition to the apiCode constants of /api/v1/analyze.
+const (
+	apiCodeMissingID      = "missing_id"
+	apiCodeResultNotFound = "result_not_found"
+	apiCodeURLMismatch    = "url_mismatch"
+	apiCodeStoreError     = "store_error"
+)
+
+// errURLMismatch is returned by loadDiff when the two results are of different pages.
+var errURLMismatch = errors.New("the two analyses are of different URLs")
+
+// apiDiffResponse is the body returned by GET /api/v1/diff.
+type apiDiffResponse struct {
+	XMLName xml.Name             `json:"-" xml:"comparison"`
+	URL     string               `json:"url" xml:"url,attr"`
+	Diff    *analyzer.ResultDiff `json:"diff"`
+}
+
+var diffTmpl *template.Template // parsed by loadTemplates
+
+type diffData struct {
+	URL      string
+	From, To *analyzer.AnalysisResult
+	Diff     *analyzer.ResultDiff
+	Error    string
+}
+
+// loadDiff compares the stored results fromID and toID, which must be analyses of the same URL.
+func loadDiff(ctx context.Context, fromID, toID string) (diffData, error) {
+	from, err := savedResults.Get(ctx, fromID)
+	if err != nil {
+		return diffData{}, err
+	}
+	to, err := savedResults.Get(ctx, toID)
+	if err != nil {
+		return diffData{}, err
+	}
+	if from.URL != to.URL {
+		return diffData{}, errURLMismatch
+	}
+	return diffData{URL: to.URL, From: from.Result, To: to.Result, Diff: analyzer.DiffResults(from.Result, to.Result)}, nil
+}
+
+// handleAPIDiff serves GET /api/v1/diff?from={id}&to={id}, comparing two stored analyses of
+// the same URL.
+func handleAPIDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+	fromID, toID := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if fromID == "" || toID == "" {
+		writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: "from and to result IDs are required", Code: apiCodeMissingID})
+		return
+	}
+
+	data, err := loadDiff(r.Context(), fromID, toID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "result not found or expired", Code: apiCodeResultNotFound})
+	case errors.Is(err, errURLMismatch):
+		writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: err.Error(), Code: apiCodeURLMismatch})
+	case err != nil:
+		slog.ErrorContext(r.Context(), "Failed to load results for diff", "error", err)
+		writeAPIResponse(w, r, http.StatusInternalServerError, apiError{Error: "results could not be loaded", Code: apiCodeStoreError})
+	default:
+		writeAPIResponse(w, r, http.StatusOK, apiDiffResponse{URL: data.URL, Diff: data.Diff})
+	}
+}
+
+// handleDiff serves GET /diff?from={id}&to={id}, the UI view of handleAPIDiff.
+func handleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	data, err := loadDiff(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		data.Error = "One of the results is no longer available."
+	case errors.Is(err, errURLMismatch):
+		w.WriteHeader(http.StatusBadRequest)
+		data.Error = "Only analyses of the same URL can be compared."
+	case err != nil:
+		serverError(w, err)
+		return
+	}
+
+	if err := diffTmpl.Execute(w, data); err != nil {
+		serverError(w, err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"web-analyzer/internal/store"
+	"web-analyzer/pkg/analyzer"
+)
+
+// reportTmpl renders a result as a standalone HTML page that needs no server to view.
+var reportTmpl *template.Template // parsed by loadTemplates
+
+// handleDownload serves a saved result as an attachment: GET /download/{id}.json as JSON,
+// GET /download/{id}.csv as its link results in CSV and GET /download/{id}.html as a
+// standalone HTML report.
+func handleDownload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/download/")
+	ext := path.Ext(name)
+	if ext != ".json" && ext != ".csv" && ext != ".html" {
+		http.NotFound(w, r)
+		return
+	}
+	entry, err := savedResults.Get(r.Context(), strings.TrimSuffix(name, ext))
+	if errors.Is(err, store.ErrNotFound) {
+		clientError(w, http.StatusNotFound, "This result is no longer available. Analyze the page again to download it.")
+		return
+	}
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	var body bytes.Buffer
+	switch ext {
+	case ".json":
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(&body)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entry.Result); err != nil {
+			serverError(w, err)
+			return
+		}
+	case ".csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if err := analyzer.WriteLinksCSV(&body, entry.Result); err != nil {
+			serverError(w, err)
+			return
+		}
+	case ".html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := reportTmpl.Execute(&body, TemplateData{URL: entry.URL, Results: entry.Result}); err != nil {
+			serverError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", downloadFilename(entry, ext)))
+	w.Write(body.Bytes())
+}
+
+// downloadFilename names a download after the analyzed host and time, e.g.
+// "analysis-example.com-20250102-150405.json".
+func downloadFilename(entry store.Record, ext string) string {
+	host := "page"
+	if u, err := url.Parse(entry.URL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	host = strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, host)
+	return fmt.Sprintf("analysis-%s-%s%s", host, entry.Result.AnalyzedAt.Format("20060102-150405"), ext)
+}
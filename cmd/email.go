@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"web-analyzer/internal/store"
+	"web-analyzer/pkg/analyzer"
+)
+
+// smtpTimeout bounds sending one email, from dialing to the end of the message.
+const smtpTimeout = 30 * time.Second
+
+var (
+	errEmailDisabled   = errors.New("email delivery is not configured on this server")
+	errInvalidEmail    = errors.New("invalid email address")
+	errEmailNotAllowed = errors.New("reports may not be emailed to this domain")
+)
+
+// mailer sends HTML reports by email. A mailer without an address is disabled.
+type mailer struct {
+	// addr is the SMTP server as host:port.
+	addr     string
+	username string
+	password string
+	from     string
+	// allowedDomains restricts recipients to these domains and their subdomains; empty
+	// allows any recipient.
+	allowedDomains []string
+}
+
+// reportMailer is configured from the -smtp-* flags.
+var reportMailer mailer
+
+func (m *mailer) enabled() bool {
+	return m.addr != ""
+}
+
+// checkRecipient parses to and checks that reports may be sent to it.
+func (m *mailer) checkRecipient(to string) (*mail.Address, error) {
+	if !m.enabled() {
+		return nil, errEmailDisabled
+	}
+	addr, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, errInvalidEmail
+	}
+	if len(m.allowedDomains) == 0 {
+		return addr, nil
+	}
+	_, domain, _ := strings.Cut(strings.ToLower(addr.Address), "@")
+	for _, allowed := range m.allowedDomains {
+		allowed = strings.ToLower(allowed)
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return addr, nil
+		}
+	}
+	return nil, errEmailNotAllowed
+}
+
+// sendReport emails the HTML report of the analysis of pageURL to the recipient to.
+func (m *mailer) sendReport(to, pageURL string, result *analyzer.AnalysisResult) error {
+	recipient, err := m.checkRecipient(to)
+	if err != nil {
+		return err
+	}
+
+	var report bytes.Buffer
+	if err := reportTmpl.Execute(&report, TemplateData{URL: pageURL, Results: result}); err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Web analysis of %s: grade %s (%d/100)", pageURL, result.Grade, result.Score)
+	if result.Classification != nil {
+		subject = fmt.Sprintf("Web analysis of %s: not scored (%s)", pageURL, result.Classification.Kind)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&msg)
+	qp.Write(report.Bytes())
+	qp.Close()
+
+	return m.send(recipient.Address, msg.Bytes())
+}
+
+// send delivers msg to the recipient to, upgrading to TLS when the server supports it.
+func (m *mailer) send(to string, msg []byte) error {
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", m.addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailReportInBackground emails the report without holding up the caller, logging failures
+// with the request ID of ctx.
+func emailReportInBackground(ctx context.Context, to, pageURL string, result *analyzer.AnalysisResult) {
+	go func() {
+		if err := reportMailer.sendReport(to, pageURL, result); err != nil {
+			slog.WarnContext(ctx, "Failed to email report", "url", pageURL, "id", result.ID, "error", err)
+			return
+		}
+		slog.InfoContext(ctx, "Report emailed", "url", pageURL, "id", result.ID)
+	}()
+}
+
+// handleEmail serves POST /email/{id}, emailing the HTML report of a saved result to the
+// address in the "to" form field and showing the result again with the outcome.
+func handleEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if !parseForm(w, r) {
+		return
+	}
+	rec, err := savedResults.Get(r.Context(), strings.TrimPrefix(r.URL.Path, "/email/"))
+	if errors.Is(err, store.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		renderTemplate(w, TemplateData{Error: "This result is no longer available. Analyze the page again to email it."})
+		return
+	}
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+
+	data := TemplateData{URL: rec.URL, Results: rec.Result, Permalink: true}
+	to := r.FormValue("to")
+	if err := reportMailer.sendReport(to, rec.URL, rec.Result); err != nil {
+		slog.WarnContext(r.Context(), "Failed to email report", "url", rec.URL, "id", rec.Result.ID, "error", err)
+		data.Error = emailErrorMessage(err)
+	} else {
+		data.Notice = "The report was emailed to " + to + "."
+	}
+	renderTemplate(w, data)
+}
+
+// emailErrorMessage turns an email delivery error into a message safe to show to users.
+func emailErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, errEmailDisabled):
+		return "Email delivery is not configured on this server."
+	case errors.Is(err, errInvalidEmail):
+		return "Enter a valid email address."
+	case errors.Is(err, errEmailNotAllowed):
+		return "Reports cannot be emailed to this address."
+	default:
+		return "The report could not be emailed. Try again later."
+	}
+}
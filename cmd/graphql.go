@@ -0,0 +1,689 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-analyzer/internal/graphql"
+	"web-analyzer/internal/store"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Error codes returned in the extensions of GraphQL errors, besides the apiCode constants of
+// failed analyses.
+const (
+	apiCodeMissingQuery    = "missing_query"
+	apiCodeTooManyAnalyses = "too_many_analyses"
+)
+
+// graphQLAnalysis is the value of an Analysis in GraphQL responses.
+type graphQLAnalysis struct {
+	*analyzer.AnalysisResult
+	Cached bool
+}
+
+// graphQLLinks is the value of Links: the counts, and the outcomes of the links for the lists.
+type graphQLLinks struct {
+	analyzer.LinkSummary
+	results []analyzer.LinkResult
+}
+
+// graphQLClient is the caller of a GraphQL request, for the rate limits of the analyses it
+// starts.
+type graphQLClient struct {
+	apiKey, ip string
+	analyses   int
+}
+
+type graphQLClientKey struct{}
+
+var graphQLSchema = mustGraphQLSchema()
+
+func mustGraphQLSchema() *graphql.Schema {
+	nonNullString := graphql.NewNonNull(graphql.String)
+	nonNullInt := graphql.NewNonNull(graphql.Int)
+
+	link := &graphql.Object{
+		Name:        "Link",
+		Description: "A link, iframe or stylesheet found on the page, and the outcome of checking it.",
+		Fields: []*graphql.Field{
+			{Name: "url", Type: nonNullString},
+			{Name: "type", Type: nonNullString, Description: "internal or external"},
+			{Name: "kind", Type: nonNullString, Description: "link, iframe, css or media"},
+			{Name: "anchorText", Type: graphql.String, Resolve: omitZero(func(l analyzer.LinkResult) string { return l.AnchorText })},
+			{Name: "status", Type: nonNullString, Description: "ok, inaccessible or not_checked"},
+			{Name: "statusCode", Type: graphql.Int, Description: "The last HTTP status code received, if any.", Resolve: omitZero(func(l analyzer.LinkResult) int { return l.StatusCode })},
+			{Name: "error", Type: graphql.String, Description: "Why the link is inaccessible, or why it was not checked.", Resolve: omitZero(func(l analyzer.LinkResult) string { return l.Error })},
+			{
+				Name:        "redirects",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The URLs the link redirected to, in order.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(analyzer.LinkResult).Redirects...), nil
+				},
+			},
+		},
+	}
+	linkList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(link)))
+
+	links := &graphql.Object{
+		Name: "Links",
+		Fields: []*graphql.Field{
+			{Name: "internalCount", Type: nonNullInt},
+			{Name: "externalCount", Type: nonNullInt},
+			{Name: "inaccessibleCount", Type: nonNullInt},
+			{
+				Name:        "notCheckedCount",
+				Type:        nonNullInt,
+				Description: "The number of links left unchecked, for whatever reason.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					total := 0
+					for _, n := range p.Source.(graphQLLinks).NotCheckedCounts {
+						total += n
+					}
+					return total, nil
+				},
+			},
+			{Name: "brokenAnchorCount", Type: nonNullInt},
+			{Name: "internalIframeCount", Type: nonNullInt},
+			{Name: "externalIframeCount", Type: nonNullInt},
+			{Name: "cssResourceCount", Type: nonNullInt},
+			{Name: "mediaSourceCount", Type: nonNullInt},
+			{Name: "all", Type: linkList, Resolve: linksWithStatus("")},
+			{Name: "inaccessible", Type: linkList, Resolve: linksWithStatus(analyzer.LinkStatusInaccessible)},
+			{Name: "notChecked", Type: linkList, Resolve: linksWithStatus(analyzer.LinkStatusNotChecked)},
+		},
+	}
+
+	heading := &graphql.Object{
+		Name: "Heading",
+		Fields: []*graphql.Field{
+			{Name: "level", Type: nonNullInt, Description: "1 for h1 through 6 for h6"},
+			{Name: "count", Type: nonNullInt},
+		},
+	}
+
+	finding := &graphql.Object{
+		Name: "SecurityFinding",
+		Fields: []*graphql.Field{
+			{Name: "ruleId", Type: nonNullString},
+			{Name: "severity", Type: nonNullString, Description: "error, warning or note"},
+			{Name: "message", Type: nonNullString},
+			{Name: "url", Type: graphql.String, Description: "The offending resource, for findings about a specific link.", Resolve: omitZero(func(f analyzer.SecurityFinding) string { return f.URL })},
+		},
+	}
+
+	checkError := &graphql.Object{
+		Name:        "CheckError",
+		Description: "A check that failed, leaving its part of the result empty.",
+		Fields: []*graphql.Field{
+			{Name: "check", Type: nonNullString},
+			{Name: "message", Type: nonNullString},
+		},
+	}
+
+	pagination := &graphql.Object{
+		Name:        "Pagination",
+		Description: "The links of a page to its neighbours in a paginated series.",
+		Fields: []*graphql.Field{
+			{Name: "next", Type: graphql.String, Resolve: omitZero(func(p *analyzer.Pagination) string { return p.Next })},
+			{Name: "prev", Type: graphql.String, Resolve: omitZero(func(p *analyzer.Pagination) string { return p.Prev })},
+		},
+	}
+
+	richResult := &graphql.Object{
+		Name:        "RichResult",
+		Description: "A structured data item of the page with a Google rich result, and whether it is eligible for it.",
+		Fields: []*graphql.Field{
+			{Name: "type", Type: nonNullString, Description: "The schema.org type of the item."},
+			{Name: "enhancement", Type: nonNullString, Description: "article, faq, how_to or product_snippet"},
+			{Name: "eligible", Type: graphql.NewNonNull(graphql.Boolean)},
+			{
+				Name:        "missing",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The required properties the item lacks.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(analyzer.RichResult).Missing...), nil
+				},
+			},
+		},
+	}
+
+	spelling := &graphql.Object{
+		Name:        "Spelling",
+		Description: "The likely misspellings in the visible text of the page.",
+		Fields: []*graphql.Field{
+			{Name: "language", Type: nonNullString, Description: "The language of the dictionary the page was checked with."},
+			{
+				Name: "misspellings",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(&graphql.Object{
+					Name: "Misspelling",
+					Fields: []*graphql.Field{
+						{Name: "word", Type: nonNullString},
+						{Name: "count", Type: nonNullInt},
+						{Name: "context", Type: nonNullString, Description: "The text around the first appearance of the word."},
+					},
+				}))),
+			},
+		},
+	}
+
+	placeholder := &graphql.Object{
+		Name:        "Placeholder",
+		Description: "Placeholder text left in the visible text of the page.",
+		Fields: []*graphql.Field{
+			{Name: "kind", Type: nonNullString, Description: "lorem_ipsum, todo, coming_soon or cms_default"},
+			{Name: "text", Type: nonNullString, Description: "The placeholder as it first appears."},
+			{Name: "count", Type: nonNullInt},
+			{Name: "context", Type: nonNullString, Description: "The text around the first appearance of the placeholder."},
+		},
+	}
+
+	classification := &graphql.Object{
+		Name:        "Classification",
+		Description: "Marks a parking page or a web server's welcome page, which is not scored and whose links are not checked.",
+		Fields: []*graphql.Field{
+			{Name: "kind", Type: nonNullString, Description: "parked or server_default"},
+			{Name: "signature", Type: nonNullString, Description: "What the page was recognized as, e.g. Sedo or nginx."},
+		},
+	}
+
+	noscript := &graphql.Object{
+		Name:        "Noscript",
+		Description: "The <noscript> blocks of the page, what crawlers and visitors without JavaScript see in place of its scripts.",
+		Fields: []*graphql.Field{
+			{Name: "count", Type: nonNullInt},
+			{Name: "bytes", Type: nonNullInt, Description: "The size of the markup of the blocks."},
+			{Name: "links", Type: nonNullInt, Description: "The links and iframes in the blocks, which are part of the page's links."},
+			{
+				Name:        "images",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The images in the blocks, as absolute URLs.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(*analyzer.Noscript).Images...), nil
+				},
+			},
+		},
+	}
+
+	media := &graphql.Object{
+		Name:        "Media",
+		Description: "The video and audio inventory of the page.",
+		Fields: []*graphql.Field{
+			{Name: "videos", Type: nonNullInt},
+			{Name: "audios", Type: nonNullInt},
+			{
+				Name:        "sources",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The files and posters of the videos and audios, checked along with the links.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(*analyzer.Media).Sources...), nil
+				},
+			},
+			{
+				Name: "embeds",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(&graphql.Object{
+					Name:        "MediaEmbed",
+					Description: "The embedded player of a video or audio service.",
+					Fields: []*graphql.Field{
+						{Name: "provider", Type: nonNullString, Description: "youtube, vimeo or spotify"},
+						{Name: "url", Type: nonNullString},
+					},
+				}))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.MediaEmbed{}, p.Source.(*analyzer.Media).Embeds...), nil
+				},
+			},
+		},
+	}
+
+	customElement := &graphql.Object{
+		Name:        "CustomElement",
+		Description: "A custom element of the page, a web component rendered by a script.",
+		Fields: []*graphql.Field{
+			{Name: "name", Type: nonNullString},
+			{Name: "count", Type: nonNullInt, Description: "The number of elements in the markup; 0 if an inline script defines it but only creates it from scripts."},
+			{Name: "empty", Type: nonNullInt, Description: "The elements without content of their own in the markup."},
+			{Name: "defined", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether an inline script of the page defines the element."},
+		},
+	}
+
+	iframe := &graphql.Object{
+		Name:        "Iframe",
+		Description: "An iframe of the page and the attributes restricting what it may do.",
+		Fields: []*graphql.Field{
+			{Name: "url", Type: nonNullString},
+			{Name: "thirdParty", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the iframe is on another host than the page."},
+			{Name: "sandboxed", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the iframe has a sandbox attribute."},
+			{Name: "sandbox", Type: nonNullString, Description: "The restrictions the sandbox attribute lifts; empty if it lifts none or the iframe is not sandboxed."},
+			{Name: "allow", Type: nonNullString, Description: "The iframe's permissions policy; empty if it has none."},
+			{Name: "referrerPolicy", Type: nonNullString, Description: "The referrer policy of the iframe's requests; empty if it has none."},
+		},
+	}
+
+	mxRecord := &graphql.Object{
+		Name:        "MXRecord",
+		Description: "A mail server of a host.",
+		Fields: []*graphql.Field{
+			{Name: "host", Type: nonNullString},
+			{Name: "preference", Type: nonNullInt, Description: "Lower values are preferred."},
+		},
+	}
+
+	dnsRecords := &graphql.Object{
+		Name:        "DNSRecords",
+		Description: "The DNS records of the analyzed host.",
+		Fields: []*graphql.Field{
+			{Name: "cname", Type: nonNullString, Description: "The canonical name the host is an alias of; empty if it is not an alias."},
+			{
+				Name:        "a",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The IPv4 addresses of the host.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(*analyzer.DNSRecords).A...), nil
+				},
+			},
+			{
+				Name:        "aaaa",
+				Type:        graphql.NewNonNull(graphql.NewList(nonNullString)),
+				Description: "The IPv6 addresses of the host.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]string{}, p.Source.(*analyzer.DNSRecords).AAAA...), nil
+				},
+			},
+			{
+				Name:        "mx",
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(mxRecord))),
+				Description: "The mail servers of the host, most preferred first.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.MXRecord{}, p.Source.(*analyzer.DNSRecords).MX...), nil
+				},
+			},
+			{Name: "spf", Type: nonNullString, Description: "The host's SPF record; empty if it has none."},
+			{Name: "dmarc", Type: nonNullString, Description: "The DMARC record of the host's _dmarc subdomain; empty if it has none."},
+		},
+	}
+
+	hostingIP := &graphql.Object{
+		Name:        "HostingIP",
+		Description: "An address the analyzed host resolves to and the network it belongs to.",
+		Fields: []*graphql.Field{
+			{Name: "ip", Type: nonNullString},
+			{Name: "asn", Type: graphql.Int, Description: "The number of the AS announcing the address; null without an IP database or if it does not have the address.", Resolve: omitZero(func(ip analyzer.HostingIP) int { return ip.ASN })},
+			{Name: "organization", Type: graphql.String, Description: "The name the AS is registered under.", Resolve: omitZero(func(ip analyzer.HostingIP) string { return ip.Organization })},
+			{Name: "country", Type: graphql.String, Description: "The ISO 3166 country code of the AS.", Resolve: omitZero(func(ip analyzer.HostingIP) string { return ip.Country })},
+		},
+	}
+
+	registrationTime := func(get func(*analyzer.DomainRegistration) *time.Time) func(context.Context, graphql.ResolveParams) (any, error) {
+		return func(_ context.Context, p graphql.ResolveParams) (any, error) {
+			if t := get(p.Source.(*analyzer.DomainRegistration)); t != nil {
+				return t.Format(time.RFC3339), nil
+			}
+			return nil, nil
+		}
+	}
+	registration := &graphql.Object{
+		Name:        "DomainRegistration",
+		Description: "The registration of the registrable domain of the analyzed host, as published over RDAP.",
+		Fields: []*graphql.Field{
+			{Name: "domain", Type: nonNullString, Description: "The registrable domain, e.g. example.co.uk for www.example.co.uk."},
+			{
+				Name:        "registered",
+				Type:        graphql.String,
+				Description: "When the domain was registered, in RFC 3339 format; null if the registry does not publish it.",
+				Resolve:     registrationTime(func(r *analyzer.DomainRegistration) *time.Time { return r.Registered }),
+			},
+			{
+				Name:        "expires",
+				Type:        graphql.String,
+				Description: "When the registration expires, in RFC 3339 format; null if the registry does not publish it.",
+				Resolve:     registrationTime(func(r *analyzer.DomainRegistration) *time.Time { return r.Expires }),
+			},
+		},
+	}
+
+	archive := &graphql.Object{
+		Name:        "ArchiveSnapshot",
+		Description: "A snapshot of the analyzed page in the Wayback Machine.",
+		Fields: []*graphql.Field{
+			{Name: "url", Type: nonNullString, Description: "The snapshot's page in the Wayback Machine."},
+			{
+				Name:        "timestamp",
+				Type:        nonNullString,
+				Description: "When the snapshot was taken, in RFC 3339 format.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return p.Source.(*analyzer.ArchiveSnapshot).Timestamp.Format(time.RFC3339), nil
+				},
+			},
+			{Name: "analyzed", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the analysis is of the snapshot rather than of the live page."},
+		},
+	}
+
+	analysis := &graphql.Object{
+		Name:        "Analysis",
+		Description: "The analysis of a page. Only the checks needed for the selected fields run, except that id, score and grade need all of them.",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: graphql.NewNonNull(graphql.ID), Description: "Identifies the result for the result query and permalinks."},
+			{Name: "schemaVersion", Type: nonNullString},
+			{Name: "host", Type: nonNullString},
+			{Name: "hostUnicode", Type: nonNullString},
+			{Name: "htmlVersion", Type: nonNullString},
+			{Name: "title", Type: nonNullString},
+			{Name: "description", Type: nonNullString, Description: "The content of the page's meta description; empty if it has none."},
+			{Name: "noindex", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the page asks search engines not to index it."},
+			{Name: "wordCount", Type: graphql.NewNonNull(graphql.Int), Description: "The number of words of the page's visible text."},
+			{Name: "pageBytes", Type: graphql.NewNonNull(graphql.Int), Description: "The size of the page's HTML after decompression."},
+			{Name: "pagination", Type: pagination, Description: "The page's rel=\"next\" and rel=\"prev\" links; null if it has none."},
+			{
+				Name: "richResults",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(richResult))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.RichResult{}, p.Source.(graphQLAnalysis).RichResults...), nil
+				},
+			},
+			{Name: "spelling", Type: spelling, Description: "Null unless the server has a spellcheck dictionary for the page's language."},
+			{
+				Name: "customElements",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(customElement))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.CustomElement{}, p.Source.(graphQLAnalysis).CustomElements...), nil
+				},
+			},
+			{
+				Name: "iframes",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(iframe))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.Iframe{}, p.Source.(graphQLAnalysis).Iframes...), nil
+				},
+			},
+			{
+				Name: "hosting",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(hostingIP))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.HostingIP{}, p.Source.(graphQLAnalysis).Hosting...), nil
+				},
+			},
+			{Name: "registration", Type: registration, Description: "Null unless the server has an RDAP service and it knows the host's domain."},
+			{Name: "archive", Type: archive, Description: "Null unless the server has a Wayback Machine URL and it has archived the page."},
+			{Name: "dns", Type: dnsRecords, Description: "Null if the host is an IP address or has no DNS records."},
+			{Name: "media", Type: media, Description: "Null if the page has no videos, audios or embedded players."},
+			{Name: "noscript", Type: noscript, Description: "Null if the page has no <noscript> blocks."},
+			{Name: "classification", Type: classification, Description: "Null unless the page is a parking page or a web server's welcome page."},
+			{
+				Name: "placeholders",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(placeholder))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.Placeholder{}, p.Source.(graphQLAnalysis).Placeholders...), nil
+				},
+			},
+			{Name: "contentFingerprint", Type: graphql.String, Description: "A simhash of the page's visible text, as 16 hex digits; null if it has none.", Resolve: omitZero(func(r graphQLAnalysis) string { return r.ContentFingerprint })},
+			{
+				Name: "headings",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(heading))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					headings := []map[string]any{}
+					for level := 1; level <= 6; level++ {
+						if n := p.Source.(graphQLAnalysis).Headings["h"+strconv.Itoa(level)]; n > 0 {
+							headings = append(headings, map[string]any{"level": level, "count": n})
+						}
+					}
+					return headings, nil
+				},
+			},
+			{
+				Name: "links",
+				Type: graphql.NewNonNull(links),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					result := p.Source.(graphQLAnalysis)
+					return graphQLLinks{LinkSummary: result.Links, results: result.LinkResults}, nil
+				},
+			},
+			{Name: "containsLoginForm", Type: graphql.NewNonNull(graphql.Boolean)},
+			{
+				Name:        "analyzedAt",
+				Type:        nonNullString,
+				Description: "When the page was analyzed, in RFC 3339 format.",
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return p.Source.(graphQLAnalysis).AnalyzedAt.Format(time.RFC3339), nil
+				},
+			},
+			{
+				Name: "securityFindings",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(finding))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return append([]analyzer.SecurityFinding{}, p.Source.(graphQLAnalysis).SecurityFindings...), nil
+				},
+			},
+			{Name: "score", Type: nonNullInt, Description: "Rates the page from 0 to 100."},
+			{Name: "grade", Type: nonNullString, Description: "The score as a letter from A to F; empty, with a score of 0, for a classified page."},
+			{
+				Name: "errors",
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(checkError))),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					errs := []map[string]any{}
+					for _, check := range slices.Sorted(maps.Keys(p.Source.(graphQLAnalysis).Errors)) {
+						errs = append(errs, map[string]any{"check": check, "message": p.Source.(graphQLAnalysis).Errors[check]})
+					}
+					return errs, nil
+				},
+			},
+			{Name: "cached", Type: graphql.NewNonNull(graphql.Boolean), Description: "Whether the result was served from the cache."},
+		},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name:        "analyze",
+				Type:        analysis,
+				Description: "Analyzes a page with the server-wide settings. A request may start at most one analysis.",
+				Args: []*graphql.Argument{
+					{Name: "url", Type: nonNullString},
+					{Name: "refresh", Type: graphql.Boolean, Description: "Skip any cached result.", Default: false},
+					{Name: "workers", Type: graphql.Int, Description: "Size of the link check worker pool."},
+				},
+				Resolve: resolveAnalyze,
+			},
+			{
+				Name:        "result",
+				Type:        analysis,
+				Description: "Looks up a stored analysis by ID; null if it does not exist or has expired.",
+				Args:        []*graphql.Argument{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     resolveResult,
+			},
+		},
+	}
+
+	schema, err := graphql.NewSchema(query)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// omitZero resolves a field of T with get, returning null for the zero value.
+func omitZero[T any, V comparable](get func(T) V) func(context.Context, graphql.ResolveParams) (any, error) {
+	return func(_ context.Context, p graphql.ResolveParams) (any, error) {
+		var zero V
+		if v := get(p.Source.(T)); v != zero {
+			return v, nil
+		}
+		return nil, nil
+	}
+}
+
+// linksWithStatus resolves a list of the links with the given status, or of all links for "".
+func linksWithStatus(status string) func(context.Context, graphql.ResolveParams) (any, error) {
+	return func(_ context.Context, p graphql.ResolveParams) (any, error) {
+		links := []analyzer.LinkResult{}
+		for _, link := range p.Source.(graphQLLinks).results {
+			if status == "" || link.Status == status {
+				links = append(links, link)
+			}
+		}
+		return links, nil
+	}
+}
+
+func resolveAnalyze(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	client, _ := ctx.Value(graphQLClientKey{}).(*graphQLClient)
+	if client == nil {
+		return nil, errors.New("no client")
+	}
+	client.analyses++
+	if client.analyses > 1 {
+		return nil, &graphql.Error{
+			Message:    "a request may start at most one analysis",
+			Extensions: map[string]any{"code": apiCodeTooManyAnalyses},
+		}
+	}
+
+	body := apiAnalyzeRequest{URL: p.Args["url"].(string)}
+	body.Refresh, _ = p.Args["refresh"].(bool)
+	body.Workers, _ = p.Args["workers"].(int)
+	req, invalid := body.analysisRequest()
+	if len(invalid) > 0 {
+		apiErr := invalid.apiError(body.URL)
+		return nil, &graphql.Error{Message: apiErr.Error, Extensions: map[string]any{"code": apiErr.Code}}
+	}
+	req.Options.Checks = graphQLChecks(p.Selection)
+
+	if rejection := admitAnalysis(ctx, client.apiKey, client.ip); rejection != nil {
+		extensions := map[string]any{"code": rejection.code}
+		if rejection.retryAfter > 0 {
+			extensions["retryAfter"] = ceilSeconds(rejection.retryAfter)
+		}
+		return nil, &graphql.Error{Message: rejection.message, Extensions: extensions}
+	}
+
+	result, cached, err := runAnalysis(ctx, slog.Default(), req)
+	if err != nil {
+		_, apiErr := apiAnalysisError(body.URL, err)
+		extensions := map[string]any{"code": apiErr.Code}
+		if apiErr.UpstreamStatus != 0 {
+			extensions["upstreamStatus"] = apiErr.UpstreamStatus
+		}
+		return nil, &graphql.Error{Message: apiErr.Error, Extensions: extensions}
+	}
+	return graphQLAnalysis{AnalysisResult: result, Cached: cached}, nil
+}
+
+func resolveResult(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	rec, err := savedResults.Get(ctx, p.Args["id"].(string))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return nil, nil
+	case err != nil:
+		slog.ErrorContext(ctx, "Failed to load stored result", "error", err)
+		return nil, &graphql.Error{Message: "the result store could not be read", Extensions: map[string]any{"code": apiCodeStoreError}}
+	}
+	return graphQLAnalysis{AnalysisResult: rec.Result}, nil
+}
+
+// graphQLChecks returns the analyzer checks needed for the fields sel selects from an
+// Analysis, or nil if they need every check.
+func graphQLChecks(sel graphql.Selection) []string {
+	if sel.Has("id") || sel.Has("score") || sel.Has("grade") {
+		return nil
+	}
+
+	checks := []string{}
+	need := func(names ...string) {
+		for _, name := range names {
+			if !slices.Contains(checks, name) {
+				checks = append(checks, name)
+			}
+		}
+	}
+	if sel.Has("htmlVersion") {
+		need(analyzer.CheckHTMLVersion)
+	}
+	if sel.Has("headings") {
+		need(analyzer.CheckHeadings)
+	}
+	if sel.Has("spelling") {
+		need(analyzer.CheckSpelling)
+	}
+	if sel.Has("media") {
+		need(analyzer.CheckMedia)
+	}
+	if sel.Has("registration") {
+		need(analyzer.CheckRegistration)
+	}
+	if sel.Has("archive") {
+		need(analyzer.CheckArchive)
+	}
+	if sel.Has("dns") || sel.Has("hosting") {
+		need(analyzer.CheckDNS)
+	}
+	if sel.Has("iframes") {
+		need(analyzer.CheckLinks)
+	}
+	if sel.Has("containsLoginForm") {
+		need(analyzer.CheckLoginForm)
+	}
+	if sel.Has("securityFindings") {
+		// Mixed content, unsandboxed iframes and insecure login forms are found among the links
+		// and forms, login forms on new domains with the registration, and blocklisted URLs
+		// among the page and its external links.
+		need(analyzer.CheckSecurity, analyzer.CheckLinks, analyzer.CheckCSSResources, analyzer.CheckLoginForm, analyzer.CheckMarkupValidation, analyzer.CheckRegistration, analyzer.CheckBlocklist)
+	}
+	for name, sub := range sel["links"] {
+		switch name {
+		case "cssResourceCount":
+			need(analyzer.CheckCSSResources)
+		case "mediaSourceCount":
+			need(analyzer.CheckMedia)
+		case "inaccessibleCount", "notCheckedCount", "inaccessible", "notChecked":
+			need(analyzer.CheckLinks, analyzer.CheckCSSResources, analyzer.CheckMedia, analyzer.CheckLinkStatus)
+		case "all":
+			need(analyzer.CheckLinks, analyzer.CheckCSSResources, analyzer.CheckMedia)
+			if sub.Has("status") || sub.Has("statusCode") || sub.Has("error") {
+				need(analyzer.CheckLinkStatus)
+			}
+		default:
+			need(analyzer.CheckLinks)
+		}
+	}
+	return checks
+}
+
+// handleGraphQL executes a GraphQL query POSTed as JSON. Analyses started by the query are
+// rate-limited like those of POST /api/v1/analyze; looking up stored results is not.
+func handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+
+	var req graphql.Request
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: "query is required", Code: apiCodeMissingQuery})
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphQLClientKey{}, &graphQLClient{apiKey: r.Header.Get(apiKeyHeader), ip: clientIP(r)})
+	writeJSON(w, http.StatusOK, graphQLSchema.Execute(ctx, req))
+}
+
+// handleGraphQLSchema serves the GraphQL schema in the schema definition language.
+func handleGraphQLSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(graphQLSchema.SDL()))
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"web-analyzer/pkg/analyzer"
+	"web-analyzer/pkg/analyzerpb"
+)
+
+// grpcErrorDomain is the domain of the errdetails.ErrorInfo attached to failed calls. Its
+// Reason is one of the apiCode constants, as returned by the JSON API.
+const grpcErrorDomain = "web-analyzer"
+
+// gRPC metadata keys, the lower-case forms of the HTTP headers of the JSON API.
+const (
+	grpcAPIKeyMetadata    = "x-api-key"
+	grpcRequestIDMetadata = "x-request-id"
+)
+
+// grpcServer serves the gRPC API when -grpc-addr is set; serve stops it on shutdown.
+var grpcServer *grpc.Server
+
+// newGRPCServer returns a gRPC server with the analyzer service, the standard health service
+// and server reflection, for tools like grpcurl. It serves over TLS if tlsConfig is set, and
+// with requireKey it refuses analyses without one of apiKeys.
+func newGRPCServer(tlsConfig *tls.Config, requireKey bool) *grpc.Server {
+	guard := grpcGuard{requireKey: requireKey}
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(guard.unary),
+		grpc.ChainStreamInterceptor(guard.stream),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig.Clone())))
+	}
+	srv := grpc.NewServer(opts...)
+	analyzerpb.RegisterAnalyzerServiceServer(srv, analyzerService{})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	return srv
+}
+
+// serveGRPC serves srv on lis until srv is stopped.
+func serveGRPC(srv *grpc.Server, lis net.Listener) {
+	if err := srv.Serve(lis); err != nil {
+		slog.Error("gRPC server failed", "error", err)
+	}
+}
+
+// stopGRPC stops srv once its calls in progress have finished, canceling those still
+// running when ctx is done.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("gRPC calls did not finish in time, canceling them")
+		srv.Stop()
+		<-done
+	}
+}
+
+// analyzerService implements the AnalyzerService of proto/webanalyzer/v1/analyzer.proto on
+// top of the same analyses, caches and settings as the JSON API.
+type analyzerService struct {
+	analyzerpb.UnimplementedAnalyzerServiceServer
+}
+
+func (analyzerService) Analyze(ctx context.Context, in *analyzerpb.AnalyzeRequest) (*analyzerpb.AnalyzeResponse, error) {
+	req, err := grpcAnalysisRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	result, cached, err := runAnalysis(ctx, slog.Default(), req)
+	if err != nil {
+		return nil, grpcAnalysisError(in.GetUrl(), err)
+	}
+	return &analyzerpb.AnalyzeResponse{Result: protoResult(result), Cached: cached}, nil
+}
+
+func (analyzerService) AnalyzeStream(in *analyzerpb.AnalyzeRequest, stream grpc.ServerStreamingServer[analyzerpb.AnalyzeEvent]) error {
+	req, err := grpcAnalysisRequest(in)
+	if err != nil {
+		return err
+	}
+
+	// Only the latest update is kept for the sender, so a slow client sees fewer updates
+	// instead of holding up the link checks. Options.Progress calls never overlap, so after
+	// draining the channel the send cannot block.
+	updates := make(chan analyzer.Progress, 1)
+	req.Options.Progress = func(p analyzer.Progress) {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- p
+	}
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for p := range updates {
+			// A failed send means the client is gone, which cancels the analysis too.
+			stream.Send(&analyzerpb.AnalyzeEvent{Event: &analyzerpb.AnalyzeEvent_Progress{Progress: protoProgress(p)}})
+		}
+	}()
+
+	result, cached, err := runAnalysis(stream.Context(), slog.Default(), req)
+	close(updates)
+	<-sent
+	if err != nil {
+		return grpcAnalysisError(in.GetUrl(), err)
+	}
+	return stream.Send(&analyzerpb.AnalyzeEvent{Event: &analyzerpb.AnalyzeEvent_Result{
+		Result: &analyzerpb.AnalyzeResponse{Result: protoResult(result), Cached: cached},
+	}})
+}
+
+// grpcAnalysisRequest checks in like a JSON API request and turns it into the analysis it asks
+// for, or returns an InvalidArgument error listing every invalid field.
+func grpcAnalysisRequest(in *analyzerpb.AnalyzeRequest) (analysisRequest, error) {
+	body := apiAnalyzeRequest{
+		URL:       in.GetUrl(),
+		Workers:   int(in.GetWorkers()),
+		Refresh:   in.GetRefresh(),
+		UserAgent: in.GetUserAgent(),
+		Headers:   in.GetHeaders(),
+		Proxy:     in.GetProxy(),
+		Cookie:    in.GetCookie(),
+		Cookies:   in.GetCookies(),
+	}
+	if auth := in.GetBasicAuth(); auth != nil {
+		body.BasicAuth = &apiBasicAuth{Username: auth.GetUsername(), Password: auth.GetPassword()}
+	}
+	req, invalid := body.analysisRequest()
+	if len(invalid) == 0 {
+		return req, nil
+	}
+
+	apiErr := invalid.apiError(body.URL)
+	violations := &errdetails.BadRequest{}
+	for _, fe := range invalid {
+		violations.FieldViolations = append(violations.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Field + " " + fe.Message,
+			Reason:      fe.Code,
+		})
+	}
+	return req, grpcError(codes.InvalidArgument, apiErr.Code, apiErr.Error, nil, violations)
+}
+
+// grpcCodes maps the HTTP statuses of the JSON API's errors to gRPC codes.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusUnprocessableEntity: codes.FailedPrecondition,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	statusClientClosedRequest:      codes.Canceled,
+	http.StatusBadGateway:          codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// grpcAnalysisError maps a failed analysis of pageURL to the status returned to gRPC clients,
+// following apiAnalysisError.
+func grpcAnalysisError(pageURL string, err error) error {
+	httpStatus, apiErr := apiAnalysisError(pageURL, err)
+	info := map[string]string{"url": pageURL}
+	if apiErr.UpstreamStatus != 0 {
+		info["upstream_status"] = strconv.Itoa(apiErr.UpstreamStatus)
+	}
+	return grpcError(grpcCodes[httpStatus], apiErr.Code, apiErr.Error, info)
+}
+
+// grpcError returns a status error with an ErrorInfo naming apiCode, followed by details.
+func grpcError(code codes.Code, apiCode, message string, info map[string]string, details ...protoadapt.MessageV1) error {
+	st := status.New(code, message)
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: apiCode, Domain: grpcErrorDomain, Metadata: info}}, details...)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// grpcGuard does for gRPC calls what the HTTP middleware does for the JSON API: it assigns
+// request IDs, checks API keys, applies the rate limits and logs each call.
+type grpcGuard struct {
+	requireKey bool
+}
+
+func (g grpcGuard) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx, err := g.admit(ctx, info.FullMethod)
+	var resp any
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	logGRPCCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func (g grpcGuard) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, err := g.admit(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, contextStream{ServerStream: ss, ctx: ctx})
+	}
+	logGRPCCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// admit gives the call a request ID and, for the analyzer service, whose every method starts
+// an analysis, checks the API key and applies the rate limits.
+func (g grpcGuard) admit(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := firstMetadata(md, grpcRequestIDMetadata)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	ctx = withRequestID(ctx, id)
+	grpc.SetHeader(ctx, metadata.Pairs(grpcRequestIDMetadata, id))
+
+	if !strings.HasPrefix(method, "/"+analyzerpb.AnalyzerService_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+	secret := firstMetadata(md, grpcAPIKeyMetadata)
+	if secret == "" && g.requireKey {
+		return ctx, grpcError(codes.Unauthenticated, apiCodeMissingAPIKey, "an API key is required in the "+grpcAPIKeyMetadata+" metadata", nil)
+	}
+	if rejection := admitAnalysis(ctx, secret, grpcClientIP(ctx)); rejection != nil {
+		var details []protoadapt.MessageV1
+		if rejection.retryAfter > 0 {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(rejection.retryAfter)})
+		}
+		return ctx, grpcError(grpcCodes[rejection.status], rejection.code, rejection.message, nil, details...)
+	}
+	return ctx, nil
+}
+
+// contextStream is a server stream whose handler sees ctx as the stream's context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcClientIP returns the address the call came from, like clientIP does for HTTP requests.
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// logGRPCCall logs a call once it has been served, like accessLogMiddleware does for HTTP.
+func logGRPCCall(ctx context.Context, method string, start time.Time, err error) {
+	slog.InfoContext(ctx, "gRPC call served",
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("client_ip", grpcClientIP(ctx)),
+	)
+}
+
+// grpcStages maps the analyzer's stages to their protobuf enum.
+var grpcStages = map[string]analyzerpb.Stage{
+	analyzer.StageFetching:      analyzerpb.Stage_STAGE_FETCHING,
+	analyzer.StageParsing:       analyzerpb.Stage_STAGE_PARSING,
+	analyzer.StageCheckingLinks: analyzerpb.Stage_STAGE_CHECKING_LINKS,
+}
+
+func protoProgress(p analyzer.Progress) *analyzerpb.Progress {
+	return &analyzerpb.Progress{
+		Stage:        grpcStages[p.Stage],
+		LinksChecked: int32(p.LinksChecked),
+		LinksTotal:   int32(p.LinksTotal),
+	}
+}
+
+// protoResult converts an analysis result to its protobuf message.
+func protoResult(r *analyzer.AnalysisResult) *analyzerpb.AnalysisResult {
+	out := &analyzerpb.AnalysisResult{
+		SchemaVersion: r.SchemaVersion,
+		Id:            r.ID,
+		Host:          r.Host,
+		HostUnicode:   r.HostUnicode,
+		HtmlVersion:   r.HTMLVersion,
+		Title:         r.Title,
+		Headings:      int32Map(r.Headings),
+		Links: &analyzerpb.LinkSummary{
+			InternalCount:       int32(r.Links.InternalCount),
+			ExternalCount:       int32(r.Links.ExternalCount),
+			InaccessibleCount:   int32(r.Links.InaccessibleCount),
+			NotCheckedCounts:    int32Map(r.Links.NotCheckedCounts),
+			BrokenAnchorCount:   int32(r.Links.BrokenAnchorCount),
+			SkippedCounts:       int32Map(r.Links.SkippedCounts),
+			InternalIframeCount: int32(r.Links.InternalIframeCount),
+			ExternalIframeCount: int32(r.Links.ExternalIframeCount),
+			CssResourceCount:    int32(r.Links.CSSResourceCount),
+		},
+		ContainsLoginForm: r.ContainsLoginForm,
+		AnalyzedAt:        timestamppb.New(r.AnalyzedAt),
+		Etag:              r.ETag,
+		LastModified:      r.LastModified,
+		Score:             int32(r.Score),
+		Grade:             r.Grade,
+		Errors:            r.Errors,
+	}
+	for _, link := range r.LinkResults {
+		out.LinkResults = append(out.LinkResults, &analyzerpb.LinkResult{
+			Url:        link.URL,
+			Type:       link.Type,
+			Kind:       link.Kind,
+			AnchorText: link.AnchorText,
+			Status:     link.Status,
+			StatusCode: int32(link.StatusCode),
+			Error:      link.Error,
+		})
+	}
+	for _, finding := range r.SecurityFindings {
+		out.SecurityFindings = append(out.SecurityFindings, &analyzerpb.SecurityFinding{
+			RuleId:   finding.RuleID,
+			Severity: finding.Severity,
+			Message:  finding.Message,
+			Url:      finding.URL,
+		})
+	}
+	return out
+}
+
+func int32Map(m map[string]int) map[string]int32 {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]int32, len(m))
+	for k, v := range m {
+		out[k] = int32(v)
+	}
+	return out
+}
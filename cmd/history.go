@@ -0,0 +1,53 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+
+	"web-analyzer/internal/store"
+)
+
+// historyLimit is the number of past analyses the history page lists.
+const historyLimit = 50
+
+var historyTmpl *template.Template // parsed by loadTemplates
+
+type historyData struct {
+	URL  string
+	Rows []historyRow
+}
+
+type historyRow struct {
+	store.Record
+	// PreviousID is the ID of the next older analysis, to compare this one with.
+	PreviousID string
+}
+
+// handleHistory serves GET /history?url=..., listing the stored analyses of url, newest
+// first, each linking to its permalink.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	data := historyData{URL: r.URL.Query().Get("url")}
+	if data.URL != "" {
+		records, err := savedResults.ListByURL(r.Context(), data.URL, historyLimit)
+		if err != nil {
+			serverError(w, err)
+			return
+		}
+		for i, rec := range records {
+			row := historyRow{Record: rec}
+			if i+1 < len(records) {
+				row.PreviousID = records[i+1].Result.ID
+			}
+			data.Rows = append(data.Rows, row)
+		}
+	}
+
+	if err := historyTmpl.Execute(w, data); err != nil {
+		serverError(w, err)
+	}
+}
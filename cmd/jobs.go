@@ -0,0 +1,278 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// Error codes returned by the /api/v1/jobs endpoints.
+const (
+	apiCodeTooManyJobs = "too_many_jobs"
+	apiCodeJobNotFound = "job_not_found"
+)
+
+// maxJobs bounds the analysis jobs kept by the server's jobStore; once it is reached, starting
+// a job forgets the oldest finished one.
+const maxJobs = 1000
+
+// errTooManyJobs is returned by jobStore.start when as many jobs as its limit are still running.
+var errTooManyJobs = errors.New("too many jobs in progress")
+
+// Values of apiJob.Status.
+const (
+	jobStatusRunning = "running"
+	jobStatusDone    = "done"
+	jobStatusFailed  = "failed"
+)
+
+// jobs runs the analyses started through POST /api/v1/jobs; main creates it.
+var jobs *jobStore
+
+// apiJob is an analysis run in the background, as returned by the /api/v1/jobs endpoints.
+// While it runs, Result holds what the completed checks found so far.
+type apiJob struct {
+	XMLName xml.Name `json:"-" xml:"job"`
+	ID      string   `json:"id" xml:"id,attr"`
+	URL     string   `json:"url" xml:"url"`
+	// Status is jobStatusRunning, jobStatusDone or jobStatusFailed.
+	Status string `json:"status" xml:"status"`
+	// Stage, LinksChecked and LinksTotal report how far a running analysis has got, see
+	// analyzer.Progress.
+	Stage        string `json:"stage,omitempty" xml:"stage,omitempty"`
+	LinksChecked int    `json:"links_checked,omitempty" xml:"links_checked,omitempty"`
+	LinksTotal   int    `json:"links_total,omitempty" xml:"links_total,omitempty"`
+	// Checks lists the status of each check started so far, by name. It is empty for a
+	// cached result, and for an analysis run by another instance of a shared queue, neither
+	// of which reports its checks as they complete.
+	Checks []apiJobCheck            `json:"checks,omitempty" xml:"checks>check,omitempty"`
+	Result *analyzer.AnalysisResult `json:"result,omitempty" xml:"result,omitempty"`
+	Cached bool                     `json:"cached,omitempty" xml:"cached,attr,omitempty"`
+	// Error says why a failed job's analysis failed.
+	Error      *apiError `json:"error,omitempty" xml:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at" xml:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero" xml:"finished_at,omitempty"`
+}
+
+// apiJobCheck is the status of one check of a job, one of the analyzer.CheckStatus constants.
+type apiJobCheck struct {
+	Name   string `json:"name" xml:"name,attr"`
+	Status string `json:"status" xml:"status,attr"`
+}
+
+// jobStore keeps the analysis jobs, running each in its own goroutine until Stop.
+type jobStore struct {
+	limit int
+	run   func(ctx context.Context, logger *slog.Logger, req analysisRequest) (*analyzer.AnalysisResult, bool, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*jobEntry
+}
+
+type jobEntry struct {
+	job    apiJob
+	cancel context.CancelFunc
+}
+
+// newJobStore returns a jobStore that analyzes with run and keeps at most limit jobs.
+func newJobStore(limit int, run func(ctx context.Context, logger *slog.Logger, req analysisRequest) (*analyzer.AnalysisResult, bool, error)) *jobStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobStore{limit: limit, run: run, ctx: ctx, cancel: cancel, entries: make(map[string]*jobEntry)}
+}
+
+// start runs req in the background and returns the job just started. ctx supplies the
+// request ID logged with the analysis, not its lifetime; deliver is called with the outcome.
+func (s *jobStore) start(ctx context.Context, req analysisRequest, deliver func(ctx context.Context, result *analyzer.AnalysisResult, cached bool, err error)) (apiJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.limit && !s.forgetOldestFinished() {
+		return apiJob{}, errTooManyJobs
+	}
+
+	jobCtx, cancel := context.WithCancel(withRequestID(s.ctx, requestIDFrom(ctx)))
+	id := newRequestID()
+	entry := &jobEntry{job: apiJob{ID: id, URL: req.URL, Status: jobStatusRunning, StartedAt: time.Now()}, cancel: cancel}
+	s.entries[id] = entry
+
+	req.Options.Progress = func(p analyzer.Progress) {
+		s.update(id, func(job *apiJob) {
+			job.Stage, job.LinksChecked, job.LinksTotal = p.Stage, p.LinksChecked, p.LinksTotal
+		})
+	}
+	req.Options.Partial = func(p analyzer.PartialResult) {
+		s.update(id, func(job *apiJob) {
+			job.Result, job.Checks = p.Result, jobChecks(p.Checks)
+		})
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		result, cached, err := s.run(jobCtx, slog.Default(), req)
+		s.update(id, func(job *apiJob) {
+			job.Stage, job.LinksChecked, job.LinksTotal = "", 0, 0
+			job.FinishedAt = time.Now()
+			if err != nil {
+				_, apiErr := apiAnalysisError(req.URL, err)
+				job.Status, job.Result, job.Error = jobStatusFailed, nil, &apiErr
+				return
+			}
+			job.Status, job.Result, job.Cached = jobStatusDone, result, cached
+			job.Checks = settleChecks(job.Checks, result)
+		})
+		deliver(jobCtx, result, cached, err)
+	}()
+	return entry.job, nil
+}
+
+// update applies fn to the job id, unless it was removed.
+func (s *jobStore) update(id string, fn func(*apiJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[id]; ok {
+		fn(&entry.job)
+	}
+}
+
+// forgetOldestFinished removes the finished job started first, reporting whether there was
+// one. s.mu must be held.
+func (s *jobStore) forgetOldestFinished() bool {
+	var oldest *jobEntry
+	for _, entry := range s.entries {
+		if entry.job.Status != jobStatusRunning && (oldest == nil || entry.job.StartedAt.Before(oldest.job.StartedAt)) {
+			oldest = entry
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(s.entries, oldest.job.ID)
+	return true
+}
+
+// get returns the job id as it stands.
+func (s *jobStore) get(id string) (apiJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		return apiJob{}, false
+	}
+	return entry.job, true
+}
+
+// remove cancels the job id if it is running and forgets it, reporting whether it existed.
+func (s *jobStore) remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if ok {
+		entry.cancel()
+		delete(s.entries, id)
+	}
+	return ok
+}
+
+// Stop cancels the running jobs and waits for them to return.
+func (s *jobStore) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// jobChecks lists the check statuses of checks by name.
+func jobChecks(checks map[string]string) []apiJobCheck {
+	list := make([]apiJobCheck, 0, len(checks))
+	for name, status := range checks {
+		list = append(list, apiJobCheck{Name: name, Status: status})
+	}
+	slices.SortFunc(list, func(a, b apiJobCheck) int { return cmp.Compare(a.Name, b.Name) })
+	return list
+}
+
+// settleChecks returns checks with those still running as the analysis completes with result
+// marked done, or failed if result has an error for them. checks is left alone, since copies
+// of the job handed out may share it.
+func settleChecks(checks []apiJobCheck, result *analyzer.AnalysisResult) []apiJobCheck {
+	settled := slices.Clone(checks)
+	for i, check := range settled {
+		if check.Status != analyzer.CheckStatusRunning {
+			continue
+		}
+		settled[i].Status = analyzer.CheckStatusDone
+		if _, failed := result.Errors[check.Name]; failed {
+			settled[i].Status = analyzer.CheckStatusFailed
+		}
+	}
+	return settled
+}
+
+// handleAPIJobs starts an analysis in the background and returns its job, which can be
+// polled for the results of the checks completed so far.
+func handleAPIJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+
+	var body apiAnalyzeRequest
+	if !decodeJSONBody(w, r, &body) {
+		return
+	}
+	req, invalid := body.analysisRequest()
+	body.checkDelivery(&invalid)
+	if len(invalid) > 0 {
+		writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(body.URL))
+		return
+	}
+
+	started, err := jobs.start(r.Context(), req, func(ctx context.Context, result *analyzer.AnalysisResult, cached bool, err error) {
+		if body.WebhookURL != "" {
+			notifyWebhookInBackground(ctx, body.WebhookURL, "api", body.URL, result, cached, err)
+		}
+		if err == nil && body.Email != "" {
+			emailReportInBackground(ctx, body.Email, body.URL, result)
+		}
+	})
+	if err != nil {
+		writeAPIResponse(w, r, http.StatusConflict, apiError{Error: err.Error(), Code: apiCodeTooManyJobs, URL: body.URL})
+		return
+	}
+	w.Header().Set("Location", apiPrefix+"/jobs/"+started.ID)
+	writeAPIResponse(w, r, http.StatusAccepted, started)
+}
+
+// handleAPIJob returns (GET) or cancels and removes (DELETE) the job /api/v1/jobs/{id}.
+func handleAPIJob(w http.ResponseWriter, r *http.Request) {
+	_, id, _ := strings.Cut(r.URL.Path, "/jobs/")
+	switch r.Method {
+	case http.MethodGet:
+		job, ok := jobs.get(id)
+		if !ok {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "job not found", Code: apiCodeJobNotFound})
+			return
+		}
+		writeAPIResponse(w, r, http.StatusOK, job)
+	case http.MethodDelete:
+		if !jobs.remove(id) {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "job not found", Code: apiCodeJobNotFound})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// blockingRun is a jobStore run function that waits until its job is canceled, sending the
+// context's error to canceled if it is not nil.
+func blockingRun(canceled chan<- error) func(context.Context, *slog.Logger, analysisRequest) (*analyzer.AnalysisResult, bool, error) {
+	return func(ctx context.Context, _ *slog.Logger, _ analysisRequest) (*analyzer.AnalysisResult, bool, error) {
+		<-ctx.Done()
+		if canceled != nil {
+			canceled <- ctx.Err()
+		}
+		return nil, false, ctx.Err()
+	}
+}
+
+// useJobStore makes store the server's jobStore for the rest of the test.
+func useJobStore(t *testing.T, store *jobStore) {
+	oldJobs := jobs
+	jobs = store
+	t.Cleanup(func() {
+		store.Stop()
+		jobs = oldJobs
+	})
+}
+
+func TestJobStore_ForgetsOldestFinishedJob(t *testing.T) {
+	results := make(chan error, 4)
+	store := newJobStore(2, func(ctx context.Context, _ *slog.Logger, req analysisRequest) (*analyzer.AnalysisResult, bool, error) {
+		if req.URL == "https://running.example" {
+			<-ctx.Done()
+			return nil, false, ctx.Err()
+		}
+		return &analyzer.AnalysisResult{}, false, nil
+	})
+	defer store.Stop()
+	deliver := func(_ context.Context, _ *analyzer.AnalysisResult, _ bool, err error) { results <- err }
+
+	finished, err := store.start(context.Background(), analysisRequest{URL: "https://finished.example"}, deliver)
+	if err != nil {
+		t.Fatalf("Expected the first job to start, but got %v", err)
+	}
+	<-results
+	running, err := store.start(context.Background(), analysisRequest{URL: "https://running.example"}, deliver)
+	if err != nil {
+		t.Fatalf("Expected the second job to start, but got %v", err)
+	}
+	latest, err := store.start(context.Background(), analysisRequest{URL: "https://latest.example"}, deliver)
+	if err != nil {
+		t.Fatalf("Expected the third job to start by forgetting the finished one, but got %v", err)
+	}
+	<-results
+
+	if _, ok := store.get(finished.ID); ok {
+		t.Error("Expected the oldest finished job to be forgotten")
+	}
+	if _, ok := store.get(running.ID); !ok {
+		t.Error("Expected the running job to be kept")
+	}
+	if job, ok := store.get(latest.ID); !ok || job.Status != jobStatusDone {
+		t.Errorf("Expected the latest job to be kept and done, but got %+v", job)
+	}
+	if _, err := store.start(context.Background(), analysisRequest{URL: "https://running.example"}, deliver); err != nil {
+		t.Errorf("Expected a job to start by forgetting the latest finished job, but got %v", err)
+	}
+}
+
+func TestJobStore_TooManyJobs(t *testing.T) {
+	store := newJobStore(1, blockingRun(nil))
+	defer store.Stop()
+	deliver := func(context.Context, *analyzer.AnalysisResult, bool, error) {}
+
+	if _, err := store.start(context.Background(), analysisRequest{URL: "https://example.com"}, deliver); err != nil {
+		t.Fatalf("Expected the first job to start, but got %v", err)
+	}
+	if _, err := store.start(context.Background(), analysisRequest{URL: "https://example.com"}, deliver); err != errTooManyJobs {
+		t.Errorf("Expected %v, but got %v", errTooManyJobs, err)
+	}
+}
+
+func TestJobStore_Settles(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus string
+		wantChecks []apiJobCheck
+	}{
+		{
+			name:       "Done",
+			wantStatus: jobStatusDone,
+			wantChecks: []apiJobCheck{
+				{Name: "dns", Status: analyzer.CheckStatusFailed},
+				{Name: "links", Status: analyzer.CheckStatusDone},
+				{Name: "tls", Status: analyzer.CheckStatusDone},
+			},
+		},
+		{name: "Failed", err: analyzer.ErrNotHTML, wantStatus: jobStatusFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			done := make(chan struct{})
+			store := newJobStore(1, func(_ context.Context, _ *slog.Logger, req analysisRequest) (*analyzer.AnalysisResult, bool, error) {
+				req.Options.Partial(analyzer.PartialResult{
+					Result: &analyzer.AnalysisResult{},
+					Checks: map[string]string{"links": analyzer.CheckStatusRunning, "dns": analyzer.CheckStatusRunning, "tls": analyzer.CheckStatusDone},
+				})
+				if tc.err != nil {
+					return nil, false, tc.err
+				}
+				return &analyzer.AnalysisResult{Errors: map[string]string{"dns": "lookup failed"}}, false, nil
+			})
+			defer store.Stop()
+
+			started, err := store.start(context.Background(), analysisRequest{URL: "https://example.com"}, func(context.Context, *analyzer.AnalysisResult, bool, error) {
+				close(done)
+			})
+			if err != nil {
+				t.Fatalf("Expected the job to start, but got %v", err)
+			}
+			<-done
+
+			job, _ := store.get(started.ID)
+			if job.Status != tc.wantStatus {
+				t.Errorf("Expected status %q, but got %q", tc.wantStatus, job.Status)
+			}
+			if job.FinishedAt.IsZero() {
+				t.Error("Expected the finish time to be set")
+			}
+			if tc.err != nil {
+				if job.Result != nil || job.Error == nil || job.Error.Code != apiCodeNotHTML {
+					t.Errorf("Expected no result and a %q error, but got %+v", apiCodeNotHTML, job)
+				}
+				return
+			}
+			if !slices.Equal(job.Checks, tc.wantChecks) {
+				t.Errorf("Expected checks %v, but got %v", tc.wantChecks, job.Checks)
+			}
+		})
+	}
+}
+
+func TestSettleChecks_LeavesChecksAlone(t *testing.T) {
+	checks := []apiJobCheck{{Name: "links", Status: analyzer.CheckStatusRunning}}
+	settled := settleChecks(checks, &analyzer.AnalysisResult{})
+	if settled[0].Status != analyzer.CheckStatusDone {
+		t.Errorf("Expected the check to be settled as done, but got %q", settled[0].Status)
+	}
+	if checks[0].Status != analyzer.CheckStatusRunning {
+		t.Errorf("Expected the original checks to be left alone, but got %q", checks[0].Status)
+	}
+}
+
+func TestHandleAPIJobs_TooManyJobs(t *testing.T) {
+	useJobStore(t, newJobStore(1, blockingRun(nil)))
+
+	post := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, apiPrefix+"/jobs", strings.NewReader(`{"url": "https://example.com"}`))
+		w := httptest.NewRecorder()
+		handleAPIJobs(w, r)
+		return w
+	}
+
+	if w := post(); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusAccepted, w.Code, w.Body)
+	}
+	w := post()
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status %d, but got %d: %s", http.StatusConflict, w.Code, w.Body)
+	}
+	var apiErr apiError
+	if err := json.Unmarshal(w.Body.Bytes(), &apiErr); err != nil {
+		t.Fatalf("Expected a JSON error, but got %v", err)
+	}
+	if apiErr.Code != apiCodeTooManyJobs {
+		t.Errorf("Expected code %q, but got %q", apiCodeTooManyJobs, apiErr.Code)
+	}
+}
+
+func TestHandleAPIJob_DeleteCancelsJob(t *testing.T) {
+	canceled := make(chan error, 1)
+	useJobStore(t, newJobStore(1, blockingRun(canceled)))
+
+	r := httptest.NewRequest(http.MethodPost, apiPrefix+"/jobs", strings.NewReader(`{"url": "https://example.com"}`))
+	w := httptest.NewRecorder()
+	handleAPIJobs(w, r)
+	var started apiJob
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil || started.ID == "" {
+		t.Fatalf("Expected a started job, but got %s (%v)", w.Body, err)
+	}
+
+	w = httptest.NewRecorder()
+	handleAPIJob(w, httptest.NewRequest(http.MethodDelete, apiPrefix+"/jobs/"+started.ID, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, but got %d", http.StatusNoContent, w.Code)
+	}
+	select {
+	case err := <-canceled:
+		if err != context.Canceled {
+			t.Errorf("Expected the analysis to be canceled, but got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected deleting the job to cancel its analysis")
+	}
+
+	w = httptest.NewRecorder()
+	handleAPIJob(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/jobs/"+started.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a deleted job, but got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// logLevel is the minimum level of the server's log records. The admin server's
+// /debug/loglevel changes it at runtime.
+var logLevel = new(slog.LevelVar)
+
+// newLogger returns the server's logger, which writes every record, the analyzer's
+// included, to stdout as "json" or "text", with the request ID of the record's context.
+func newLogger(format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want json or text)", format)
+	}
+	return slog.New(requestIDHandler{handler}), nil
+}
+
+// parseLogLevel parses debug, info, warn or error, in any case.
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	switch strings.ToLower(s) {
+	case "debug", "info", "warn", "error":
+		err := level.UnmarshalText([]byte(s))
+		return level, err
+	}
+	return level, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+}
+
+// logLevelBody is the JSON body of /debug/loglevel.
+type logLevelBody struct {
+	Level string `json:"level"`
+}
+
+// handleLogLevel serves /debug/loglevel: GET returns the current log level, and PUT sets it
+// from a {"level": "debug"} body until the next restart.
+func handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var body logLevelBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := parseLogLevel(body.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		previous := logLevel.Level()
+		logLevel.Set(level)
+		slog.Warn("Log level changed", "from", previous, "to", level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, logLevelBody{Level: strings.ToLower(logLevel.Level().String())})
+}
@@ -2,45 +2,439 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"html/template"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
 	"runtime/debug"
-	"web-analyzer/internal/analyzer"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"web-analyzer/internal/alert"
+	"web-analyzer/internal/clientlimit"
+	"web-analyzer/internal/config"
+	"web-analyzer/internal/cors"
+	"web-analyzer/internal/crawl"
+	"web-analyzer/internal/jobqueue"
+	"web-analyzer/internal/scheduler"
+	"web-analyzer/internal/store"
+	"web-analyzer/pkg/analyzer"
 )
 
 func main() {
-	fs := http.FileServer(http.Dir("../ui/static"))
+	configPath := flag.String("config", envString("ANALYZER_CONFIG", ""), "YAML file of settings keyed by flag name; flags and environment variables take precedence over it")
+	addr := flag.String("addr", envString("ANALYZER_ADDR", ":8080"), "address the server listens on")
+	uiDir := flag.String("ui-dir", envString("ANALYZER_UI_DIR", "../ui"), "directory holding the html templates and static files")
+	tlsCert := flag.String("tls-cert", envString("ANALYZER_TLS_CERT", ""), "PEM certificate file to serve HTTPS with (with -tls-key)")
+	tlsKey := flag.String("tls-key", envString("ANALYZER_TLS_KEY", ""), "PEM private key file of -tls-cert")
+	autocertDomains := flag.String("autocert-domains", envString("ANALYZER_AUTOCERT_DOMAINS", ""), "comma-separated domains to serve HTTPS for with certificates from Let's Encrypt (empty disables autocert)")
+	autocertCache := flag.String("autocert-cache", envString("ANALYZER_AUTOCERT_CACHE", "autocert-cache"), "directory where Let's Encrypt certificates and the account key are kept")
+	autocertEmail := flag.String("autocert-email", envString("ANALYZER_AUTOCERT_EMAIL", ""), "contact address given to Let's Encrypt for expiry and account notices")
+	grpcAddr := flag.String("grpc-addr", envString("ANALYZER_GRPC_ADDR", ""), "address of the gRPC API, e.g. :9090 (empty disables it); it uses the HTTPS certificates when they are set")
+	httpRedirectAddr := flag.String("http-redirect-addr", envString("ANALYZER_HTTP_REDIRECT_ADDR", ""), "address of a plain HTTP server redirecting to HTTPS, e.g. :80 (empty disables it)")
+	maxBodyBytesFlag := flag.Int64("max-body-bytes", int64(envInt("ANALYZER_MAX_BODY_BYTES", int(maxBodyBytes))), "maximum size of a request body in bytes (0 disables the limit)")
+	readHeaderTimeout := flag.Duration("read-header-timeout", envDuration("ANALYZER_READ_HEADER_TIMEOUT", 10*time.Second), "how long clients get to send request headers (0 means no limit)")
+	rateLimit := flag.Float64("rate-limit", envFloat("ANALYZER_RATE_LIMIT", 0), "maximum outbound requests per second across all analyses (0 means unlimited)")
+	rateBurst := flag.Int("rate-burst", envInt("ANALYZER_RATE_BURST", 1), "number of outbound requests allowed in a burst above the rate limit")
+	workers := flag.Int("workers", envInt("ANALYZER_WORKERS", analysisOptions.Workers), "number of concurrent link check workers per analysis")
+	linkTimeout := flag.Duration("link-timeout", envDuration("ANALYZER_LINK_TIMEOUT", 10*time.Second), "timeout of each link check request (0 means no limit)")
+	retryAttempts := flag.Int("retry-attempts", envInt("ANALYZER_RETRY_ATTEMPTS", analysisOptions.Retry.MaxRetries), "attempts made for the page fetch and each link check before giving up")
+	retryBackoff := flag.Duration("retry-backoff", envDuration("ANALYZER_RETRY_BACKOFF", analysisOptions.Retry.InitialBackoff), "wait after the first failed attempt; it doubles on each further attempt")
+	retryMaxBackoff := flag.Duration("retry-max-backoff", envDuration("ANALYZER_RETRY_MAX_BACKOFF", analysisOptions.Retry.MaxBackoff), "cap on the wait between attempts")
+	clientRateLimit := flag.Float64("client-rate-limit", envFloat("ANALYZER_CLIENT_RATE_LIMIT", 30), "analyses each client IP may start per minute (0 means unlimited)")
+	clientRateBurst := flag.Int("client-rate-burst", envInt("ANALYZER_CLIENT_RATE_BURST", 10), "analyses a client may start in a burst above its rate limit")
+	apiKeyList := flag.String("api-keys", envString("ANALYZER_API_KEYS", ""), "comma-separated API keys as SECRET, NAME:SECRET or NAME:SECRET:DAILY_QUOTA; requests with one in the X-API-Key header are rate-limited per key instead of per IP")
+	apiKeysFile := flag.String("api-keys-file", envString("ANALYZER_API_KEYS_FILE", ""), "file of further API keys, one per line in the format of -api-keys")
+	apiKeyDailyQuota := flag.Int("api-key-daily-quota", envInt("ANALYZER_API_KEY_DAILY_QUOTA", 0), "analyses each API key without its own quota may start per UTC day (0 means unlimited)")
+	corsOrigins := flag.String("cors-origins", envString("ANALYZER_CORS_ORIGINS", ""), "comma-separated origins allowed to call the JSON API from browsers, e.g. https://dashboard.example.com, https://*.example.com or * (empty disables CORS)")
+	corsMethods := flag.String("cors-methods", envString("ANALYZER_CORS_METHODS", "GET, POST, DELETE"), "comma-separated methods cross-origin pages may use on the JSON API")
+	corsHeaders := flag.String("cors-headers", envString("ANALYZER_CORS_HEADERS", "Content-Type, X-API-Key"), "comma-separated request headers cross-origin pages may send to the JSON API")
+	corsMaxAge := flag.Duration("cors-max-age", envDuration("ANALYZER_CORS_MAX_AGE", 10*time.Minute), "how long browsers may cache the answer to a CORS preflight request")
+	requireKey := flag.Bool("require-api-key", envBool("ANALYZER_REQUIRE_API_KEY", false), "refuse JSON API requests without a valid X-API-Key header")
+	apiKeyRateLimit := flag.Float64("api-key-rate-limit", envFloat("ANALYZER_API_KEY_RATE_LIMIT", 0), "analyses each API key may start per minute (0 means unlimited)")
+	queueSpec := flag.String("queue", envString("ANALYZER_QUEUE", "local"), "where analyses wait for a worker: local, or a redis:// URL whose queue is shared by every instance using it")
+	queueWorkers := flag.Int("queue-workers", envInt("ANALYZER_QUEUE_WORKERS", 8), "number of analyses this instance runs at once; further ones wait in the queue")
+	queueSize := flag.Int("queue-size", envInt("ANALYZER_QUEUE_SIZE", 32), "number of analyses that may wait for a free worker; more are refused with 429 Too Many Requests")
+	crawlMaxPagesFlag := flag.Int("crawl-max-pages", envInt("ANALYZER_CRAWL_MAX_PAGES", crawlMaxPages), "most pages a crawl may analyze, and the default for crawls that do not say")
+	crawlConcurrency := flag.Int("crawl-concurrency", envInt("ANALYZER_CRAWL_CONCURRENCY", 4), "pages of one crawl analyzed at once; with a shared -queue, raise it to spread crawls over more instances")
+	flag.BoolVar(&crawlIgnoreRobots, "crawl-ignore-robots", envBool("ANALYZER_CRAWL_IGNORE_ROBOTS", false), "crawl pages robots.txt disallows and ignore its Crawl-delay, e.g. to audit your own site")
+	resultCacheTTL := flag.Duration("result-cache-ttl", envDuration("ANALYZER_RESULT_CACHE_TTL", 5*time.Minute), "how long complete analysis results are served from cache (0 disables the cache)")
+	allowPrivateNetworks := flag.Bool("allow-private-networks", envBool("ANALYZER_ALLOW_PRIVATE_NETWORKS", false), "allow fetching private, loopback, link-local and cloud metadata addresses")
+	allowHosts := flag.String("allow-hosts", envString("ANALYZER_ALLOW_HOSTS", ""), "comma-separated hostname globs, IPs or CIDR ranges that may be fetched (empty allows all)")
+	denyHosts := flag.String("deny-hosts", envString("ANALYZER_DENY_HOSTS", ""), "comma-separated hostname globs, IPs or CIDR ranges that are never fetched")
+	maxPageBytes := flag.Int64("max-page-bytes", int64(envInt("ANALYZER_MAX_PAGE_BYTES", int(analysisOptions.MaxPageBytes))), "maximum size of an analyzed page body in bytes (0 disables the limit)")
+	userAgent := flag.String("user-agent", envString("ANALYZER_USER_AGENT", analysisOptions.UserAgent), "User-Agent sent with page fetches and link checks")
+	headers := headerFlag(envLines("ANALYZER_HEADERS"))
+	flag.Var(&headers, "header", "extra request header as \"Name: value\" (repeatable)")
+	proxy := flag.String("proxy", envString("ANALYZER_PROXY", ""), "HTTP, HTTPS or SOCKS5 proxy URL for all outbound requests (empty uses HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	streamingThreshold := flag.Int64("streaming-threshold", int64(envInt("ANALYZER_STREAMING_THRESHOLD", int(analysisOptions.StreamingThreshold))), "page size in bytes above which pages are analyzed with a streaming tokenizer instead of a DOM (0 disables streaming)")
+	stripTrailingSlash := flag.Bool("strip-trailing-slash", envBool("ANALYZER_STRIP_TRAILING_SLASH", analysisOptions.Normalize.StripTrailingSlash), "treat link URLs that differ only by a trailing slash on a non-root path as the same link")
+	stripUTMParams := flag.Bool("strip-utm-params", envBool("ANALYZER_STRIP_UTM_PARAMS", analysisOptions.Normalize.StripUTMParams), "drop utm_* campaign tracking parameters from link URLs, so links differing only by them count as the same link")
+	validatorURL := flag.String("validator-url", envString("ANALYZER_VALIDATOR_URL", ""), "URL of a Nu HTML Checker, e.g. http://localhost:8888/, that analyzed pages are submitted to for markup validation (empty disables it)")
+	spellcheckDir := flag.String("spellcheck-dir", envString("ANALYZER_SPELLCHECK_DIR", ""), "directory of word lists named by language, e.g. en.txt, to spellcheck the visible text of pages with (empty disables the spellcheck)")
+	rdapURL := flag.String("rdap-url", envString("ANALYZER_RDAP_URL", ""), "URL of an RDAP service, e.g. https://rdap.org/, to look up the registration of analyzed domains with (empty disables it)")
+	blocklistFiles := flag.String("blocklist", envString("ANALYZER_BLOCKLIST", ""), "comma-separated blocklist files of hosts, URLs or hosts-file lines to check analyzed pages and their external links against (empty disables it)")
+	safeBrowsingKey := flag.String("safe-browsing-key", envString("ANALYZER_SAFE_BROWSING_KEY", ""), "Google Safe Browsing API key to look analyzed pages and their external links up with (empty disables it)")
+	waybackURL := flag.String("wayback-url", envString("ANALYZER_WAYBACK_URL", ""), "URL of the Wayback Machine availability API, e.g. https://archive.org/wayback/available, to look up archived snapshots of analyzed pages with (empty disables it)")
+	ipDatabasePath := flag.String("ip-database", envString("ANALYZER_IP_DATABASE", ""), "ip2asn TSV file, optionally gzipped, to report the AS and country of the analyzed hosts' addresses from (empty reports the addresses only)")
+	spellcheckLanguage := flag.String("spellcheck-language", envString("ANALYZER_SPELLCHECK_LANGUAGE", "en"), "language pages without a lang attribute are spellchecked in")
+	adminAddr := flag.String("admin-addr", envString("ANALYZER_ADMIN_ADDR", ""), "address for the admin server with pprof and runtime stats, e.g. localhost:6060 (empty disables it)")
+	linkCacheTTL := flag.Duration("link-cache-ttl", envDuration("ANALYZER_LINK_CACHE_TTL", 5*time.Minute), "how long link check results are reused across analyses (0 disables the cache)")
+	resultRetention := flag.Duration("result-retention", envDuration("ANALYZER_RESULT_RETENTION", 24*time.Hour), "how long analysis results stay available at their permalink and for download (0 keeps them)")
+	scheduleLines := scheduleFlag(envLines("ANALYZER_SCHEDULES"))
+	flag.Var(&scheduleLines, "schedule", "URL analyzed on a cron schedule as \"CRON URL\", e.g. \"0 * * * * https://example.com\" (repeatable)")
+	alertWebhookURL := flag.String("alert-webhook", envString("ANALYZER_ALERT_WEBHOOK", ""), "URL that change-detection alerts of scheduled analyses are POSTed to as JSON (empty only logs them)")
+	alertRules := flag.String("alert-rules", envString("ANALYZER_ALERT_RULES", ""), "comma-separated alert rules to enable: "+strings.Join(alert.Rules, ", ")+" (empty enables all)")
+	alertScoreThreshold := flag.Int("alert-score-threshold", envInt("ANALYZER_ALERT_SCORE_THRESHOLD", 0), "alert when a scheduled analysis' score drops below this value (0 disables it)")
+	smtpAddr := flag.String("smtp-addr", envString("ANALYZER_SMTP_ADDR", ""), "SMTP server as host:port for emailing reports (empty disables email)")
+	smtpUsername := flag.String("smtp-username", envString("ANALYZER_SMTP_USERNAME", ""), "SMTP username (empty skips authentication)")
+	webhookSecret := flag.String("webhook-secret", envString("ANALYZER_WEBHOOK_SECRET", ""), "key that signs webhook deliveries with HMAC-SHA256 in the X-Analyzer-Signature header (empty sends them unsigned); prefer the environment variable over the flag")
+	smtpPassword := flag.String("smtp-password", envString("ANALYZER_SMTP_PASSWORD", ""), "SMTP password; prefer the environment variable over the flag")
+	smtpFrom := flag.String("smtp-from", envString("ANALYZER_SMTP_FROM", ""), "sender address of emailed reports")
+	smtpAllowedDomains := flag.String("smtp-allowed-domains", envString("ANALYZER_SMTP_ALLOWED_DOMAINS", ""), "comma-separated domains reports may be emailed to, including subdomains (empty allows any)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", envDuration("ANALYZER_SHUTDOWN_TIMEOUT", 20*time.Second), "how long to wait for requests and scheduled runs in progress on SIGINT/SIGTERM before canceling them")
+	otlpEndpoint := flag.String("otlp-endpoint", envString("ANALYZER_OTLP_ENDPOINT", ""), "OTLP/HTTP endpoint that analysis traces are exported to, e.g. http://localhost:4318 (empty disables tracing)")
+	logLevelName := flag.String("log-level", envString("ANALYZER_LOG_LEVEL", "info"), "minimum level logged: debug, info, warn or error (changeable at runtime on the admin server)")
+	logFormat := flag.String("log-format", envString("ANALYZER_LOG_FORMAT", "json"), "log format: json or text")
+	storeSpec := flag.String("store", envString("ANALYZER_STORE", "memory"), "where analysis results are saved: memory, sqlite:PATH or a postgres:// URL")
+	flag.Parse()
 
-	http.Handle("/static/", http.StripPrefix("/static/", fs))
-	http.HandleFunc("/", handleRequest)
+	var err error
+	if *configPath != "" {
+		file, err := config.Load(*configPath)
+		if err == nil {
+			err = config.Apply(flag.CommandLine, file, flagEnvName)
+		}
+		if err != nil {
+			slog.Error("Invalid config file", "path", *configPath, "error", err)
+			os.Exit(1)
+		}
+	}
+	logger, err := newLogger(*logFormat)
+	if err != nil {
+		slog.Error("Invalid log format", "error", err)
+		os.Exit(1)
+	}
+	level, err := parseLogLevel(*logLevelName)
+	if err != nil {
+		slog.Error("Invalid log level", "error", err)
+		os.Exit(1)
+	}
+	logLevel.Set(level)
+	slog.SetDefault(logger)
+	schedules = scheduler.New(logger, runScheduledAnalysis)
 
-	slog.Info("Server starting...", "addr", ":8080")
+	if err := loadTemplates(*uiDir); err != nil {
+		slog.Error("Could not load templates", "error", err)
+		os.Exit(1)
+	}
+
+	analyzer.SetOutboundRateLimit(*rateLimit, *rateBurst)
+	analyzer.SetBlockPrivateNetworks(!*allowPrivateNetworks)
+	if *allowPrivateNetworks {
+		slog.Warn("Private network protection disabled; any address reachable from this server can be fetched")
+	}
+	if err := analyzer.SetProxy(*proxy); err != nil {
+		slog.Error("Invalid proxy", "error", err)
+		os.Exit(1)
+	}
+	if err := analyzer.SetHostPolicy(splitList(*allowHosts), splitList(*denyHosts)); err != nil {
+		slog.Error("Invalid host policy", "error", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing := func(context.Context) error { return nil }
+	if *otlpEndpoint != "" {
+		if shutdownTracing, err = setupTracing(context.Background(), *otlpEndpoint); err != nil {
+			slog.Error("Could not set up tracing", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	err := http.ListenAndServe(":8080", nil)
+	maxBodyBytes = *maxBodyBytesFlag
+	ipLimiter = clientlimit.New(*clientRateLimit, *clientRateBurst)
+	keyLimiter = clientlimit.New(*apiKeyRateLimit, *clientRateBurst)
+	apiKeys, err = loadAPIKeys(splitList(*apiKeyList), *apiKeysFile, *apiKeyDailyQuota)
 	if err != nil {
-		slog.Error("Server failed to start", "error", err)
+		slog.Error("Invalid API keys", "error", err)
+		os.Exit(1)
+	}
+	corsPolicy = cors.Policy{
+		Origins:        splitList(*corsOrigins),
+		Methods:        splitList(*corsMethods),
+		Headers:        splitList(*corsHeaders),
+		ExposedHeaders: []string{"Retry-After", requestIDHeader},
+		MaxAge:         *corsMaxAge,
+	}
+	if err := corsPolicy.Validate(); err != nil {
+		slog.Error("Invalid CORS settings", "error", err)
+		os.Exit(1)
+	}
+	if *requireKey && apiKeys.Len() == 0 {
+		slog.Error("-require-api-key needs at least one key in -api-keys or -api-keys-file")
+		os.Exit(1)
+	}
+	if apiSpec, err = buildAPISpec(*requireKey); err != nil {
+		slog.Error("Could not build the OpenAPI document", "error", err)
+		os.Exit(1)
+	}
+
+	resultCache = analyzer.NewResultCache(*resultCacheTTL)
+	savedResults, err = store.Open(context.Background(), *storeSpec, *resultRetention)
+	if err != nil {
+		slog.Error("Could not open result store", "error", err)
+		os.Exit(1)
+	}
+	analysisQueue, err = jobqueue.Open(context.Background(), slog.Default(), *queueSpec, *queueWorkers, *queueSize, runAnalysisJob)
+	if err != nil {
+		slog.Error("Could not open analysis queue", "error", err)
+		os.Exit(1)
+	}
+	crawlMaxPages = max(*crawlMaxPagesFlag, 1)
+	crawls = crawl.New(slog.Default(), analyzeCrawlPage, listCrawlSitemap, fetchCrawlRobots, *crawlConcurrency)
+	jobs = newJobStore(maxJobs, runAnalysis)
+	analyzer.SetLinkTimeout(*linkTimeout)
+	analysisOptions.Retry.MaxRetries = max(*retryAttempts, 1)
+	analysisOptions.Retry.InitialBackoff = *retryBackoff
+	analysisOptions.Retry.MaxBackoff = *retryMaxBackoff
+	analysisOptions.LinkCacheTTL = *linkCacheTTL
+	analysisOptions.MaxPageBytes = *maxPageBytes
+	analysisOptions.StreamingThreshold = *streamingThreshold
+	analysisOptions.Normalize.StripTrailingSlash = *stripTrailingSlash
+	analysisOptions.Normalize.StripUTMParams = *stripUTMParams
+	analysisOptions.UserAgent = *userAgent
+	if *validatorURL != "" {
+		if u, err := url.Parse(*validatorURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			slog.Error("Invalid validator URL, expected an http or https URL", "url", *validatorURL)
+			os.Exit(1)
+		}
+	}
+	analysisOptions.ValidatorURL = *validatorURL
+	if *rdapURL != "" {
+		if u, err := url.Parse(*rdapURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			slog.Error("Invalid RDAP URL, expected an http or https URL", "url", *rdapURL)
+			os.Exit(1)
+		}
+	}
+	analysisOptions.RDAPURL = *rdapURL
+	if *waybackURL != "" {
+		if u, err := url.Parse(*waybackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			slog.Error("Invalid Wayback Machine URL, expected an http or https URL", "url", *waybackURL)
+			os.Exit(1)
+		}
+	}
+	analysisOptions.WaybackURL = *waybackURL
+	if *spellcheckDir != "" {
+		dicts, err := analyzer.LoadDictionaries(*spellcheckDir)
+		if err != nil {
+			slog.Error("Could not load spellcheck dictionaries", "dir", *spellcheckDir, "error", err)
+			os.Exit(1)
+		}
+		analyzer.SetSpellcheck(dicts, *spellcheckLanguage)
+		slog.Info("Spellcheck enabled", "languages", slices.Sorted(maps.Keys(dicts)))
+	}
+	if files := splitList(*blocklistFiles); len(files) > 0 {
+		blocklist, err := analyzer.LoadBlocklist(files...)
+		if err != nil {
+			slog.Error("Could not load blocklist", "files", files, "error", err)
+			os.Exit(1)
+		}
+		analyzer.SetBlocklist(blocklist)
+		slog.Info("Blocklist loaded", "entries", blocklist.Len())
+	}
+	analyzer.SetSafeBrowsing(*safeBrowsingKey)
+	if *ipDatabasePath != "" {
+		db, err := analyzer.LoadIPDatabase(*ipDatabasePath)
+		if err != nil {
+			slog.Error("Could not load IP database", "path", *ipDatabasePath, "error", err)
+			os.Exit(1)
+		}
+		analyzer.SetIPDatabase(db)
+		slog.Info("IP database loaded", "ranges", db.Len())
+	}
+	analysisOptions.Headers, err = headers.header()
+	if err != nil {
+		slog.Error("Invalid request header", "error", err)
+		os.Exit(1)
+	}
+	analysisOptions.Workers = analyzer.BoundedWorkers(*workers)
+	if analysisOptions.Workers != *workers {
+		slog.Warn("Worker count out of bounds, clamped", "requested", *workers, "workers", analysisOptions.Workers)
+	}
+
+	reportMailer = mailer{
+		addr:           *smtpAddr,
+		username:       *smtpUsername,
+		password:       *smtpPassword,
+		from:           *smtpFrom,
+		allowedDomains: splitList(*smtpAllowedDomains),
+	}
+	if reportMailer.enabled() {
+		if _, err := mail.ParseAddress(*smtpFrom); err != nil {
+			slog.Error("Invalid SMTP sender address", "from", *smtpFrom, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	alertConfig.ScoreThreshold = *alertScoreThreshold
+	if alertConfig.Rules, err = alert.ParseRules(*alertRules); err != nil {
+		slog.Error("Invalid alert rules", "error", err)
+		os.Exit(1)
+	}
+	completionWebhooks.Secret = []byte(*webhookSecret)
+	alertWebhooks.Secret = []byte(*webhookSecret)
+	if *alertWebhookURL != "" {
+		alertWebhook = alert.NewWebhook(*alertWebhookURL, alertWebhooks)
+	}
+	for _, line := range scheduleLines {
+		schedule, err := parseScheduleLine(line)
+		if err == nil {
+			_, err = schedules.Add(schedule)
+		}
+		if err != nil {
+			slog.Error("Invalid schedule", "schedule", line, "error", err)
+			os.Exit(1)
+		}
+	}
+	schedules.Start()
+
+	if *adminAddr != "" {
+		go serveAdmin(*adminAddr)
+	}
+
+	fs := http.FileServer(http.Dir(filepath.Join(*uiDir, "static")))
+
+	// A dedicated mux keeps the admin handlers, which net/http/pprof also registers on
+	// http.DefaultServeMux, off the public port.
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", fs))
+	mux.HandleFunc("/", limitAnalyses(handleRequest))
+	mux.HandleFunc("/download/", handleDownload)
+	mux.HandleFunc("/results/", handleResult)
+	mux.HandleFunc("/email/", limitAnalyses(handleEmail))
+	mux.HandleFunc("/history", handleHistory)
+	mux.HandleFunc("/diff", handleDiff)
+	mux.HandleFunc("/compare", limitAnalyses(handleCompare))
+	mux.HandleFunc("/benchmark", limitAnalyses(handleBenchmark))
+	mux.HandleFunc("/schedules", limitAnalyses(handleSchedules))
+	mux.HandleFunc("/badge", handleBadge)
+	registerAPI(mux)
+
+	var handler http.Handler = mux
+	if *requireKey {
+		handler = requireAPIKey(handler)
+	}
+	// CORS goes first, since preflight requests never carry the API key.
+	handler = corsPolicy.Handler(isAPIRequest, handler)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           requestIDMiddleware(accessLogMiddleware(limitRequestBody(handler))),
+		ReadHeaderTimeout: *readHeaderTimeout,
+	}
+	redirect, err := configureTLS(srv, tlsSettings{
+		certFile:         *tlsCert,
+		keyFile:          *tlsKey,
+		autocertDomains:  splitList(*autocertDomains),
+		autocertCacheDir: *autocertCache,
+		autocertEmail:    *autocertEmail,
+		redirectAddr:     *httpRedirectAddr,
+	})
+	if err != nil {
+		slog.Error("Invalid TLS settings", "error", err)
+		os.Exit(1)
+	}
+	if *httpRedirectAddr != "" {
+		go serveRedirects(*httpRedirectAddr, redirect)
+	}
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			slog.Error("Could not listen for gRPC", "addr", *grpcAddr, "error", err)
+			os.Exit(1)
+		}
+		grpcServer = newGRPCServer(srv.TLSConfig, *requireKey)
+		slog.Info("gRPC server starting...", "addr", *grpcAddr, "tls", srv.TLSConfig != nil)
+		go serveGRPC(grpcServer, lis)
+	}
+
+	slog.Info("Server starting...", "addr", *addr, "tls", srv.TLSConfig != nil)
+	if err := serve(srv, *shutdownTimeout); err != nil {
+		shutdownTracing(context.Background())
+		slog.Error("Server failed", "error", err)
 		os.Exit(1)
 	}
+
+	crawls.Stop()
+	jobs.Stop()
+	if err := analysisQueue.Close(); err != nil {
+		slog.Warn("Could not close analysis queue", "error", err)
+	}
+	if err := savedResults.Close(); err != nil {
+		slog.Warn("Could not close result store", "error", err)
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Warn("Could not flush traces", "error", err)
+	}
+	slog.Info("Server stopped")
 }
 
+// analysisOptions holds the server-wide analyzer settings; requests may override some of them.
+var analysisOptions = analyzer.DefaultOptions()
+
+// corsPolicy lets browser pages on the configured origins call the JSON API.
+var corsPolicy cors.Policy
+
+// resultCache serves recent analyses of the same URL without re-running them.
+var resultCache = analyzer.NewResultCache(0)
+
+// analysisQueue runs the analyses that are not served from the cache, so a burst of
+// requests waits for free workers instead of starting unbounded concurrent work.
+var analysisQueue jobqueue.Backend // opened by main
+
 type TemplateData struct {
 	URL     string
 	Error   string
 	Results *analyzer.AnalysisResult
+	Cached  bool
+	Cookies string
+	// Permalink is set when Results is shown at its /results/{id} permalink.
+	Permalink bool
+	// Notice is a success message shown above Results.
+	Notice string
+	// EmailEnabled shows the form to email the report; renderTemplate sets it.
+	EmailEnabled bool
+	// FieldErrors maps the form fields that failed validation to their problem.
+	FieldErrors map[string]string
+	// WaybackEnabled shows the option to analyze the archived snapshot; renderTemplate sets it.
+	WaybackEnabled bool
 }
 
 func clientError(w http.ResponseWriter, status int, message string) {
 	http.Error(w, message, status)
 }
 
-var tmpl = template.Must(template.ParseFiles("../ui/html/index.html"))
+var tmpl *template.Template // parsed by loadTemplates
 
+// serverError logs err and answers with a 500 naming the request ID, which
+// requestIDMiddleware has already set on the response, so the user can report it.
 func serverError(w http.ResponseWriter, err error) {
 	trace := string(debug.Stack())
-	slog.Error("Internal Server Error", "error", err, "trace", trace)
-	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	id := w.Header().Get(requestIDHeader)
+	slog.Error("Internal Server Error", "error", err, "trace", trace, "request_id", id)
+	message := http.StatusText(http.StatusInternalServerError)
+	if id != "" {
+		message += " (request ID " + id + ")"
+	}
+	http.Error(w, message, http.StatusInternalServerError)
 }
 
 func handleRequest(w http.ResponseWriter, r *http.Request) {
@@ -49,26 +443,278 @@ func handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !parseForm(w, r) {
+		return
+	}
 	data := TemplateData{}
 
 	if r.Method == http.MethodPost {
 		urlToAnalyze := r.FormValue("url")
 		data.URL = urlToAnalyze
-		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-		ctx := context.Background()
-		results, err := analyzer.AnalyzePage(ctx, logger, urlToAnalyze)
+		logger := slog.Default()
+		ctx := r.Context()
+		opts := analysisOptions
+		var invalid fieldErrors
+		checkPageURL(&invalid, "url", urlToAnalyze)
+		if workers := r.FormValue("workers"); workers != "" {
+			if n, err := strconv.Atoi(workers); err == nil {
+				opts.Workers = analyzer.BoundedWorkers(n)
+			} else {
+				slog.WarnContext(ctx, "Ignoring invalid workers parameter", "workers", workers)
+			}
+		}
+		data.Cookies = r.FormValue("cookies")
+		if data.Cookies != "" {
+			cookies, err := http.ParseCookie(data.Cookies)
+			if err != nil {
+				invalid.add("cookies", apiCodeInvalidCookie, "must be given as name=value pairs separated by semicolons")
+			}
+			opts.Cookies = cookies
+		}
+		if snapshot := r.FormValue("snapshot"); snapshot != "" {
+			checkSnapshot(&invalid, "snapshot", snapshot)
+			opts.Snapshot = snapshot
+		}
+		if len(invalid) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			data.FieldErrors = invalid.byField()
+			renderTemplate(w, data)
+			return
+		}
+		results, cached, err := runAnalysis(ctx, logger, analysisRequest{
+			URL:     urlToAnalyze,
+			Options: opts,
+			Refresh: r.FormValue("refresh") != "",
+			Custom:  len(opts.Cookies) > 0 || opts.Snapshot != "",
+		})
 		if err != nil {
-			slog.Warn("Analysis failed for URL", "url", urlToAnalyze, "error", err)
-			data.Error = "Failed to analyze the page. The URL might be unreachable or the content invalid."
+			if errors.Is(err, jobqueue.ErrFull) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+			data.Error = analysisErrorMessage(err)
 		} else {
-			slog.Info("Analysis successful", "url", urlToAnalyze)
 			data.Results = results
+			data.Cached = cached
 		}
 	}
 
+	renderTemplate(w, data)
+}
+
+// analysisRequest is one analysis asked for through the UI or the API.
+type analysisRequest struct {
+	URL     string
+	Options analyzer.Options
+	// Refresh skips any cached result; the new result still replaces it.
+	Refresh bool
+	// Custom marks per-request headers, User-Agent, proxy, credentials or snapshots. Such results may differ from a default
+	// fetch of the same URL, so they are neither served from nor stored in the result cache,
+	// and their link checks neither read nor fill the shared link cache.
+	Custom bool
+}
+
+// runAnalysis analyzes req.URL, serving a fresh cached result when allowed and revalidating
+// an expired one. The returned bool reports whether the result came from the cache.
+func runAnalysis(ctx context.Context, logger *slog.Logger, req analysisRequest) (*analyzer.AnalysisResult, bool, error) {
+	opts := req.Options
+	if req.Custom {
+		opts.LinkCacheTTL = 0
+	}
+	if !req.Custom && !req.Refresh {
+		if cached, ok := resultCache.Get(req.URL); ok {
+			slog.InfoContext(ctx, "Serving cached analysis", "url", req.URL, "analyzed_at", cached.AnalyzedAt)
+			saveResult(ctx, req.URL, cached, false)
+			return cached, true, nil
+		}
+		if stale, ok := resultCache.Stale(req.URL); ok {
+			opts.Revalidate = stale
+		}
+	}
+
+	results, err := queueAnalysis(ctx, logger, req.URL, opts)
+	if err != nil {
+		slog.WarnContext(ctx, "Analysis failed for URL", "url", req.URL, "error", err)
+		return nil, false, err
+	}
+
+	slog.InfoContext(ctx, "Analysis successful", "url", req.URL)
+	// Results of only some checks are incomplete by design, so they are neither saved nor cached.
+	if req.Options.Checks != nil {
+		return results, false, nil
+	}
+	saveResult(ctx, req.URL, results, req.Custom)
+	// Partial results are not cached, so the next request retries the failed checks.
+	if !req.Custom && len(results.Errors) == 0 {
+		resultCache.Put(req.URL, results)
+	}
+	return results, false, nil
+}
+
+// saveResult saves result for its permalink. A custom result is kept out of the history and
+// badge of pageURL, since it may show what only its requester may see. Failures are logged
+// rather than returned, since the analysis itself succeeded.
+func saveResult(ctx context.Context, pageURL string, result *analyzer.AnalysisResult, custom bool) {
+	if err := savedResults.Save(ctx, store.Record{URL: pageURL, Result: result, Custom: custom}); err != nil {
+		slog.WarnContext(ctx, "Could not save result", "url", pageURL, "id", result.ID, "error", err)
+	}
+}
+
+// analysisErrorMessage turns an analysis error into a message safe to show to users.
+func analysisErrorMessage(err error) string {
+	var statusErr *analyzer.HTTPStatusError
+	var queuedErr *queuedAnalysisError
+	switch {
+	case errors.As(err, &queuedErr):
+		return queuedErr.apiErr.Error
+	case errors.Is(err, jobqueue.ErrFull):
+		return "The server is busy with other analyses. Try again in a moment."
+	case errors.Is(err, context.Canceled):
+		return "The analysis was canceled."
+	case errors.Is(err, analyzer.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "The page took too long to respond. Try again later."
+	case errors.Is(err, analyzer.ErrBlockedAddress):
+		return "This URL points to a host or network address this server is not allowed to analyze."
+	case errors.Is(err, analyzer.ErrPageTooLarge):
+		return "The page is too large to analyze."
+	case errors.Is(err, analyzer.ErrDNSFailure):
+		return "The host name could not be found. Check the URL for typos."
+	case errors.Is(err, analyzer.ErrTLS):
+		return "A secure connection to the page could not be established; its certificate may be invalid or expired."
+	case errors.Is(err, analyzer.ErrNotHTML):
+		return "The URL does not point to an HTML page."
+	case errors.Is(err, analyzer.ErrNoSnapshot):
+		return "The Wayback Machine has no archived snapshot of the page."
+	case errors.Is(err, analyzer.ErrRedirectLoop):
+		return "The page redirects in a loop."
+	case errors.Is(err, analyzer.ErrTooManyRedirects):
+		return "The page redirects too many times."
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("The page responded with HTTP status %d %s.", statusErr.Code, http.StatusText(statusErr.Code))
+	default:
+		return "Failed to analyze the page. The URL might be unreachable or the content invalid."
+	}
+}
+
+func renderTemplate(w http.ResponseWriter, data TemplateData) {
+	data.EmailEnabled = reportMailer.enabled()
+	data.WaybackEnabled = analysisOptions.WaybackURL != ""
 	err := tmpl.Execute(w, data)
 
 	if err != nil {
 		serverError(w, err)
 	}
 }
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envString returns the value of the environment variable key, or fallback if it is unset.
+func envString(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the integer value of the environment variable key, or fallback if it is unset or invalid.
+func envInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		slog.Warn("Ignoring invalid integer environment variable", "key", key, "value", value)
+	}
+	return fallback
+}
+
+// envFloat returns the float value of the environment variable key, or fallback if it is unset or invalid.
+func envFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		slog.Warn("Ignoring invalid numeric environment variable", "key", key, "value", value)
+	}
+	return fallback
+}
+
+// envDuration returns the duration value of the environment variable key, or fallback if it is unset or invalid.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		slog.Warn("Ignoring invalid duration environment variable", "key", key, "value", value)
+	}
+	return fallback
+}
+
+// envBool returns the boolean value of the environment variable key, or fallback if it is unset or invalid.
+func envBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		slog.Warn("Ignoring invalid boolean environment variable", "key", key, "value", value)
+	}
+	return fallback
+}
+
+// headerFlag collects repeated -header flags as "Name: value" lines.
+type headerFlag []string
+
+func (f *headerFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *headerFlag) Set(value string) error {
+	if _, _, err := analyzer.ParseHeader(value); err != nil {
+		return err
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// header converts the collected lines into an http.Header.
+func (f headerFlag) header() (http.Header, error) {
+	header := make(http.Header)
+	for _, line := range f {
+		name, value, err := analyzer.ParseHeader(line)
+		if err != nil {
+			return nil, err
+		}
+		header.Add(name, value)
+	}
+	return header, nil
+}
+
+// envLines returns the non-empty lines of the environment variable key.
+func envLines(key string) []string {
+	var lines []string
+	for _, line := range strings.Split(os.Getenv(key), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// flagEnvName returns the environment variable of the flag name, for config.Apply. The
+// repeatable flags are named in the plural.
+func flagEnvName(name string) string {
+	switch name {
+	case "header":
+		return "ANALYZER_HEADERS"
+	case "schedule":
+		return "ANALYZER_SCHEDULES"
+	}
+	return config.EnvName(name)
+}
@@ -0,0 +1,538 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"web-analyzer/internal/crawl"
+	"web-analyzer/internal/graphql"
+	"web-analyzer/internal/openapi"
+	"web-analyzer/internal/scheduler"
+	"web-analyzer/pkg/analyzer"
+)
+
+// apiPrefix is where the current version of the JSON API is mounted.
+const apiPrefix = "/api/v1"
+
+// legacyAPIPrefix is where the API was served before it was versioned. Its routes still
+// work but answer with a Deprecation header pointing at their successor under apiPrefix.
+const legacyAPIPrefix = "/api"
+
+// apiRoute is an endpoint of the JSON API.
+type apiRoute struct {
+	// path is relative to apiPrefix; a path ending in / matches everything below it.
+	path string
+	// specPath is path as an OpenAPI path template, when it has parameters.
+	specPath string
+	// versionedOnly keeps the route, added after the API was versioned, off legacyAPIPrefix.
+	versionedOnly bool
+	handler       http.HandlerFunc
+	// describe returns the OpenAPI operation of each method the handler serves.
+	describe func(b *openapi.Builder) map[string]openapi.Operation
+}
+
+// apiRoutes lists the endpoints of the JSON API. Registering and documenting them from the
+// same list keeps the served OpenAPI document in step with the handlers.
+var apiRoutes = []apiRoute{
+	{
+		path:    "/analyze",
+		handler: limitAnalyses(handleAPIAnalyze),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodPost: {
+				OperationID: "analyze",
+				Summary:     "Analyze a page",
+				Description: "Fetches the page, checks its links and returns the result. Per-request headers, User-Agent, proxy and credentials apply to this analysis only and bypass the result and link caches.",
+				Tags:        []string{"analyses"},
+				Parameters:  []openapi.Parameter{formatParameter("json", "xml", "junit", "sarif")},
+				RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiAnalyzeRequest{})},
+				Responses: analysisResponses(b, openapi.Response{
+					Description: "The analysis result. With format=junit a JUnit XML report, and with format=sarif a SARIF log of the security findings.",
+					Content:     b.JSON(apiAnalyzeResponse{}),
+				}),
+			}}
+		},
+	},
+	{
+		path:    "/compare",
+		handler: limitAnalyses(handleAPICompare),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodPost: {
+				OperationID: "compare",
+				Summary:     "Analyze two pages side by side",
+				Description: "Analyzes both URLs concurrently with the server-wide settings. If either analysis fails, the response is that URL's error.",
+				Tags:        []string{"analyses"},
+				Parameters:  []openapi.Parameter{formatParameter("json", "xml")},
+				RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiCompareRequest{})},
+				Responses: analysisResponses(b, openapi.Response{
+					Description: "The results, in the order of the requested URLs.",
+					Content:     b.JSON(apiCompareResponse{}),
+				}),
+			}}
+		},
+	},
+	{
+		path:          "/benchmark",
+		versionedOnly: true,
+		handler:       limitAnalyses(handleAPIBenchmark),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodPost: {
+				OperationID: "benchmark",
+				Summary:     "Benchmark a page against its competitors",
+				Description: fmt.Sprintf("Analyzes the page and up to %d competitors concurrently with the server-wide settings and compares their score, word count, headings, page weight and broken links. If the page itself cannot be analyzed, the response is its error; a competitor that cannot be is listed with its error.", maxBenchmarkCompetitors),
+				Tags:        []string{"analyses"},
+				Parameters:  []openapi.Parameter{formatParameter("json", "xml", "csv")},
+				RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiBenchmarkRequest{})},
+				Responses: analysisResponses(b, openapi.Response{
+					Description: "The benchmark, with the page first. With format=csv a spreadsheet of it, one row per page.",
+					Content:     b.JSON(analyzer.Benchmark{}),
+				}),
+			}}
+		},
+	},
+	{
+		path:          "/jobs",
+		versionedOnly: true,
+		handler:       limitAnalyses(handleAPIJobs),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodPost: {
+				OperationID: "startJob",
+				Summary:     "Analyze a page in the background",
+				Description: "Starts analyzing the page like POST /analyze, without waiting for the result. Poll the returned job: once the page is parsed, its result holds what the document checks found, such as the title, headings and HTML version, while the link checks and lookups still run, and checks lists the status of each check. A cached result completes the job at once.",
+				Tags:        []string{"analyses"},
+				Parameters:  []openapi.Parameter{formatParameter("json", "xml")},
+				RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiAnalyzeRequest{})},
+				Responses: map[string]openapi.Response{
+					"202": {Description: "The job, just started. The Location header points at it.", Content: b.JSON(apiJob{})},
+					"400": errorResponse(b, "The body is malformed or has invalid fields, all listed in fields."),
+					"409": errorResponse(b, "Too many jobs are running (too_many_jobs)."),
+					"413": errorResponse(b, "The request body is too large (body_too_large)."),
+					"429": rateLimitedResponse(b),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/jobs/",
+		specPath:      "/jobs/{id}",
+		versionedOnly: true,
+		handler:       handleAPIJob,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			id := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+			notFound := errorResponse(b, "No job has the ID (job_not_found).")
+			return map[string]openapi.Operation{
+				http.MethodGet: {
+					OperationID: "getJob",
+					Summary:     "Get a job and the results of the checks completed so far",
+					Description: "A failed job has the analysis error in error, with the code POST /analyze would have returned.",
+					Tags:        []string{"analyses"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "The job.", Content: b.JSON(apiJob{})},
+						"404": notFound,
+					},
+				},
+				http.MethodDelete: {
+					OperationID: "deleteJob",
+					Summary:     "Cancel and remove a job",
+					Tags:        []string{"analyses"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"204": {Description: "The job was canceled if running, and removed."},
+						"404": notFound,
+					},
+				},
+			}
+		},
+	},
+	{
+		path:    "/diff",
+		handler: handleAPIDiff,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "diff",
+				Summary:     "Compare two stored analyses of the same URL",
+				Tags:        []string{"analyses"},
+				Parameters: []openapi.Parameter{
+					{Name: "from", In: "query", Required: true, Description: "ID of the older analysis", Schema: &openapi.Schema{Type: "string"}},
+					{Name: "to", In: "query", Required: true, Description: "ID of the newer analysis", Schema: &openapi.Schema{Type: "string"}},
+					formatParameter("json", "xml"),
+				},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "What changed between the two analyses.", Content: b.JSON(apiDiffResponse{})},
+					"400": errorResponse(b, "An ID is missing (missing_id) or the analyses are of different URLs (url_mismatch)."),
+					"404": errorResponse(b, "A result does not exist or has expired (result_not_found)."),
+					"500": errorResponse(b, "The result store could not be read (store_error)."),
+				},
+			}}
+		},
+	},
+	{
+		path:    "/schedules",
+		handler: limitAnalyses(handleAPISchedules),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{
+				http.MethodGet: {
+					OperationID: "listSchedules",
+					Summary:     "List the schedules",
+					Tags:        []string{"schedules"},
+					Responses:   map[string]openapi.Response{"200": {Description: "Every registered schedule.", Content: b.JSON(apiSchedulesResponse{})}},
+				},
+				http.MethodPost: {
+					OperationID: "createSchedule",
+					Summary:     "Analyze a URL on a cron schedule",
+					Tags:        []string{"schedules"},
+					RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiScheduleRequest{})},
+					Responses: map[string]openapi.Response{
+						"201": {Description: "The registered schedule.", Content: b.JSON(scheduler.Schedule{})},
+						"400": errorResponse(b, "The body is malformed, or has an invalid field or cron expression."),
+						"409": errorResponse(b, "The schedule limit has been reached (too_many_schedules)."),
+						"429": rateLimitedResponse(b),
+					},
+				},
+			}
+		},
+	},
+	{
+		path:     "/schedules/",
+		specPath: "/schedules/{id}",
+		handler:  handleAPISchedule,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			id := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+			notFound := errorResponse(b, "No schedule has the ID (schedule_not_found).")
+			return map[string]openapi.Operation{
+				http.MethodGet: {
+					OperationID: "getSchedule",
+					Summary:     "Get a schedule and its recent runs",
+					Tags:        []string{"schedules"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "The schedule.", Content: b.JSON(scheduler.Schedule{})},
+						"404": notFound,
+					},
+				},
+				http.MethodDelete: {
+					OperationID: "deleteSchedule",
+					Summary:     "Remove a schedule",
+					Tags:        []string{"schedules"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"204": {Description: "The schedule was removed."},
+						"404": notFound,
+					},
+				},
+			}
+		},
+	},
+	{
+		path:          "/crawls",
+		versionedOnly: true,
+		handler:       limitAnalyses(handleAPICrawls),
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{
+				http.MethodGet: {
+					OperationID: "listCrawls",
+					Summary:     "List the crawls",
+					Tags:        []string{"crawls"},
+					Responses:   map[string]openapi.Response{"200": {Description: "Every crawl kept, without its pages.", Content: b.JSON(apiCrawlsResponse{})}},
+				},
+				http.MethodPost: {
+					OperationID: "startCrawl",
+					Summary:     "Crawl a site from a start page or sitemap",
+					Description: "Analyzes the start page and, breadth-first, the pages in scope it links to, each once, until max_pages are analyzed. Pages are in scope on the start page's host (or, with scope domain, its registrable domain), up to max_depth links away, if they match one of the include patterns (when given) and none of the exclude patterns. Given a sitemap instead of a url, analyzes the pages in scope it lists, following sitemap indexes but not links; a crawl whose sitemap cannot be read fails. The crawl runs in the background; poll the returned crawl for its pages.",
+					Tags:        []string{"crawls"},
+					RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(apiCrawlRequest{})},
+					Responses: map[string]openapi.Response{
+						"202": {Description: "The crawl, just started. The Location header points at it.", Content: b.JSON(crawl.Crawl{})},
+						"400": errorResponse(b, "The body is malformed or has invalid fields, all listed in fields."),
+						"409": errorResponse(b, "Too many crawls are running (too_many_crawls)."),
+						"429": rateLimitedResponse(b),
+					},
+				},
+			}
+		},
+	},
+	{
+		path:          "/crawls/",
+		specPath:      "/crawls/{id}",
+		versionedOnly: true,
+		handler:       handleAPICrawl,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			id := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+			notFound := errorResponse(b, "No crawl has the ID (crawl_not_found).")
+			return map[string]openapi.Operation{
+				http.MethodGet: {
+					OperationID: "getCrawl",
+					Summary:     "Get a crawl and the pages analyzed so far",
+					Tags:        []string{"crawls"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"200": {Description: "The crawl.", Content: b.JSON(crawl.Crawl{})},
+						"404": notFound,
+					},
+				},
+				http.MethodDelete: {
+					OperationID: "deleteCrawl",
+					Summary:     "Cancel and remove a crawl",
+					Tags:        []string{"crawls"},
+					Parameters:  []openapi.Parameter{id},
+					Responses: map[string]openapi.Response{
+						"204": {Description: "The crawl was canceled if running, and removed."},
+						"404": notFound,
+					},
+				},
+			}
+		},
+	},
+	{
+		path:          "/crawls/{id}/report",
+		versionedOnly: true,
+		handler:       handleAPICrawlReport,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "getCrawlReport",
+				Summary:     "Get the site report of a crawl",
+				Description: "Rolls up the pages analyzed so far: broken links in total, pages missing a title or meta description, pages sharing one, redirect loops and chains with the pages linking to them, paginated series and their pagination issues, pages with nearly the same content, the HTML versions used and the lowest-scoring pages.",
+				Tags:        []string{"crawls"},
+				Parameters:  []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The site report.", Content: b.JSON(crawl.Report{})},
+					"404": errorResponse(b, "No crawl has the ID (crawl_not_found)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/crawls/{id}/broken-links",
+		versionedOnly: true,
+		handler:       handleAPICrawlBrokenLinks,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "getCrawlBrokenLinks",
+				Summary:     "Get the broken internal links of a crawl",
+				Description: "Lists each inaccessible internal link target found on the pages analyzed so far, with the pages referencing it, the targets referenced by the most pages first.",
+				Tags:        []string{"crawls"},
+				Parameters: []openapi.Parameter{
+					{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+					formatParameter("json", "xml"),
+				},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The broken internal links.", Content: b.JSON(crawl.BrokenLinkReport{})},
+					"404": errorResponse(b, "No crawl has the ID (crawl_not_found)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/crawls/{id}/click-depth",
+		versionedOnly: true,
+		handler:       handleAPICrawlClickDepth,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "getCrawlClickDepth",
+				Summary:     "Get the click depths of the pages of a crawl",
+				Description: "Gives the fewest links to follow from the start page to each page analyzed so far, flags the indexable pages more than 3 clicks deep, and lists the pages no links lead to.",
+				Tags:        []string{"crawls"},
+				Parameters: []openapi.Parameter{
+					{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+					formatParameter("json", "xml"),
+				},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The click depths.", Content: b.JSON(crawl.ClickDepthReport{})},
+					"404": errorResponse(b, "No crawl has the ID (crawl_not_found)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/crawls/{id}/sitemap.xml",
+		versionedOnly: true,
+		handler:       handleAPICrawlSitemap,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "getCrawlSitemap",
+				Summary:     "Download a sitemap of a crawl",
+				Description: "Generates a sitemap.xml listing the pages of the crawl analyzed successfully, leaving out those that ask not to be indexed (noindex).",
+				Tags:        []string{"crawls"},
+				Parameters:  []openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The sitemap, as an attachment.", Content: map[string]openapi.MediaType{"application/xml": {Schema: &openapi.Schema{Type: "string"}}}},
+					"404": errorResponse(b, "No crawl has the ID (crawl_not_found)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/crawls/{id}/graph",
+		versionedOnly: true,
+		handler:       handleAPICrawlGraph,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "getCrawlGraph",
+				Summary:     "Get the internal link graph of a crawl",
+				Description: "Lists the pages analyzed so far and the links between them, with the pages no other page links to (orphans) and those only one other page links to (weakly_linked). With format dot, the graph is written in the DOT language of Graphviz instead.",
+				Tags:        []string{"crawls"},
+				Parameters: []openapi.Parameter{
+					{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+					formatParameter("json", "xml", "dot"),
+				},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The link graph.", Content: b.JSON(crawl.Graph{})},
+					"404": errorResponse(b, "No crawl has the ID (crawl_not_found)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/graphql",
+		versionedOnly: true,
+		handler:       handleGraphQL,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodPost: {
+				OperationID: "graphql",
+				Summary:     "Run a GraphQL query",
+				Description: "Executes a GraphQL query against the schema served at /graphql/schema. An analysis runs only the checks needed for the selected fields, and is rate-limited like POST /analyze; failures are reported in errors, with the error code in extensions.code.",
+				Tags:        []string{"analyses"},
+				RequestBody: &openapi.RequestBody{Required: true, Content: b.JSON(graphql.Request{})},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The query result, and the errors of the query or the fields that failed.", Content: b.JSON(graphql.Response{})},
+					"400": errorResponse(b, "The body is malformed or has no query (missing_query)."),
+					"413": errorResponse(b, "The request body is too large (body_too_large)."),
+				},
+			}}
+		},
+	},
+	{
+		path:          "/graphql/schema",
+		versionedOnly: true,
+		handler:       handleGraphQLSchema,
+		describe: func(b *openapi.Builder) map[string]openapi.Operation {
+			return map[string]openapi.Operation{http.MethodGet: {
+				OperationID: "graphqlSchema",
+				Summary:     "Get the GraphQL schema",
+				Tags:        []string{"analyses"},
+				Responses: map[string]openapi.Response{
+					"200": {Description: "The schema in the GraphQL schema definition language.", Content: map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}},
+				},
+			}}
+		},
+	},
+}
+
+// registerAPI mounts apiRoutes under apiPrefix and, deprecated, under legacyAPIPrefix, along
+// with the OpenAPI document and its Swagger UI.
+func registerAPI(mux *http.ServeMux) {
+	for _, route := range apiRoutes {
+		mux.HandleFunc(apiPrefix+route.path, route.handler)
+		if !route.versionedOnly {
+			mux.HandleFunc(legacyAPIPrefix+route.path, deprecatedAPI(route.handler))
+		}
+	}
+	mux.HandleFunc(apiPrefix+"/openapi.json", handleOpenAPI)
+	mux.HandleFunc(apiPrefix+"/docs", handleAPIDocs)
+}
+
+// deprecatedAPI serves a route under legacyAPIPrefix, announcing its successor.
+func deprecatedAPI(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		successor := apiPrefix + strings.TrimPrefix(r.URL.Path, legacyAPIPrefix)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
+
+// apiSpec is the OpenAPI document served at /api/v1/openapi.json; main builds it.
+var apiSpec []byte
+
+// buildAPISpec describes apiRoutes as an OpenAPI document. requireKey marks the API key as
+// required for every operation.
+func buildAPISpec(requireKey bool) ([]byte, error) {
+	b := openapi.New(openapi.Info{
+		Title:       "Web Analyzer API",
+		Version:     "1",
+		Description: "Analyzes web pages: HTML version, headings, links and their reachability, login forms and security headers. Failed requests return an Error whose code is stable.",
+	})
+	// The request and response types are unexported and named api...; drop the prefix.
+	b.Name = func(t reflect.Type) string {
+		if t.PkgPath() == reflect.TypeFor[graphql.Request]().PkgPath() {
+			return "GraphQL" + t.Name()
+		}
+		name := strings.TrimPrefix(t.Name(), "api")
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	b.AddServer(apiPrefix)
+	b.AddAPIKey("apiKey", apiKeyHeader, "One of the server's API keys. Requests with a key are rate-limited and counted against the key's daily quota instead of the client IP's limit.", requireKey)
+	for _, route := range apiRoutes {
+		path := route.path
+		if route.specPath != "" {
+			path = route.specPath
+		}
+		for method, op := range route.describe(b) {
+			b.Add(method, path, op)
+		}
+	}
+	return json.MarshalIndent(b.Document(), "", "  ")
+}
+
+// formatParameter is the format query parameter, taking one of formats.
+func formatParameter(formats ...string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        "format",
+		In:          "query",
+		Description: "Response format. XML is also chosen by an Accept header preferring application/xml.",
+		Schema:      &openapi.Schema{Type: "string", Enum: formats},
+	}
+}
+
+func errorResponse(b *openapi.Builder, description string) openapi.Response {
+	return openapi.Response{Description: description, Content: b.JSON(apiError{})}
+}
+
+func rateLimitedResponse(b *openapi.Builder) openapi.Response {
+	resp := errorResponse(b, "Too many analyses (rate_limited), the API key's daily quota is used up (quota_exceeded) or the server's analysis queue is full (queue_full).")
+	resp.Headers = map[string]openapi.Header{"Retry-After": {Description: "Seconds until the request may be retried; not sent for queue_full.", Schema: &openapi.Schema{Type: "integer"}}}
+	return resp
+}
+
+// analysisResponses returns ok together with the failures of an operation that runs analyses.
+func analysisResponses(b *openapi.Builder, ok openapi.Response) map[string]openapi.Response {
+	return map[string]openapi.Response{
+		"200": ok,
+		"400": errorResponse(b, "The body is malformed or has invalid fields, all listed in fields."),
+		"401": errorResponse(b, "The API key is missing (missing_api_key) or unknown (invalid_api_key)."),
+		"403": errorResponse(b, "The URL's host may not be fetched (blocked_address)."),
+		"413": errorResponse(b, "The request body is too large (body_too_large)."),
+		"422": errorResponse(b, "The page is too large (page_too_large), not HTML (not_html) or has no archived snapshot to analyze (no_snapshot)."),
+		"429": rateLimitedResponse(b),
+		"499": errorResponse(b, "The client went away before the analysis finished (canceled)."),
+		"502": errorResponse(b, "The page could not be analyzed: dns_failure, tls_error, redirect_loop, too_many_redirects, upstream_status or analysis_failed."),
+		"504": errorResponse(b, "The page did not respond in time (timeout)."),
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document of the API.
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(apiSpec)
+}
+
+var apiDocsTmpl *template.Template // parsed by loadTemplates
+
+// handleAPIDocs serves Swagger UI for the OpenAPI document.
+func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	if err := apiDocsTmpl.Execute(w, struct{ SpecURL string }{apiPrefix + "/openapi.json"}); err != nil {
+		serverError(w, err)
+	}
+}
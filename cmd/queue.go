@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// analysisJob is an analysis as queued for the workers, which may belong to another
+// instance sharing the queue.
+type analysisJob struct {
+	URL     string           `json:"url"`
+	Options analyzer.Options `json:"options"`
+	// Proxy stands in for Options.Proxy, whose credentials do not survive JSON.
+	Proxy string `json:"proxy,omitempty"`
+	// RequestID tags the worker's logs with the ID of the request that queued the job.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// analysisOutcome is what a worker reports back for an analysisJob: the result, or the
+// failure already mapped to its API error, since errors cannot be queued as they are.
+type analysisOutcome struct {
+	Result *analyzer.AnalysisResult `json:"result,omitempty"`
+	Status int                      `json:"status,omitempty"`
+	Error  *apiError                `json:"error,omitempty"`
+	Cause  string                   `json:"cause,omitempty"`
+}
+
+// queuedAnalysisError is an analysis failure reported by the worker that ran it.
+type queuedAnalysisError struct {
+	status int
+	apiErr apiError
+	cause  string
+}
+
+func (e *queuedAnalysisError) Error() string {
+	return e.cause
+}
+
+// localAnalysisKey is the context key of a localAnalysis.
+type localAnalysisKey struct{}
+
+// localAnalysis carries what cannot be queued: the caller's logger and its progress and
+// partial result callbacks. They apply only when the job runs with the caller's context, on
+// the local queue.
+type localAnalysis struct {
+	logger   *slog.Logger
+	progress func(analyzer.Progress)
+	partial  func(analyzer.PartialResult)
+}
+
+// queueAnalysis analyzes pageURL on the analysis queue and waits for the result.
+func queueAnalysis(ctx context.Context, logger *slog.Logger, pageURL string, opts analyzer.Options) (*analyzer.AnalysisResult, error) {
+	job := analysisJob{URL: pageURL, Options: opts, RequestID: requestIDFrom(ctx)}
+	if opts.Proxy != nil {
+		job.Proxy, job.Options.Proxy = opts.Proxy.String(), nil
+	}
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("encode analysis job: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, localAnalysisKey{}, localAnalysis{logger: logger, progress: opts.Progress, partial: opts.Partial})
+	out, err := analysisQueue.Run(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	var outcome analysisOutcome
+	if err := json.Unmarshal(out, &outcome); err != nil {
+		return nil, fmt.Errorf("decode analysis outcome: %w", err)
+	}
+	if outcome.Error != nil {
+		return nil, &queuedAnalysisError{status: outcome.Status, apiErr: *outcome.Error, cause: outcome.Cause}
+	}
+	if outcome.Result == nil {
+		return nil, errors.New("analysis outcome has neither result nor error")
+	}
+	return outcome.Result, nil
+}
+
+// runAnalysisJob is the handler of the analysis queue's workers.
+func runAnalysisJob(ctx context.Context, payload []byte) ([]byte, error) {
+	var job analysisJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, fmt.Errorf("decode analysis job: %w", err)
+	}
+	if job.Proxy != "" {
+		proxy, err := url.Parse(job.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("decode analysis job: %w", err)
+		}
+		job.Options.Proxy = proxy
+	}
+
+	logger := slog.Default()
+	if local, ok := ctx.Value(localAnalysisKey{}).(localAnalysis); ok {
+		logger, job.Options.Progress, job.Options.Partial = local.logger, local.progress, local.partial
+	} else {
+		if job.RequestID != "" {
+			ctx = withRequestID(ctx, job.RequestID)
+		}
+		slog.InfoContext(ctx, "Running queued analysis", "url", job.URL)
+	}
+
+	var outcome analysisOutcome
+	var err error
+	outcome.Result, err = analyzer.AnalyzePage(ctx, logger, job.URL, job.Options)
+	if err != nil {
+		status, apiErr := apiAnalysisError(job.URL, err)
+		outcome.Status, outcome.Error, outcome.Cause = status, &apiErr, err.Error()
+	}
+	return json.Marshal(outcome)
+}
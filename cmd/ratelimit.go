@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-analyzer/internal/apikey"
+	"web-analyzer/internal/clientlimit"
+)
+
+// Error codes returned by rate-limited endpoints.
+const (
+	apiCodeRateLimited   = "rate_limited"
+	apiCodeInvalidAPIKey = "invalid_api_key"
+	apiCodeQuotaExceeded = "quota_exceeded"
+)
+
+// apiKeyHeader identifies a client by one of the configured API keys.
+const apiKeyHeader = "X-API-Key"
+
+// Every analysis makes many outbound requests, so the endpoints that start one are limited
+// per client: per API key for requests carrying one of apiKeys, per client IP otherwise.
+// Requests with a key also count against its daily quota.
+var (
+	ipLimiter  = clientlimit.New(0, 1)
+	keyLimiter = clientlimit.New(0, 1)
+	apiKeys, _ = apikey.New(nil)
+)
+
+// analysisRejection says why a client may not start an analysis now.
+type analysisRejection struct {
+	status  int
+	code    string
+	message string
+	// retryAfter is how long until the client may try again; zero if waiting will not help.
+	retryAfter time.Duration
+}
+
+// admitAnalysis applies the rate limits and quotas to a client about to start an analysis.
+// The client is identified by the API key secret it sent or, without one, by its IP. It
+// returns nil if the analysis may start.
+func admitAnalysis(ctx context.Context, secret, ip string) *analysisRejection {
+	if secret == "" {
+		if ok, retryAfter := ipLimiter.Allow(ip); !ok {
+			return rateLimited(retryAfter)
+		}
+		return nil
+	}
+
+	key, known := apiKeys.Lookup(secret)
+	if !known {
+		return &analysisRejection{status: http.StatusUnauthorized, code: apiCodeInvalidAPIKey, message: "unknown API key"}
+	}
+	if ok, retryAfter := keyLimiter.Allow(key.Name); !ok {
+		return rateLimited(retryAfter)
+	}
+	if ok, resetIn := apiKeys.Use(key); !ok {
+		slog.InfoContext(ctx, "API key quota exceeded", "api_key", key.Name, "daily_quota", key.DailyQuota)
+		return &analysisRejection{
+			status:     http.StatusTooManyRequests,
+			code:       apiCodeQuotaExceeded,
+			message:    fmt.Sprintf("daily quota of %d analyses used up, it resets at midnight UTC", key.DailyQuota),
+			retryAfter: resetIn,
+		}
+	}
+	return nil
+}
+
+func rateLimited(retryAfter time.Duration) *analysisRejection {
+	return &analysisRejection{
+		status:     http.StatusTooManyRequests,
+		code:       apiCodeRateLimited,
+		message:    fmt.Sprintf("too many analyses, try again in %d seconds", ceilSeconds(retryAfter)),
+		retryAfter: retryAfter,
+	}
+}
+
+// limitAnalyses rate-limits the POST requests of next, which start analyses or similar work;
+// other methods pass through.
+func limitAnalyses(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+
+		if rejection := admitAnalysis(r.Context(), r.Header.Get(apiKeyHeader), clientIP(r)); rejection != nil {
+			if rejection.retryAfter > 0 {
+				setRetryAfter(w, rejection.retryAfter)
+			}
+			rejectRequest(w, r, rejection.status, rejection.code, rejection.message)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ceilSeconds returns d rounded up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// setRetryAfter sets the Retry-After header to d rounded up to whole seconds.
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(d)))
+}
+
+// rejectRequest answers API requests with an apiError and UI requests with plain text.
+func rejectRequest(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if isAPIRequest(r) {
+		writeAPIResponse(w, r, status, apiError{Error: message, Code: code})
+		return
+	}
+	clientError(w, status, strings.ToUpper(message[:1])+message[1:]+".")
+}
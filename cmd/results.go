@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"web-analyzer/internal/store"
+)
+
+// savedResults keeps completed analyses by ID for permalinks and downloads, so both serve
+// exactly the result the user saw, including uncached and partial results.
+var savedResults store.Store = store.NewMemory(24 * time.Hour)
+
+// handleResult serves GET /results/{id}, the permalink of a saved analysis, rendered like a
+// fresh one in the UI.
+func handleResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	rec, err := savedResults.Get(r.Context(), strings.TrimPrefix(r.URL.Path, "/results/"))
+	if errors.Is(err, store.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		renderTemplate(w, TemplateData{Error: "This result is no longer available. Analyze the page again to get a new link."})
+		return
+	}
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	renderTemplate(w, TemplateData{URL: rec.URL, Results: rec.Result, Permalink: true})
+}
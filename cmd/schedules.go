@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"web-analyzer/internal/alert"
+	"web-analyzer/internal/scheduler"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Error codes returned by the /api/v1/schedules endpoints.
+const (
+	apiCodeInvalidCron      = "invalid_cron"
+	apiCodeTooManySchedules = "too_many_schedules"
+	apiCodeScheduleNotFound = "schedule_not_found"
+)
+
+// schedules runs the registered recurring analyses; main creates it once logging is set up.
+var schedules *scheduler.Scheduler
+
+// alertConfig selects the changes between scheduled runs that fire alerts, which are
+// logged and, when alertWebhook is set, delivered to it.
+var (
+	alertConfig  alert.Config
+	alertWebhook *alert.Webhook
+)
+
+// runScheduledAnalysis re-analyzes the schedule's URL with the server-wide settings,
+// bypassing the result cache so every run checks the live page. The result is saved like
+// any other and compared with the previous run, and the schedule's webhook hears about
+// failed runs too.
+func runScheduledAnalysis(ctx context.Context, schedule scheduler.Schedule) (*analyzer.AnalysisResult, error) {
+	ctx = withRequestID(ctx, newRequestID())
+	logger := slog.Default()
+	result, _, err := runAnalysis(ctx, logger, analysisRequest{URL: schedule.URL, Options: analysisOptions, Refresh: true})
+	if schedule.WebhookURL != "" {
+		notifyWebhookInBackground(ctx, schedule.WebhookURL, "schedule:"+schedule.ID, schedule.URL, result, false, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkForChanges(ctx, schedule, result)
+	if schedule.Email != "" {
+		emailReportInBackground(ctx, schedule.Email, schedule.URL, result)
+	}
+	return result, nil
+}
+
+// checkForChanges compares result with the last successful run of schedule and fires an
+// alert if it changed in a way alertConfig cares about.
+func checkForChanges(ctx context.Context, schedule scheduler.Schedule, result *analyzer.AnalysisResult) {
+	i := slices.IndexFunc(schedule.Runs, func(run scheduler.Run) bool { return run.ResultID != "" })
+	if i < 0 {
+		return
+	}
+	previous, err := savedResults.Get(ctx, schedule.Runs[i].ResultID)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not load previous result for change detection", "schedule", schedule.ID, "result_id", schedule.Runs[i].ResultID, "error", err)
+		return
+	}
+
+	a := alertConfig.Evaluate(schedule.URL, previous.Result, result)
+	if a == nil {
+		return
+	}
+	a.Source = "schedule:" + schedule.ID
+	slog.WarnContext(ctx, "Scheduled analysis changed", "url", schedule.URL, "schedule", schedule.ID, "from_id", a.FromID, "to_id", a.ToID, "reasons", a.Reasons)
+	if alertWebhook != nil {
+		if err := alertWebhook.Send(ctx, a); err != nil {
+			slog.ErrorContext(ctx, "Failed to deliver alert", "url", schedule.URL, "schedule", schedule.ID, "error", err)
+		}
+	}
+}
+
+// scheduleFlag collects -schedule values of the form "CRON URL [EMAIL]", e.g.
+// "0 * * * * https://example.com ops@example.com".
+type scheduleFlag []string
+
+func (f *scheduleFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *scheduleFlag) Set(value string) error {
+	if _, err := parseScheduleLine(value); err != nil {
+		return err
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// parseScheduleLine parses "CRON URL [EMAIL]". The cron expression itself contains spaces,
+// so the URL and the optional email address are taken from the end.
+func parseScheduleLine(line string) (scheduler.Schedule, error) {
+	fields := strings.Fields(line)
+	var schedule scheduler.Schedule
+	if n := len(fields); n > 2 && !strings.Contains(fields[n-1], "://") && strings.Contains(fields[n-1], "@") {
+		schedule.Email, fields = fields[n-1], fields[:n-1]
+	}
+	if len(fields) < 2 {
+		return scheduler.Schedule{}, errors.New(`schedule must be "CRON URL [EMAIL]"`)
+	}
+	schedule.Cron, schedule.URL = strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+	if err := scheduler.ParseCron(schedule.Cron); err != nil {
+		return scheduler.Schedule{}, err
+	}
+	if !validPageURL(schedule.URL) {
+		return scheduler.Schedule{}, errors.New("schedule URL must be an absolute http or https URL")
+	}
+	if schedule.Email != "" {
+		if _, err := mail.ParseAddress(schedule.Email); err != nil {
+			return scheduler.Schedule{}, fmt.Errorf("invalid schedule email %q: %w", schedule.Email, err)
+		}
+	}
+	return schedule, nil
+}
+
+// apiScheduleRequest is the JSON body accepted by POST /api/v1/schedules.
+type apiScheduleRequest struct {
+	URL   string `json:"url"`
+	Cron  string `json:"cron"`
+	Email string `json:"email,omitempty"`
+	// WebhookURL, if set, is POSTed the outcome of every run.
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+// apiSchedulesResponse is the body returned by GET /api/v1/schedules.
+type apiSchedulesResponse struct {
+	XMLName   xml.Name             `json:"-" xml:"schedules"`
+	Schedules []scheduler.Schedule `json:"schedules" xml:"schedule"`
+}
+
+// handleAPISchedules lists the schedules (GET) or registers a new one (POST).
+func handleAPISchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeAPIResponse(w, r, http.StatusOK, apiSchedulesResponse{Schedules: schedules.List()})
+	case http.MethodPost:
+		var body apiScheduleRequest
+		if !decodeJSONBody(w, r, &body) {
+			return
+		}
+		var invalid fieldErrors
+		checkPageURL(&invalid, "url", body.URL)
+		if body.Email != "" {
+			if _, err := reportMailer.checkRecipient(body.Email); err != nil {
+				invalid.add("email", apiCodeInvalidEmail, "is invalid: "+err.Error())
+			}
+		}
+		if body.WebhookURL != "" && !validPageURL(body.WebhookURL) {
+			invalid.add("webhook_url", apiCodeInvalidWebhookURL, "must be an absolute http or https URL")
+		}
+		if len(invalid) > 0 {
+			writeAPIResponse(w, r, http.StatusBadRequest, invalid.apiError(body.URL))
+			return
+		}
+		schedule, err := schedules.Add(scheduler.Schedule{URL: body.URL, Cron: body.Cron, Email: body.Email, WebhookURL: body.WebhookURL})
+		if err != nil {
+			status, apiErr := apiScheduleError(err)
+			apiErr.URL = body.URL
+			writeAPIResponse(w, r, status, apiErr)
+			return
+		}
+		writeAPIResponse(w, r, http.StatusCreated, schedule)
+	default:
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+	}
+}
+
+// handleAPISchedule returns (GET) or removes (DELETE) the schedule /api/v1/schedules/{id}.
+func handleAPISchedule(w http.ResponseWriter, r *http.Request) {
+	_, id, _ := strings.Cut(r.URL.Path, "/schedules/")
+	switch r.Method {
+	case http.MethodGet:
+		schedule, ok := schedules.Get(id)
+		if !ok {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "schedule not found", Code: apiCodeScheduleNotFound})
+			return
+		}
+		writeAPIResponse(w, r, http.StatusOK, schedule)
+	case http.MethodDelete:
+		if !schedules.Remove(id) {
+			writeAPIResponse(w, r, http.StatusNotFound, apiError{Error: "schedule not found", Code: apiCodeScheduleNotFound})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeAPIResponse(w, r, http.StatusMethodNotAllowed, apiError{Error: "method not allowed", Code: apiCodeMethodNotAllowed})
+	}
+}
+
+// apiScheduleError maps a failure to register a schedule to the HTTP status and error body
+// returned to API clients.
+func apiScheduleError(err error) (int, apiError) {
+	if errors.Is(err, scheduler.ErrTooManySchedules) {
+		return http.StatusConflict, apiError{Error: err.Error(), Code: apiCodeTooManySchedules}
+	}
+	return http.StatusBadRequest, apiError{Error: err.Error(), Code: apiCodeInvalidCron}
+}
+
+var schedulesTmpl *template.Template // parsed by loadTemplates
+
+type schedulesData struct {
+	Schedules []scheduler.Schedule
+	URL       string
+	Cron      string
+	Email     string
+	Error     string
+	// FieldErrors maps the form fields that failed validation to their problem.
+	FieldErrors map[string]string
+	// EmailEnabled shows the email field.
+	EmailEnabled bool
+}
+
+// handleSchedules serves the schedules page: GET lists the schedules, and POST adds one
+// (url and cron form fields) or, with a remove field, removes the schedule with that ID.
+func handleSchedules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		clientError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+
+	if !parseForm(w, r) {
+		return
+	}
+	data := schedulesData{}
+	if r.Method == http.MethodPost {
+		if id := r.FormValue("remove"); id != "" {
+			schedules.Remove(id)
+			http.Redirect(w, r, "/schedules", http.StatusSeeOther)
+			return
+		}
+		data.URL, data.Cron, data.Email = r.FormValue("url"), r.FormValue("cron"), r.FormValue("email")
+		var invalid fieldErrors
+		checkPageURL(&invalid, "url", data.URL)
+		if len(invalid) > 0 {
+			data.FieldErrors = invalid.byField()
+		} else if _, err := reportMailer.checkRecipient(data.Email); data.Email != "" && err != nil {
+			data.Error = emailErrorMessage(err)
+		} else if _, err := schedules.Add(scheduler.Schedule{URL: data.URL, Cron: data.Cron, Email: data.Email}); err != nil {
+			_, apiErr := apiScheduleError(err)
+			data.Error = apiErr.Error
+		} else {
+			http.Redirect(w, r, "/schedules", http.StatusSeeOther)
+			return
+		}
+	}
+
+	data.Schedules = schedules.List()
+	data.EmailEnabled = reportMailer.enabled()
+	if err := schedulesTmpl.Execute(w, data); err != nil {
+		serverError(w, err)
+	}
+}
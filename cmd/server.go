@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// cancelGrace is how long canceled requests get to return and write their error response
+// before their connections are closed.
+const cancelGrace = 5 * time.Second
+
+// serve runs srv, over TLS if srv.TLSConfig is set, until it fails or the process receives SIGINT or SIGTERM. On a signal it
+// stops accepting connections, gRPC calls and scheduled runs and waits up to timeout for the
+// requests, calls and runs in progress. Whatever is still running then is canceled and, after cancelGrace,
+// its connection closed.
+func serve(srv *http.Server, timeout time.Duration) error {
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv.BaseContext = func(net.Listener) context.Context { return requestCtx }
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			// The certificates are in TLSConfig already.
+			serveErr <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-signalCtx.Done():
+	}
+	// A second signal kills the process right away.
+	stop()
+	slog.Info("Shutting down, waiting for requests in progress", "timeout", timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := schedules.Shutdown(ctx); err != nil {
+			slog.Warn("Scheduled runs did not finish in time, canceled them", "error", err)
+		}
+	}()
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopGRPC(ctx, grpcServer)
+		}()
+	}
+
+	err := srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("Requests did not finish in time, canceling them", "grace", cancelGrace)
+		cancelRequests()
+		graceCtx, cancelGraceCtx := context.WithTimeout(context.Background(), cancelGrace)
+		defer cancelGraceCtx()
+		if err = srv.Shutdown(graceCtx); err != nil {
+			err = srv.Close()
+		}
+	}
+	wg.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"html/template"
+	"path/filepath"
+)
+
+// loadTemplates parses the page templates from the html directory of uiDir.
+func loadTemplates(uiDir string) error {
+	pages := []struct {
+		tmpl **template.Template
+		file string
+	}{
+		{&tmpl, "index.html"},
+		{&apiDocsTmpl, "apidocs.html"},
+		{&benchmarkTmpl, "benchmark.html"},
+		{&compareTmpl, "compare.html"},
+		{&diffTmpl, "diff.html"},
+		{&historyTmpl, "history.html"},
+		{&reportTmpl, "report.html"},
+		{&schedulesTmpl, "schedules.html"},
+	}
+	for _, page := range pages {
+		parsed, err := template.ParseFiles(filepath.Join(uiDir, "html", page.file))
+		if err != nil {
+			return err
+		}
+		*page.tmpl = parsed
+	}
+	return nil
+}
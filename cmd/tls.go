@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsSettings selects how the server serves HTTPS: from certificate files, with certificates
+// obtained from Let's Encrypt for autocertDomains, or not at all.
+type tlsSettings struct {
+	certFile, keyFile string
+	autocertDomains   []string
+	autocertCacheDir  string
+	autocertEmail     string
+	// redirectAddr, if set, is an address serving plain HTTP that redirects to HTTPS and
+	// answers Let's Encrypt's HTTP-01 challenges.
+	redirectAddr string
+}
+
+func (t tlsSettings) enabled() bool {
+	return t.certFile != "" || t.keyFile != "" || len(t.autocertDomains) > 0
+}
+
+// configureTLS sets srv.TLSConfig according to t, failing early on missing or unreadable
+// certificates. It returns the handler for t.redirectAddr.
+func configureTLS(srv *http.Server, t tlsSettings) (http.Handler, error) {
+	if !t.enabled() {
+		if t.redirectAddr != "" {
+			return nil, errors.New("an HTTP redirect address needs TLS to redirect to")
+		}
+		return nil, nil
+	}
+	if len(t.autocertDomains) > 0 && (t.certFile != "" || t.keyFile != "") {
+		return nil, errors.New("use either certificate files or autocert, not both")
+	}
+
+	redirect := httpsRedirect(srv.Addr)
+	if len(t.autocertDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(t.autocertDomains...),
+			Cache:      autocert.DirCache(t.autocertCacheDir),
+			Email:      t.autocertEmail,
+		}
+		// The manager's config also answers TLS-ALPN-01 challenges on the HTTPS port itself,
+		// so no plain HTTP listener is needed unless one is wanted for redirects.
+		srv.TLSConfig = manager.TLSConfig()
+		return manager.HTTPHandler(redirect), nil
+	}
+
+	if t.certFile == "" || t.keyFile == "" {
+		return nil, errors.New("a TLS certificate needs both the certificate and the key file")
+	}
+	cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	return redirect, nil
+}
+
+// httpsRedirect redirects plain HTTP requests to the same URL on the HTTPS server listening
+// on httpsAddr.
+func httpsRedirect(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// serveRedirects runs the plain HTTP redirect server on addr until the process exits.
+func serveRedirects(addr string, handler http.Handler) {
+	slog.Info("HTTP redirect server starting...", "addr", addr)
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		slog.Error("HTTP redirect server stopped", "error", err)
+	}
+}
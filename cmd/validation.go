@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// apiCodeBodyTooLarge is returned for request bodies larger than maxBodyBytes.
+const apiCodeBodyTooLarge = "body_too_large"
+
+// maxURLLength is the longest page URL accepted for analysis.
+const maxURLLength = 2048
+
+// maxBodyBytes caps the size of request bodies; limitRequestBody enforces it.
+var maxBodyBytes int64 = 64 << 10
+
+// limitRequestBody caps every request body at maxBodyBytes, so oversized form or JSON bodies
+// fail to parse instead of being read into memory.
+func limitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fieldError is a problem with one field of a request. Code is one of the apiCode constants,
+// and Message describes the problem following the field name, as in "url is required", so
+// templates can put their own label in front of it.
+type fieldError struct {
+	Field   string `json:"field" xml:"field,attr"`
+	Code    string `json:"code" xml:"code,attr"`
+	Message string `json:"message" xml:",chardata"`
+}
+
+// fieldErrors collects the problems of a request, so they are reported together rather
+// than one per attempt.
+type fieldErrors []fieldError
+
+func (e *fieldErrors) add(field, code, message string) {
+	*e = append(*e, fieldError{Field: field, Code: code, Message: message})
+}
+
+// apiError returns the error body for the collected problems: the first one as the error
+// and code, and all of them in fields.
+func (e fieldErrors) apiError(pageURL string) apiError {
+	apiErr := apiError{Error: e[0].Field + " " + e[0].Message, Code: e[0].Code, URL: pageURL, Fields: e}
+	if len(e) > 1 {
+		apiErr.Error = fmt.Sprintf("%s (and %d more invalid fields)", apiErr.Error, len(e)-1)
+	}
+	return apiErr
+}
+
+// byField returns the messages keyed by field name, for showing next to form inputs.
+func (e fieldErrors) byField() map[string]string {
+	if len(e) == 0 {
+		return nil
+	}
+	messages := make(map[string]string, len(e))
+	for _, fe := range e {
+		if _, ok := messages[fe.Field]; !ok {
+			messages[fe.Field] = fe.Message
+		}
+	}
+	return messages
+}
+
+// checkPageURL adds a problem to errs if pageURL, given in field, is missing, too long or
+// not a URL analyzer.AnalyzePage accepts.
+func checkPageURL(errs *fieldErrors, field, pageURL string) {
+	switch {
+	case pageURL == "":
+		errs.add(field, apiCodeMissingURL, "is required")
+	case len(pageURL) > maxURLLength:
+		errs.add(field, apiCodeInvalidURL, fmt.Sprintf("must be at most %d characters long", maxURLLength))
+	case !analyzer.IsValidURL(pageURL):
+		errs.add(field, apiCodeInvalidURL, "must be an absolute http or https URL with a domain name, such as https://example.com")
+	}
+}
+
+// checkSnapshot adds a problem to errs if snapshot, given in field, is not a valid
+// analyzer.Options.Snapshot or the server has no Wayback Machine to take it from.
+func checkSnapshot(errs *fieldErrors, field, snapshot string) {
+	switch {
+	case !analyzer.ValidSnapshot(snapshot):
+		errs.add(field, apiCodeInvalidSnapshot, fmt.Sprintf("must be %q or a timestamp such as 20190615", analyzer.SnapshotLatest))
+	case snapshot != "" && analysisOptions.WaybackURL == "":
+		errs.add(field, apiCodeInvalidSnapshot, "is not available, as the server has no Wayback Machine URL")
+	}
+}
+
+// decodeJSONBody decodes the JSON request body into v. If it cannot, it answers the request
+// with the reason and returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		message := fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)
+		writeAPIResponse(w, r, http.StatusRequestEntityTooLarge, apiError{Error: message, Code: apiCodeBodyTooLarge})
+		return false
+	}
+	writeAPIResponse(w, r, http.StatusBadRequest, apiError{Error: "invalid JSON body: " + err.Error(), Code: apiCodeInvalidJSON})
+	return false
+}
+
+// parseForm parses the form of a UI request. If it cannot, it answers the request with
+// the reason and returns false.
+func parseForm(w http.ResponseWriter, r *http.Request) bool {
+	err := r.ParseForm()
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		clientError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("The submitted form exceeds %d bytes.", tooLarge.Limit))
+		return false
+	}
+	clientError(w, http.StatusBadRequest, "The submitted form could not be read.")
+	return false
+}
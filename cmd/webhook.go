@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"web-analyzer/internal/webhook"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Events delivered to completion webhooks.
+const (
+	eventAnalysisCompleted = "analysis.completed"
+	eventAnalysisFailed    = "analysis.failed"
+)
+
+// webhookTimeout bounds a single webhook delivery attempt; webhookDeadline bounds all of
+// them, retries included.
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookDeadline = time.Minute
+)
+
+// completionWebhooks delivers to the user-supplied webhook_url of analyses and schedules.
+// It shares the analyses' transport, so a webhook cannot reach what a page URL could not.
+// alertWebhooks delivers alerts to the operator's -alert-webhook, which may well be an
+// internal service. Both sign with -webhook-secret.
+var (
+	completionWebhooks = &webhook.Sender{Client: analyzer.NewOutboundClient(webhookTimeout), Retries: 2, Backoff: time.Second}
+	alertWebhooks      = &webhook.Sender{Client: &http.Client{Timeout: webhookTimeout}, Retries: 2, Backoff: time.Second}
+)
+
+// completionPayload is the JSON body POSTed to a completion webhook.
+type completionPayload struct {
+	Event  string                   `json:"event"`
+	URL    string                   `json:"url"`
+	Source string                   `json:"source"`
+	Result *analyzer.AnalysisResult `json:"result,omitempty"`
+	Cached bool                     `json:"cached"`
+	Error  *apiError                `json:"error,omitempty"`
+}
+
+// notifyWebhookInBackground POSTs the outcome of an analysis of pageURL to hookURL without
+// holding up the caller, logging with the request ID of ctx. source says what triggered the
+// analysis: "api" or "schedule:ID".
+func notifyWebhookInBackground(ctx context.Context, hookURL, source, pageURL string, result *analyzer.AnalysisResult, cached bool, err error) {
+	payload := completionPayload{Event: eventAnalysisCompleted, URL: pageURL, Source: source, Result: result, Cached: cached}
+	if err != nil {
+		_, apiErr := apiAnalysisError(pageURL, err)
+		payload.Event, payload.Result, payload.Error = eventAnalysisFailed, nil, &apiErr
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookDeadline)
+		defer cancel()
+		if err := completionWebhooks.Send(ctx, hookURL, payload.Event, payload); err != nil {
+			slog.WarnContext(ctx, "Failed to deliver webhook", "url", pageURL, "event", payload.Event, "error", err)
+			return
+		}
+		slog.InfoContext(ctx, "Webhook delivered", "url", pageURL, "event", payload.Event)
+	}()
+}
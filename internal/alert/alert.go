@@ -0,0 +1,115 @@
+// Package alert decides when a re-analysis of a page differs enough from the previous one
+// to tell someone, and delivers such alerts to a webhook.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"web-analyzer/internal/webhook"
+	"web-analyzer/pkg/analyzer"
+)
+
+// Alert rules, selectable with Config.Rules.
+const (
+	RuleNewBrokenLinks   = "new_broken_links"
+	RuleTitleChanged     = "title_changed"
+	RuleLoginFormRemoved = "login_form_removed"
+	RuleScoreBelow       = "score_below_threshold"
+)
+
+// Rules lists every alert rule.
+var Rules = []string{RuleNewBrokenLinks, RuleTitleChanged, RuleLoginFormRemoved, RuleScoreBelow}
+
+// Config selects the rules that fire alerts.
+type Config struct {
+	// Rules are the enabled rules; empty enables all of them.
+	Rules []string
+	// ScoreThreshold fires RuleScoreBelow when the score drops from at least the threshold
+	// to below it. 0 disables the rule.
+	ScoreThreshold int
+}
+
+// ParseRules parses a comma-separated list of rule names.
+func ParseRules(list string) ([]string, error) {
+	var rules []string
+	for _, rule := range strings.Split(list, ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		if !slices.Contains(Rules, rule) {
+			return nil, fmt.Errorf("unknown alert rule %q (want one of %s)", rule, strings.Join(Rules, ", "))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Reason is one rule that fired.
+type Reason struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Alert reports that the analysis of URL changed between two results.
+type Alert struct {
+	URL     string               `json:"url"`
+	FromID  string               `json:"from_id"`
+	ToID    string               `json:"to_id"`
+	Reasons []Reason             `json:"reasons"`
+	Diff    *analyzer.ResultDiff `json:"diff"`
+	// Source names what triggered the analysis, e.g. "schedule:{id}".
+	Source string `json:"source,omitempty"`
+}
+
+// Evaluate compares the previous and current analyses of pageURL and returns an alert if
+// any enabled rule fires, or nil.
+func (c Config) Evaluate(pageURL string, previous, current *analyzer.AnalysisResult) *Alert {
+	diff := analyzer.DiffResults(previous, current)
+	var reasons []Reason
+	add := func(rule, format string, args ...any) {
+		if len(c.Rules) == 0 || slices.Contains(c.Rules, rule) {
+			reasons = append(reasons, Reason{Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	if n := len(diff.NewBrokenLinks); n > 0 {
+		add(RuleNewBrokenLinks, "%d newly inaccessible links: %s", n, strings.Join(diff.NewBrokenLinks, ", "))
+	}
+	if previous.Title != current.Title {
+		add(RuleTitleChanged, "title changed from %q to %q", previous.Title, current.Title)
+	}
+	if previous.ContainsLoginForm && !current.ContainsLoginForm {
+		add(RuleLoginFormRemoved, "the login form disappeared")
+	}
+	if c.ScoreThreshold > 0 && previous.Score >= c.ScoreThreshold && current.Score < c.ScoreThreshold {
+		add(RuleScoreBelow, "score dropped from %d to %d, below the threshold of %d", previous.Score, current.Score, c.ScoreThreshold)
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &Alert{URL: pageURL, FromID: previous.ID, ToID: current.ID, Reasons: reasons, Diff: diff}
+}
+
+// EventAlert names alert deliveries in the X-Analyzer-Event header.
+const EventAlert = "alert"
+
+// Webhook delivers alerts to a URL through a webhook.Sender, so they are signed and retried
+// like every other outbound webhook.
+type Webhook struct {
+	URL    string
+	Sender *webhook.Sender
+}
+
+// NewWebhook returns a webhook posting to url through sender.
+func NewWebhook(url string, sender *webhook.Sender) *Webhook {
+	return &Webhook{URL: url, Sender: sender}
+}
+
+// Send posts a to the webhook. Any 2xx response counts as delivered.
+func (w *Webhook) Send(ctx context.Context, a *Alert) error {
+	return w.Sender.Send(ctx, w.URL, EventAlert, a)
+}
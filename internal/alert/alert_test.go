@@ -0,0 +1,129 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"web-analyzer/internal/webhook"
+	"web-analyzer/pkg/analyzer"
+)
+
+func TestConfig_Evaluate(t *testing.T) {
+	previous := &analyzer.AnalysisResult{
+		ID:                "before",
+		Title:             "Login",
+		ContainsLoginForm: true,
+		Score:             90,
+		LinkResults:       []analyzer.LinkResult{{URL: "https://example.com/a", Status: analyzer.LinkStatusOK}},
+	}
+
+	testCases := []struct {
+		name          string
+		config        Config
+		current       analyzer.AnalysisResult
+		expectedRules []string
+	}{
+		{
+			name:    "Unchanged",
+			config:  Config{ScoreThreshold: 80},
+			current: *previous,
+		},
+		{
+			name:   "Everything changed",
+			config: Config{ScoreThreshold: 80},
+			current: analyzer.AnalysisResult{
+				ID:          "after",
+				Title:       "Welcome",
+				Score:       70,
+				LinkResults: []analyzer.LinkResult{{URL: "https://example.com/a", Status: analyzer.LinkStatusInaccessible}},
+			},
+			expectedRules: []string{RuleNewBrokenLinks, RuleTitleChanged, RuleLoginFormRemoved, RuleScoreBelow},
+		},
+		{
+			name:          "Only enabled rules fire",
+			config:        Config{Rules: []string{RuleTitleChanged}, ScoreThreshold: 80},
+			current:       analyzer.AnalysisResult{ID: "after", Title: "Welcome", Score: 70},
+			expectedRules: []string{RuleTitleChanged},
+		},
+		{
+			name:    "Score already below the threshold",
+			config:  Config{ScoreThreshold: 95},
+			current: analyzer.AnalysisResult{ID: "after", Title: "Login", ContainsLoginForm: true, Score: 60},
+		},
+		{
+			name:    "Score threshold disabled",
+			current: analyzer.AnalysisResult{ID: "after", Title: "Login", ContainsLoginForm: true, Score: 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := tc.config.Evaluate("https://example.com/", previous, &tc.current)
+
+			var rules []string
+			if a != nil {
+				for _, reason := range a.Reasons {
+					rules = append(rules, reason.Rule)
+				}
+			}
+			if len(rules) != len(tc.expectedRules) {
+				t.Fatalf("Expected rules %v, but got %v", tc.expectedRules, rules)
+			}
+			for i := range rules {
+				if rules[i] != tc.expectedRules[i] {
+					t.Errorf("Expected rules %v, but got %v", tc.expectedRules, rules)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(" title_changed, score_below_threshold ,")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(rules) != 2 || rules[0] != RuleTitleChanged || rules[1] != RuleScoreBelow {
+		t.Errorf("Expected the two rules, but got %v", rules)
+	}
+
+	if _, err := ParseRules("title_changed,nope"); err == nil {
+		t.Error("Expected an error for an unknown rule, but got nil")
+	}
+}
+
+func TestWebhook_Send(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON request, but got %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Expected a JSON body, but got: %v", err)
+		}
+		if r.Header.Get(webhook.HeaderEvent) != EventAlert {
+			t.Errorf("Expected event %q, but got %q", EventAlert, r.Header.Get(webhook.HeaderEvent))
+		}
+		if received.URL == "https://fail.example/" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL, &webhook.Sender{Client: server.Client()})
+	a := &Alert{URL: "https://example.com/", FromID: "a", ToID: "b", Reasons: []Reason{{Rule: RuleTitleChanged, Message: "changed"}}}
+	if err := hook.Send(context.Background(), a); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if received.ToID != "b" || len(received.Reasons) != 1 {
+		t.Errorf("Expected the alert to be delivered, but got %+v", received)
+	}
+
+	a.URL = "https://fail.example/"
+	if err := hook.Send(context.Background(), a); err == nil {
+		t.Error("Expected an error for a failing webhook, but got nil")
+	}
+}
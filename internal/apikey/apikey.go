@@ -0,0 +1,211 @@
+// Package apikey authenticates API clients by key, enforces each key's daily analysis quota
+// and tracks how much each key is used.
+package apikey
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Key is an API key given to a client.
+type Key struct {
+	// Name identifies the key in logs and usage reports, so the secret itself is never shown.
+	Name   string
+	Secret string
+	// DailyQuota is the number of analyses the key may start per UTC day; 0 means unlimited.
+	DailyQuota int
+}
+
+// Parse parses a key given as SECRET, NAME:SECRET or NAME:SECRET:QUOTA. Keys without a name
+// are named after the first characters of their secret, and keys without a quota get
+// defaultQuota.
+func Parse(entry string, defaultQuota int) (Key, error) {
+	parts := strings.Split(strings.TrimSpace(entry), ":")
+	key := Key{DailyQuota: defaultQuota}
+	switch len(parts) {
+	case 1:
+		key.Secret = parts[0]
+	case 2:
+		key.Name, key.Secret = parts[0], parts[1]
+	case 3:
+		key.Name, key.Secret = parts[0], parts[1]
+		quota, err := strconv.Atoi(parts[2])
+		if err != nil || quota < 0 {
+			return Key{}, fmt.Errorf("invalid daily quota %q", parts[2])
+		}
+		key.DailyQuota = quota
+	default:
+		return Key{}, errors.New("want SECRET, NAME:SECRET or NAME:SECRET:QUOTA")
+	}
+	if key.Secret == "" {
+		return Key{}, errors.New("secret is empty")
+	}
+	if key.Name == "" {
+		key.Name = key.Secret[:min(4, len(key.Secret))] + "…"
+	}
+	return key, nil
+}
+
+// LoadFile reads keys from path, one per line in the format accepted by Parse. Blank lines
+// and lines starting with # are skipped.
+func LoadFile(path string, defaultQuota int) ([]Key, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []Key
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := Parse(line, defaultQuota)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Usage is how much a key has been used since the server started.
+type Usage struct {
+	Name       string `json:"name"`
+	DailyQuota int    `json:"daily_quota"`
+	// UsedToday counts the analyses started this UTC day, and Remaining those still allowed;
+	// Remaining is omitted for unlimited keys.
+	UsedToday int  `json:"used_today"`
+	Remaining *int `json:"remaining,omitempty"`
+	// RejectedToday counts the analyses refused this UTC day because the quota was used up.
+	RejectedToday int        `json:"rejected_today"`
+	Total         int        `json:"total"`
+	LastUsed      *time.Time `json:"last_used,omitempty"`
+}
+
+// Registry holds the known keys and their usage. It is safe for concurrent use.
+type Registry struct {
+	keys []Key
+
+	mu    sync.Mutex
+	usage map[string]*usage
+	now   func() time.Time
+}
+
+type usage struct {
+	day      string
+	today    int
+	rejected int
+	total    int
+	lastUsed time.Time
+}
+
+// New returns a registry of keys. Names and secrets must be unique.
+func New(keys []Key) (*Registry, error) {
+	names := make(map[string]bool, len(keys))
+	secrets := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if names[key.Name] {
+			return nil, fmt.Errorf("duplicate API key name %q", key.Name)
+		}
+		if secrets[key.Secret] {
+			return nil, fmt.Errorf("API key %q repeats the secret of another key", key.Name)
+		}
+		names[key.Name], secrets[key.Secret] = true, true
+	}
+	return &Registry{keys: keys, usage: make(map[string]*usage), now: time.Now}, nil
+}
+
+// Len returns the number of keys.
+func (r *Registry) Len() int {
+	return len(r.keys)
+}
+
+// Lookup returns the key with the given secret, comparing in constant time.
+func (r *Registry) Lookup(secret string) (Key, bool) {
+	var found Key
+	ok := false
+	for _, key := range r.keys {
+		if subtle.ConstantTimeCompare([]byte(key.Secret), []byte(secret)) == 1 {
+			found, ok = key, true
+		}
+	}
+	return found, ok
+}
+
+// Use counts an analysis started with key against its daily quota. If the quota is used
+// up, the analysis is refused and Use also returns how long until the quota resets at the
+// next UTC midnight.
+func (r *Registry) Use(key Key) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now().UTC()
+	u := r.current(key.Name, now)
+	if key.DailyQuota > 0 && u.today >= key.DailyQuota {
+		u.rejected++
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return false, midnight.Sub(now)
+	}
+	u.today++
+	u.total++
+	u.lastUsed = now
+	return true, 0
+}
+
+// current returns the usage of the key named name, starting a new day's count if the last
+// use was on an earlier day. r.mu must be held.
+func (r *Registry) current(name string, now time.Time) *usage {
+	u, ok := r.usage[name]
+	if !ok {
+		u = &usage{}
+		r.usage[name] = u
+	}
+	if day := now.Format(time.DateOnly); u.day != day {
+		u.day, u.today, u.rejected = day, 0, 0
+	}
+	return u
+}
+
+// Usage returns the usage of every key, sorted by name.
+func (r *Registry) Usage() []Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now().UTC()
+	report := make([]Usage, 0, len(r.keys))
+	for _, key := range r.keys {
+		u := r.current(key.Name, now)
+		entry := Usage{
+			Name:          key.Name,
+			DailyQuota:    key.DailyQuota,
+			UsedToday:     u.today,
+			RejectedToday: u.rejected,
+			Total:         u.total,
+		}
+		if key.DailyQuota > 0 {
+			remaining := max(key.DailyQuota-u.today, 0)
+			entry.Remaining = &remaining
+		}
+		if !u.lastUsed.IsZero() {
+			lastUsed := u.lastUsed
+			entry.LastUsed = &lastUsed
+		}
+		report = append(report, entry)
+	}
+	slices.SortFunc(report, func(a, b Usage) int { return strings.Compare(a.Name, b.Name) })
+	return report
+}
@@ -0,0 +1,152 @@
+package apikey
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		entry    string
+		expected Key
+		errText  string
+	}{
+		{name: "Secret only", entry: "s3cr3tkey", expected: Key{Name: "s3cr…", Secret: "s3cr3tkey", DailyQuota: 100}},
+		{name: "Named", entry: "ci:s3cr3tkey", expected: Key{Name: "ci", Secret: "s3cr3tkey", DailyQuota: 100}},
+		{name: "Named with quota", entry: "ci:s3cr3tkey:5", expected: Key{Name: "ci", Secret: "s3cr3tkey", DailyQuota: 5}},
+		{name: "Unlimited quota", entry: "ci:s3cr3tkey:0", expected: Key{Name: "ci", Secret: "s3cr3tkey"}},
+		{name: "Invalid quota", entry: "ci:s3cr3tkey:lots", errText: "invalid daily quota"},
+		{name: "Negative quota", entry: "ci:s3cr3tkey:-1", errText: "invalid daily quota"},
+		{name: "Empty secret", entry: "ci:", errText: "secret is empty"},
+		{name: "Too many parts", entry: "a:b:1:2", errText: "want SECRET"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := Parse(tc.entry, 100)
+			if tc.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errText) {
+					t.Fatalf("Expected an error containing %q, but got: %v", tc.errText, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if key != tc.expected {
+				t.Errorf("Expected %+v, but got %+v", tc.expected, key)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# CI pipelines\nci:key1:10\n\ndashboard:key2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadFile(path, 50)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	expected := []Key{{Name: "ci", Secret: "key1", DailyQuota: 10}, {Name: "dashboard", Secret: "key2", DailyQuota: 50}}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %v, but got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected key %d to be %+v, but got %+v", i, expected[i], keys[i])
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("ci:key1:x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path, 50); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("Expected an error naming line 1, but got: %v", err)
+	}
+}
+
+func TestNew_RejectsDuplicates(t *testing.T) {
+	if _, err := New([]Key{{Name: "a", Secret: "x"}, {Name: "a", Secret: "y"}}); err == nil {
+		t.Error("Expected an error for duplicate names")
+	}
+	if _, err := New([]Key{{Name: "a", Secret: "x"}, {Name: "b", Secret: "x"}}); err == nil {
+		t.Error("Expected an error for duplicate secrets")
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r, err := New([]Key{{Name: "ci", Secret: "key1"}, {Name: "dashboard", Secret: "key2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, ok := r.Lookup("key2"); !ok || key.Name != "dashboard" {
+		t.Errorf("Expected key2 to be the dashboard key, but got %+v, %v", key, ok)
+	}
+	if _, ok := r.Lookup("key3"); ok {
+		t.Error("Expected an unknown secret not to be found")
+	}
+}
+
+func TestRegistry_Quota(t *testing.T) {
+	key := Key{Name: "ci", Secret: "key1", DailyQuota: 2}
+	r, err := New([]Key{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := r.Use(key); !ok {
+			t.Fatalf("Expected use %d to be within the quota", i+1)
+		}
+	}
+	ok, resetIn := r.Use(key)
+	if ok {
+		t.Fatal("Expected the third use to exceed the quota")
+	}
+	if resetIn != 2*time.Hour {
+		t.Errorf("Expected the quota to reset in 2h, but got %v", resetIn)
+	}
+
+	usage := r.Usage()[0]
+	if usage.UsedToday != 2 || usage.RejectedToday != 1 || usage.Total != 2 || *usage.Remaining != 0 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if ok, _ := r.Use(key); !ok {
+		t.Fatal("Expected the quota to reset on the next day")
+	}
+	usage = r.Usage()[0]
+	if usage.UsedToday != 1 || usage.RejectedToday != 0 || usage.Total != 3 || *usage.Remaining != 1 {
+		t.Errorf("Unexpected usage after the reset: %+v", usage)
+	}
+	if usage.LastUsed == nil || !usage.LastUsed.Equal(now) {
+		t.Errorf("Expected last use at %v, but got %v", now, usage.LastUsed)
+	}
+}
+
+func TestRegistry_Unlimited(t *testing.T) {
+	key := Key{Name: "ci", Secret: "key1"}
+	r, err := New([]Key{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if ok, _ := r.Use(key); !ok {
+			t.Fatalf("Expected an unlimited key to allow use %d", i+1)
+		}
+	}
+	if usage := r.Usage()[0]; usage.Remaining != nil || usage.Total != 100 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
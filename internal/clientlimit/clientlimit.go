@@ -0,0 +1,93 @@
+// Package clientlimit rate-limits requests per client, such as per IP address or API key,
+// with a token bucket for each client.
+package clientlimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter hands out a token bucket per client key. It is safe for concurrent use.
+type Limiter struct {
+	limit rate.Limit
+	burst int
+	// idle is how long a client's bucket is kept after its last request. By then it has
+	// refilled, so forgetting it changes nothing.
+	idle time.Duration
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// New returns a limiter allowing each client perMinute requests per minute on average, in
+// bursts of up to burst requests. A non-positive perMinute allows everything.
+func New(perMinute float64, burst int) *Limiter {
+	l := &Limiter{
+		limit:   rate.Inf,
+		burst:   max(burst, 1),
+		clients: make(map[string]*client),
+		now:     time.Now,
+	}
+	if perMinute > 0 {
+		l.limit = rate.Limit(perMinute / 60)
+		// A full bucket refills within this time.
+		l.idle = time.Duration(float64(l.burst) / float64(l.limit) * float64(time.Second))
+	}
+	return l
+}
+
+// Allow reports whether the client identified by key may make a request now. If not, it
+// also returns how long until it may.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.limit == rate.Inf {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+
+	reservation := c.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep forgets the clients idle for longer than l.idle, at most once per l.idle.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idle {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) >= l.idle {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// clientCount returns the number of clients tracked, for tests.
+func (l *Limiter) clientCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.clients)
+}
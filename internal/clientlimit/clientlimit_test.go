@@ -0,0 +1,67 @@
+package clientlimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l := New(60, 2)
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	testCases := []struct {
+		name          string
+		key           string
+		advance       time.Duration
+		expectedOK    bool
+		expectedDelay time.Duration
+	}{
+		{name: "First request of a burst", key: "a", expectedOK: true},
+		{name: "Second request of a burst", key: "a", expectedOK: true},
+		{name: "Burst exhausted", key: "a", expectedOK: false, expectedDelay: time.Second},
+		{name: "Other clients are unaffected", key: "b", expectedOK: true},
+		{name: "Still limited before the refill", key: "a", advance: 500 * time.Millisecond, expectedOK: false, expectedDelay: 500 * time.Millisecond},
+		{name: "Refilled after a second", key: "a", advance: 500 * time.Millisecond, expectedOK: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.advance)
+			ok, delay := l.Allow(tc.key)
+			if ok != tc.expectedOK {
+				t.Errorf("Expected allowed %v, but got %v", tc.expectedOK, ok)
+			}
+			if delay != tc.expectedDelay {
+				t.Errorf("Expected delay %v, but got %v", tc.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := New(0, 1)
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("Expected an unlimited limiter to allow request %d", i)
+		}
+	}
+	if l.clientCount() != 0 {
+		t.Errorf("Expected no clients tracked without a limit, but got %d", l.clientCount())
+	}
+}
+
+func TestLimiter_ForgetsIdleClients(t *testing.T) {
+	l := New(60, 5)
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+	l.Allow("b")
+	now = now.Add(5 * time.Second)
+	l.Allow("c")
+
+	if l.clientCount() != 1 {
+		t.Errorf("Expected only the active client to be tracked, but got %d", l.clientCount())
+	}
+}
@@ -0,0 +1,116 @@
+// Package config loads server settings from a YAML file into command-line flags, so every
+// setting can be given as a flag, an environment variable or a config file entry.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variable of every setting.
+const EnvPrefix = "ANALYZER_"
+
+// File is a parsed config file: flag names mapped to their values. Repeatable flags may
+// have several values.
+type File map[string][]string
+
+// Load reads a YAML config file whose keys are flag names without the leading dash, e.g.
+//
+//	addr: ":8080"
+//	workers: 20
+//	result-cache-ttl: 5m
+//	header:
+//	  - "Accept-Language: en-GB"
+//
+// Values are scalars, or lists of scalars for repeatable flags.
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	f := make(File, len(raw))
+	for name, value := range raw {
+		values, err := scalars(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+		f[name] = values
+	}
+	return f, nil
+}
+
+// scalars converts a YAML value to the strings passed to flag.Value.Set.
+func scalars(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, errors.New("value is empty")
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := scalars(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(s) != 1 {
+				return nil, errors.New("lists must hold scalars only")
+			}
+			values = append(values, s[0])
+		}
+		return values, nil
+	case map[string]any:
+		return nil, errors.New("value must be a scalar or a list, not a mapping")
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// EnvName returns the environment variable of the flag name: EnvPrefix followed by the name
+// in upper case, with dashes as underscores.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Apply sets the flags of fs from f, except those given on the command line or through
+// their environment variable, as named by envName. Together with flags whose defaults are
+// read from the environment, this gives the precedence: command line, then environment,
+// then config file, then built-in default. fs must already be parsed.
+func Apply(fs *flag.FlagSet, f File, envName func(string) string) error {
+	given := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { given[fl.Name] = true })
+
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		if given[name] {
+			continue
+		}
+		if _, ok := os.LookupEnv(envName(name)); ok {
+			continue
+		}
+		for _, value := range f[name] {
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", value, name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected File
+		errText  string
+	}{
+		{
+			name:     "Scalars and lists",
+			content:  "addr: \":9090\"\nworkers: 20\nallow-private-networks: true\nresult-cache-ttl: 5m\nheader:\n  - \"A: 1\"\n  - \"B: 2\"\n",
+			expected: File{"addr": {":9090"}, "workers": {"20"}, "allow-private-networks": {"true"}, "result-cache-ttl": {"5m"}, "header": {"A: 1", "B: 2"}},
+		},
+		{name: "Empty value", content: "store:\n", errText: "value is empty"},
+		{name: "Nested mapping", content: "store:\n  path: x\n", errText: "not a mapping"},
+		{name: "Invalid YAML", content: "addr: [\n", errText: "parse"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Load(writeFile(t, tc.content))
+			if tc.errText != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errText) {
+					t.Fatalf("Expected an error containing %q, but got: %v", tc.errText, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if len(f) != len(tc.expected) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, f)
+			}
+			for name, values := range tc.expected {
+				if !slices.Equal(f[name], values) {
+					t.Errorf("Expected %s to be %v, but got %v", name, values, f[name])
+				}
+			}
+		})
+	}
+}
+
+func TestApply_Precedence(t *testing.T) {
+	t.Setenv("TEST_WORKERS", "30")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "")
+	workers := fs.Int("workers", 10, "")
+	ttl := fs.Duration("ttl", time.Minute, "")
+	store := fs.String("store", "memory", "")
+	var headers listFlag
+	fs.Var(&headers, "header", "")
+	if err := fs.Parse([]string{"-addr", ":7070"}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := File{"addr": {":9090"}, "workers": {"20"}, "ttl": {"5m"}, "header": {"A: 1", "B: 2"}}
+	envName := func(name string) string { return "TEST_" + strings.ToUpper(name) }
+	if err := Apply(fs, f, envName); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if *addr != ":7070" {
+		t.Errorf("Expected the command line to win, but got addr %q", *addr)
+	}
+	if *workers != 10 {
+		t.Errorf("Expected the file to be ignored for a setting in the environment, but got workers %d", *workers)
+	}
+	if *ttl != 5*time.Minute {
+		t.Errorf("Expected the file to set ttl, but got %v", *ttl)
+	}
+	if *store != "memory" {
+		t.Errorf("Expected the default for a setting given nowhere, but got store %q", *store)
+	}
+	if !slices.Equal(headers, listFlag{"A: 1", "B: 2"}) {
+		t.Errorf("Expected every listed header, but got %v", headers)
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    File
+		errText string
+	}{
+		{name: "Unknown setting", file: File{"wrokers": {"1"}}, errText: `unknown setting "wrokers"`},
+		{name: "Invalid value", file: File{"workers": {"many"}}, errText: `invalid value "many" for workers`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.Int("workers", 10, "")
+			if err := fs.Parse(nil); err != nil {
+				t.Fatal(err)
+			}
+			err := Apply(fs, tc.file, EnvName)
+			if err == nil || !strings.Contains(err.Error(), tc.errText) {
+				t.Errorf("Expected an error containing %q, but got: %v", tc.errText, err)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("result-cache-ttl"); got != "ANALYZER_RESULT_CACHE_TTL" {
+		t.Errorf("Expected ANALYZER_RESULT_CACHE_TTL, but got %s", got)
+	}
+}
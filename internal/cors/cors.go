@@ -0,0 +1,143 @@
+// Package cors lets browser pages on other origins call the server, by answering CORS
+// preflight requests and adding the Access-Control-* headers to responses.
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy says which cross-origin requests are allowed.
+type Policy struct {
+	// Origins are the allowed origins, such as https://dashboard.example.com. An origin may
+	// start its host with *. to allow every subdomain, and * alone allows any origin.
+	Origins []string
+	// Methods and Headers are the request methods and headers pages may use, besides the
+	// CORS-safelisted ones.
+	Methods []string
+	Headers []string
+	// ExposedHeaders are the response headers pages may read, besides the safelisted ones.
+	ExposedHeaders []string
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// Validate checks that every origin is *, or a scheme and host with an optional port and no
+// path.
+func (p Policy) Validate() error {
+	for _, origin := range p.Origins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+			return fmt.Errorf("invalid CORS origin %q: want scheme://host[:port] or *", origin)
+		}
+	}
+	return nil
+}
+
+// allowsOrigin reports whether origin, as sent in the Origin header, is allowed.
+func (p Policy) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.Origins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		scheme, domain, ok := strings.Cut(allowed, "://*.")
+		if !ok {
+			continue
+		}
+		host, ok := strings.CutPrefix(origin, scheme+"://")
+		if !ok {
+			continue
+		}
+		// The subdomain must be one or more host labels, without a port or path of its own.
+		subdomain, ok := strings.CutSuffix(host, "."+domain)
+		if ok && subdomain != "" && !strings.ContainsAny(subdomain, ":/") {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHeaders reports whether every header in the comma-separated list requested is allowed.
+func (p Policy) allowsHeaders(requested string) bool {
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(p.Headers, func(h string) bool { return strings.EqualFold(h, name) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler applies p to the requests for which match returns true, and passes every request
+// on to next except preflights. A preflight from an allowed origin asking for allowed
+// methods and headers is answered with 204 No Content, and any other with 403 Forbidden.
+// Requests with no or a disallowed Origin reach next without CORS headers, so browsers keep
+// their responses from the page.
+func (p Policy) Handler(match func(*http.Request) bool, next http.Handler) http.Handler {
+	methods := strings.Join(p.Methods, ", ")
+	headers := strings.Join(p.Headers, ", ")
+	exposed := strings.Join(p.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(p.MaxAge.Seconds()))
+	anyOrigin := slices.Contains(p.Origins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(p.Origins) == 0 || !match(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !anyOrigin {
+			// Responses differ by origin, so caches must not share them.
+			w.Header().Add("Vary", "Origin")
+		}
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" || !p.allowsOrigin(origin) {
+			if preflight {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowOrigin := origin
+		if anyOrigin {
+			allowOrigin = "*"
+		}
+		w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+		if !preflight {
+			if exposed != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		if !slices.Contains(p.Methods, method) || !p.allowsHeaders(r.Header.Get("Access-Control-Request-Headers")) {
+			http.Error(w, "Method or headers not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", methods)
+		if headers != "" {
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+		}
+		if p.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", maxAge)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
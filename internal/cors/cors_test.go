@@ -0,0 +1,174 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		origin  string
+		isValid bool
+	}{
+		{name: "Any origin", origin: "*", isValid: true},
+		{name: "Exact origin", origin: "https://dashboard.example.com", isValid: true},
+		{name: "Origin with port", origin: "http://localhost:3000", isValid: true},
+		{name: "Subdomain wildcard", origin: "https://*.example.com", isValid: true},
+		{name: "Missing scheme", origin: "dashboard.example.com", isValid: false},
+		{name: "With path", origin: "https://example.com/app", isValid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Policy{Origins: []string{tc.origin}}.Validate()
+			if (err == nil) != tc.isValid {
+				t.Errorf("Expected valid %v, but got error: %v", tc.isValid, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_AllowsOrigin(t *testing.T) {
+	p := Policy{Origins: []string{"https://dashboard.example.com", "https://*.example.org"}}
+	testCases := []struct {
+		origin   string
+		expected bool
+	}{
+		{origin: "https://dashboard.example.com", expected: true},
+		{origin: "https://Dashboard.Example.com", expected: true},
+		{origin: "http://dashboard.example.com", expected: false},
+		{origin: "https://evil.example.com", expected: false},
+		{origin: "https://a.example.org", expected: true},
+		{origin: "https://a.b.example.org", expected: true},
+		{origin: "https://example.org", expected: false},
+		{origin: "https://a.example.org:8443", expected: false},
+		{origin: "https://evilexample.org", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.origin, func(t *testing.T) {
+			if got := p.allowsOrigin(tc.origin); got != tc.expected {
+				t.Errorf("Expected %v, but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPolicy_Handler(t *testing.T) {
+	p := Policy{
+		Origins:        []string{"https://dashboard.example.com"},
+		Methods:        []string{"GET", "POST"},
+		Headers:        []string{"Content-Type", "X-API-Key"},
+		ExposedHeaders: []string{"Retry-After"},
+		MaxAge:         10 * time.Minute,
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := p.Handler(func(r *http.Request) bool { return strings.HasPrefix(r.URL.Path, "/api/") }, next)
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedHeader map[string]string
+	}{
+		{
+			name:           "Preflight from an allowed origin",
+			method:         http.MethodOptions,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "content-type, x-api-key"},
+			expectedStatus: http.StatusNoContent,
+			expectedHeader: map[string]string{"Access-Control-Allow-Origin": "https://dashboard.example.com", "Access-Control-Allow-Methods": "GET, POST", "Access-Control-Allow-Headers": "Content-Type, X-API-Key", "Access-Control-Max-Age": "600", "Vary": "Origin"},
+		},
+		{
+			name:           "Preflight for a method not allowed",
+			method:         http.MethodOptions,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "DELETE"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Preflight for a header not allowed",
+			method:         http.MethodOptions,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "Authorization"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Preflight from another origin",
+			method:         http.MethodOptions,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://evil.example", "Access-Control-Request-Method": "POST"},
+			expectedStatus: http.StatusForbidden,
+			expectedHeader: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "Request from an allowed origin",
+			method:         http.MethodPost,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com"},
+			expectedStatus: http.StatusTeapot,
+			expectedHeader: map[string]string{"Access-Control-Allow-Origin": "https://dashboard.example.com", "Access-Control-Expose-Headers": "Retry-After"},
+		},
+		{
+			name:           "Request from another origin",
+			method:         http.MethodPost,
+			path:           "/api/analyze",
+			headers:        map[string]string{"Origin": "https://evil.example"},
+			expectedStatus: http.StatusTeapot,
+			expectedHeader: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:           "Paths outside the match",
+			method:         http.MethodOptions,
+			path:           "/",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "POST"},
+			expectedStatus: http.StatusTeapot,
+			expectedHeader: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("Expected status %d, but got %d", tc.expectedStatus, rec.Code)
+			}
+			for name, value := range tc.expectedHeader {
+				if got := rec.Header().Get(name); got != value {
+					t.Errorf("Expected %s %q, but got %q", name, value, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_HandlerAnyOrigin(t *testing.T) {
+	p := Policy{Origins: []string{"*"}, Methods: []string{"GET"}}
+	handler := p.Handler(func(*http.Request) bool { return true }, http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diff", nil)
+	req.Header.Set("Origin", "https://anywhere.example")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected Access-Control-Allow-Origin *, but got %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "" {
+		t.Errorf("Expected no Vary header for any origin, but got %q", got)
+	}
+}
@@ -0,0 +1,57 @@
+package crawl
+
+import (
+	"cmp"
+	"encoding/xml"
+	"slices"
+)
+
+// BrokenLinkReport lists the broken internal links of a crawl by target, so each can be
+// fixed on the pages linking to it.
+type BrokenLinkReport struct {
+	XMLName xml.Name     `json:"-" xml:"broken_links"`
+	CrawlID string       `json:"crawl_id" xml:"crawl_id,attr"`
+	URL     string       `json:"url" xml:"url"`
+	Links   []BrokenLink `json:"links" xml:"link"`
+}
+
+// BrokenLink is an inaccessible internal link target and the pages of a crawl referencing it.
+type BrokenLink struct {
+	URL string `json:"url" xml:"url,attr"`
+	// StatusCode and Error are those of the link check on the first page found referencing it.
+	StatusCode int    `json:"status_code,omitempty" xml:"status_code,attr,omitempty"`
+	Error      string `json:"error,omitempty" xml:"error,omitempty"`
+	// Sources lists the URLs of the pages referencing the target, whether by a link, an
+	// iframe or a stylesheet.
+	Sources []string `json:"sources" xml:"source"`
+}
+
+// FindBrokenLinks returns the broken internal links of the pages of crawl, the targets
+// referenced by the most pages first.
+func FindBrokenLinks(crawl Crawl) BrokenLinkReport {
+	report := BrokenLinkReport{CrawlID: crawl.ID, URL: crawl.URL, Links: []BrokenLink{}}
+
+	index := make(map[string]int)
+	for _, page := range crawl.Pages {
+		for _, link := range page.broken {
+			key := pageKey(link.URL)
+			i, ok := index[key]
+			if !ok {
+				i = len(report.Links)
+				index[key] = i
+				report.Links = append(report.Links, BrokenLink{URL: link.URL, StatusCode: link.StatusCode, Error: link.Error})
+			}
+			if !slices.Contains(report.Links[i].Sources, page.URL) {
+				report.Links[i].Sources = append(report.Links[i].Sources, page.URL)
+			}
+		}
+	}
+
+	for _, link := range report.Links {
+		slices.Sort(link.Sources)
+	}
+	slices.SortFunc(report.Links, func(a, b BrokenLink) int {
+		return cmp.Or(cmp.Compare(len(b.Sources), len(a.Sources)), cmp.Compare(a.URL, b.URL))
+	})
+	return report
+}
@@ -0,0 +1,34 @@
+package crawl
+
+import (
+	"reflect"
+	"testing"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+func TestFindBrokenLinks(t *testing.T) {
+	gone := analyzer.LinkResult{URL: "https://example.com/gone", StatusCode: 404, Error: "HTTP 404"}
+	crawl := Crawl{
+		ID:  "c1",
+		URL: "https://example.com/",
+		Pages: []Page{
+			{URL: "https://example.com/b", broken: []analyzer.LinkResult{gone, {URL: "https://example.com/style.css", StatusCode: 500}}},
+			{URL: "https://example.com/", broken: []analyzer.LinkResult{{URL: "https://EXAMPLE.com/gone#top", StatusCode: 404}}},
+			{URL: "https://example.com/a", broken: []analyzer.LinkResult{gone, gone}},
+			{URL: "https://example.com/c", Error: "timeout"},
+		},
+	}
+
+	want := []BrokenLink{
+		{URL: "https://example.com/gone", StatusCode: 404, Error: "HTTP 404", Sources: []string{"https://example.com/", "https://example.com/a", "https://example.com/b"}},
+		{URL: "https://example.com/style.css", StatusCode: 500, Sources: []string{"https://example.com/b"}},
+	}
+	if report := FindBrokenLinks(crawl); !reflect.DeepEqual(report.Links, want) {
+		t.Errorf("Expected %+v, but got %+v", want, report.Links)
+	}
+
+	if report := FindBrokenLinks(Crawl{}); report.Links == nil || len(report.Links) != 0 {
+		t.Errorf("Expected an empty list of links, but got %#v", report.Links)
+	}
+}
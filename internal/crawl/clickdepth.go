@@ -0,0 +1,117 @@
+package crawl
+
+import (
+	"cmp"
+	"encoding/xml"
+	"net/url"
+	"slices"
+)
+
+// DeepClicks is the most clicks from the start page at which ClickDepths expects to find the
+// pages of a site; pages deeper than that are hard for visitors and search engines to reach.
+const DeepClicks = 3
+
+// ClickDepthReport gives the click depth of the pages of a crawl: the fewest links to follow
+// from the start page to reach each.
+type ClickDepthReport struct {
+	XMLName xml.Name `json:"-" xml:"click_depth"`
+	CrawlID string   `json:"crawl_id" xml:"crawl_id,attr"`
+	URL     string   `json:"url" xml:"url"`
+	// Pages lists the pages reachable from the start page, the shallowest first.
+	Pages []ClickDepth `json:"pages" xml:"pages>page"`
+	// Levels counts the pages at each click depth.
+	Levels []LevelCount `json:"levels" xml:"levels>level"`
+	// Deep lists the indexable pages, those analyzed successfully and not noindex, more than
+	// DeepClicks clicks deep, the deepest first.
+	Deep []ClickDepth `json:"deep" xml:"deep>page"`
+	// Unreachable lists the pages that no chain of links between the pages of the crawl
+	// leads to from the start page.
+	Unreachable []string `json:"unreachable" xml:"unreachable>url"`
+}
+
+// ClickDepth is the click depth of a page of a crawl.
+type ClickDepth struct {
+	URL    string `json:"url" xml:"url,attr"`
+	Clicks int    `json:"clicks" xml:"clicks,attr"`
+}
+
+// LevelCount is the number of pages of a crawl at a click depth.
+type LevelCount struct {
+	Clicks int `json:"clicks" xml:"clicks,attr"`
+	Pages  int `json:"pages" xml:"pages,attr"`
+}
+
+// ClickDepths returns the click depths of the pages of crawl, following the links between
+// them. The Depth of a Page, by contrast, is how deep the crawl happened to find a page, which may be
+// more. A sitemap crawl has no start page, so the home page of the sitemap's site is taken.
+func ClickDepths(crawl Crawl) ClickDepthReport {
+	report := ClickDepthReport{
+		CrawlID:     crawl.ID,
+		URL:         crawl.URL,
+		Pages:       []ClickDepth{},
+		Levels:      []LevelCount{},
+		Deep:        []ClickDepth{},
+		Unreachable: []string{},
+	}
+
+	index := make(map[string]int, len(crawl.Pages))
+	for i, page := range crawl.Pages {
+		index[pageKey(page.URL)] = i
+	}
+	clicks := make([]int, len(crawl.Pages))
+	for i := range clicks {
+		clicks[i] = -1
+	}
+
+	var queue []int
+	for i, page := range crawl.Pages {
+		if (!crawl.Sitemap && page.Depth == 0) || (crawl.Sitemap && pageKey(page.URL) == homePage(crawl.URL)) {
+			clicks[i] = 0
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, link := range crawl.Pages[i].links {
+			if j, ok := index[pageKey(link)]; ok && clicks[j] < 0 {
+				clicks[j] = clicks[i] + 1
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	for i, page := range crawl.Pages {
+		if clicks[i] < 0 {
+			report.Unreachable = append(report.Unreachable, page.URL)
+			continue
+		}
+		depth := ClickDepth{URL: page.URL, Clicks: clicks[i]}
+		report.Pages = append(report.Pages, depth)
+		if depth.Clicks > DeepClicks && page.Error == "" && !page.Noindex {
+			report.Deep = append(report.Deep, depth)
+		}
+		for level := len(report.Levels); level <= depth.Clicks; level++ {
+			report.Levels = append(report.Levels, LevelCount{Clicks: level})
+		}
+		report.Levels[depth.Clicks].Pages++
+	}
+
+	slices.SortFunc(report.Pages, func(a, b ClickDepth) int {
+		return cmp.Or(cmp.Compare(a.Clicks, b.Clicks), cmp.Compare(a.URL, b.URL))
+	})
+	slices.SortFunc(report.Deep, func(a, b ClickDepth) int {
+		return cmp.Or(cmp.Compare(b.Clicks, a.Clicks), cmp.Compare(a.URL, b.URL))
+	})
+	slices.Sort(report.Unreachable)
+	return report
+}
+
+// homePage returns the visitKey of the root page of the site of siteURL.
+func homePage(siteURL string) string {
+	u, err := url.Parse(siteURL)
+	if err != nil {
+		return ""
+	}
+	return visitKey(&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"})
+}
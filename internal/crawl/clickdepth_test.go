@@ -0,0 +1,59 @@
+package crawl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClickDepths(t *testing.T) {
+	// The crawl found /d through the long chain first, but /a links to it directly.
+	crawl := Crawl{
+		URL: "https://example.com/",
+		Pages: []Page{
+			{URL: "https://example.com/", links: []string{"https://example.com/a", "https://example.com/b"}},
+			{URL: "https://example.com/b", Depth: 1, links: []string{"https://example.com/c"}},
+			{URL: "https://example.com/c", Depth: 2, links: []string{"https://example.com/c2"}},
+			{URL: "https://example.com/c2", Depth: 3, links: []string{"https://example.com/c3"}},
+			{URL: "https://example.com/c3", Depth: 4, links: []string{"https://example.com/d", "https://example.com/deep", "https://example.com/private", "https://example.com/broken"}},
+			{URL: "https://example.com/a", Depth: 1, links: []string{"https://example.com/d#top"}},
+			{URL: "https://example.com/d", Depth: 5},
+			{URL: "https://example.com/deep", Depth: 5},
+			{URL: "https://example.com/private", Depth: 5, Noindex: true},
+			{URL: "https://example.com/broken", Depth: 5, Error: "not found"},
+			{URL: "https://example.com/lost", Depth: 2},
+		},
+	}
+
+	report := ClickDepths(crawl)
+	for _, page := range report.Pages {
+		if page.URL == "https://example.com/d" && page.Clicks != 2 {
+			t.Errorf("Expected /d 2 clicks deep, but got %d", page.Clicks)
+		}
+	}
+	if want := []LevelCount{{0, 1}, {1, 2}, {2, 2}, {3, 1}, {4, 1}, {5, 3}}; !reflect.DeepEqual(report.Levels, want) {
+		t.Errorf("Expected levels %v, but got %v", want, report.Levels)
+	}
+	want := []ClickDepth{{"https://example.com/deep", 5}, {"https://example.com/c3", 4}}
+	if !reflect.DeepEqual(report.Deep, want) {
+		t.Errorf("Expected deep pages %v, but got %v", want, report.Deep)
+	}
+	if want := []string{"https://example.com/lost"}; !reflect.DeepEqual(report.Unreachable, want) {
+		t.Errorf("Expected unreachable pages %q, but got %q", want, report.Unreachable)
+	}
+}
+
+func TestClickDepths_Sitemap(t *testing.T) {
+	crawl := Crawl{
+		URL:     "https://example.com/sitemap.xml",
+		Sitemap: true,
+		Pages: []Page{
+			{URL: "https://example.com/a", links: []string{"https://example.com/b"}},
+			{URL: "https://example.com/b"},
+			{URL: "https://example.com", links: []string{"https://example.com/a"}},
+		},
+	}
+	want := []ClickDepth{{"https://example.com", 0}, {"https://example.com/a", 1}, {"https://example.com/b", 2}}
+	if report := ClickDepths(crawl); !reflect.DeepEqual(report.Pages, want) || len(report.Unreachable) != 0 {
+		t.Errorf("Expected depths from the home page %v, but got %v and unreachable %q", want, report.Pages, report.Unreachable)
+	}
+}
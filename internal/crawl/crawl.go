@@ -0,0 +1,480 @@
+// Package crawl audits a site by analyzing a start page and then, breadth-first, the pages
+// on the same host it links to, or else the pages listed by the site's sitemap. Each page
+// is analyzed by a caller-supplied function, which may hand it to a queue shared by several
+// analyzer instances, so the pages of one crawl are spread over all of them while the crawl
+// itself keeps track of what was visited.
+package crawl
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// MaxCrawls bounds the crawls kept; once it is reached, starting a crawl forgets the oldest
+// finished one.
+const MaxCrawls = 50
+
+// ErrTooManyCrawls is returned by Start when MaxCrawls crawls are still running.
+var ErrTooManyCrawls = errors.New("too many crawls in progress")
+
+// Values of Crawl.Status.
+const (
+	StatusRunning  = "running"
+	StatusDone     = "done"
+	StatusCanceled = "canceled"
+	// StatusFailed means the crawl could not start analyzing pages, e.g. because its
+	// sitemap could not be read; Crawl.Error says why.
+	StatusFailed = "failed"
+)
+
+// Values of Options.Scope.
+const (
+	// ScopeHost keeps a crawl on the host of its start URL.
+	ScopeHost = "host"
+	// ScopeDomain keeps a crawl on the registrable domain of its start URL, such as
+	// example.co.uk for www.example.co.uk, so it covers the domain's subdomains too.
+	ScopeDomain = "domain"
+)
+
+// AnalyzeFunc analyzes one page of a crawl. The result is expected to be stored by the
+// caller, e.g. in the result store, and its ID is kept with the page.
+type AnalyzeFunc func(ctx context.Context, pageURL string) (*analyzer.AnalysisResult, error)
+
+// SitemapFunc returns the page URLs listed by the sitemap at sitemapURL, at most limit of
+// them.
+type SitemapFunc func(ctx context.Context, sitemapURL string, limit int) ([]string, error)
+
+// Options control a crawl.
+type Options struct {
+	// MaxPages bounds the pages analyzed; pages found beyond it are counted but not analyzed.
+	MaxPages int
+	// MaxDepth bounds the links followed from the start page to reach a page; 0 means no
+	// bound.
+	MaxDepth int
+	// Scope is ScopeHost, the default, or ScopeDomain.
+	Scope string
+	// Include, if not empty, limits the crawl to the URLs matching one of its patterns, and
+	// Exclude leaves out the URLs matching one of its. The start page is crawled regardless.
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+	// Sitemap makes the start URL a sitemap: the pages it lists in scope are analyzed, and
+	// the links on them are not followed.
+	Sitemap bool
+	// IgnoreRobots crawls pages robots.txt disallows, without the Crawl-delay it asks for.
+	IgnoreRobots bool
+}
+
+// Crawl is a site audit started from URL, with the pages analyzed so far.
+type Crawl struct {
+	ID  string `json:"id" xml:"id,attr"`
+	URL string `json:"url" xml:"url"`
+	// Sitemap is set when URL is a sitemap whose pages are audited, see Options.Sitemap.
+	Sitemap  bool     `json:"sitemap,omitempty" xml:"sitemap,omitempty"`
+	MaxPages int      `json:"max_pages" xml:"max_pages"`
+	MaxDepth int      `json:"max_depth,omitempty" xml:"max_depth,omitempty"`
+	Scope    string   `json:"scope" xml:"scope"`
+	Include  []string `json:"include,omitempty" xml:"include>pattern,omitempty"`
+	Exclude  []string `json:"exclude,omitempty" xml:"exclude>pattern,omitempty"`
+	// IgnoreRobots is set when the crawl disregards robots.txt, see Options.IgnoreRobots.
+	IgnoreRobots bool `json:"ignore_robots,omitempty" xml:"ignore_robots,omitempty"`
+	// Status is StatusRunning, StatusDone, StatusCanceled or StatusFailed.
+	Status string `json:"status" xml:"status"`
+	// Error says why a crawl failed.
+	Error      string    `json:"error,omitempty" xml:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at" xml:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero" xml:"finished_at,omitempty"`
+	// Queued counts the pages found but not analyzed yet. Pages still queued once a crawl is
+	// done were left out because of MaxPages.
+	Queued int `json:"queued" xml:"queued"`
+	// Pages lists the analyzed pages in the order their analyses finished.
+	Pages []Page `json:"pages" xml:"pages>page"`
+	// Disallowed lists the pages found but not analyzed because robots.txt disallows them.
+	Disallowed []string `json:"disallowed,omitempty" xml:"disallowed>url,omitempty"`
+}
+
+// Page is a page of a crawl. Exactly one of ResultID and Error is set.
+type Page struct {
+	URL string `json:"url" xml:"url,attr"`
+	// Depth is the number of links followed from the start page to find the page.
+	Depth int `json:"depth" xml:"depth,attr"`
+	// Referrer is the page the URL was first found on; empty for the start page.
+	Referrer string `json:"referrer,omitempty" xml:"referrer,attr,omitempty"`
+	ResultID string `json:"result_id,omitempty" xml:"result_id,attr,omitempty"`
+	Title    string `json:"title,omitempty" xml:"title,omitempty"`
+	Score    int    `json:"score,omitempty" xml:"score,attr,omitempty"`
+	Grade    string `json:"grade,omitempty" xml:"grade,attr,omitempty"`
+	Error    string `json:"error,omitempty" xml:"error,omitempty"`
+	// Description, HTMLVersion, BrokenLinks, the number of inaccessible links on the page,
+	// and ContentFingerprint are kept for the crawl's Report.
+	Description        string `json:"description,omitempty" xml:"description,omitempty"`
+	HTMLVersion        string `json:"html_version,omitempty" xml:"html_version,attr,omitempty"`
+	BrokenLinks        int    `json:"broken_links,omitempty" xml:"broken_links,attr,omitempty"`
+	ContentFingerprint string `json:"content_fingerprint,omitempty" xml:"content_fingerprint,attr,omitempty"`
+	// Noindex reports whether the page asks not to be indexed, which keeps it out of the
+	// crawl's sitemap.
+	Noindex bool `json:"noindex,omitempty" xml:"noindex,attr,omitempty"`
+	// Pagination holds the page's rel="next" and rel="prev" links, for the paginated series
+	// of the Report.
+	Pagination *analyzer.Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	// Classification marks a parking page or a web server's welcome page, which has no score.
+	Classification *analyzer.Classification `json:"classification,omitempty" xml:"classification,omitempty"`
+
+	// links are the internal pages the page links to, for the crawl's Graph, broken its
+	// inaccessible internal links, for FindBrokenLinks, and redirects the internal URLs found
+	// redirecting while crawling it, for the Report.
+	links     []string
+	broken    []analyzer.LinkResult
+	redirects []redirect
+}
+
+// redirect is an internal URL that redirected while a page was crawled: one of its links, if
+// linked, or else the page itself.
+type redirect struct {
+	url       string
+	redirects []string
+	linked    bool
+}
+
+// Crawler runs crawls in the background and keeps them for inspection. It is safe for
+// concurrent use.
+type Crawler struct {
+	logger  *slog.Logger
+	analyze AnalyzeFunc
+	sitemap SitemapFunc
+	robots  RobotsFunc
+	// concurrency is the number of pages of one crawl analyzed at once.
+	concurrency int
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	crawl  Crawl
+	cancel context.CancelFunc
+}
+
+// New returns a crawler that analyzes pages with analyze, up to concurrency (at least one)
+// of each crawl at once, lists the pages of sitemap crawls with sitemap and honors the
+// robots.txt files fetched with robots. A nil robots crawls every page without delay.
+func New(logger *slog.Logger, analyze AnalyzeFunc, sitemap SitemapFunc, robots RobotsFunc, concurrency int) *Crawler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Crawler{
+		logger:      logger,
+		analyze:     analyze,
+		sitemap:     sitemap,
+		robots:      robots,
+		concurrency: max(concurrency, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+		entries:     make(map[string]*entry),
+	}
+}
+
+// Start starts crawling from startURL, an absolute http or https URL, and returns the crawl
+// as it stands.
+func (c *Crawler) Start(startURL string, opts Options) (Crawl, error) {
+	start, err := url.Parse(startURL)
+	if err != nil {
+		return Crawl{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Crawl{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= MaxCrawls && !c.forgetOldestFinished() {
+		return Crawl{}, ErrTooManyCrawls
+	}
+
+	if opts.Scope == "" {
+		opts.Scope = ScopeHost
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	e := &entry{
+		crawl: Crawl{
+			ID:           id,
+			URL:          startURL,
+			Sitemap:      opts.Sitemap,
+			MaxPages:     max(opts.MaxPages, 1),
+			MaxDepth:     max(opts.MaxDepth, 0),
+			Scope:        opts.Scope,
+			Include:      patternStrings(opts.Include),
+			Exclude:      patternStrings(opts.Exclude),
+			IgnoreRobots: opts.IgnoreRobots,
+			Status:       StatusRunning,
+			StartedAt:    time.Now().UTC(),
+			Queued:       1,
+		},
+		cancel: cancel,
+	}
+	c.entries[id] = e
+	c.wg.Add(1)
+	go c.run(ctx, e, start, opts)
+	c.logger.Info("Crawl started", "id", id, "url", startURL, "sitemap", opts.Sitemap, "max_pages", e.crawl.MaxPages)
+	return c.snapshot(e), nil
+}
+
+// forgetOldestFinished removes the finished crawl that started first and reports whether
+// there was one. c.mu must be held.
+func (c *Crawler) forgetOldestFinished() bool {
+	var oldest *entry
+	for _, e := range c.entries {
+		if e.crawl.Status != StatusRunning && (oldest == nil || e.crawl.StartedAt.Before(oldest.crawl.StartedAt)) {
+			oldest = e
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(c.entries, oldest.crawl.ID)
+	return true
+}
+
+// Remove cancels the crawl with the given ID if it is running, forgets it and reports
+// whether it existed.
+func (c *Crawler) Remove(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return false
+	}
+	e.cancel()
+	delete(c.entries, id)
+	c.logger.Info("Crawl removed", "id", id, "url", e.crawl.URL)
+	return true
+}
+
+// Get returns the crawl with the given ID.
+func (c *Crawler) Get(id string) (Crawl, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return Crawl{}, false
+	}
+	return c.snapshot(e), true
+}
+
+// List returns all crawls, oldest first, without their pages, analyzed or disallowed.
+func (c *Crawler) List() []Crawl {
+	c.mu.Lock()
+	list := make([]Crawl, 0, len(c.entries))
+	for _, e := range c.entries {
+		crawl := e.crawl
+		crawl.Pages, crawl.Disallowed = nil, nil
+		list = append(list, crawl)
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b Crawl) int {
+		return cmp.Or(a.StartedAt.Compare(b.StartedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return list
+}
+
+// Stop cancels the running crawls and waits for them to return.
+func (c *Crawler) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// snapshot copies the crawl of e so callers cannot race with its run. c.mu must be held.
+func (c *Crawler) snapshot(e *entry) Crawl {
+	crawl := e.crawl
+	crawl.Pages = append([]Page{}, e.crawl.Pages...)
+	crawl.Disallowed = slices.Clone(e.crawl.Disallowed)
+	return crawl
+}
+
+// pageDone is a page whose analysis has returned.
+type pageDone struct {
+	page   Page
+	result *analyzer.AnalysisResult
+	err    error
+}
+
+// run crawls from start, analyzing up to c.concurrency pages at once. Only run touches the
+// frontier and politeness, so the pages found are deduplicated and the robots.txt files
+// applied without locking.
+func (c *Crawler) run(ctx context.Context, e *entry, start *url.URL, opts Options) {
+	defer c.wg.Done()
+	defer e.cancel()
+
+	f := newFrontier(start, opts)
+	if !e.crawl.Sitemap {
+		f.start(e.crawl.URL)
+	} else if err := c.seedSitemap(ctx, e, f); err != nil {
+		c.mu.Lock()
+		e.crawl.Status, e.crawl.Error, e.crawl.Queued, e.crawl.FinishedAt = StatusFailed, err.Error(), 0, time.Now().UTC()
+		if ctx.Err() != nil {
+			e.crawl.Status, e.crawl.Error = StatusCanceled, ""
+		}
+		c.mu.Unlock()
+		c.logger.Warn("Crawl failed", "id", e.crawl.ID, "url", e.crawl.URL, "error", err)
+		return
+	}
+	c.mu.Lock()
+	e.crawl.Queued = f.len()
+	c.mu.Unlock()
+
+	polite := newPoliteness(c.robots)
+	if opts.IgnoreRobots {
+		polite = newPoliteness(nil)
+	}
+	done := make(chan pageDone)
+	inFlight, started := 0, 0
+	// held is a page waiting out the crawl delay of its origin, and wait fires when it is over.
+	var held *Page
+	for {
+		var wait <-chan time.Time
+		for inFlight < c.concurrency && started < e.crawl.MaxPages && ctx.Err() == nil {
+			var page Page
+			var ok bool
+			if held != nil {
+				page, ok, held = *held, true, nil
+			} else if page, ok = f.next(); ok && !polite.allows(ctx, page.URL) {
+				c.mu.Lock()
+				e.crawl.Disallowed = append(e.crawl.Disallowed, page.URL)
+				e.crawl.Queued = f.len()
+				c.mu.Unlock()
+				continue
+			}
+			if !ok {
+				break
+			}
+			if delay := polite.reserve(ctx, page.URL, time.Now()); delay > 0 {
+				held, wait = &page, time.After(delay)
+				break
+			}
+			started++
+			inFlight++
+			go func() {
+				result, err := c.analyze(ctx, page.URL)
+				done <- pageDone{page: page, result: result, err: err}
+			}()
+		}
+		if inFlight == 0 && wait == nil {
+			break
+		}
+
+		// Waiting out a crawl delay ends early if the crawl is canceled.
+		var canceled <-chan struct{}
+		if wait != nil {
+			canceled = ctx.Done()
+		}
+		var d pageDone
+		select {
+		case <-wait:
+			continue
+		case <-canceled:
+			continue
+		case d = <-done:
+		}
+		inFlight--
+		page := d.page
+		var redirectErr *analyzer.RedirectError
+		if errors.As(d.err, &redirectErr) {
+			page.redirects = append(page.redirects, redirect{url: page.URL, redirects: redirectErr.Redirects})
+		}
+		if d.err != nil {
+			page.Error = d.err.Error()
+		} else {
+			page.ResultID, page.Title, page.Score, page.Grade = d.result.ID, d.result.Title, d.result.Score, d.result.Grade
+			page.Description, page.HTMLVersion, page.BrokenLinks = d.result.Description, d.result.HTMLVersion, d.result.Links.InaccessibleCount
+			page.ContentFingerprint, page.Noindex, page.Pagination = d.result.ContentFingerprint, d.result.Noindex, d.result.Pagination
+			page.Classification = d.result.Classification
+			if len(d.result.Redirects) > 0 {
+				page.redirects = append(page.redirects, redirect{url: page.URL, redirects: d.result.Redirects})
+			}
+			for _, link := range d.result.LinkResults {
+				if link.Type != analyzer.LinkTypeInternal {
+					continue
+				}
+				if len(link.Redirects) > 0 {
+					page.redirects = append(page.redirects, redirect{url: link.URL, redirects: link.Redirects, linked: true})
+				}
+				if link.Status == analyzer.LinkStatusInaccessible {
+					page.broken = append(page.broken, link)
+				}
+				if link.Kind != analyzer.LinkKindLink {
+					continue
+				}
+				if !slices.Contains(page.links, link.URL) {
+					page.links = append(page.links, link.URL)
+				}
+				if !e.crawl.Sitemap && link.Status != analyzer.LinkStatusInaccessible {
+					f.add(link.URL, page.Depth+1, page.URL)
+				}
+			}
+		}
+
+		c.mu.Lock()
+		e.crawl.Pages = append(e.crawl.Pages, page)
+		e.crawl.Queued = f.len()
+		if held != nil {
+			e.crawl.Queued++
+		}
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	e.crawl.Status, e.crawl.FinishedAt = StatusDone, time.Now().UTC()
+	if ctx.Err() != nil {
+		e.crawl.Status = StatusCanceled
+	}
+	status, pages, queued := e.crawl.Status, len(e.crawl.Pages), e.crawl.Queued
+	c.mu.Unlock()
+	c.logger.Info("Crawl finished", "id", e.crawl.ID, "url", e.crawl.URL, "status", status, "pages", pages, "queued", queued)
+}
+
+// seedSitemap queues the pages listed by the sitemap of a sitemap crawl.
+func (c *Crawler) seedSitemap(ctx context.Context, e *entry, f *frontier) error {
+	if c.sitemap == nil {
+		return errors.New("sitemap crawls are not supported")
+	}
+	urls, err := c.sitemap(ctx, e.crawl.URL, e.crawl.MaxPages)
+	if err != nil {
+		return err
+	}
+	for _, pageURL := range urls {
+		f.add(pageURL, 0, "")
+	}
+	return nil
+}
+
+// patternStrings returns the source text of patterns.
+func patternStrings(patterns []*regexp.Regexp) []string {
+	var strs []string
+	for _, re := range patterns {
+		strs = append(strs, re.String())
+	}
+	return strs
+}
+
+func newID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
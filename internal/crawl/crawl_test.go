@@ -0,0 +1,327 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// site analyzes the pages of a fake site, whose pages link to the given URLs. Pages missing
+// from it fail to analyze.
+type site map[string][]string
+
+func (s site) analyze(_ context.Context, pageURL string) (*analyzer.AnalysisResult, error) {
+	links, ok := s[pageURL]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	result := &analyzer.AnalysisResult{ID: "id:" + pageURL, Title: "Title of " + pageURL}
+	page, _ := url.Parse(pageURL)
+	for _, link := range links {
+		u, _ := url.Parse(link)
+		linkType := analyzer.LinkTypeExternal
+		if u.Host == page.Host {
+			linkType = analyzer.LinkTypeInternal
+		}
+		result.LinkResults = append(result.LinkResults, analyzer.LinkResult{URL: link, Type: linkType, Kind: analyzer.LinkKindLink, Status: analyzer.LinkStatusOK})
+	}
+	return result, nil
+}
+
+// waitDone polls the crawl until it is no longer running.
+func waitDone(t *testing.T, c *Crawler, id string) Crawl {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if crawl, ok := c.Get(id); ok && crawl.Status != StatusRunning {
+			return crawl
+		}
+	}
+	t.Fatal("Expected the crawl to finish")
+	return Crawl{}
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	s := site{
+		"https://example.com/":  {"https://example.com/a", "https://example.com/b#top", "https://other.example/x"},
+		"https://example.com/a": {"https://example.com/", "https://example.com/missing"},
+		"https://example.com/b": {"https://example.com/a", "https://EXAMPLE.com/b"},
+	}
+	c := New(testLogger, s.analyze, nil, nil, 2)
+	defer c.Stop()
+
+	crawl, err := c.Start("https://example.com/", Options{MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Expected the crawl to start, but got %v", err)
+	}
+	crawl = waitDone(t, c, crawl.ID)
+
+	if crawl.Status != StatusDone || crawl.Queued != 0 {
+		t.Errorf("Expected a finished crawl with nothing queued, but got status %q with %d queued", crawl.Status, crawl.Queued)
+	}
+	slices.SortFunc(crawl.Pages, func(a, b Page) int { return a.Depth - b.Depth })
+	var urls []string
+	for _, page := range crawl.Pages {
+		urls = append(urls, page.URL)
+	}
+	if len(urls) != 4 || urls[0] != "https://example.com/" || !slices.Contains(urls, "https://example.com/b") || !slices.Contains(urls, "https://example.com/missing") {
+		t.Fatalf("Expected each same-host page once, but got %q", urls)
+	}
+	for _, page := range crawl.Pages {
+		switch page.URL {
+		case "https://example.com/":
+			if page.Depth != 0 || page.Referrer != "" || page.ResultID != "id:https://example.com/" {
+				t.Errorf("Expected the start page at depth 0 with its result, but got %+v", page)
+			}
+		case "https://example.com/b":
+			if page.Depth != 1 || page.ResultID == "" {
+				t.Errorf("Expected the page linked with a fragment to be analyzed without it, but got %+v", page)
+			}
+		case "https://example.com/missing":
+			if page.Depth != 2 || page.Referrer != "https://example.com/a" || page.Error != "not found" {
+				t.Errorf("Expected the missing page at depth 2 with an error, but got %+v", page)
+			}
+		}
+	}
+}
+
+func TestCrawler_MaxPages(t *testing.T) {
+	s := site{"https://example.com/": {"https://example.com/a", "https://example.com/b", "https://example.com/c"}}
+	c := New(testLogger, s.analyze, nil, nil, 1)
+	defer c.Stop()
+
+	crawl, _ := c.Start("https://example.com/", Options{MaxPages: 2})
+	crawl = waitDone(t, c, crawl.ID)
+	if len(crawl.Pages) != 2 || crawl.Queued != 2 {
+		t.Errorf("Expected 2 pages with 2 left queued, but got %d pages and %d queued", len(crawl.Pages), crawl.Queued)
+	}
+}
+
+func TestCrawler_Remove(t *testing.T) {
+	started := make(chan struct{})
+	c := New(testLogger, func(ctx context.Context, _ string) (*analyzer.AnalysisResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, nil, nil, 1)
+
+	crawl, _ := c.Start("https://example.com/", Options{MaxPages: 10})
+	<-started
+	if list := c.List(); len(list) != 1 || list[0].ID != crawl.ID || list[0].Status != StatusRunning {
+		t.Errorf("Expected the running crawl to be listed, but got %+v", list)
+	}
+	if !c.Remove(crawl.ID) {
+		t.Error("Expected removing a running crawl to succeed")
+	}
+	if _, ok := c.Get(crawl.ID); ok {
+		t.Error("Expected a removed crawl to be gone")
+	}
+	// Stop returns only once the canceled crawl has.
+	c.Stop()
+}
+
+func TestVisitKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://example.com", "https://EXAMPLE.com/", true},
+		{"https://example.com/a#x", "HTTPS://example.com/a", true},
+		{"https://example.com/a?q=1", "https://example.com/a?q=2", false},
+		{"https://example.com/a", "http://example.com/a", false},
+	}
+	for _, tt := range tests {
+		a, _ := url.Parse(tt.a)
+		b, _ := url.Parse(tt.b)
+		if same := visitKey(a) == visitKey(b); same != tt.same {
+			t.Errorf("Expected %q and %q to be the same page: %v, but got %v", tt.a, tt.b, tt.same, same)
+		}
+	}
+}
+
+func TestCrawler_Sitemap(t *testing.T) {
+	s := site{
+		"https://example.com/":  {"https://example.com/linked"},
+		"https://example.com/a": nil,
+	}
+	var limit int
+	listSitemap := func(_ context.Context, sitemapURL string, max int) ([]string, error) {
+		limit = max
+		if sitemapURL != "https://example.com/sitemap.xml" {
+			return nil, errors.New("not a sitemap")
+		}
+		return []string{"https://example.com/", "https://example.com/a", "https://example.com/#x", "https://other.example/"}, nil
+	}
+	c := New(testLogger, s.analyze, listSitemap, nil, 2)
+	defer c.Stop()
+
+	crawl, _ := c.Start("https://example.com/sitemap.xml", Options{MaxPages: 5, Sitemap: true})
+	crawl = waitDone(t, c, crawl.ID)
+	if crawl.Status != StatusDone || !crawl.Sitemap || limit != 5 {
+		t.Errorf("Expected a finished sitemap crawl reading up to 5 URLs, but got status %q and limit %d", crawl.Status, limit)
+	}
+	var urls []string
+	for _, page := range crawl.Pages {
+		urls = append(urls, page.URL)
+		if page.Depth != 0 || page.Referrer != "" {
+			t.Errorf("Expected sitemap pages at depth 0 without a referrer, but got %+v", page)
+		}
+	}
+	slices.Sort(urls)
+	if want := []string{"https://example.com/", "https://example.com/a"}; !slices.Equal(urls, want) {
+		t.Errorf("Expected the listed same-host pages %q without following links, but got %q", want, urls)
+	}
+
+	failed, _ := c.Start("https://example.com/feed.xml", Options{MaxPages: 5, Sitemap: true})
+	failed = waitDone(t, c, failed.ID)
+	if failed.Status != StatusFailed || failed.Error != "not a sitemap" || len(failed.Pages) != 0 {
+		t.Errorf("Expected the crawl of an unreadable sitemap to fail, but got %+v", failed)
+	}
+}
+
+func TestFrontier_Scope(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		url   string
+		depth int
+		want  bool
+	}{
+		{"same host", Options{}, "https://www.example.co.uk/a", 1, true},
+		{"subdomain, host scope", Options{}, "https://shop.example.co.uk/a", 1, false},
+		{"subdomain, domain scope", Options{Scope: ScopeDomain}, "https://shop.example.co.uk/a", 1, true},
+		{"apex, domain scope", Options{Scope: ScopeDomain}, "https://example.co.uk/", 1, true},
+		{"other domain under the same suffix", Options{Scope: ScopeDomain}, "https://other.co.uk/", 1, false},
+		{"within max depth", Options{MaxDepth: 2}, "https://www.example.co.uk/a", 2, true},
+		{"beyond max depth", Options{MaxDepth: 2}, "https://www.example.co.uk/a", 3, false},
+		{"included", Options{Include: []*regexp.Regexp{regexp.MustCompile(`/blog/`)}}, "https://www.example.co.uk/blog/post", 1, true},
+		{"not included", Options{Include: []*regexp.Regexp{regexp.MustCompile(`/blog/`)}}, "https://www.example.co.uk/shop/", 1, false},
+		{"excluded", Options{Exclude: []*regexp.Regexp{regexp.MustCompile(`\?page=`)}}, "https://www.example.co.uk/list?page=2", 1, false},
+		{"excluded by fragment only", Options{Exclude: []*regexp.Regexp{regexp.MustCompile(`#`)}}, "https://www.example.co.uk/a#top", 1, true},
+	}
+	start, _ := url.Parse("https://www.example.co.uk/")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newFrontier(start, tt.opts).add(tt.url, tt.depth, ""); got != tt.want {
+				t.Errorf("Expected %q at depth %d to be queued: %v, but got %v", tt.url, tt.depth, tt.want, got)
+			}
+		})
+	}
+
+	f := newFrontier(start, Options{Include: []*regexp.Regexp{regexp.MustCompile(`/blog/`)}})
+	if !f.start("https://www.example.co.uk/") {
+		t.Error("Expected the start page to be queued whatever the include patterns")
+	}
+}
+
+func TestCrawler_MaxDepth(t *testing.T) {
+	s := site{
+		"https://example.com/":  {"https://example.com/a"},
+		"https://example.com/a": {"https://example.com/b"},
+		"https://example.com/b": nil,
+	}
+	c := New(testLogger, s.analyze, nil, nil, 1)
+	defer c.Stop()
+
+	crawl, _ := c.Start("https://example.com/", Options{MaxPages: 10, MaxDepth: 1})
+	crawl = waitDone(t, c, crawl.ID)
+	if len(crawl.Pages) != 2 || crawl.MaxDepth != 1 || crawl.Scope != ScopeHost {
+		t.Errorf("Expected the pages up to depth 1 of a host-scoped crawl, but got %+v", crawl)
+	}
+}
+
+// fakeRobots disallows the URLs containing /private and asks for delay between requests.
+type fakeRobots struct{ delay time.Duration }
+
+func (r fakeRobots) Allows(pageURL string) bool { return !strings.Contains(pageURL, "/private") }
+func (r fakeRobots) CrawlDelay() time.Duration  { return r.delay }
+
+func TestCrawler_Robots(t *testing.T) {
+	s := site{
+		"https://example.com/":          {"https://example.com/a", "https://example.com/private/x", "https://example.com/b"},
+		"https://example.com/a":         nil,
+		"https://example.com/b":         nil,
+		"https://example.com/private/x": nil,
+	}
+	var mu sync.Mutex
+	var starts []time.Time
+	analyze := func(ctx context.Context, pageURL string) (*analyzer.AnalysisResult, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return s.analyze(ctx, pageURL)
+	}
+	const delay = 20 * time.Millisecond
+	var fetched []string
+	robots := func(_ context.Context, pageURL string) Robots {
+		fetched = append(fetched, pageURL)
+		return fakeRobots{delay: delay}
+	}
+	c := New(testLogger, analyze, nil, robots, 4)
+	defer c.Stop()
+
+	crawl, _ := c.Start("https://example.com/", Options{MaxPages: 10})
+	crawl = waitDone(t, c, crawl.ID)
+	if len(crawl.Pages) != 3 || !slices.Equal(crawl.Disallowed, []string{"https://example.com/private/x"}) {
+		t.Errorf("Expected 3 pages with the private one disallowed, but got %d pages and %q disallowed", len(crawl.Pages), crawl.Disallowed)
+	}
+	if len(fetched) != 1 {
+		t.Errorf("Expected robots.txt to be fetched once for the origin, but got %d fetches", len(fetched))
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < delay {
+			t.Errorf("Expected fetches at least %v apart, but fetch %d followed after %v", delay, i, gap)
+		}
+	}
+
+	ignoring, _ := c.Start("https://example.com/", Options{MaxPages: 10, IgnoreRobots: true})
+	ignoring = waitDone(t, c, ignoring.ID)
+	if len(ignoring.Pages) != 4 || len(ignoring.Disallowed) != 0 || !ignoring.IgnoreRobots {
+		t.Errorf("Expected every page crawled when ignoring robots.txt, but got %d pages and %q disallowed", len(ignoring.Pages), ignoring.Disallowed)
+	}
+}
+
+func TestCrawler_Redirects(t *testing.T) {
+	analyze := func(_ context.Context, pageURL string) (*analyzer.AnalysisResult, error) {
+		switch pageURL {
+		case "https://example.com/":
+			return &analyzer.AnalysisResult{Redirects: []string{"https://example.com/home"}, LinkResults: []analyzer.LinkResult{
+				{URL: "https://example.com/loop", Type: analyzer.LinkTypeInternal, Kind: analyzer.LinkKindLink, Status: analyzer.LinkStatusOK, Redirects: []string{"https://example.com/loop2", "https://example.com/loop"}},
+				{URL: "https://example.com/ok", Type: analyzer.LinkTypeInternal, Kind: analyzer.LinkKindLink, Status: analyzer.LinkStatusOK},
+			}}, nil
+		case "https://example.com/loop":
+			return nil, &analyzer.RedirectError{Redirects: []string{"https://example.com/loop2", "https://example.com/loop"}}
+		}
+		return &analyzer.AnalysisResult{}, nil
+	}
+	c := New(testLogger, analyze, nil, nil, 1)
+	defer c.Stop()
+
+	crawl, _ := c.Start("https://example.com/", Options{MaxPages: 10})
+	crawl = waitDone(t, c, crawl.ID)
+	redirects := make(map[string][]redirect)
+	for _, page := range crawl.Pages {
+		redirects[page.URL] = page.redirects
+	}
+	if want := []redirect{
+		{url: "https://example.com/", redirects: []string{"https://example.com/home"}},
+		{url: "https://example.com/loop", redirects: []string{"https://example.com/loop2", "https://example.com/loop"}, linked: true},
+	}; !reflect.DeepEqual(redirects["https://example.com/"], want) {
+		t.Errorf("Expected the start page's redirects %+v, but got %+v", want, redirects["https://example.com/"])
+	}
+	if want := []redirect{{url: "https://example.com/loop", redirects: []string{"https://example.com/loop2", "https://example.com/loop"}}}; !reflect.DeepEqual(redirects["https://example.com/loop"], want) {
+		t.Errorf("Expected the failed page's redirect loop %+v, but got %+v", want, redirects["https://example.com/loop"])
+	}
+}
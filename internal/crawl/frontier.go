@@ -0,0 +1,120 @@
+package crawl
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// frontier holds the pages of a crawl waiting to be analyzed, in the order they were found,
+// and every URL seen so far, so each page is analyzed once however many pages link to it.
+// It only takes pages in the crawl's scope.
+type frontier struct {
+	scope    string
+	site     string
+	maxDepth int
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	seen     map[string]bool
+	waiting  []Page
+}
+
+func newFrontier(start *url.URL, opts Options) *frontier {
+	f := &frontier{
+		scope:    opts.Scope,
+		maxDepth: opts.MaxDepth,
+		include:  opts.Include,
+		exclude:  opts.Exclude,
+		seen:     make(map[string]bool),
+	}
+	f.site = f.siteOf(start)
+	return f
+}
+
+// siteOf returns what u must share with the start page to be in scope: its host or, with
+// ScopeDomain, its registrable domain. Hosts without one, such as IP addresses, are their
+// own domain.
+func (f *frontier) siteOf(u *url.URL) string {
+	if f.scope != ScopeDomain {
+		return strings.ToLower(u.Host)
+	}
+	host := strings.ToLower(u.Hostname())
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
+
+// start queues the start page of a crawl, which is crawled whatever the include and
+// exclude patterns say.
+func (f *frontier) start(pageURL string) bool {
+	return f.queue(pageURL, 0, "", false)
+}
+
+// add queues pageURL without its fragment, found on referrer at depth links from the start
+// page, unless it has been seen before or is out of scope: on another site, deeper than
+// MaxDepth, or not matching the include and exclude patterns. It reports whether the URL
+// was queued.
+func (f *frontier) add(pageURL string, depth int, referrer string) bool {
+	return f.queue(pageURL, depth, referrer, true)
+}
+
+func (f *frontier) queue(pageURL string, depth int, referrer string, filter bool) bool {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || f.siteOf(u) != f.site {
+		return false
+	}
+	if f.maxDepth > 0 && depth > f.maxDepth {
+		return false
+	}
+	u.Fragment, u.RawFragment = "", ""
+	if filter && !f.matches(u.String()) {
+		return false
+	}
+	key := visitKey(u)
+	if f.seen[key] {
+		return false
+	}
+	f.seen[key] = true
+	f.waiting = append(f.waiting, Page{URL: u.String(), Depth: depth, Referrer: referrer})
+	return true
+}
+
+// matches reports whether pageURL matches one of the include patterns, if there are any,
+// and none of the exclude patterns.
+func (f *frontier) matches(pageURL string) bool {
+	match := func(re *regexp.Regexp) bool { return re.MatchString(pageURL) }
+	if len(f.include) > 0 && !slices.ContainsFunc(f.include, match) {
+		return false
+	}
+	return !slices.ContainsFunc(f.exclude, match)
+}
+
+// next takes the page that has waited longest, so the crawl goes breadth-first.
+func (f *frontier) next() (Page, bool) {
+	if len(f.waiting) == 0 {
+		return Page{}, false
+	}
+	page := f.waiting[0]
+	f.waiting = f.waiting[1:]
+	return page, true
+}
+
+func (f *frontier) len() int {
+	return len(f.waiting)
+}
+
+// visitKey identifies the page at u: URLs differing only in the case of the scheme and host,
+// in the fragment or in an empty path are the same page.
+func visitKey(u *url.URL) string {
+	page := *u
+	page.Scheme, page.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	page.Fragment, page.RawFragment = "", ""
+	if page.Path == "" {
+		page.Path, page.RawPath = "/", ""
+	}
+	return page.String()
+}
@@ -0,0 +1,140 @@
+package crawl
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// WeakInbound is the most pages linking to a page for Graph to list it as weakly linked.
+const WeakInbound = 1
+
+// Graph is the internal link graph of a crawl: its pages and which of them link to which.
+type Graph struct {
+	XMLName xml.Name `json:"-" xml:"graph"`
+	CrawlID string   `json:"crawl_id" xml:"crawl_id,attr"`
+	URL     string   `json:"url" xml:"url"`
+	Nodes   []Node   `json:"nodes" xml:"nodes>node"`
+	Edges   []Edge   `json:"edges" xml:"edges>edge"`
+	// Orphans lists the pages that no other page of the crawl links to, apart from the start
+	// page of a crawl following links. Such a crawl only finds pages that are linked, so
+	// orphans turn up in sitemap crawls: pages the sitemap lists but the site does not link.
+	Orphans []string `json:"orphans" xml:"orphans>url"`
+	// WeaklyLinked lists the pages that at most WeakInbound other pages link to, with the
+	// same exception.
+	WeaklyLinked []string `json:"weakly_linked" xml:"weakly_linked>url"`
+}
+
+// Node is a page of a crawl in its Graph.
+type Node struct {
+	URL   string `json:"url" xml:"url,attr"`
+	Depth int    `json:"depth" xml:"depth,attr"`
+	// Inbound counts the other pages of the crawl linking to the page, and Outbound those
+	// it links to.
+	Inbound  int  `json:"inbound" xml:"inbound,attr"`
+	Outbound int  `json:"outbound" xml:"outbound,attr"`
+	Failed   bool `json:"failed,omitempty" xml:"failed,attr,omitempty"`
+}
+
+// Edge is a link from one page of a crawl to another.
+type Edge struct {
+	From string `json:"from" xml:"from,attr"`
+	To   string `json:"to" xml:"to,attr"`
+}
+
+// BuildGraph returns the link graph of the pages of crawl. Links to pages the crawl did not
+// analyze, e.g. because of its scope or MaxPages, are left out.
+func BuildGraph(crawl Crawl) Graph {
+	graph := Graph{
+		CrawlID:      crawl.ID,
+		URL:          crawl.URL,
+		Nodes:        []Node{},
+		Edges:        []Edge{},
+		Orphans:      []string{},
+		WeaklyLinked: []string{},
+	}
+
+	pages := slices.Clone(crawl.Pages)
+	slices.SortFunc(pages, func(a, b Page) int {
+		return cmp.Or(cmp.Compare(a.Depth, b.Depth), cmp.Compare(a.URL, b.URL))
+	})
+	index := make(map[string]int, len(pages))
+	for i, page := range pages {
+		index[pageKey(page.URL)] = i
+		graph.Nodes = append(graph.Nodes, Node{URL: page.URL, Depth: page.Depth, Failed: page.Error != ""})
+	}
+
+	for i, page := range pages {
+		linked := make(map[int]bool)
+		for _, link := range page.links {
+			j, ok := index[pageKey(link)]
+			if !ok || j == i || linked[j] {
+				continue
+			}
+			linked[j] = true
+			graph.Edges = append(graph.Edges, Edge{From: page.URL, To: pages[j].URL})
+			graph.Nodes[i].Outbound++
+			graph.Nodes[j].Inbound++
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		switch {
+		case node.Depth == 0 && !crawl.Sitemap:
+			// The start page needs no links to it.
+		case node.Inbound == 0:
+			graph.Orphans = append(graph.Orphans, node.URL)
+		case node.Inbound <= WeakInbound:
+			graph.WeaklyLinked = append(graph.WeaklyLinked, node.URL)
+		}
+	}
+	return graph
+}
+
+// pageKey is the visitKey of pageURL, or pageURL itself if it cannot be parsed.
+func pageKey(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	return visitKey(u)
+}
+
+// WriteDOT writes graph in the DOT language of Graphviz, with orphans and weakly linked
+// pages highlighted, e.g. for rendering with "dot -Tsvg".
+func WriteDOT(w io.Writer, graph Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote("crawl "+graph.CrawlID))
+	fmt.Fprintf(bw, "\tlabel=%s;\n\tnode [shape=box];\n", dotQuote(graph.URL))
+	for _, node := range graph.Nodes {
+		var attrs []string
+		switch {
+		case node.Failed:
+			attrs = append(attrs, `color="gray"`, `fontcolor="gray"`)
+		case slices.Contains(graph.Orphans, node.URL):
+			attrs = append(attrs, `color="red"`)
+		case slices.Contains(graph.WeaklyLinked, node.URL):
+			attrs = append(attrs, `color="orange"`)
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(bw, "\t%s;\n", dotQuote(node.URL))
+		} else {
+			fmt.Fprintf(bw, "\t%s [%s];\n", dotQuote(node.URL), strings.Join(attrs, ", "))
+		}
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(bw, "\t%s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
+
+// dotQuote returns s as a quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
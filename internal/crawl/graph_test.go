@@ -0,0 +1,79 @@
+package crawl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildGraph(t *testing.T) {
+	crawl := Crawl{
+		ID:  "c1",
+		URL: "https://example.com/",
+		Pages: []Page{
+			{URL: "https://example.com/", links: []string{"https://example.com/a", "https://example.com/b", "https://example.com/b#x", "https://example.com/#top", "https://example.com/out-of-scope"}},
+			{URL: "https://example.com/a", Depth: 1, links: []string{"https://EXAMPLE.com/b", "https://example.com"}},
+			{URL: "https://example.com/b", Depth: 1},
+			{URL: "https://example.com/c", Depth: 2, Error: "not found"},
+		},
+	}
+
+	graph := BuildGraph(crawl)
+	wantEdges := []Edge{
+		{"https://example.com/", "https://example.com/a"},
+		{"https://example.com/", "https://example.com/b"},
+		{"https://example.com/a", "https://example.com/b"},
+		{"https://example.com/a", "https://example.com/"},
+	}
+	if !reflect.DeepEqual(graph.Edges, wantEdges) {
+		t.Errorf("Expected edges %v, but got %v", wantEdges, graph.Edges)
+	}
+	wantNodes := []Node{
+		{URL: "https://example.com/", Inbound: 1, Outbound: 2},
+		{URL: "https://example.com/a", Depth: 1, Inbound: 1, Outbound: 2},
+		{URL: "https://example.com/b", Depth: 1, Inbound: 2},
+		{URL: "https://example.com/c", Depth: 2, Failed: true},
+	}
+	if !reflect.DeepEqual(graph.Nodes, wantNodes) {
+		t.Errorf("Expected nodes %+v, but got %+v", wantNodes, graph.Nodes)
+	}
+	if want := []string{"https://example.com/c"}; !reflect.DeepEqual(graph.Orphans, want) {
+		t.Errorf("Expected orphans %q, but got %q", want, graph.Orphans)
+	}
+	if want := []string{"https://example.com/a"}; !reflect.DeepEqual(graph.WeaklyLinked, want) {
+		t.Errorf("Expected weakly linked pages %q, but got %q", want, graph.WeaklyLinked)
+	}
+
+	// In a sitemap crawl, every page needs links to it.
+	crawl.Sitemap = true
+	if graph := BuildGraph(crawl); !reflect.DeepEqual(graph.WeaklyLinked, []string{"https://example.com/", "https://example.com/a"}) {
+		t.Errorf("Expected the sitemap's first page to count as weakly linked, but got %q", graph.WeaklyLinked)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	graph := Graph{
+		CrawlID:      "c1",
+		URL:          "https://example.com/",
+		Nodes:        []Node{{URL: "https://example.com/"}, {URL: `https://example.com/"q"`}, {URL: "https://example.com/orphan"}},
+		Edges:        []Edge{{From: "https://example.com/", To: `https://example.com/"q"`}},
+		Orphans:      []string{"https://example.com/orphan"},
+		WeaklyLinked: []string{`https://example.com/"q"`},
+	}
+	var b strings.Builder
+	if err := WriteDOT(&b, graph); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := `digraph "crawl c1" {
+	label="https://example.com/";
+	node [shape=box];
+	"https://example.com/";
+	"https://example.com/\"q\"" [color="orange"];
+	"https://example.com/orphan" [color="red"];
+	"https://example.com/" -> "https://example.com/\"q\"";
+}
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", want, b.String())
+	}
+}
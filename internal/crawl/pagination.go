@@ -0,0 +1,230 @@
+package crawl
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pageNumberParams are the query parameters commonly holding the number of a page in a
+// paginated series, as in /blog?page=2. "p" is left out, as it often identifies a post.
+var pageNumberParams = []string{"page", "pg", "paged"}
+
+// pageNumberPath matches a page number at the end of a path, as in /blog/page/2 or /blog/page2.
+var pageNumberPath = regexp.MustCompile(`(?i)/page[-/]?(\d+)/?$`)
+
+// Series is a paginated series of pages of a crawl.
+type Series struct {
+	// Pages lists the pages of the series found by the crawl, in order.
+	Pages []string `json:"pages" xml:"url"`
+	// Markup reports whether pages of the series declare rel="next" and rel="prev" links,
+	// rather than the series only being recognized by the page numbers in its URLs.
+	Markup bool `json:"markup" xml:"markup,attr"`
+	// Issues describes the problems found with the pagination of the series.
+	Issues []string `json:"issues" xml:"issue"`
+}
+
+// paginatedSeries groups pages into paginated series, linking the pages declaring each other
+// as their next or previous page and the pages whose URLs differ only in a page number.
+// Series of a single page are left out unless they have issues.
+func paginatedSeries(pages []Page) []Series {
+	index := make(map[string]int, len(pages))
+	for i, page := range pages {
+		index[pageKey(page.URL)] = i
+	}
+
+	// group holds the index of the first page of each page's series, merging series as links
+	// between them are found.
+	group := make([]int, len(pages))
+	for i := range group {
+		group[i] = i
+	}
+	root := func(i int) int {
+		for group[i] != i {
+			i = group[i]
+		}
+		return i
+	}
+	join := func(i, j int) {
+		a, b := root(i), root(j)
+		group[max(a, b)] = min(a, b)
+	}
+
+	numbers := make([]int, len(pages))
+	numbered := make(map[string]int)
+	for i, page := range pages {
+		if page.Pagination != nil {
+			for _, target := range []string{page.Pagination.Next, page.Pagination.Prev} {
+				if j, ok := index[pageKey(target)]; target != "" && ok {
+					join(i, j)
+				}
+			}
+		}
+		if key, number, ok := seriesKey(page.URL); ok {
+			numbers[i] = number
+			if j, ok := numbered[key]; ok {
+				join(i, j)
+			} else {
+				numbered[key] = i
+			}
+		}
+	}
+	// The first page of a series is often its URL without a page number.
+	for i, page := range pages {
+		if j, ok := numbered[seriesBase(page.URL)]; ok && numbers[i] == 0 {
+			numbers[i] = 1
+			join(i, j)
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range pages {
+		members[root(i)] = append(members[root(i)], i)
+	}
+	series := []Series{}
+	for _, indexes := range members {
+		s := Series{Pages: []string{}, Issues: []string{}}
+		var unmarked []string
+		for _, i := range indexes {
+			page := pages[i]
+			if page.Pagination == nil {
+				if page.Error == "" {
+					unmarked = append(unmarked, page.URL)
+				}
+				continue
+			}
+			s.Markup = true
+			s.Issues = append(s.Issues, paginationIssues(page, "next", page.Pagination.Next, pages, index)...)
+			s.Issues = append(s.Issues, paginationIssues(page, "previous", page.Pagination.Prev, pages, index)...)
+		}
+		if len(indexes) < 2 && len(s.Issues) == 0 {
+			continue
+		}
+		if !s.Markup {
+			s.Issues = append(s.Issues, `The series declares no rel="next" or rel="prev" links.`)
+		} else {
+			slices.Sort(unmarked)
+			for _, u := range unmarked {
+				s.Issues = append(s.Issues, fmt.Sprintf(`%s declares no rel="next" or rel="prev" links, unlike the rest of the series.`, u))
+			}
+		}
+		for _, i := range seriesOrder(indexes, pages, index, numbers) {
+			s.Pages = append(s.Pages, pages[i].URL)
+		}
+		series = append(series, s)
+	}
+	slices.SortFunc(series, func(a, b Series) int {
+		return cmp.Or(cmp.Compare(len(b.Pages), len(a.Pages)), cmp.Compare(a.Pages[0], b.Pages[0]))
+	})
+	return series
+}
+
+// paginationIssues returns the problems with the next or previous page, target, that page
+// declares: pointing at itself, at a page that failed, or at a page that does not point back.
+// Targets the crawl did not reach are not checked.
+func paginationIssues(page Page, rel, target string, pages []Page, index map[string]int) []string {
+	if target == "" {
+		return nil
+	}
+	if pageKey(target) == pageKey(page.URL) {
+		return []string{fmt.Sprintf("%s declares itself as its %s page.", page.URL, rel)}
+	}
+	j, ok := index[pageKey(target)]
+	if !ok {
+		return nil
+	}
+	other := pages[j]
+	if other.Error != "" {
+		return []string{fmt.Sprintf("The %s page of %s, %s, failed: %s", rel, page.URL, other.URL, other.Error)}
+	}
+	back, backRel := "", "previous"
+	if other.Pagination != nil {
+		back = other.Pagination.Prev
+	}
+	if rel == "previous" {
+		backRel = "next"
+		if other.Pagination != nil {
+			back = other.Pagination.Next
+		}
+	}
+	if pageKey(back) != pageKey(page.URL) {
+		return []string{fmt.Sprintf("%s declares %s as its %s page, but %s does not declare it as its %s page.", page.URL, other.URL, rel, other.URL, backRel)}
+	}
+	return nil
+}
+
+// seriesOrder orders the pages of a series, following their rel="next" links from the pages
+// that no other page of the series declares as its next page, then by page number and URL.
+func seriesOrder(indexes []int, pages []Page, index map[string]int, numbers []int) []int {
+	next := make(map[int]int)
+	pointedAt := make(map[int]bool)
+	for _, i := range indexes {
+		if pages[i].Pagination == nil || pages[i].Pagination.Next == "" {
+			continue
+		}
+		if j, ok := index[pageKey(pages[i].Pagination.Next)]; ok && j != i {
+			next[i] = j
+			pointedAt[j] = true
+		}
+	}
+	starts := slices.Clone(indexes)
+	slices.SortFunc(starts, func(a, b int) int {
+		if pointedAt[a] != pointedAt[b] {
+			if pointedAt[a] {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(numbers[a], numbers[b]), cmp.Compare(pages[a].URL, pages[b].URL))
+	})
+
+	var order []int
+	seen := make(map[int]bool)
+	for _, i := range starts {
+		for ok := true; ok && !seen[i]; i, ok = next[i] {
+			seen[i] = true
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// seriesKey returns the URL of a page without its page number, along with the number, if the
+// URL has one in a common place. Pages sharing the key belong to the same series.
+func seriesKey(pageURL string) (key string, number int, ok bool) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", 0, false
+	}
+	query := u.Query()
+	for _, name := range pageNumberParams {
+		if n, err := strconv.Atoi(query.Get(name)); err == nil && n > 0 {
+			query.Del(name)
+			u.RawQuery = query.Encode()
+			return seriesBase(u.String()), n, true
+		}
+	}
+	if m := pageNumberPath.FindStringSubmatchIndex(u.Path); m != nil {
+		if n, err := strconv.Atoi(u.Path[m[2]:m[3]]); err == nil && n > 0 {
+			u.Path, u.RawPath = u.Path[:m[0]], ""
+			return seriesBase(u.String()), n, true
+		}
+	}
+	return "", 0, false
+}
+
+// seriesBase returns the visitKey of pageURL with its query sorted and no trailing slash, so
+// /blog/ and /blog/page/2 share the base of their series.
+func seriesBase(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	u.Path, u.RawPath = strings.TrimSuffix(u.Path, "/"), ""
+	u.RawQuery = u.Query().Encode()
+	return visitKey(u)
+}
@@ -0,0 +1,68 @@
+package crawl
+
+import (
+	"cmp"
+	"slices"
+)
+
+// LongRedirectChain is the fewest redirects in a row for a Report to flag them as a long
+// chain. A single redirect, such as from http to https, is normal.
+const LongRedirectChain = 2
+
+// Redirect is an internal URL of a crawl that redirects in a loop or through a long chain.
+type Redirect struct {
+	URL string `json:"url" xml:"url,attr"`
+	// Redirects lists the URLs it redirects to, in order. A loop ends with a URL visited
+	// before.
+	Redirects []string `json:"redirects" xml:"redirects>url"`
+	// Sources lists the pages of the crawl linking to the URL, to fix the links on. A start
+	// page or a page listed in a sitemap may have none.
+	Sources []string `json:"sources" xml:"sources>url"`
+}
+
+// findRedirects returns the redirect loops and long redirect chains found crawling pages,
+// those linked from the most pages first.
+func findRedirects(pages []Page) (loops, chains []Redirect) {
+	var found []Redirect
+	index := make(map[string]int)
+	for _, page := range pages {
+		for _, r := range page.redirects {
+			key := pageKey(r.url)
+			i, ok := index[key]
+			if !ok {
+				i = len(found)
+				index[key] = i
+				found = append(found, Redirect{URL: r.url, Redirects: r.redirects, Sources: []string{}})
+			}
+			if r.linked && !slices.Contains(found[i].Sources, page.URL) {
+				found[i].Sources = append(found[i].Sources, page.URL)
+			}
+		}
+	}
+
+	loops, chains = []Redirect{}, []Redirect{}
+	for _, r := range found {
+		slices.Sort(r.Sources)
+		switch {
+		case isRedirectLoop(r):
+			loops = append(loops, r)
+		case len(r.Redirects) >= LongRedirectChain:
+			chains = append(chains, r)
+		}
+	}
+	byImpact := func(a, b Redirect) int {
+		return cmp.Or(cmp.Compare(len(b.Sources), len(a.Sources)), cmp.Compare(len(b.Redirects), len(a.Redirects)), cmp.Compare(a.URL, b.URL))
+	}
+	slices.SortFunc(loops, byImpact)
+	slices.SortFunc(chains, byImpact)
+	return loops, chains
+}
+
+// isRedirectLoop reports whether r ends up back at a URL it had already visited.
+func isRedirectLoop(r Redirect) bool {
+	last := pageKey(r.Redirects[len(r.Redirects)-1])
+	if last == pageKey(r.URL) {
+		return true
+	}
+	return slices.ContainsFunc(r.Redirects[:len(r.Redirects)-1], func(u string) bool { return pageKey(u) == last })
+}
@@ -0,0 +1,223 @@
+package crawl
+
+import (
+	"cmp"
+	"encoding/xml"
+	"slices"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+const (
+	// WorstPages is the number of lowest-scoring pages listed in a Report.
+	WorstPages = 10
+	// NearDuplicateDistance is the most bits in which the content fingerprints of two pages
+	// may differ for a Report to count them as near duplicates.
+	NearDuplicateDistance = 3
+)
+
+// Report rolls the pages of a crawl up into a site report.
+type Report struct {
+	XMLName xml.Name `json:"-" xml:"report"`
+	CrawlID string   `json:"crawl_id" xml:"crawl_id,attr"`
+	URL     string   `json:"url" xml:"url"`
+	// Status is the crawl's status; the report of a running crawl covers the pages so far.
+	Status string `json:"status" xml:"status"`
+	// Pages counts the pages analyzed, and FailedPages those whose analysis failed, which the
+	// rest of the report leaves out.
+	Pages       int `json:"pages" xml:"pages"`
+	FailedPages int `json:"failed_pages" xml:"failed_pages"`
+	// BrokenLinks totals the inaccessible links over all pages, counting a link once per page
+	// it is on.
+	BrokenLinks int `json:"broken_links" xml:"broken_links"`
+	// MissingTitle and MissingDescription list the URLs of the pages without a title or meta
+	// description.
+	MissingTitle       []string `json:"missing_title" xml:"missing_title>url"`
+	MissingDescription []string `json:"missing_description" xml:"missing_description>url"`
+	// DuplicateTitles and DuplicateDescriptions group the pages sharing a title or meta
+	// description, the largest groups first.
+	DuplicateTitles       []Duplicate `json:"duplicate_titles" xml:"duplicate_titles>duplicate"`
+	DuplicateDescriptions []Duplicate `json:"duplicate_descriptions" xml:"duplicate_descriptions>duplicate"`
+	// RedirectLoops lists the internal URLs redirecting in a loop, and RedirectChains those
+	// going through at least LongRedirectChain redirects, with the pages linking to each.
+	RedirectLoops  []Redirect `json:"redirect_loops" xml:"redirect_loops>redirect"`
+	RedirectChains []Redirect `json:"redirect_chains" xml:"redirect_chains>redirect"`
+	// PaginatedSeries groups the pages into paginated series, the longest first, with the
+	// problems found with their pagination.
+	PaginatedSeries []Series `json:"paginated_series" xml:"paginated_series>series"`
+	// NearDuplicates groups the pages with nearly the same visible text, candidates for a
+	// canonical URL, the largest groups first.
+	NearDuplicates []NearDuplicate `json:"near_duplicates" xml:"near_duplicates>group"`
+	// HTMLVersions counts the pages of each HTML version, most common first.
+	HTMLVersions []VersionCount `json:"html_versions" xml:"html_versions>version"`
+	// ClassifiedPages lists the parking pages and web server welcome pages, which are not
+	// scored, in URL order.
+	ClassifiedPages []Page `json:"classified_pages" xml:"classified_pages>page"`
+	// WorstPages lists up to WorstPages of the scored pages with the lowest scores, lowest
+	// first.
+	WorstPages []Page `json:"worst_pages" xml:"worst_pages>page"`
+}
+
+// VersionCount is the number of pages of a crawl with an HTML version.
+type VersionCount struct {
+	Version string `json:"version" xml:"name,attr"`
+	Pages   int    `json:"pages" xml:"pages,attr"`
+}
+
+// Duplicate is a title or meta description shared by several pages of a crawl.
+type Duplicate struct {
+	Text  string   `json:"text" xml:"text"`
+	Pages []string `json:"pages" xml:"pages>url"`
+}
+
+// NearDuplicate is a group of pages of a crawl with nearly the same content: each page's
+// fingerprint is within NearDuplicateDistance of another's in the group.
+type NearDuplicate struct {
+	Pages []string `json:"pages" xml:"url"`
+	// Distance is the most bits in which the fingerprints of two pages of the group differ; 0
+	// means their text is the same.
+	Distance int `json:"distance" xml:"distance,attr"`
+}
+
+// Summarize returns the site report of crawl.
+func Summarize(crawl Crawl) Report {
+	report := Report{
+		CrawlID:            crawl.ID,
+		URL:                crawl.URL,
+		Status:             crawl.Status,
+		Pages:              len(crawl.Pages),
+		MissingTitle:       []string{},
+		MissingDescription: []string{},
+		HTMLVersions:       []VersionCount{},
+		ClassifiedPages:    []Page{},
+	}
+
+	versions := make(map[string]int)
+	titles, descriptions := make(map[string][]string), make(map[string][]string)
+	var analyzed []Page
+	for _, page := range crawl.Pages {
+		if page.Error != "" {
+			report.FailedPages++
+			continue
+		}
+		analyzed = append(analyzed, page)
+		report.BrokenLinks += page.BrokenLinks
+		if page.Title == "" {
+			report.MissingTitle = append(report.MissingTitle, page.URL)
+		} else {
+			titles[page.Title] = append(titles[page.Title], page.URL)
+		}
+		if page.Description == "" {
+			report.MissingDescription = append(report.MissingDescription, page.URL)
+		} else {
+			descriptions[page.Description] = append(descriptions[page.Description], page.URL)
+		}
+		versions[page.HTMLVersion]++
+	}
+	slices.Sort(report.MissingTitle)
+	slices.Sort(report.MissingDescription)
+	report.DuplicateTitles = duplicates(titles)
+	report.DuplicateDescriptions = duplicates(descriptions)
+	report.NearDuplicates = nearDuplicates(analyzed)
+	report.PaginatedSeries = paginatedSeries(crawl.Pages)
+	// Failed pages count here, as a page redirecting in a loop fails.
+	report.RedirectLoops, report.RedirectChains = findRedirects(crawl.Pages)
+
+	for version, pages := range versions {
+		report.HTMLVersions = append(report.HTMLVersions, VersionCount{Version: version, Pages: pages})
+	}
+	slices.SortFunc(report.HTMLVersions, func(a, b VersionCount) int {
+		return cmp.Or(cmp.Compare(b.Pages, a.Pages), cmp.Compare(a.Version, b.Version))
+	})
+
+	var scored []Page
+	for _, page := range analyzed {
+		if page.Classification != nil {
+			report.ClassifiedPages = append(report.ClassifiedPages, page)
+		} else {
+			scored = append(scored, page)
+		}
+	}
+	slices.SortFunc(report.ClassifiedPages, func(a, b Page) int { return cmp.Compare(a.URL, b.URL) })
+	slices.SortFunc(scored, func(a, b Page) int {
+		return cmp.Or(cmp.Compare(a.Score, b.Score), cmp.Compare(a.URL, b.URL))
+	})
+	report.WorstPages = scored[:min(len(scored), WorstPages)]
+	if report.WorstPages == nil {
+		report.WorstPages = []Page{}
+	}
+	return report
+}
+
+// duplicates returns the texts of pages shared by more than one page, the most pages first.
+func duplicates(pages map[string][]string) []Duplicate {
+	groups := []Duplicate{}
+	for text, urls := range pages {
+		if len(urls) > 1 {
+			slices.Sort(urls)
+			groups = append(groups, Duplicate{Text: text, Pages: urls})
+		}
+	}
+	slices.SortFunc(groups, func(a, b Duplicate) int {
+		return cmp.Or(cmp.Compare(len(b.Pages), len(a.Pages)), cmp.Compare(a.Text, b.Text))
+	})
+	return groups
+}
+
+// nearDuplicates groups the pages whose content fingerprints are within NearDuplicateDistance
+// of each other, directly or through other pages of the group.
+func nearDuplicates(pages []Page) []NearDuplicate {
+	var fingerprinted []Page
+	for _, page := range pages {
+		if page.ContentFingerprint != "" {
+			fingerprinted = append(fingerprinted, page)
+		}
+	}
+
+	// group holds the index of the first page of each page's group, merging groups as close
+	// pairs are found.
+	group := make([]int, len(fingerprinted))
+	for i := range group {
+		group[i] = i
+	}
+	root := func(i int) int {
+		for group[i] != i {
+			i = group[i]
+		}
+		return i
+	}
+	for i := range fingerprinted {
+		for j := i + 1; j < len(fingerprinted); j++ {
+			distance, ok := analyzer.FingerprintDistance(fingerprinted[i].ContentFingerprint, fingerprinted[j].ContentFingerprint)
+			if ok && distance <= NearDuplicateDistance {
+				a, b := root(i), root(j)
+				group[max(a, b)] = min(a, b)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range fingerprinted {
+		members[root(i)] = append(members[root(i)], i)
+	}
+	groups := []NearDuplicate{}
+	for _, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		var dup NearDuplicate
+		for n, i := range indexes {
+			dup.Pages = append(dup.Pages, fingerprinted[i].URL)
+			for _, j := range indexes[n+1:] {
+				distance, _ := analyzer.FingerprintDistance(fingerprinted[i].ContentFingerprint, fingerprinted[j].ContentFingerprint)
+				dup.Distance = max(dup.Distance, distance)
+			}
+		}
+		slices.Sort(dup.Pages)
+		groups = append(groups, dup)
+	}
+	slices.SortFunc(groups, func(a, b NearDuplicate) int {
+		return cmp.Or(cmp.Compare(len(b.Pages), len(a.Pages)), cmp.Compare(a.Pages[0], b.Pages[0]))
+	})
+	return groups
+}
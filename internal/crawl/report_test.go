@@ -0,0 +1,215 @@
+package crawl
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+func TestSummarize(t *testing.T) {
+	crawl := Crawl{
+		ID:     "c1",
+		URL:    "https://example.com/",
+		Status: StatusDone,
+		Pages: []Page{
+			{URL: "https://example.com/", Title: "Home", Description: "Welcome", HTMLVersion: "HTML5", Score: 90, BrokenLinks: 1},
+			{URL: "https://example.com/b", Description: "B", HTMLVersion: "HTML 4.01", Score: 40, BrokenLinks: 2},
+			{URL: "https://example.com/a", Title: "A", HTMLVersion: "HTML5", Score: 40},
+			{URL: "https://example.com/missing", Error: "not found"},
+		},
+	}
+
+	report := Summarize(crawl)
+	if report.CrawlID != "c1" || report.Status != StatusDone || report.Pages != 4 || report.FailedPages != 1 {
+		t.Errorf("Expected 4 pages with 1 failed, but got %+v", report)
+	}
+	if report.BrokenLinks != 3 {
+		t.Errorf("Expected 3 broken links, but got %d", report.BrokenLinks)
+	}
+	if want := []string{"https://example.com/b"}; !reflect.DeepEqual(report.MissingTitle, want) {
+		t.Errorf("Expected missing titles %q, but got %q", want, report.MissingTitle)
+	}
+	if want := []string{"https://example.com/a"}; !reflect.DeepEqual(report.MissingDescription, want) {
+		t.Errorf("Expected missing descriptions %q, but got %q", want, report.MissingDescription)
+	}
+	if want := []VersionCount{{"HTML5", 2}, {"HTML 4.01", 1}}; !reflect.DeepEqual(report.HTMLVersions, want) {
+		t.Errorf("Expected HTML versions %v, but got %v", want, report.HTMLVersions)
+	}
+	var worst []string
+	for _, page := range report.WorstPages {
+		worst = append(worst, page.URL)
+	}
+	if want := []string{"https://example.com/a", "https://example.com/b", "https://example.com/"}; !reflect.DeepEqual(worst, want) {
+		t.Errorf("Expected worst pages %q, but got %q", want, worst)
+	}
+}
+
+func TestSummarize_WorstPagesLimit(t *testing.T) {
+	var crawl Crawl
+	for i := range WorstPages + 5 {
+		crawl.Pages = append(crawl.Pages, Page{URL: fmt.Sprintf("https://example.com/%d", i), Score: 100 - i})
+	}
+	report := Summarize(crawl)
+	if len(report.WorstPages) != WorstPages || report.WorstPages[0].Score != 100-(WorstPages+4) {
+		t.Errorf("Expected the %d lowest-scoring pages, but got %+v", WorstPages, report.WorstPages)
+	}
+	if empty := Summarize(Crawl{}); empty.WorstPages == nil || empty.MissingTitle == nil || empty.HTMLVersions == nil || empty.ClassifiedPages == nil {
+		t.Errorf("Expected empty lists rather than nil for a crawl without pages, but got %+v", empty)
+	}
+}
+
+func TestSummarize_ClassifiedPages(t *testing.T) {
+	parked := &analyzer.Classification{Kind: analyzer.ClassificationParked, Signature: "Sedo"}
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/", Score: 80},
+		{URL: "https://example.com/shop/", Classification: &analyzer.Classification{Kind: analyzer.ClassificationServerDefault, Signature: "nginx"}},
+		{URL: "https://example.com/old/", Classification: parked},
+	}}
+	report := Summarize(crawl)
+	var classified []string
+	for _, page := range report.ClassifiedPages {
+		classified = append(classified, page.URL)
+	}
+	if want := []string{"https://example.com/old/", "https://example.com/shop/"}; !reflect.DeepEqual(classified, want) {
+		t.Errorf("Expected classified pages %q, but got %q", want, classified)
+	}
+	if len(report.WorstPages) != 1 || report.WorstPages[0].URL != "https://example.com/" {
+		t.Errorf("Expected only the scored page among the worst pages, but got %+v", report.WorstPages)
+	}
+}
+
+func TestSummarize_Duplicates(t *testing.T) {
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/a", Title: "A", Description: "Same"},
+		{URL: "https://example.com/a2", Title: "A", Description: "Same"},
+		{URL: "https://example.com/b", Title: "B", Description: "B"},
+		{URL: "https://example.com/b2", Title: "B2", Description: "B"},
+		{URL: "https://example.com/d", Description: "Same"},
+		{URL: "https://example.com/c"},
+		{URL: "https://example.com/failed", Title: "B", Error: "timeout"},
+	}}
+
+	report := Summarize(crawl)
+	if want := []Duplicate{{"A", []string{"https://example.com/a", "https://example.com/a2"}}}; !reflect.DeepEqual(report.DuplicateTitles, want) {
+		t.Errorf("Expected duplicate titles %v, but got %v", want, report.DuplicateTitles)
+	}
+	want := []Duplicate{
+		{"Same", []string{"https://example.com/a", "https://example.com/a2", "https://example.com/d"}},
+		{"B", []string{"https://example.com/b", "https://example.com/b2"}},
+	}
+	if !reflect.DeepEqual(report.DuplicateDescriptions, want) {
+		t.Errorf("Expected duplicate descriptions %v, but got %v", want, report.DuplicateDescriptions)
+	}
+	if empty := Summarize(Crawl{}); empty.DuplicateTitles == nil || empty.DuplicateDescriptions == nil {
+		t.Errorf("Expected empty lists rather than nil for a crawl without pages, but got %+v", empty)
+	}
+}
+
+func TestSummarize_NearDuplicates(t *testing.T) {
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/a", ContentFingerprint: "0000000000000000"},
+		{URL: "https://example.com/c", ContentFingerprint: "0000000000000007"},
+		{URL: "https://example.com/b", ContentFingerprint: "000000000000003f"},
+		{URL: "https://example.com/far", ContentFingerprint: "ffffffffffffffff"},
+		{URL: "https://example.com/x", ContentFingerprint: "ff00ff00ff00ff00"},
+		{URL: "https://example.com/y", ContentFingerprint: "ff00ff00ff00ff00"},
+		{URL: "https://example.com/empty"},
+		{URL: "https://example.com/failed", ContentFingerprint: "ff00ff00ff00ff00", Error: "timeout"},
+	}}
+
+	want := []NearDuplicate{
+		{Pages: []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}, Distance: 6},
+		{Pages: []string{"https://example.com/x", "https://example.com/y"}},
+	}
+	if got := Summarize(crawl).NearDuplicates; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected near duplicates %+v, but got %+v", want, got)
+	}
+	if empty := Summarize(Crawl{}); empty.NearDuplicates == nil || len(empty.NearDuplicates) != 0 {
+		t.Errorf("Expected an empty list rather than nil for a crawl without pages, but got %#v", empty.NearDuplicates)
+	}
+}
+
+func TestSummarize_Redirects(t *testing.T) {
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/", redirects: []redirect{
+			{url: "https://example.com/old", redirects: []string{"https://example.com/older", "https://example.com/new"}, linked: true},
+			{url: "https://example.com/moved", redirects: []string{"https://example.com/new"}, linked: true},
+			{url: "https://example.com/loop", redirects: []string{"https://example.com/loop2", "https://example.com/loop"}, linked: true},
+		}},
+		{URL: "https://example.com/a", redirects: []redirect{
+			{url: "https://example.com/old#top", redirects: []string{"https://example.com/older", "https://example.com/new"}, linked: true},
+			{url: "https://example.com/a", redirects: []string{"https://example.com/a/", "https://example.com/a/index", "https://example.com/a/"}},
+		}},
+		{URL: "https://example.com/loop", Error: "redirect loop", redirects: []redirect{
+			{url: "https://example.com/loop", redirects: []string{"https://example.com/loop2", "https://example.com/loop"}},
+		}},
+	}}
+
+	report := Summarize(crawl)
+	wantLoops := []Redirect{
+		{URL: "https://example.com/loop", Redirects: []string{"https://example.com/loop2", "https://example.com/loop"}, Sources: []string{"https://example.com/"}},
+		{URL: "https://example.com/a", Redirects: []string{"https://example.com/a/", "https://example.com/a/index", "https://example.com/a/"}, Sources: []string{}},
+	}
+	if !reflect.DeepEqual(report.RedirectLoops, wantLoops) {
+		t.Errorf("Expected redirect loops %+v, but got %+v", wantLoops, report.RedirectLoops)
+	}
+	wantChains := []Redirect{
+		{URL: "https://example.com/old", Redirects: []string{"https://example.com/older", "https://example.com/new"}, Sources: []string{"https://example.com/", "https://example.com/a"}},
+	}
+	if !reflect.DeepEqual(report.RedirectChains, wantChains) {
+		t.Errorf("Expected redirect chains %+v, but got %+v", wantChains, report.RedirectChains)
+	}
+	if empty := Summarize(Crawl{}); empty.RedirectLoops == nil || empty.RedirectChains == nil {
+		t.Errorf("Expected empty lists rather than nil for a crawl without pages, but got %+v", empty)
+	}
+}
+
+func TestSummarize_PaginatedSeries(t *testing.T) {
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/blog/page/2", Pagination: &analyzer.Pagination{Next: "https://example.com/blog/page/3", Prev: "https://example.com/blog/"}},
+		{URL: "https://example.com/blog/", Pagination: &analyzer.Pagination{Next: "https://example.com/blog/page/2"}},
+		{URL: "https://example.com/blog/page/3", Pagination: &analyzer.Pagination{Prev: "https://example.com/blog/page/1"}},
+		{URL: "https://example.com/blog/page/4"},
+		{URL: "https://example.com/news?page=2&sort=new"},
+		{URL: "https://example.com/news?sort=new"},
+		{URL: "https://example.com/news?page=3&sort=old"},
+		{URL: "https://example.com/shop/older", Pagination: &analyzer.Pagination{Next: "https://example.com/shop/oldest"}},
+		{URL: "https://example.com/shop/oldest", Error: "status code 500"},
+		{URL: "https://example.com/about", Pagination: &analyzer.Pagination{Next: "https://example.com/about#more"}},
+		{URL: "https://example.com/contact", Pagination: &analyzer.Pagination{Next: "https://example.com/elsewhere"}},
+	}}
+
+	report := Summarize(crawl)
+	want := []Series{
+		{
+			Pages:  []string{"https://example.com/blog/", "https://example.com/blog/page/2", "https://example.com/blog/page/3", "https://example.com/blog/page/4"},
+			Markup: true,
+			Issues: []string{
+				"https://example.com/blog/page/2 declares https://example.com/blog/page/3 as its next page, but https://example.com/blog/page/3 does not declare it as its previous page.",
+				`https://example.com/blog/page/4 declares no rel="next" or rel="prev" links, unlike the rest of the series.`,
+			},
+		},
+		{
+			Pages:  []string{"https://example.com/news?sort=new", "https://example.com/news?page=2&sort=new"},
+			Issues: []string{`The series declares no rel="next" or rel="prev" links.`},
+		},
+		{
+			Pages:  []string{"https://example.com/shop/older", "https://example.com/shop/oldest"},
+			Markup: true,
+			Issues: []string{"The next page of https://example.com/shop/older, https://example.com/shop/oldest, failed: status code 500"},
+		},
+		{
+			Pages:  []string{"https://example.com/about"},
+			Markup: true,
+			Issues: []string{"https://example.com/about declares itself as its next page."},
+		},
+	}
+	if !reflect.DeepEqual(report.PaginatedSeries, want) {
+		t.Errorf("Expected paginated series %+v, but got %+v", want, report.PaginatedSeries)
+	}
+	if empty := Summarize(Crawl{}); empty.PaginatedSeries == nil {
+		t.Errorf("Expected an empty list rather than nil for a crawl without pages, but got %+v", empty)
+	}
+}
@@ -0,0 +1,80 @@
+package crawl
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MaxCrawlDelay caps the Crawl-delay honored, so a site asking for hours between requests
+// cannot stall a crawl indefinitely.
+const MaxCrawlDelay = time.Minute
+
+// Robots is what a site's robots.txt asks of the crawler; *analyzer.Robots implements it.
+type Robots interface {
+	Allows(pageURL string) bool
+	CrawlDelay() time.Duration
+}
+
+// RobotsFunc fetches the robots.txt of the origin of pageURL.
+type RobotsFunc func(ctx context.Context, pageURL string) Robots
+
+// politeness applies the robots.txt of each origin a crawl visits: which of its pages may be
+// fetched, and how long to leave between fetches. Only the run of one crawl uses it.
+type politeness struct {
+	fetch  RobotsFunc
+	robots map[string]Robots
+	// next is the earliest time of the next fetch from each origin with a crawl delay.
+	next map[string]time.Time
+}
+
+// newPoliteness returns the politeness of a crawl fetching robots.txt files with fetch. A
+// nil fetch allows every page without delay.
+func newPoliteness(fetch RobotsFunc) *politeness {
+	return &politeness{fetch: fetch, robots: make(map[string]Robots), next: make(map[string]time.Time)}
+}
+
+// allows reports whether robots.txt permits fetching pageURL.
+func (p *politeness) allows(ctx context.Context, pageURL string) bool {
+	robots := p.robotsFor(ctx, pageURL)
+	return robots == nil || robots.Allows(pageURL)
+}
+
+// reserve returns how long to wait before fetching pageURL. When that is no time at all,
+// the fetch is assumed to happen now and delays the next one from the same origin.
+func (p *politeness) reserve(ctx context.Context, pageURL string, now time.Time) time.Duration {
+	robots := p.robotsFor(ctx, pageURL)
+	if robots == nil || robots.CrawlDelay() <= 0 {
+		return 0
+	}
+	key := origin(pageURL)
+	if wait := p.next[key].Sub(now); wait > 0 {
+		return wait
+	}
+	p.next[key] = now.Add(min(robots.CrawlDelay(), MaxCrawlDelay))
+	return 0
+}
+
+// robotsFor returns the robots.txt of the origin of pageURL, fetching it the first time.
+func (p *politeness) robotsFor(ctx context.Context, pageURL string) Robots {
+	if p.fetch == nil {
+		return nil
+	}
+	key := origin(pageURL)
+	robots, ok := p.robots[key]
+	if !ok {
+		robots = p.fetch(ctx, pageURL)
+		p.robots[key] = robots
+	}
+	return robots
+}
+
+// origin returns the scheme and host of pageURL, to which robots.txt applies.
+func origin(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
@@ -0,0 +1,52 @@
+package crawl
+
+import (
+	"cmp"
+	"encoding/xml"
+	"io"
+	"slices"
+)
+
+// MaxSitemapURLs is the most URLs the sitemaps protocol allows in one sitemap file.
+const MaxSitemapURLs = 50000
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// WriteSitemap writes a sitemap.xml of the indexable pages of crawl: those analyzed
+// successfully that do not ask to be kept out of search engines with noindex. Pages come in
+// order of depth, then URL, up to MaxSitemapURLs of them.
+func WriteSitemap(w io.Writer, crawl Crawl) error {
+	var pages []Page
+	for _, page := range crawl.Pages {
+		if page.Error == "" && !page.Noindex {
+			pages = append(pages, page)
+		}
+	}
+	slices.SortFunc(pages, func(a, b Page) int {
+		return cmp.Or(cmp.Compare(a.Depth, b.Depth), cmp.Compare(a.URL, b.URL))
+	})
+
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: []sitemapURL{}}
+	for _, page := range pages[:min(len(pages), MaxSitemapURLs)] {
+		set.URLs = append(set.URLs, sitemapURL{Loc: page.URL})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(set); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,37 @@
+package crawl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	crawl := Crawl{Pages: []Page{
+		{URL: "https://example.com/b", Depth: 1},
+		{URL: "https://example.com/", ResultID: "r1"},
+		{URL: "https://example.com/a?x=1&y=2", Depth: 1},
+		{URL: "https://example.com/private", Depth: 1, Noindex: true},
+		{URL: "https://example.com/missing", Depth: 2, Error: "not found"},
+	}}
+
+	var b strings.Builder
+	if err := WriteSitemap(&b, crawl); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/</loc>
+  </url>
+  <url>
+    <loc>https://example.com/a?x=1&amp;y=2</loc>
+  </url>
+  <url>
+    <loc>https://example.com/b</loc>
+  </url>
+</urlset>
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", want, b.String())
+	}
+}
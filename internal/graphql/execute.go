@@ -0,0 +1,520 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Request is a GraphQL request, as POSTed by clients.
+type Request struct {
+	Query string `json:"query"`
+	// OperationName picks the operation to run when Query holds several.
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response is the result of executing a Request. It marshals to JSON as the spec requires:
+// without "data" if the request failed before execution started, and with "errors" only if
+// there are any.
+type Response struct {
+	// Data is the result, with fields in the order the query selected them; nil if a
+	// non-null field failed at the root or the request failed before execution.
+	Data   any      `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+
+	executed bool
+}
+
+func (r *Response) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Errors []*Error `json:"errors,omitempty"`
+		Data   *any     `json:"data,omitempty"`
+	}{Errors: r.Errors}
+	if r.executed {
+		out.Data = &r.Data
+	}
+	return json.Marshal(out)
+}
+
+// Error is an error in a Response. Resolvers may return an *Error to set its Extensions,
+// e.g. a machine-readable code.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path is the response keys and list indexes leading to the field that failed.
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Execute runs the query of req. Syntax, validation and variable errors fail the whole
+// request; a resolver error nulls its field, or the nearest nullable field above it.
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{err.(*Error)}}
+	}
+	if errs := validate(s, doc, req.Query); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{src: req.Query, fragments: make(map[string]*fragment)}
+	for _, f := range doc.fragments {
+		e.fragments[f.name] = f
+	}
+	if errs := e.coerceVariables(s, op, req.Variables); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	resp := &Response{executed: true}
+	if data, ok := e.object(ctx, s.query, op.selections, nil, nil); ok {
+		resp.Data = data
+	}
+	resp.Errors = e.errors
+	return resp
+}
+
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		switch len(d.operations) {
+		case 0:
+			return nil, errors.New("the query has no operation")
+		case 1:
+			return d.operations[0], nil
+		}
+		return nil, errors.New("the query has several operations, operationName must name the one to run")
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("the query has no operation named %q", name)
+}
+
+// executor runs one operation, executing fields serially.
+type executor struct {
+	src       string
+	fragments map[string]*fragment
+	vars      map[string]any
+	errors    []*Error
+}
+
+func (e *executor) coerceVariables(s *Schema, op *operation, inputs map[string]any) []*Error {
+	e.vars = make(map[string]any, len(op.vars))
+	var errs []*Error
+	for _, def := range op.vars {
+		t, _ := s.inputType(def.typ) // validated
+		input, given := inputs[def.name]
+		if !given {
+			switch _, required := t.(*NonNull); {
+			case def.def != nil:
+				e.vars[def.name] = coerceLiteral(t, def.def, nil)
+			case required:
+				errs = append(errs, &Error{
+					Message:   fmt.Sprintf("variable $%s of required type %s was not provided", def.name, t),
+					Locations: []Location{location(e.src, def.pos)},
+				})
+			}
+			continue
+		}
+		v, err := coerceInput(t, input)
+		if err != nil {
+			errs = append(errs, &Error{
+				Message:   fmt.Sprintf("variable $%s got an invalid value: %v", def.name, err),
+				Locations: []Location{location(e.src, def.pos)},
+			})
+			continue
+		}
+		e.vars[def.name] = v
+	}
+	return errs
+}
+
+// inputType resolves a variable's type, which must be built from scalars and enums.
+func (s *Schema) inputType(ref *typeRef) (Type, bool) {
+	var t Type
+	if ref.elem != nil {
+		elem, ok := s.inputType(ref.elem)
+		if !ok {
+			return nil, false
+		}
+		t = NewList(elem)
+	} else {
+		named, ok := s.byName[ref.name]
+		if !ok || !isLeaf(named) {
+			return nil, false
+		}
+		t = named
+	}
+	if ref.nonNull {
+		t = NewNonNull(t)
+	}
+	return t, true
+}
+
+// coerceInput coerces a variable's JSON value to t.
+func coerceInput(t Type, v any) (any, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nn.OfType)
+		}
+		return coerceInput(nn.OfType, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			item, err := coerceInput(t.OfType, v)
+			return []any{item}, err
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			var err error
+			if out[i], err = coerceInput(t.OfType, item); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return out, nil
+	case *Scalar:
+		if out, ok := t.parse(v); ok {
+			return out, nil
+		}
+	case *Enum:
+		if s, ok := v.(string); ok && t.has(s) {
+			return s, nil
+		}
+	}
+	data, _ := json.Marshal(v)
+	return nil, fmt.Errorf("%s is not a valid %s", data, t)
+}
+
+// coerceLiteral coerces a validated literal to t. Variables take their value from vars.
+func coerceLiteral(t Type, val *value, vars map[string]any) any {
+	if val.kind == valueVariable {
+		return vars[val.raw]
+	}
+	if nn, ok := t.(*NonNull); ok {
+		return coerceLiteral(nn.OfType, val, vars)
+	}
+	if val.kind == valueNull {
+		return nil
+	}
+	if list, ok := t.(*List); ok {
+		if val.kind != valueList {
+			return []any{coerceLiteral(list.OfType, val, vars)}
+		}
+		out := make([]any, len(val.list))
+		for i, item := range val.list {
+			out[i] = coerceLiteral(list.OfType, item, vars)
+		}
+		return out
+	}
+	v, _ := parseLiteral(t, val)
+	return v
+}
+
+// parseLiteral parses a literal of a scalar or enum type t.
+func parseLiteral(t Type, val *value) (any, bool) {
+	switch t := t.(type) {
+	case *Scalar:
+		switch val.kind {
+		case valueInt:
+			return t.parse(json.Number(val.raw))
+		case valueFloat:
+			if t == Float {
+				return t.parse(json.Number(val.raw))
+			}
+		case valueString:
+			return t.parse(val.raw)
+		case valueBoolean:
+			return t.parse(val.raw == "true")
+		}
+	case *Enum:
+		if val.kind == valueEnum && t.has(val.raw) {
+			return val.raw, true
+		}
+	}
+	return nil, false
+}
+
+// object is a JSON object that keeps the order of its fields.
+type object struct {
+	keys   []string
+	values []any
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// object executes the fields selected from an object of type obj whose value is source. It
+// returns false if a non-null field failed, so the object itself must be null.
+func (e *executor) object(ctx context.Context, obj *Object, selections []selection, source any, path []any) (*object, bool) {
+	var keys []string
+	groups := make(map[string][]*fieldNode)
+	e.collect(selections, &keys, groups, make(map[string]bool))
+
+	out := &object{keys: keys, values: make([]any, len(keys))}
+	for i, key := range keys {
+		nodes := groups[key]
+		if nodes[0].name == "__typename" {
+			out.values[i] = obj.Name
+			continue
+		}
+		v, ok := e.field(ctx, obj.fields[nodes[0].name], nodes, source, append(slices.Clip(path), key))
+		if !ok {
+			return nil, false
+		}
+		out.values[i] = v
+	}
+	return out, true
+}
+
+// collect groups the fields of selections that are not skipped by directives by their
+// response key, appending new keys to keys.
+func (e *executor) collect(selections []selection, keys *[]string, groups map[string][]*fieldNode, visited map[string]bool) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *fieldNode:
+			if e.skipped(sel.directives) {
+				continue
+			}
+			key := sel.key()
+			if _, ok := groups[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			groups[key] = append(groups[key], sel)
+		case *inlineFragment:
+			if !e.skipped(sel.directives) {
+				e.collect(sel.selections, keys, groups, visited)
+			}
+		case *fragmentSpread:
+			if !e.skipped(sel.directives) && !visited[sel.name] {
+				visited[sel.name] = true
+				e.collect(e.fragments[sel.name].selections, keys, groups, visited)
+			}
+		}
+	}
+}
+
+// skipped applies the @skip and @include directives.
+func (e *executor) skipped(ds []*directive) bool {
+	for _, d := range ds {
+		cond, _ := coerceLiteral(directiveArgs[0].Type, d.args[0].val, e.vars).(bool)
+		if cond == (d.name == "skip") {
+			return true
+		}
+	}
+	return false
+}
+
+// selection returns what selections select, for ResolveParams.Selection.
+func (e *executor) selection(selections []selection) Selection {
+	if selections == nil {
+		return nil
+	}
+	var keys []string
+	groups := make(map[string][]*fieldNode)
+	e.collect(selections, &keys, groups, make(map[string]bool))
+
+	s := make(Selection)
+	for _, key := range keys {
+		for _, f := range groups[key] {
+			if f.name != "__typename" {
+				s[f.name] = mergeSelections(s[f.name], e.selection(f.selections))
+			}
+		}
+	}
+	return s
+}
+
+func mergeSelections(a, b Selection) Selection {
+	if a == nil {
+		return b
+	}
+	for name, sub := range b {
+		a[name] = mergeSelections(a[name], sub)
+	}
+	return a
+}
+
+// field resolves and completes one field, merged from nodes. It returns false if the field
+// is non-null but its value is null, so the null must propagate to the parent.
+func (e *executor) field(ctx context.Context, def *Field, nodes []*fieldNode, source any, path []any) (any, bool) {
+	var sub []selection
+	for _, n := range nodes {
+		sub = append(sub, n.selections...)
+	}
+
+	var v any
+	err := ctx.Err()
+	if err == nil {
+		if def.Resolve == nil {
+			v, err = defaultResolve(source, def.Name)
+		} else {
+			v, err = def.Resolve(ctx, ResolveParams{Source: source, Args: e.args(def.Args, nodes[0].args), Selection: e.selection(sub)})
+		}
+	}
+	if err != nil {
+		e.fieldError(err, nodes[0], path)
+		_, nonNull := def.Type.(*NonNull)
+		return nil, !nonNull
+	}
+	return e.complete(ctx, def.Type, nodes[0], sub, v, path)
+}
+
+func (e *executor) args(defs []*Argument, nodes []*argNode) map[string]any {
+	args := make(map[string]any, len(defs))
+	for _, def := range defs {
+		i := slices.IndexFunc(nodes, func(n *argNode) bool { return n.name == def.Name })
+		if i >= 0 && nodes[i].val.kind == valueVariable {
+			if v, ok := e.vars[nodes[i].val.raw]; ok {
+				args[def.Name] = v
+				continue
+			}
+			i = -1 // an unset variable leaves the argument unset
+		}
+		switch {
+		case i >= 0:
+			args[def.Name] = coerceLiteral(def.Type, nodes[i].val, e.vars)
+		case def.Default != nil:
+			args[def.Name] = def.Default
+		}
+	}
+	return args
+}
+
+// defaultResolve reads the field name from source, for fields without a Resolve function.
+func defaultResolve(source any, name string) (any, error) {
+	if m, ok := source.(map[string]any); ok {
+		return m[name], nil
+	}
+	rv := reflect.ValueOf(source)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		for _, f := range reflect.VisibleFields(rv.Type()) {
+			if f.IsExported() && !f.Anonymous && strings.EqualFold(f.Name, name) {
+				return rv.FieldByIndex(f.Index).Interface(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("cannot read field %q from a %T", name, source)
+}
+
+// complete turns the resolved value v into the JSON value of type t.
+func (e *executor) complete(ctx context.Context, t Type, node *fieldNode, sub []selection, v any, path []any) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		out, ok := e.completeNullable(ctx, nn.OfType, node, sub, v, path)
+		if ok && out == nil {
+			e.fieldError(errors.New("cannot return null for a non-null field"), node, path)
+		}
+		return out, ok && out != nil
+	}
+	out, ok := e.completeNullable(ctx, t, node, sub, v, path)
+	if !ok {
+		return nil, true
+	}
+	return out, true
+}
+
+// completeNullable completes v for a type that is not non-null. It returns false on errors,
+// which complete turns into a null value or propagates.
+func (e *executor) completeNullable(ctx context.Context, t Type, node *fieldNode, sub []selection, v any, path []any) (any, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, true
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *List:
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fieldError(fmt.Errorf("expected a list, got a %T", v), node, path)
+			return nil, false
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, true
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, ok := e.complete(ctx, t.OfType, node, sub, rv.Index(i).Interface(), append(slices.Clip(path), i))
+			if !ok {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	case *Scalar:
+		if out, ok := t.serialize(rv); ok {
+			return out, true
+		}
+	case *Enum:
+		if rv.Kind() == reflect.String && t.has(rv.String()) {
+			return rv.String(), true
+		}
+	case *Object:
+		out, ok := e.object(ctx, t, sub, v, path)
+		if !ok {
+			return nil, false
+		}
+		return out, true
+	}
+	e.fieldError(fmt.Errorf("cannot represent %v as a %s", v, t), node, path)
+	return nil, false
+}
+
+// fieldError records err as the error of the field at path.
+func (e *executor) fieldError(err error, node *fieldNode, path []any) {
+	gqlErr := &Error{Message: err.Error()}
+	var custom *Error
+	if errors.As(err, &custom) {
+		gqlErr.Message = custom.Message
+		gqlErr.Extensions = custom.Extensions
+	}
+	gqlErr.Locations = []Location{location(e.src, node.pos)}
+	gqlErr.Path = slices.Clone(path)
+	e.errors = append(e.errors, gqlErr)
+}
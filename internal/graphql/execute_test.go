@@ -0,0 +1,346 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testBook struct {
+	Title   string
+	Pages   int
+	Authors []string
+	Rating  *float64
+	Format  string
+}
+
+var testFormat = &Enum{Name: "Format", Values: []EnumValue{{Name: "HARDCOVER"}, {Name: "PAPERBACK"}}}
+
+// newTestSchema returns a schema of books. Its last Selection records what the book resolver
+// was asked for.
+func newTestSchema(t *testing.T) (*Schema, *Selection) {
+	t.Helper()
+	rating := 4.5
+	books := []testBook{
+		{Title: "Go", Pages: 380, Authors: []string{"Alan", "Brian"}, Rating: &rating, Format: "PAPERBACK"},
+		{Title: "SICP", Pages: 657, Authors: []string{"Harold", "Gerald"}, Format: "HARDCOVER"},
+	}
+	var last Selection
+
+	book := &Object{
+		Name: "Book",
+		Fields: []*Field{
+			{Name: "title", Type: NewNonNull(String)},
+			{Name: "pages", Type: Int},
+			{Name: "authors", Type: NewList(NewNonNull(String))},
+			{Name: "rating", Type: Float},
+			{Name: "format", Type: testFormat},
+			{
+				Name: "fails",
+				Type: String,
+				Resolve: func(context.Context, ResolveParams) (any, error) {
+					return nil, &Error{Message: "no luck", Extensions: map[string]any{"code": "unlucky"}}
+				},
+			},
+			{
+				Name: "required",
+				Type: NewNonNull(String),
+				Resolve: func(context.Context, ResolveParams) (any, error) {
+					return nil, nil
+				},
+			},
+		},
+	}
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{
+				Name: "book",
+				Type: book,
+				Args: []*Argument{{Name: "title", Type: NewNonNull(String)}},
+				Resolve: func(_ context.Context, p ResolveParams) (any, error) {
+					last = p.Selection
+					for _, b := range books {
+						if b.Title == p.Args["title"] {
+							return b, nil
+						}
+					}
+					return nil, errors.New("no such book")
+				},
+			},
+			{
+				Name: "books",
+				Type: NewNonNull(NewList(NewNonNull(book))),
+				Args: []*Argument{
+					{Name: "limit", Type: Int, Default: 10},
+					{Name: "format", Type: testFormat},
+				},
+				Resolve: func(_ context.Context, p ResolveParams) (any, error) {
+					var out []testBook
+					for _, b := range books {
+						if len(out) < p.Args["limit"].(int) && (p.Args["format"] == nil || p.Args["format"] == b.Format) {
+							out = append(out, b)
+						}
+					}
+					return out, nil
+				},
+			},
+		},
+	}
+	s, err := NewSchema(query)
+	if err != nil {
+		t.Fatalf("Expected a valid schema, but got %v", err)
+	}
+	return s, &last
+}
+
+func execute(t *testing.T, s *Schema, req Request) string {
+	t.Helper()
+	data, err := json.Marshal(s.Execute(context.Background(), req))
+	if err != nil {
+		t.Fatalf("Expected the response to marshal, but got %v", err)
+	}
+	return string(data)
+}
+
+func TestSchema_Execute(t *testing.T) {
+	s, _ := newTestSchema(t)
+
+	testCases := []struct {
+		name     string
+		req      Request
+		expected string
+	}{
+		{
+			name:     "fields in query order",
+			req:      Request{Query: `{ book(title: "Go") { pages title authors } }`},
+			expected: `{"data":{"book":{"pages":380,"title":"Go","authors":["Alan","Brian"]}}}`,
+		},
+		{
+			name:     "aliases and typename",
+			req:      Request{Query: `{ a: book(title: "Go") { __typename t: title } b: book(title: "SICP") { t: title } }`},
+			expected: `{"data":{"a":{"__typename":"Book","t":"Go"},"b":{"t":"SICP"}}}`,
+		},
+		{
+			name:     "null pointer",
+			req:      Request{Query: `{ books { rating } }`},
+			expected: `{"data":{"books":[{"rating":4.5},{"rating":null}]}}`,
+		},
+		{
+			name:     "default and enum arguments",
+			req:      Request{Query: `{ books(format: HARDCOVER) { title format } }`},
+			expected: `{"data":{"books":[{"title":"SICP","format":"HARDCOVER"}]}}`,
+		},
+		{
+			name: "variables",
+			req: Request{
+				Query:     `query Q($title: String!, $limit: Int = 1) { book(title: $title) { title } books(limit: $limit) { title } }`,
+				Variables: map[string]any{"title": "SICP"},
+			},
+			expected: `{"data":{"book":{"title":"SICP"},"books":[{"title":"Go"}]}}`,
+		},
+		{
+			name: "fragments and directives",
+			req: Request{
+				Query: `query ($skip: Boolean!) {
+					book(title: "Go") { ...parts ... on Book { pages @skip(if: $skip) } }
+				}
+				fragment parts on Book { title format @include(if: false) }`,
+				Variables: map[string]any{"skip": true},
+			},
+			expected: `{"data":{"book":{"title":"Go"}}}`,
+		},
+		{
+			name: "operation name",
+			req: Request{
+				Query:         `query A { book(title: "Go") { title } } query B { book(title: "SICP") { title } }`,
+				OperationName: "B",
+			},
+			expected: `{"data":{"book":{"title":"SICP"}}}`,
+		},
+		{
+			name:     "resolver error",
+			req:      Request{Query: `{ book(title: "Nope") { title } }`},
+			expected: `{"errors":[{"message":"no such book","locations":[{"line":1,"column":3}],"path":["book"]}],"data":{"book":null}}`,
+		},
+		{
+			name:     "error extensions",
+			req:      Request{Query: `{ book(title: "Go") { title fails } }`},
+			expected: `{"errors":[{"message":"no luck","locations":[{"line":1,"column":29}],"path":["book","fails"],"extensions":{"code":"unlucky"}}],"data":{"book":{"title":"Go","fails":null}}}`,
+		},
+		{
+			name:     "null propagation",
+			req:      Request{Query: `{ books { required } }`},
+			expected: `{"errors":[{"message":"cannot return null for a non-null field","locations":[{"line":1,"column":11}],"path":["books",0,"required"]}],"data":null}`,
+		},
+		{
+			name:     "syntax error",
+			req:      Request{Query: `{ book(title: "Go") { title }`},
+			expected: `{"errors":[{"message":"syntax error: expected a name, found end of query","locations":[{"line":1,"column":30}]}]}`,
+		},
+		{
+			name:     "missing variable",
+			req:      Request{Query: `query ($title: String!) { book(title: $title) { title } }`},
+			expected: `{"errors":[{"message":"variable $title of required type String! was not provided","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:     "invalid variable",
+			req:      Request{Query: `query ($limit: Int) { books(limit: $limit) { title } }`, Variables: map[string]any{"limit": 1.5}},
+			expected: `{"errors":[{"message":"variable $limit got an invalid value: 1.5 is not a valid Int","locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			name:     "ambiguous operation",
+			req:      Request{Query: `query A { books { title } } query B { books { pages } }`},
+			expected: `{"errors":[{"message":"the query has several operations, operationName must name the one to run"}]}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := execute(t, s, tc.req); actual != tc.expected {
+				t.Errorf("Expected %s, but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSchema_Execute_InvalidQueries(t *testing.T) {
+	s, _ := newTestSchema(t)
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{query: `{ book(title: "Go") { isbn } }`, expected: `type Book has no field "isbn"`},
+		{query: `{ book { title } }`, expected: `field Query.book requires argument "title"`},
+		{query: `{ book(title: "Go", year: 1) { title } }`, expected: `field Query.book has no argument "year"`},
+		{query: `{ book(title: 1) { title } }`, expected: `1 is not a valid String`},
+		{query: `{ books(limit: 1.5) { title } }`, expected: `1.5 is not a valid Int`},
+		{query: `{ books(format: EBOOK) { title } }`, expected: `EBOOK is not a valid Format`},
+		{query: `{ book(title: "Go") }`, expected: `must have a selection of subfields`},
+		{query: `{ books { title { x } } }`, expected: `cannot have a selection of subfields`},
+		{query: `{ books { ...missing } }`, expected: `unknown fragment "missing"`},
+		{query: `{ books { title } } fragment unused on Book { title }`, expected: `fragment "unused" is never used`},
+		{query: `{ books { ...a } } fragment a on Book { ...a }`, expected: `fragment "a" spreads itself`},
+		{query: `{ books { ...q } } fragment q on Query { books { title } }`, expected: `a fragment on Query cannot be spread within Book`},
+		{query: `{ books { title @deprecated } }`, expected: `unknown directive @deprecated`},
+		{query: `query ($x: Int) { books { title } }`, expected: `variable $x is never used`},
+		{query: `{ book(title: $title) { title } }`, expected: `variable $title is not defined`},
+		{query: `query ($t: String) { book(title: $t) { title } }`, expected: `variable $t of type String cannot be used where String! is expected`},
+		{query: `{ books { x: title x: pages } }`, expected: `fields "x" conflict`},
+		{query: `mutation { books { title } }`, expected: `mutation operations are not supported`},
+		{query: `{ books { title } } { books { pages } }`, expected: `an anonymous operation must be the only operation`},
+		{query: `{ books(limit: 01) { title } }`, expected: `unexpected leading zero`},
+		{query: `{ book(title: "Go\q") { title } }`, expected: `invalid escape sequence`},
+		{query: `{}`, expected: `a selection set cannot be empty`},
+		{query: ``, expected: `the query is empty`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			resp := s.Execute(context.Background(), Request{Query: tc.query})
+			if resp.executed || len(resp.Errors) == 0 {
+				t.Fatalf("Expected the query to be rejected, but got %+v", resp)
+			}
+			if !strings.Contains(resp.Errors[0].Message, tc.expected) {
+				t.Errorf("Expected an error containing %q, but got %q", tc.expected, resp.Errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestSchema_Execute_Selection(t *testing.T) {
+	s, last := newTestSchema(t)
+	execute(t, s, Request{
+		Query: `query ($skip: Boolean = true) {
+			book(title: "Go") { t: title ...f authors @skip(if: $skip) }
+			other: book(title: "Go") { pages }
+		}
+		fragment f on Book { format }`,
+	})
+
+	// The last resolver call is for the second field, which selects pages only.
+	if !last.Has("pages") || last.Has("title") {
+		t.Errorf("Expected the selection of the second book to hold only pages, but got %v", *last)
+	}
+
+	execute(t, s, Request{Query: `query ($skip: Boolean = true) { book(title: "Go") { t: title ...f authors @skip(if: $skip) } } fragment f on Book { format }`})
+	for _, name := range []string{"title", "format"} {
+		if !last.Has(name) {
+			t.Errorf("Expected %s to be selected, but got %v", name, *last)
+		}
+	}
+	if last.Has("authors") {
+		t.Errorf("Expected the skipped authors not to be selected, but got %v", *last)
+	}
+}
+
+func TestSelection_Has(t *testing.T) {
+	s := Selection{"links": {"inaccessible": {"url": nil}}, "title": nil}
+	testCases := []struct {
+		path     []string
+		expected bool
+	}{
+		{path: nil, expected: true},
+		{path: []string{"title"}, expected: true},
+		{path: []string{"links", "inaccessible", "url"}, expected: true},
+		{path: []string{"links", "all"}, expected: false},
+		{path: []string{"title", "x"}, expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := s.Has(tc.path...); actual != tc.expected {
+			t.Errorf("Expected Has(%v) to be %v, but got %v", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestNewSchema(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    *Object
+		expected string
+	}{
+		{
+			name:     "no fields",
+			query:    &Object{Name: "Query"},
+			expected: "object Query has no fields",
+		},
+		{
+			name:     "duplicate type names",
+			query:    &Object{Name: "Query", Fields: []*Field{{Name: "a", Type: &Object{Name: "Query", Fields: []*Field{{Name: "b", Type: String}}}}}},
+			expected: "two types are named Query",
+		},
+		{
+			name:     "object argument",
+			query:    &Object{Name: "Query", Fields: []*Field{{Name: "a", Type: String, Args: []*Argument{{Name: "q", Type: &Object{Name: "Q"}}}}}},
+			expected: "must have a scalar or enum type",
+		},
+		{
+			name:     "reserved name",
+			query:    &Object{Name: "Query", Fields: []*Field{{Name: "__a", Type: String}}},
+			expected: "invalid field name",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSchema(tc.query)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected an error containing %q, but got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchema_SDL(t *testing.T) {
+	s, _ := newTestSchema(t)
+	sdl := s.SDL()
+	for _, expected := range []string{
+		"type Query {\n  book(title: String!): Book\n  books(limit: Int = 10, format: Format): [Book!]!\n}",
+		"type Book {\n  title: String!\n",
+		"enum Format {\n  HARDCOVER\n  PAPERBACK\n}",
+	} {
+		if !strings.Contains(sdl, expected) {
+			t.Errorf("Expected the SDL to contain %q, but got:\n%s", expected, sdl)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package graphql
+
+import "testing"
+
+func TestLexer_Tokens(t *testing.T) {
+	l := lexer{src: "\ufeff{ a(x: -1.5e3, y: \"\\u00e9\\n\") # comment\n ...on, $v }"}
+	expected := []token{
+		{kind: tokenPunct, value: "{"},
+		{kind: tokenName, value: "a"},
+		{kind: tokenPunct, value: "("},
+		{kind: tokenName, value: "x"},
+		{kind: tokenPunct, value: ":"},
+		{kind: tokenFloat, value: "-1.5e3"},
+		{kind: tokenName, value: "y"},
+		{kind: tokenPunct, value: ":"},
+		{kind: tokenString, value: "é\n"},
+		{kind: tokenPunct, value: ")"},
+		{kind: tokenPunct, value: "..."},
+		{kind: tokenName, value: "on"},
+		{kind: tokenPunct, value: "$"},
+		{kind: tokenName, value: "v"},
+		{kind: tokenPunct, value: "}"},
+		{kind: tokenEOF},
+	}
+	for i, want := range expected {
+		tok, err := l.next()
+		if err != nil {
+			t.Fatalf("Expected token %d to lex, but got %v", i, err)
+		}
+		if tok.kind != want.kind || tok.value != want.value {
+			t.Fatalf("Expected token %d to be %v, but got %v", i, want, tok)
+		}
+	}
+}
+
+func TestBlockStringValue(t *testing.T) {
+	raw := "\n    Hello,\n      World!\n\n    Bye\n  "
+	expected := "Hello,\n  World!\n\nBye"
+	if actual := blockStringValue(raw); actual != expected {
+		t.Errorf("Expected %q, but got %q", expected, actual)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	src := "{\n  é: title\n}"
+	if actual := location(src, 6); actual != (Location{Line: 2, Column: 4}) {
+		t.Errorf("Expected line 2, column 4, but got %+v", actual)
+	}
+}
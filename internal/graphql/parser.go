@@ -0,0 +1,409 @@
+package graphql
+
+import "fmt"
+
+// document is a parsed query document.
+type document struct {
+	operations []*operation
+	fragments  []*fragment
+}
+
+type operation struct {
+	name       string
+	vars       []*varDef
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+type varDef struct {
+	name string
+	typ  *typeRef
+	def  *value // nil if the variable has no default
+	pos  int
+}
+
+// typeRef is a type as written in a variable definition: a named type, or a list of elem.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+	pos     int
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// selection is a *fieldNode, *fragmentSpread or *inlineFragment.
+type selection interface {
+	position() int
+}
+
+type fieldNode struct {
+	alias      string
+	name       string
+	args       []*argNode
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+// key is the name of the field in the response.
+func (f *fieldNode) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	pos        int
+}
+
+type inlineFragment struct {
+	typeCond   string
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+func (f *fieldNode) position() int      { return f.pos }
+func (f *fragmentSpread) position() int { return f.pos }
+func (f *inlineFragment) position() int { return f.pos }
+
+type fragment struct {
+	name       string
+	typeCond   string
+	directives []*directive
+	selections []selection
+	pos        int
+}
+
+type argNode struct {
+	name string
+	val  *value
+	pos  int
+}
+
+type directive struct {
+	name string
+	args []*argNode
+	pos  int
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+// value is a literal or variable in a query. raw holds the variable name, the number or
+// enum as written, or the decoded string.
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*argNode
+	pos    int
+}
+
+// parser is a recursive descent parser of GraphQL query documents.
+type parser struct {
+	lex lexer
+	tok token
+}
+
+// parse parses a query document.
+func parse(src string) (doc *document, err error) {
+	p := &parser{lex: lexer{src: src}}
+	defer func() {
+		if r := recover(); r != nil {
+			gqlErr, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			err = gqlErr
+		}
+	}()
+
+	p.advance()
+	doc = &document{}
+	if p.tok.kind == tokenEOF {
+		p.fail(p.tok.pos, "the query is empty")
+	}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"):
+			doc.operations = append(doc.operations, &operation{selections: p.selectionSet(), pos: p.tok.pos})
+		case p.peek(tokenName, "query"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			p.fail(p.tok.pos, "%s operations are not supported, only queries", p.tok.value)
+		case p.peek(tokenName, "fragment"):
+			doc.fragments = append(doc.fragments, p.fragment())
+		default:
+			p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+// advance moves to the next token. Errors are raised as panics of *Error, recovered by parse.
+func (p *parser) advance() {
+	tok, err := p.lex.next()
+	if err != nil {
+		panic(err)
+	}
+	p.tok = tok
+}
+
+func (p *parser) fail(pos int, format string, args ...any) {
+	panic(p.lex.errorf(pos, format, args...))
+}
+
+func (p *parser) unexpected() {
+	p.fail(p.tok.pos, "unexpected %s", p.tok)
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// skip consumes the punctuator if it is next, and reports whether it was.
+func (p *parser) skip(punct string) bool {
+	if p.peek(tokenPunct, punct) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punct string) {
+	if !p.skip(punct) {
+		p.fail(p.tok.pos, "expected %q, found %s", punct, p.tok)
+	}
+}
+
+func (p *parser) name() string {
+	if p.tok.kind != tokenName {
+		p.fail(p.tok.pos, "expected a name, found %s", p.tok)
+	}
+	name := p.tok.value
+	p.advance()
+	return name
+}
+
+func (p *parser) operation() *operation {
+	op := &operation{pos: p.tok.pos}
+	p.advance() // query
+	if p.tok.kind == tokenName {
+		op.name = p.name()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			op.vars = append(op.vars, p.varDef())
+		}
+	}
+	op.directives = p.directives()
+	op.selections = p.selectionSet()
+	return op
+}
+
+func (p *parser) varDef() *varDef {
+	v := &varDef{pos: p.tok.pos}
+	p.expect("$")
+	v.name = p.name()
+	p.expect(":")
+	v.typ = p.typeRef()
+	if p.skip("=") {
+		v.def = p.value(true)
+	}
+	return v
+}
+
+func (p *parser) typeRef() *typeRef {
+	t := &typeRef{pos: p.tok.pos}
+	if p.skip("[") {
+		t.elem = p.typeRef()
+		p.expect("]")
+	} else {
+		t.name = p.name()
+	}
+	t.nonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) fragment() *fragment {
+	f := &fragment{pos: p.tok.pos}
+	p.advance() // fragment
+	if p.peek(tokenName, "on") {
+		p.fail(p.tok.pos, "a fragment cannot be named \"on\"")
+	}
+	f.name = p.name()
+	if !p.peek(tokenName, "on") {
+		p.fail(p.tok.pos, "expected \"on\", found %s", p.tok)
+	}
+	p.advance()
+	f.typeCond = p.name()
+	f.directives = p.directives()
+	f.selections = p.selectionSet()
+	return f
+}
+
+func (p *parser) selectionSet() []selection {
+	p.expect("{")
+	var selections []selection
+	for !p.skip("}") {
+		selections = append(selections, p.selection())
+	}
+	if len(selections) == 0 {
+		p.fail(p.tok.pos, "a selection set cannot be empty")
+	}
+	return selections
+}
+
+func (p *parser) selection() selection {
+	pos := p.tok.pos
+	if !p.skip("...") {
+		return p.field()
+	}
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		return &fragmentSpread{name: p.name(), directives: p.directives(), pos: pos}
+	}
+	f := &inlineFragment{pos: pos}
+	if p.peek(tokenName, "on") {
+		p.advance()
+		f.typeCond = p.name()
+	}
+	f.directives = p.directives()
+	f.selections = p.selectionSet()
+	return f
+}
+
+func (p *parser) field() *fieldNode {
+	f := &fieldNode{pos: p.tok.pos}
+	f.name = p.name()
+	if p.skip(":") {
+		f.alias, f.name = f.name, p.name()
+	}
+	f.args = p.arguments(false)
+	f.directives = p.directives()
+	if p.peek(tokenPunct, "{") {
+		f.selections = p.selectionSet()
+	}
+	return f
+}
+
+func (p *parser) arguments(constant bool) []*argNode {
+	if !p.skip("(") {
+		return nil
+	}
+	var args []*argNode
+	for !p.skip(")") {
+		arg := &argNode{pos: p.tok.pos}
+		arg.name = p.name()
+		p.expect(":")
+		arg.val = p.value(constant)
+		args = append(args, arg)
+	}
+	return args
+}
+
+func (p *parser) directives() []*directive {
+	var directives []*directive
+	for p.peek(tokenPunct, "@") {
+		d := &directive{pos: p.tok.pos}
+		p.advance()
+		d.name = p.name()
+		d.args = p.arguments(false)
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// value parses a value; constant values, such as variable defaults, cannot hold variables.
+func (p *parser) value(constant bool) *value {
+	v := &value{pos: p.tok.pos, raw: p.tok.value}
+	switch p.tok.kind {
+	case tokenInt:
+		v.kind = valueInt
+	case tokenFloat:
+		v.kind = valueFloat
+	case tokenString:
+		v.kind = valueString
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.kind = valueBoolean
+		case "null":
+			v.kind = valueNull
+		default:
+			v.kind = valueEnum
+		}
+	case tokenPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				p.fail(p.tok.pos, "variables are not allowed here")
+			}
+			p.advance()
+			return &value{kind: valueVariable, raw: p.name(), pos: v.pos}
+		case "[":
+			p.advance()
+			v.kind = valueList
+			for !p.skip("]") {
+				v.list = append(v.list, p.value(constant))
+			}
+			return v
+		case "{":
+			p.advance()
+			v.kind = valueObject
+			for !p.skip("}") {
+				field := &argNode{pos: p.tok.pos}
+				field.name = p.name()
+				p.expect(":")
+				field.val = p.value(constant)
+				v.fields = append(v.fields, field)
+			}
+			return v
+		}
+		p.unexpected()
+	default:
+		p.unexpected()
+	}
+	p.advance()
+	return v
+}
+
+func (v *value) String() string {
+	switch v.kind {
+	case valueVariable:
+		return "$" + v.raw
+	case valueString:
+		return fmt.Sprintf("%q", v.raw)
+	case valueList:
+		return "list"
+	case valueObject:
+		return "object"
+	default:
+		return v.raw
+	}
+}
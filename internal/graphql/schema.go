@@ -0,0 +1,445 @@
+// Package graphql executes GraphQL queries against a schema of objects, scalars and enums
+// defined in Go. It supports what read-only APIs need: arguments, variables, aliases,
+// fragments and the @skip and @include directives. Mutations, subscriptions, interfaces,
+// unions and input objects are not supported, and introspection is limited to __typename;
+// Schema.SDL describes the schema instead.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Type is a GraphQL type: a *Scalar, *Enum, *Object, *List or *NonNull.
+type Type interface {
+	// String returns the type as written in a query, e.g. "[String!]".
+	String() string
+	isType()
+}
+
+// Scalar is a leaf type. Use the predefined scalars; custom scalars are not supported.
+type Scalar struct {
+	Name        string
+	Description string
+
+	// serialize turns a resolved Go value into its JSON form.
+	serialize func(v reflect.Value) (any, bool)
+	// parse turns a literal or a variable's JSON value into its Go form.
+	parse func(v any) (any, bool)
+}
+
+// The built-in scalars. Their Go values are string, int, float64, bool and string.
+var (
+	String = &Scalar{
+		Name: "String",
+		serialize: func(v reflect.Value) (any, bool) {
+			if v.Kind() == reflect.String {
+				return v.String(), true
+			}
+			return nil, false
+		},
+		parse: func(v any) (any, bool) {
+			s, ok := v.(string)
+			return s, ok
+		},
+	}
+	Int = &Scalar{
+		Name: "Int",
+		serialize: func(v reflect.Value) (any, bool) {
+			switch {
+			case v.CanInt() && v.Int() >= math.MinInt32 && v.Int() <= math.MaxInt32:
+				return v.Int(), true
+			case v.CanUint() && v.Uint() <= math.MaxInt32:
+				return v.Uint(), true
+			}
+			return nil, false
+		},
+		parse: func(v any) (any, bool) {
+			f, ok := number(v)
+			if !ok || f != math.Trunc(f) || f < math.MinInt32 || f > math.MaxInt32 {
+				return nil, false
+			}
+			return int(f), true
+		},
+	}
+	Float = &Scalar{
+		Name: "Float",
+		serialize: func(v reflect.Value) (any, bool) {
+			switch {
+			case v.CanFloat() && !math.IsInf(v.Float(), 0) && !math.IsNaN(v.Float()):
+				return v.Float(), true
+			case v.CanInt():
+				return v.Int(), true
+			case v.CanUint():
+				return v.Uint(), true
+			}
+			return nil, false
+		},
+		parse: func(v any) (any, bool) {
+			return number(v)
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		serialize: func(v reflect.Value) (any, bool) {
+			if v.Kind() == reflect.Bool {
+				return v.Bool(), true
+			}
+			return nil, false
+		},
+		parse: func(v any) (any, bool) {
+			b, ok := v.(bool)
+			return b, ok
+		},
+	}
+	ID = &Scalar{
+		Name: "ID",
+		serialize: func(v reflect.Value) (any, bool) {
+			switch {
+			case v.Kind() == reflect.String:
+				return v.String(), true
+			case v.CanInt():
+				return strconv.FormatInt(v.Int(), 10), true
+			}
+			return nil, false
+		},
+		parse: func(v any) (any, bool) {
+			if s, ok := v.(string); ok {
+				return s, true
+			}
+			if f, ok := number(v); ok && f == math.Trunc(f) {
+				return strconv.FormatFloat(f, 'f', -1, 64), true
+			}
+			return nil, false
+		},
+	}
+)
+
+// number returns v as a float64 if it is a number decoded from JSON.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Enum is a leaf type with a fixed set of values. Its Go values are strings.
+type Enum struct {
+	Name        string
+	Description string
+	Values      []EnumValue
+}
+
+// EnumValue is one value of an Enum.
+type EnumValue struct {
+	Name        string
+	Description string
+}
+
+func (e *Enum) has(name string) bool {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is a type with fields, each resolved separately.
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	fields map[string]*Field // filled by NewSchema
+}
+
+// Field is a field of an Object.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve returns the field's value, given the value of the object it belongs to. If nil,
+	// the value is read from the object: the entry of a map[string]any, or the struct field
+	// whose name matches the field name regardless of case, so "hostUnicode" reads HostUnicode.
+	Resolve func(ctx context.Context, p ResolveParams) (any, error)
+}
+
+// Argument is an argument of a Field. Its Type may only be built from scalars and enums.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	// Default is used when the argument is not given; nil means it has no default.
+	Default any
+}
+
+// List is a list of values of OfType.
+type List struct {
+	OfType Type
+}
+
+// NonNull is OfType, but never null.
+type NonNull struct {
+	OfType Type
+}
+
+// NewList returns the list of t.
+func NewList(t Type) *List { return &List{OfType: t} }
+
+// NewNonNull returns the non-null t.
+func NewNonNull(t Type) *NonNull { return &NonNull{OfType: t} }
+
+func (s *Scalar) String() string  { return s.Name }
+func (e *Enum) String() string    { return e.Name }
+func (o *Object) String() string  { return o.Name }
+func (l *List) String() string    { return "[" + l.OfType.String() + "]" }
+func (n *NonNull) String() string { return n.OfType.String() + "!" }
+
+func (*Scalar) isType()  {}
+func (*Enum) isType()    {}
+func (*Object) isType()  {}
+func (*List) isType()    {}
+func (*NonNull) isType() {}
+
+// named returns the scalar, enum or object t is built from.
+func named(t Type) Type {
+	for {
+		switch tt := t.(type) {
+		case *List:
+			t = tt.OfType
+		case *NonNull:
+			t = tt.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func isLeaf(t Type) bool {
+	_, isObject := named(t).(*Object)
+	return !isObject
+}
+
+// ResolveParams are the inputs of a Field's Resolve function.
+type ResolveParams struct {
+	// Source is the value of the object the field belongs to; nil for fields of the query type.
+	Source any
+	// Args holds the coerced arguments given in the query, and the defaults of the others.
+	Args map[string]any
+	// Selection is what the query selects from the field's value, so resolvers can skip work
+	// for the parts nobody asked for.
+	Selection Selection
+}
+
+// Selection maps the names of the fields selected from an object, including those selected
+// through aliases and fragments but not those skipped by directives, to what is selected
+// from each of them in turn. Leaf fields map to nil.
+type Selection map[string]Selection
+
+// Has reports whether the field at path, a list of field names each nested in the previous
+// one, is selected.
+func (s Selection) Has(path ...string) bool {
+	for _, name := range path {
+		next, ok := s[name]
+		if !ok {
+			return false
+		}
+		s = next
+	}
+	return true
+}
+
+// Schema is a validated set of types, rooted at the query type.
+type Schema struct {
+	query *Object
+	// types lists the named types in the order they are first reached from the query type.
+	types  []Type
+	byName map[string]Type
+}
+
+// NewSchema returns the schema whose queries start at query. It checks that the types
+// reachable from query have unique, valid names and that arguments have input types.
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{query: query, byName: make(map[string]Type)}
+	for _, scalar := range []*Scalar{String, Int, Float, Boolean, ID} {
+		s.byName[scalar.Name] = scalar
+	}
+	if err := s.add(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) add(t Type) error {
+	t = named(t)
+	var name string
+	switch tt := t.(type) {
+	case *Scalar:
+		if s.byName[tt.Name] != t {
+			return fmt.Errorf("graphql: custom scalar %s is not supported", tt.Name)
+		}
+		if !s.listed(t) {
+			s.types = append(s.types, t)
+		}
+		return nil
+	case *Enum:
+		name = tt.Name
+		for _, v := range tt.Values {
+			if !validName(v.Name) || v.Name == "true" || v.Name == "false" || v.Name == "null" {
+				return fmt.Errorf("graphql: invalid value %q of enum %s", v.Name, tt.Name)
+			}
+		}
+	case *Object:
+		name = tt.Name
+	default:
+		return fmt.Errorf("graphql: unsupported type %T", t)
+	}
+
+	if existing, ok := s.byName[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types are named %s", name)
+		}
+		return nil
+	}
+	if !validName(name) {
+		return fmt.Errorf("graphql: invalid type name %q", name)
+	}
+	s.byName[name] = t
+	s.types = append(s.types, t)
+
+	obj, ok := t.(*Object)
+	if !ok {
+		return nil
+	}
+	if len(obj.Fields) == 0 {
+		return fmt.Errorf("graphql: object %s has no fields", obj.Name)
+	}
+	obj.fields = make(map[string]*Field, len(obj.Fields))
+	for _, f := range obj.Fields {
+		if !validName(f.Name) {
+			return fmt.Errorf("graphql: invalid field name %s.%q", obj.Name, f.Name)
+		}
+		if _, dup := obj.fields[f.Name]; dup {
+			return fmt.Errorf("graphql: %s has two fields named %s", obj.Name, f.Name)
+		}
+		obj.fields[f.Name] = f
+		for _, arg := range f.Args {
+			if !validName(arg.Name) {
+				return fmt.Errorf("graphql: invalid argument name %s.%s(%q)", obj.Name, f.Name, arg.Name)
+			}
+			if !isLeaf(arg.Type) {
+				return fmt.Errorf("graphql: argument %s.%s(%s) must have a scalar or enum type", obj.Name, f.Name, arg.Name)
+			}
+			if err := s.add(arg.Type); err != nil {
+				return err
+			}
+		}
+		if err := s.add(f.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) listed(t Type) bool {
+	for _, listed := range s.types {
+		if listed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// validName reports whether name is a valid GraphQL name that does not use the "__" prefix
+// reserved for introspection.
+func validName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "__") || '0' <= name[0] && name[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameByte(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SDL describes the schema in the GraphQL schema definition language.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for _, t := range s.types {
+		switch t := t.(type) {
+		case *Enum:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, v := range t.Values {
+				writeDescription(&b, "  ", v.Description)
+				fmt.Fprintf(&b, "  %s\n", v.Name)
+			}
+			b.WriteString("}\n\n")
+		case *Object:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s", f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + literal(arg.Type, arg.Default)
+						}
+					}
+					fmt.Fprintf(&b, "(%s)", strings.Join(args, ", "))
+				}
+				fmt.Fprintf(&b, ": %s\n", f.Type)
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	if s.query.Name != "Query" {
+		fmt.Fprintf(&b, "schema {\n  query: %s\n}\n\n", s.query.Name)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(description))
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.ReplaceAll(line, `"""`, `\"""`))
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
+
+// literal writes the Go value v of an argument of type t as a GraphQL literal.
+func literal(t Type, v any) string {
+	if _, isEnum := named(t).(*Enum); isEnum {
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
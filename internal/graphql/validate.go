@@ -0,0 +1,362 @@
+package graphql
+
+import (
+	"fmt"
+	"slices"
+)
+
+// validator checks a parsed document against a schema before it is executed, so execution
+// only has to deal with errors raised by resolvers.
+type validator struct {
+	schema    *Schema
+	doc       *document
+	src       string
+	fragments map[string]*fragment
+	errors    []*Error
+
+	// Per operation: its variables, the types they are declared with, and those it uses.
+	vars      map[string]*varDef
+	varTypes  map[string]Type
+	usedVars  map[string]bool
+	spreading map[string]bool // fragments being validated, to detect cycles
+}
+
+// validate returns the errors that make doc invalid against s, if any.
+func validate(s *Schema, doc *document, src string) []*Error {
+	v := &validator{schema: s, doc: doc, src: src, fragments: make(map[string]*fragment)}
+
+	opNames := make(map[string]bool)
+	for _, op := range doc.operations {
+		if op.name == "" && len(doc.operations) > 1 {
+			v.errorf(op.pos, "an anonymous operation must be the only operation in the query")
+		}
+		if op.name != "" && opNames[op.name] {
+			v.errorf(op.pos, "there can be only one operation named %q", op.name)
+		}
+		opNames[op.name] = true
+	}
+	for _, f := range doc.fragments {
+		if _, dup := v.fragments[f.name]; dup {
+			v.errorf(f.pos, "there can be only one fragment named %q", f.name)
+		}
+		v.fragments[f.name] = f
+	}
+
+	used := make(map[string]bool)
+	for _, op := range doc.operations {
+		v.operation(op)
+		v.usedFragments(op.selections, used)
+	}
+	for _, f := range doc.fragments {
+		if !used[f.name] {
+			v.errorf(f.pos, "fragment %q is never used", f.name)
+		}
+	}
+	return v.errors
+}
+
+func (v *validator) errorf(pos int, format string, args ...any) {
+	err := &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{location(v.src, pos)}}
+	// A fragment spread in several places is validated each time; report its errors once.
+	for _, reported := range v.errors {
+		if reported.Message == err.Message && reported.Locations[0] == err.Locations[0] {
+			return
+		}
+	}
+	v.errors = append(v.errors, err)
+}
+
+func (v *validator) operation(op *operation) {
+	v.vars = make(map[string]*varDef)
+	v.varTypes = make(map[string]Type)
+	v.usedVars = make(map[string]bool)
+	v.spreading = make(map[string]bool)
+
+	for _, def := range op.vars {
+		if _, dup := v.vars[def.name]; dup {
+			v.errorf(def.pos, "there can be only one variable named $%s", def.name)
+			continue
+		}
+		v.vars[def.name] = def
+		t, ok := v.schema.inputType(def.typ)
+		if !ok {
+			v.errorf(def.typ.pos, "variable $%s cannot have type %s, only scalars, enums and lists of them", def.name, def.typ)
+			continue
+		}
+		v.varTypes[def.name] = t
+		if def.def != nil {
+			v.value(t, def.def)
+		}
+	}
+
+	v.directives(op.directives, false)
+	v.selections(v.schema.query, op.selections)
+
+	for _, def := range op.vars {
+		if !v.usedVars[def.name] {
+			v.errorf(def.pos, "variable $%s is never used", def.name)
+		}
+	}
+}
+
+func (v *validator) selections(obj *Object, selections []selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *fieldNode:
+			v.field(obj, sel)
+		case *inlineFragment:
+			v.directives(sel.directives, true)
+			if sel.typeCond != "" {
+				v.typeCondition(obj, sel.typeCond, sel.pos)
+			}
+			v.selections(obj, sel.selections)
+		case *fragmentSpread:
+			v.directives(sel.directives, true)
+			f, ok := v.fragments[sel.name]
+			if !ok {
+				v.errorf(sel.pos, "unknown fragment %q", sel.name)
+				continue
+			}
+			if v.spreading[f.name] {
+				v.errorf(sel.pos, "fragment %q spreads itself", f.name)
+				continue
+			}
+			if !v.typeCondition(obj, f.typeCond, f.pos) {
+				continue
+			}
+			v.spreading[f.name] = true
+			v.directives(f.directives, false)
+			v.selections(obj, f.selections)
+			delete(v.spreading, f.name)
+		}
+	}
+	v.mergeable(selections)
+}
+
+// typeCondition checks a fragment's type condition. With no interfaces or unions, a fragment
+// only applies to the object type it names.
+func (v *validator) typeCondition(obj *Object, typeCond string, pos int) bool {
+	t, ok := v.schema.byName[typeCond]
+	switch {
+	case !ok:
+		v.errorf(pos, "unknown type %q", typeCond)
+	case t != obj:
+		v.errorf(pos, "a fragment on %s cannot be spread within %s", typeCond, obj.Name)
+	default:
+		return true
+	}
+	return false
+}
+
+func (v *validator) field(obj *Object, f *fieldNode) {
+	v.directives(f.directives, true)
+	if f.name == "__typename" {
+		if len(f.args) > 0 || f.selections != nil {
+			v.errorf(f.pos, "__typename takes no arguments and has no fields")
+		}
+		return
+	}
+	def, ok := obj.fields[f.name]
+	if !ok {
+		v.errorf(f.pos, "type %s has no field %q", obj.Name, f.name)
+		return
+	}
+	v.arguments(fmt.Sprintf("field %s.%s", obj.Name, def.Name), def.Args, f.args, f.pos)
+
+	sub, isObject := named(def.Type).(*Object)
+	switch {
+	case isObject && f.selections == nil:
+		v.errorf(f.pos, "field %q of type %s must have a selection of subfields", f.name, def.Type)
+	case !isObject && f.selections != nil:
+		v.errorf(f.pos, "field %q of type %s cannot have a selection of subfields", f.name, def.Type)
+	case isObject:
+		v.selections(sub, f.selections)
+	}
+}
+
+func (v *validator) arguments(owner string, defs []*Argument, args []*argNode, pos int) {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		if given[arg.name] {
+			v.errorf(arg.pos, "argument %q is given twice", arg.name)
+			continue
+		}
+		given[arg.name] = true
+		i := slices.IndexFunc(defs, func(def *Argument) bool { return def.Name == arg.name })
+		if i < 0 {
+			v.errorf(arg.pos, "%s has no argument %q", owner, arg.name)
+			continue
+		}
+		v.value(defs[i].Type, arg.val)
+	}
+	for _, def := range defs {
+		if _, required := def.Type.(*NonNull); required && def.Default == nil && !given[def.Name] {
+			v.errorf(pos, "%s requires argument %q", owner, def.Name)
+		}
+	}
+}
+
+var (
+	directiveArgs = []*Argument{{Name: "if", Type: NewNonNull(Boolean)}}
+	directives    = []string{"skip", "include"}
+)
+
+func (v *validator) directives(ds []*directive, allowed bool) {
+	seen := make(map[string]bool)
+	for _, d := range ds {
+		switch {
+		case !slices.Contains(directives, d.name):
+			v.errorf(d.pos, "unknown directive @%s", d.name)
+		case !allowed:
+			v.errorf(d.pos, "directive @%s is not allowed here", d.name)
+		case seen[d.name]:
+			v.errorf(d.pos, "directive @%s is given twice", d.name)
+		default:
+			v.arguments("directive @"+d.name, directiveArgs, d.args, d.pos)
+		}
+		seen[d.name] = true
+	}
+}
+
+// value checks that val can be coerced to t.
+func (v *validator) value(t Type, val *value) {
+	if val.kind == valueVariable {
+		v.variable(t, val)
+		return
+	}
+	switch tt := t.(type) {
+	case *NonNull:
+		if val.kind == valueNull {
+			v.errorf(val.pos, "expected a non-null %s", tt.OfType)
+			return
+		}
+		v.value(tt.OfType, val)
+	case *List:
+		if val.kind != valueList {
+			v.value(tt.OfType, val) // coerced to a list of one
+			return
+		}
+		for _, item := range val.list {
+			v.value(tt.OfType, item)
+		}
+	default:
+		if val.kind == valueNull {
+			return
+		}
+		if _, ok := parseLiteral(t, val); !ok {
+			v.errorf(val.pos, "%s is not a valid %s", val, t)
+		}
+	}
+}
+
+func (v *validator) variable(t Type, val *value) {
+	def, ok := v.vars[val.raw]
+	if !ok {
+		v.errorf(val.pos, "variable $%s is not defined", val.raw)
+		return
+	}
+	v.usedVars[val.raw] = true
+	varType, ok := v.varTypes[val.raw]
+	if !ok {
+		return // reported with the definition
+	}
+	if def.def != nil && def.def.kind != valueNull {
+		// A default makes a nullable variable usable where a non-null value is expected.
+		if nn, ok := t.(*NonNull); ok {
+			if _, varNonNull := varType.(*NonNull); !varNonNull {
+				t = nn.OfType
+			}
+		}
+	}
+	if !assignable(varType, t) {
+		v.errorf(val.pos, "variable $%s of type %s cannot be used where %s is expected", val.raw, varType, t)
+	}
+}
+
+// assignable reports whether values of type from can be used where type to is expected.
+func assignable(from, to Type) bool {
+	if nn, ok := to.(*NonNull); ok {
+		from, ok := from.(*NonNull)
+		return ok && assignable(from.OfType, nn.OfType)
+	}
+	if nn, ok := from.(*NonNull); ok {
+		return assignable(nn.OfType, to)
+	}
+	if toList, ok := to.(*List); ok {
+		fromList, ok := from.(*List)
+		return ok && assignable(fromList.OfType, toList.OfType)
+	}
+	return from == to
+}
+
+// mergeable checks that fields sharing a response key within a selection set, including
+// those selected through fragments, select the same field with the same arguments.
+func (v *validator) mergeable(selections []selection) {
+	byKey := make(map[string]*fieldNode)
+	var walk func([]selection, map[string]bool)
+	walk = func(selections []selection, spread map[string]bool) {
+		for _, sel := range selections {
+			switch sel := sel.(type) {
+			case *fieldNode:
+				other, ok := byKey[sel.key()]
+				if !ok {
+					byKey[sel.key()] = sel
+					continue
+				}
+				if other.name != sel.name || !sameArgs(other.args, sel.args) {
+					v.errorf(sel.pos, "fields %q conflict: they select different fields or arguments", sel.key())
+				}
+			case *inlineFragment:
+				walk(sel.selections, spread)
+			case *fragmentSpread:
+				if f, ok := v.fragments[sel.name]; ok && !spread[sel.name] {
+					spread[sel.name] = true
+					walk(f.selections, spread)
+				}
+			}
+		}
+	}
+	walk(selections, make(map[string]bool))
+}
+
+func sameArgs(a, b []*argNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		i := slices.IndexFunc(b, func(y *argNode) bool { return y.name == x.name })
+		if i < 0 || !sameValue(x.val, b[i].val) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValue(a, b *value) bool {
+	if a.kind != b.kind || a.raw != b.raw || len(a.list) != len(b.list) || !sameArgs(a.fields, b.fields) {
+		return false
+	}
+	for i := range a.list {
+		if !sameValue(a.list[i], b.list[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// usedFragments adds the fragments spread by selections, directly or not, to used.
+func (v *validator) usedFragments(selections []selection, used map[string]bool) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *fieldNode:
+			v.usedFragments(sel.selections, used)
+		case *inlineFragment:
+			v.usedFragments(sel.selections, used)
+		case *fragmentSpread:
+			if f, ok := v.fragments[sel.name]; ok && !used[sel.name] {
+				used[sel.name] = true
+				v.usedFragments(f.selections, used)
+			}
+		}
+	}
+}
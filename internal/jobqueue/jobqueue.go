@@ -0,0 +1,150 @@
+// Package jobqueue runs jobs on a fixed pool of workers, with a bounded number of jobs
+// waiting for a free worker, so a burst of requests cannot start unbounded concurrent work.
+// The pool is either local to the process or, with Redis, shared by several processes.
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrFull is returned by Run when every worker is busy and the queue has no room left.
+var ErrFull = errors.New("jobqueue: queue is full")
+
+// Handler does the work of a job described by payload and returns its result.
+type Handler func(ctx context.Context, payload []byte) ([]byte, error)
+
+// Backend queues jobs for the workers that run them with a Handler. Implementations are
+// safe for concurrent use.
+type Backend interface {
+	// Run queues payload, waits for a worker to handle it and returns the handler's result.
+	// It returns ErrFull if the queue has no room, or ctx's error if ctx is done first.
+	Run(ctx context.Context, payload []byte) ([]byte, error)
+	// Stats returns the current load of the queue.
+	Stats(ctx context.Context) (Stats, error)
+	// Close stops the workers started by the backend and releases its resources.
+	Close() error
+}
+
+// Open returns the backend described by spec, whose workers run jobs with handle and log
+// their failures to logger:
+//
+//	local                          workers in this process (the default)
+//	redis://:pass@host:6379/0      a Redis list shared by every process using it
+//
+// Either way this process starts workers workers, and up to capacity jobs may wait for one.
+func Open(ctx context.Context, logger *slog.Logger, spec string, workers, capacity int, handle Handler) (Backend, error) {
+	switch {
+	case spec == "" || spec == "local":
+		return NewLocal(workers, capacity, handle), nil
+	case strings.HasPrefix(spec, "redis://"):
+		r, err := OpenRedis(ctx, logger, spec, workers, capacity, handle)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unsupported job queue %q: want local or redis://...", spec)
+	}
+}
+
+// Queue runs jobs on its workers in the order they were submitted. It is safe for
+// concurrent use.
+type Queue struct {
+	jobs     chan *job
+	workers  int
+	capacity int
+	// pending counts the jobs admitted and not yet finished or skipped; admission keeps it
+	// at most workers+capacity, which is also the size of jobs, so sends never block.
+	pending atomic.Int64
+	running atomic.Int64
+}
+
+// Job states; a queued job is claimed either by a worker, which runs it, or by its caller
+// giving up on it.
+const (
+	stateQueued int32 = iota
+	stateRunning
+	stateAbandoned
+)
+
+type job struct {
+	ctx   context.Context
+	fn    func(context.Context)
+	state atomic.Int32
+	done  chan struct{}
+}
+
+// New starts a queue of workers workers, of which at least one is started, and room for
+// capacity jobs waiting for a worker.
+func New(workers, capacity int) *Queue {
+	q := &Queue{workers: max(workers, 1), capacity: max(capacity, 0)}
+	q.jobs = make(chan *job, q.workers+q.capacity)
+	for range q.workers {
+		go q.work()
+	}
+	return q
+}
+
+func (q *Queue) work() {
+	for j := range q.jobs {
+		if !j.state.CompareAndSwap(stateQueued, stateRunning) {
+			q.pending.Add(-1) // abandoned while queued
+			continue
+		}
+		q.running.Add(1)
+		j.fn(j.ctx)
+		q.running.Add(-1)
+		// Free the slot before waking the caller, so it may submit again right away.
+		q.pending.Add(-1)
+		close(j.done)
+	}
+}
+
+// Run runs fn with ctx on a worker and waits for it to return. If all workers are busy and
+// the queue is full it returns ErrFull right away. If ctx is done while the job is still
+// waiting, the job is dropped and Run returns ctx's error; once fn has started, Run waits
+// for it, leaving fn to give up on the done ctx.
+func (q *Queue) Run(ctx context.Context, fn func(context.Context)) error {
+	if q.pending.Add(1) > int64(q.workers+q.capacity) {
+		q.pending.Add(-1)
+		return ErrFull
+	}
+	j := &job{ctx: ctx, fn: fn, done: make(chan struct{})}
+	q.jobs <- j
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		if j.state.CompareAndSwap(stateQueued, stateAbandoned) {
+			return ctx.Err()
+		}
+		<-j.done
+		return nil
+	}
+}
+
+// Stats is a snapshot of a queue's load.
+type Stats struct {
+	Workers  int `json:"workers"`
+	Running  int `json:"running"`
+	Capacity int `json:"capacity"`
+	// Queued counts the jobs waiting for a worker, including abandoned ones not yet skipped.
+	Queued int `json:"queued"`
+}
+
+// Stats returns the current load of q.
+func (q *Queue) Stats() Stats {
+	running := int(q.running.Load())
+	return Stats{
+		Workers:  q.workers,
+		Running:  running,
+		Capacity: q.capacity,
+		Queued:   max(int(q.pending.Load())-running, 0),
+	}
+}
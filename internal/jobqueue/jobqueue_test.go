@@ -0,0 +1,169 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// block occupies a worker of q until the returned func is first called.
+func block(t *testing.T, q *Queue) func() {
+	t.Helper()
+	started, release := make(chan struct{}), make(chan struct{})
+	go q.Run(context.Background(), func(context.Context) {
+		close(started)
+		<-release
+	})
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the blocking job to start")
+	}
+	return sync.OnceFunc(func() { close(release) })
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueue_Run(t *testing.T) {
+	q := New(2, 1)
+	ran := false
+	if err := q.Run(context.Background(), func(context.Context) { ran = true }); err != nil {
+		t.Fatalf("Expected the job to run, but got %v", err)
+	}
+	if !ran {
+		t.Error("Expected Run to wait for the job")
+	}
+}
+
+func TestQueue_Run_Full(t *testing.T) {
+	q := New(1, 1)
+	release := block(t, q)
+	defer release()
+
+	queued := make(chan error, 1)
+	go func() { queued <- q.Run(context.Background(), func(context.Context) {}) }()
+	waitFor(t, "a queued job", func() bool { return q.Stats().Queued == 1 })
+
+	if err := q.Run(context.Background(), func(context.Context) { t.Error("Expected the rejected job not to run") }); !errors.Is(err, ErrFull) {
+		t.Fatalf("Expected ErrFull, but got %v", err)
+	}
+
+	expected := Stats{Workers: 1, Running: 1, Capacity: 1, Queued: 1}
+	if actual := q.Stats(); actual != expected {
+		t.Errorf("Expected stats %+v, but got %+v", expected, actual)
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Errorf("Expected the queued job to run once a worker was free, but got %v", err)
+	}
+}
+
+func TestQueue_Run_NoCapacity(t *testing.T) {
+	q := New(1, 0)
+	release := block(t, q)
+	if err := q.Run(context.Background(), func(context.Context) {}); !errors.Is(err, ErrFull) {
+		t.Errorf("Expected ErrFull while the only worker is busy, but got %v", err)
+	}
+	release()
+	waitFor(t, "the worker to be free", func() bool { return q.Stats().Running == 0 })
+	if err := q.Run(context.Background(), func(context.Context) {}); err != nil {
+		t.Errorf("Expected the job to run on the free worker, but got %v", err)
+	}
+}
+
+func TestQueue_Run_CanceledWhileQueued(t *testing.T) {
+	q := New(1, 1)
+	release := block(t, q)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- q.Run(ctx, func(context.Context) { t.Error("Expected the abandoned job not to run") })
+	}()
+	waitFor(t, "a queued job", func() bool { return q.Stats().Queued == 1 })
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+
+	release()
+	waitFor(t, "the abandoned job to be skipped", func() bool { return q.Stats() == Stats{Workers: 1, Capacity: 1} })
+	if err := q.Run(context.Background(), func(context.Context) {}); err != nil {
+		t.Errorf("Expected the job to run, but got %v", err)
+	}
+}
+
+func TestQueue_Run_CanceledWhileRunning(t *testing.T) {
+	q := New(1, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	finished := false
+	go func() {
+		<-started
+		cancel()
+	}()
+	err := q.Run(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		finished = true
+	})
+	if err != nil || !finished {
+		t.Errorf("Expected Run to wait for the started job, but got %v (finished %v)", err, finished)
+	}
+}
+
+func TestQueue_Concurrency(t *testing.T) {
+	const workers = 3
+	q := New(workers, 100)
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var wg sync.WaitGroup
+	for range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.Run(context.Background(), func(context.Context) {
+				mu.Lock()
+				active++
+				peak = max(peak, active)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+	if peak > workers {
+		t.Errorf("Expected at most %d jobs at once, but got %d", workers, peak)
+	}
+}
+
+func TestLocal_Run(t *testing.T) {
+	l := NewLocal(1, 0, func(_ context.Context, payload []byte) ([]byte, error) {
+		if len(payload) == 0 {
+			return nil, errors.New("empty payload")
+		}
+		return append(payload, '!'), nil
+	})
+	if result, err := l.Run(context.Background(), []byte("hi")); err != nil || string(result) != "hi!" {
+		t.Errorf("Expected \"hi!\", but got %q (%v)", result, err)
+	}
+	if _, err := l.Run(context.Background(), nil); err == nil || err.Error() != "empty payload" {
+		t.Errorf("Expected the handler's error, but got %v", err)
+	}
+}
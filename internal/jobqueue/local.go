@@ -0,0 +1,35 @@
+package jobqueue
+
+import "context"
+
+// Local is a Backend whose jobs run on a Queue in this process.
+type Local struct {
+	queue  *Queue
+	handle Handler
+}
+
+// NewLocal returns a local backend running jobs with handle on workers workers, with room
+// for capacity jobs waiting for one. Jobs run with the ctx passed to Run.
+func NewLocal(workers, capacity int, handle Handler) *Local {
+	return &Local{queue: New(workers, capacity), handle: handle}
+}
+
+func (l *Local) Run(ctx context.Context, payload []byte) ([]byte, error) {
+	var result []byte
+	var err error
+	if queueErr := l.queue.Run(ctx, func(ctx context.Context) {
+		result, err = l.handle(ctx, payload)
+	}); queueErr != nil {
+		return nil, queueErr
+	}
+	return result, err
+}
+
+func (l *Local) Stats(context.Context) (Stats, error) {
+	return l.queue.Stats(), nil
+}
+
+// Close does nothing; the workers of a local backend live as long as the process.
+func (l *Local) Close() error {
+	return nil
+}
@@ -0,0 +1,310 @@
+package jobqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// redisDefaultKey is the list holding waiting jobs unless the URL names another with ?key=.
+	redisDefaultKey = "jobqueue"
+	// redisPoll is how long workers and callers block on a list at a time, and so how long
+	// they take to notice Close or a done ctx.
+	redisPoll = time.Second
+	// redisReplyTTL is how long the result of a job is kept for a caller that gave up on it.
+	redisReplyTTL = time.Minute
+	// redisRetryDelay is how long a worker waits before reconnecting after an error.
+	redisRetryDelay = time.Second
+	// redisIdleConns caps the connections kept open for Run.
+	redisIdleConns = 16
+)
+
+// Redis is a Backend that keeps waiting jobs in a Redis list, so the workers of every process
+// using the same list share its jobs; each process adds its own workers. A job is removed
+// from the list by the worker that runs it, which pushes the result to a list of the
+// job's own that the caller waits on.
+type Redis struct {
+	logger   *slog.Logger
+	addr     string
+	password string
+	db       int
+	key      string
+	workers  int
+	capacity int
+	handle   Handler
+
+	running atomic.Int64
+	idle    chan *redisConn
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// redisJob is a job as stored in the list.
+type redisJob struct {
+	ID      string `json:"id"`
+	Payload []byte `json:"payload"`
+	// Deadline is the caller's deadline; workers skip the job once it has passed.
+	Deadline time.Time `json:"deadline,omitzero"`
+}
+
+// redisResult is a job's result as pushed to the caller.
+type redisResult struct {
+	Result []byte `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// OpenRedis connects to the Redis server of rawURL, redis://[:password@]host[:port][/db][?key=list],
+// and starts workers workers taking jobs from the list, up to capacity (at least one) of
+// which may wait for a worker. Since a job counts as waiting until a worker takes it off
+// the list, capacity applies to the jobs of all processes sharing it.
+func OpenRedis(ctx context.Context, logger *slog.Logger, rawURL string, workers, capacity int, handle Handler) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	r := &Redis{
+		logger:   logger,
+		addr:     u.Host,
+		key:      u.Query().Get("key"),
+		workers:  max(workers, 1),
+		capacity: max(capacity, 1),
+		handle:   handle,
+		idle:     make(chan *redisConn, redisIdleConns),
+	}
+	if u.Port() == "" {
+		r.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if r.key == "" {
+		r.key = redisDefaultKey
+	}
+	if u.User != nil {
+		// Redis before 6 has no user names, so a lone user part is taken as the password.
+		r.password, _ = u.User.Password()
+		if r.password == "" {
+			r.password = u.User.Username()
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+
+	conn, err := dialRedis(ctx, r.addr, r.password, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("connect to Redis: %w", err)
+	}
+	if _, err := conn.do(0, "PING"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect to Redis: %w", err)
+	}
+	r.idle <- conn
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	for range r.workers {
+		r.wg.Add(1)
+		go r.work()
+	}
+	return r, nil
+}
+
+func (r *Redis) Run(ctx context.Context, payload []byte) ([]byte, error) {
+	job := redisJob{ID: rand.Text(), Payload: payload}
+	job.Deadline, _ = ctx.Deadline()
+	msg, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := r.do(0, "RPUSH", r.key, string(msg))
+	if err != nil {
+		return nil, err
+	}
+	// A worker may take the job before it is removed again, in which case it is waited for.
+	if waiting, _ := reply.(int64); waiting > int64(r.capacity) && r.remove(string(msg)) {
+		return nil, ErrFull
+	}
+
+	replyKey := r.key + ":reply:" + job.ID
+	for {
+		if ctx.Err() != nil {
+			// A job a worker has already taken runs to completion; its result expires unread.
+			r.remove(string(msg))
+			return nil, ctx.Err()
+		}
+		reply, err := r.do(redisPoll, "BLPOP", replyKey, strconv.Itoa(int(redisPoll/time.Second)))
+		if err != nil {
+			return nil, err
+		}
+		if pair, ok := reply.([]any); ok && len(pair) == 2 {
+			var result redisResult
+			data, _ := pair[1].(string)
+			if err := json.Unmarshal([]byte(data), &result); err != nil {
+				return nil, fmt.Errorf("invalid job result: %w", err)
+			}
+			if result.Error != "" {
+				return nil, errors.New(result.Error)
+			}
+			return result.Result, nil
+		}
+	}
+}
+
+// remove takes a job that no worker has taken yet off the list and reports whether it did.
+func (r *Redis) remove(msg string) bool {
+	reply, err := r.do(0, "LREM", r.key, "1", msg)
+	if err != nil {
+		r.logger.Warn("Could not remove job from Redis queue", "key", r.key, "error", err)
+		return false
+	}
+	removed, _ := reply.(int64)
+	return removed > 0
+}
+
+// Stats returns the workers of this process and the jobs they are running, together with
+// the jobs of all processes waiting in the list.
+func (r *Redis) Stats(context.Context) (Stats, error) {
+	reply, err := r.do(0, "LLEN", r.key)
+	if err != nil {
+		return Stats{}, err
+	}
+	queued, _ := reply.(int64)
+	return Stats{
+		Workers:  r.workers,
+		Running:  int(r.running.Load()),
+		Capacity: r.capacity,
+		Queued:   int(queued),
+	}, nil
+}
+
+// Close stops the workers, canceling the jobs they are running, and waits for them to return.
+func (r *Redis) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	for {
+		select {
+		case conn := <-r.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do runs a command on an idle connection, opening one if there is none.
+func (r *Redis) do(block time.Duration, args ...string) (any, error) {
+	var conn *redisConn
+	select {
+	case conn = <-r.idle:
+	default:
+		var err error
+		if conn, err = dialRedis(context.Background(), r.addr, r.password, r.db); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := conn.do(block, args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		// The connection may be left mid-reply, so it is not reused.
+		conn.Close()
+		return nil, err
+	}
+	select {
+	case r.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+func (r *Redis) work() {
+	defer r.wg.Done()
+	var conn *redisConn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for r.ctx.Err() == nil {
+		if conn == nil {
+			var err error
+			if conn, err = dialRedis(r.ctx, r.addr, r.password, r.db); err != nil {
+				r.retryLater(err)
+				continue
+			}
+		}
+		reply, err := conn.do(redisPoll, "BLPOP", r.key, strconv.Itoa(int(redisPoll/time.Second)))
+		if err != nil {
+			conn.Close()
+			conn = nil
+			r.retryLater(err)
+			continue
+		}
+		if pair, ok := reply.([]any); ok && len(pair) == 2 {
+			data, _ := pair[1].(string)
+			r.runJob(data)
+		}
+	}
+}
+
+// retryLater logs a worker's connection error and waits before it tries again.
+func (r *Redis) retryLater(err error) {
+	if r.ctx.Err() != nil {
+		return
+	}
+	r.logger.Warn("Redis queue worker failed, retrying", "key", r.key, "error", err)
+	select {
+	case <-r.ctx.Done():
+	case <-time.After(redisRetryDelay):
+	}
+}
+
+func (r *Redis) runJob(data string) {
+	var job redisJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		r.logger.Warn("Skipping invalid job in Redis queue", "key", r.key, "error", err)
+		return
+	}
+	ctx := r.ctx
+	if !job.Deadline.IsZero() {
+		if time.Now().After(job.Deadline) {
+			return // the caller has given up
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, job.Deadline)
+		defer cancel()
+	}
+
+	r.running.Add(1)
+	out, err := r.handle(ctx, job.Payload)
+	r.running.Add(-1)
+	result := redisResult{Result: out}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	msg, err := json.Marshal(result)
+	if err != nil {
+		r.logger.Warn("Could not encode job result", "key", r.key, "error", err)
+		return
+	}
+	replyKey := r.key + ":reply:" + job.ID
+	if _, err := r.do(0, "RPUSH", replyKey, string(msg)); err != nil {
+		r.logger.Warn("Could not push job result to Redis", "key", r.key, "error", err)
+		return
+	}
+	if _, err := r.do(0, "EXPIRE", replyKey, strconv.Itoa(int(redisReplyTTL/time.Second))); err != nil {
+		r.logger.Warn("Could not expire job result in Redis", "key", r.key, "error", err)
+	}
+}
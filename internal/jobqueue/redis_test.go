@@ -0,0 +1,239 @@
+package jobqueue
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the list commands the queue uses, keeping lists in memory.
+type fakeRedis struct {
+	addr string
+
+	mu       sync.Mutex
+	lists    map[string][]string
+	commands []string // AUTH and SELECT commands, for checking the connection setup
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{addr: ln.Addr().String(), lists: make(map[string][]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	for {
+		req, err := c.read()
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range req.([]any) {
+			args = append(args, arg.(string))
+		}
+		io.WriteString(conn, f.exec(args))
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "AUTH", "SELECT":
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		f.mu.Unlock()
+		return "+OK\r\n"
+	case "PING":
+		return "+PONG\r\n"
+	case "EXPIRE":
+		return ":1\r\n"
+	case "RPUSH":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.lists[args[1]] = append(f.lists[args[1]], args[2:]...)
+		return fmt.Sprintf(":%d\r\n", len(f.lists[args[1]]))
+	case "LLEN":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return fmt.Sprintf(":%d\r\n", len(f.lists[args[1]]))
+	case "LREM":
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if i := slices.Index(f.lists[args[1]], args[3]); i >= 0 {
+			f.lists[args[1]] = slices.Delete(f.lists[args[1]], i, i+1)
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "BLPOP":
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			f.mu.Lock()
+			if list := f.lists[args[1]]; len(list) > 0 {
+				f.lists[args[1]] = list[1:]
+				f.mu.Unlock()
+				return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(list[0]), list[0])
+			}
+			f.mu.Unlock()
+		}
+		return "*-1\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func openFakeRedis(t *testing.T, f *fakeRedis, workers, capacity int, handle Handler) *Redis {
+	t.Helper()
+	r, err := OpenRedis(context.Background(), slog.New(slog.DiscardHandler), "redis://"+f.addr, workers, capacity, handle)
+	if err != nil {
+		t.Fatalf("Expected to connect to the fake server, but got %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRedis_Run(t *testing.T) {
+	f := newFakeRedis(t)
+	handle := func(_ context.Context, payload []byte) ([]byte, error) {
+		if string(payload) == "fail" {
+			return nil, errors.New("job failed")
+		}
+		return []byte(strings.ToUpper(string(payload))), nil
+	}
+	// Two processes sharing the list; either may run a job.
+	first := openFakeRedis(t, f, 1, 10, handle)
+	openFakeRedis(t, f, 1, 10, handle)
+
+	var wg sync.WaitGroup
+	for _, word := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := first.Run(context.Background(), []byte(word))
+			if err != nil || string(result) != strings.ToUpper(word) {
+				t.Errorf("Expected %q, but got %q (%v)", strings.ToUpper(word), result, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := first.Run(context.Background(), []byte("fail")); err == nil || err.Error() != "job failed" {
+		t.Errorf("Expected the handler's error, but got %v", err)
+	}
+}
+
+func TestRedis_Run_Full(t *testing.T) {
+	f := newFakeRedis(t)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	r := openFakeRedis(t, f, 1, 1, func(context.Context, []byte) ([]byte, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	})
+
+	results := make(chan error, 2)
+	go func() { _, err := r.Run(context.Background(), []byte("running")); results <- err }()
+	<-started
+	go func() { _, err := r.Run(context.Background(), []byte("queued")); results <- err }()
+	waitFor(t, "a queued job", func() bool {
+		stats, err := r.Stats(context.Background())
+		return err == nil && stats.Queued == 1
+	})
+
+	if _, err := r.Run(context.Background(), []byte("rejected")); !errors.Is(err, ErrFull) {
+		t.Fatalf("Expected ErrFull, but got %v", err)
+	}
+	expected := Stats{Workers: 1, Running: 1, Capacity: 1, Queued: 1}
+	if actual, _ := r.Stats(context.Background()); actual != expected {
+		t.Errorf("Expected stats %+v, but got %+v", expected, actual)
+	}
+
+	close(release)
+	for range 2 {
+		if err := <-results; err != nil {
+			t.Errorf("Expected the admitted jobs to run, but got %v", err)
+		}
+	}
+}
+
+func TestRedis_Run_CanceledWhileQueued(t *testing.T) {
+	f := newFakeRedis(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	r := openFakeRedis(t, f, 1, 1, func(_ context.Context, payload []byte) ([]byte, error) {
+		if string(payload) == "queued" {
+			t.Error("Expected the abandoned job not to run")
+			return nil, nil
+		}
+		close(started)
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+	go r.Run(context.Background(), []byte("running"))
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { _, err := r.Run(ctx, []byte("queued")); result <- err }()
+	waitFor(t, "a queued job", func() bool {
+		stats, err := r.Stats(context.Background())
+		return err == nil && stats.Queued == 1
+	})
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got %v", err)
+	}
+	if stats, _ := r.Stats(context.Background()); stats.Queued != 0 {
+		t.Errorf("Expected the abandoned job to be removed, but %d are queued", stats.Queued)
+	}
+}
+
+func TestOpenRedis_URL(t *testing.T) {
+	f := newFakeRedis(t)
+	r, err := OpenRedis(context.Background(), slog.New(slog.DiscardHandler), "redis://:secret@"+f.addr+"/2?key=analyses", 1, 1,
+		func(context.Context, []byte) ([]byte, error) { return []byte("ok"), nil })
+	if err != nil {
+		t.Fatalf("Expected to connect, but got %v", err)
+	}
+	defer r.Close()
+	if result, err := r.Run(context.Background(), nil); err != nil || string(result) != "ok" {
+		t.Fatalf("Expected the job to run, but got %q (%v)", result, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !slices.Contains(f.commands, "AUTH secret") || !slices.Contains(f.commands, "SELECT 2") {
+		t.Errorf("Expected AUTH and SELECT on connecting, but got %q", f.commands)
+	}
+	if r.key != "analyses" {
+		t.Errorf("Expected jobs in the analyses list, but got %q", r.key)
+	}
+}
+
+func TestOpen_Unsupported(t *testing.T) {
+	if _, err := Open(context.Background(), slog.Default(), "kafka://localhost", 1, 1, nil); err == nil {
+		t.Error("Expected an error for an unsupported queue")
+	}
+}
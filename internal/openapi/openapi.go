@@ -0,0 +1,305 @@
+// Package openapi builds an OpenAPI 3 document describing a JSON API, deriving the schemas
+// of request and response bodies from their Go types and json struct tags.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Version is the OpenAPI version of the documents built here.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lower-case HTTP methods to their operation.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of the OpenAPI schema object that Go types map to.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Builder assembles a Document. Struct types passed to SchemaOf become named component
+// schemas, referenced from wherever they are used.
+type Builder struct {
+	// Name returns the component name of a named struct type. By default it is the type's
+	// name with its first letter upper-cased.
+	Name func(reflect.Type) string
+
+	doc   Document
+	names map[reflect.Type]string
+}
+
+// New returns a builder of a document with the given info.
+func New(info Info) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      make(map[string]PathItem),
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// AddServer adds the base URL the paths are relative to.
+func (b *Builder) AddServer(url string) {
+	b.doc.Servers = append(b.doc.Servers, Server{URL: url})
+}
+
+// AddAPIKey declares an API key sent in the named header, and requires it for every
+// operation if required is set.
+func (b *Builder) AddAPIKey(scheme, header, description string, required bool) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = make(map[string]SecurityScheme)
+	}
+	b.doc.Components.SecuritySchemes[scheme] = SecurityScheme{Type: "apiKey", In: "header", Name: header, Description: description}
+	if required {
+		b.doc.Security = append(b.doc.Security, map[string][]string{scheme: {}})
+	}
+}
+
+// Add adds op as the operation of method on path.
+func (b *Builder) Add(method, path string, op Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = &op
+}
+
+// JSON returns the content of a request or response body of JSON shaped like v.
+func (b *Builder) JSON(v any) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: b.SchemaOf(v)}}
+}
+
+// SchemaOf returns the schema of the JSON encoding of v's type.
+func (b *Builder) SchemaOf(v any) *Schema {
+	return b.schema(reflect.TypeOf(v))
+}
+
+// Document returns the document built so far.
+func (b *Builder) Document() Document {
+	return b.doc
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func (b *Builder) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		s := b.schema(t.Elem())
+		if s.Ref != "" {
+			// $ref siblings are ignored in OpenAPI 3.0, so the reference is not made nullable.
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return b.ref(t)
+	default:
+		// Interfaces and anything else may hold any JSON value.
+		return &Schema{}
+	}
+}
+
+// ref returns a reference to the component schema of the named struct t, adding it first
+// if needed.
+func (b *Builder) ref(t reflect.Type) *Schema {
+	name, ok := b.names[t]
+	if !ok {
+		name = b.componentName(t)
+		b.names[t] = name
+		// Registered before it is built, so recursive types refer to themselves.
+		b.doc.Components.Schemas[name] = &Schema{}
+		*b.doc.Components.Schemas[name] = *b.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName returns a name for t that no other type has taken, prefixing the package
+// name on a clash.
+func (b *Builder) componentName(t reflect.Type) string {
+	name := t.Name()
+	if b.Name != nil {
+		name = b.Name(t)
+	} else {
+		r, size := utf8.DecodeRuneInString(name)
+		name = string(unicode.ToUpper(r)) + name[size:]
+	}
+	if _, taken := b.doc.Components.Schemas[name]; taken {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		r, size := utf8.DecodeRuneInString(pkg)
+		name = string(unicode.ToUpper(r)) + pkg[size:] + name
+	}
+	return name
+}
+
+// object returns the object schema of the struct t, following encoding/json's rules for
+// field names, embedded structs and omitted fields.
+func (b *Builder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for field := range fields(t) {
+		name, omit, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			embedded := b.object(field.Type)
+			for name, prop := range embedded.Properties {
+				s.Properties[name] = prop
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		s.Properties[name] = b.schema(field.Type)
+		if !omit {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// fields yields the exported fields of the struct t, and its embedded structs.
+func fields(t reflect.Type) func(func(reflect.StructField) bool) {
+	return func(yield func(reflect.StructField) bool) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if !yield(field) {
+				return
+			}
+		}
+	}
+}
+
+// jsonName returns the JSON name of field and whether it may be omitted. ok is false for
+// fields never encoded.
+func jsonName(field reflect.StructField) (name string, omit, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			omit = true
+		}
+	}
+	return name, omit, true
+}
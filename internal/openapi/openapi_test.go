@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testItem struct {
+	testBase
+	Name     string         `json:"name"`
+	Count    int            `json:"count,omitempty"`
+	Ratio    float64        `json:"ratio"`
+	At       time.Time      `json:"at,omitzero"`
+	Tags     []string       `json:"tags,omitempty"`
+	Labels   map[string]int `json:"labels"`
+	Parent   *testItem      `json:"parent,omitempty"`
+	Note     *string        `json:"note"`
+	Children []testItem     `json:"children"`
+	Raw      any            `json:"raw"`
+	Hidden   string         `json:"-"`
+	Untagged bool
+	internal string
+	Extra    map[string]string `json:"extra,omitempty"`
+}
+
+func TestBuilder_SchemaOf(t *testing.T) {
+	b := New(Info{Title: "Test", Version: "1"})
+	ref := b.SchemaOf(testItem{})
+	if ref.Ref != "#/components/schemas/TestItem" {
+		t.Fatalf("Expected a reference to TestItem, but got %+v", ref)
+	}
+
+	s := b.Document().Components.Schemas["TestItem"]
+	if s == nil || s.Type != "object" {
+		t.Fatalf("Expected TestItem to be an object schema, but got %+v", s)
+	}
+
+	testCases := []struct {
+		property string
+		expected Schema
+	}{
+		{property: "id", expected: Schema{Type: "string"}},
+		{property: "name", expected: Schema{Type: "string"}},
+		{property: "count", expected: Schema{Type: "integer", Format: "int32"}},
+		{property: "ratio", expected: Schema{Type: "number"}},
+		{property: "at", expected: Schema{Type: "string", Format: "date-time"}},
+		{property: "parent", expected: Schema{Ref: "#/components/schemas/TestItem"}},
+		{property: "note", expected: Schema{Type: "string", Nullable: true}},
+		{property: "raw", expected: Schema{}},
+		{property: "Untagged", expected: Schema{Type: "boolean"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.property, func(t *testing.T) {
+			prop := s.Properties[tc.property]
+			if prop == nil {
+				t.Fatalf("Expected property %q, but it is missing", tc.property)
+			}
+			if !reflect.DeepEqual(*prop, tc.expected) {
+				t.Errorf("Expected %+v, but got %+v", tc.expected, *prop)
+			}
+		})
+	}
+
+	if items := s.Properties["children"].Items; items == nil || items.Ref != "#/components/schemas/TestItem" {
+		t.Errorf("Expected children to be an array of TestItem, but got %+v", s.Properties["children"])
+	}
+	if values := s.Properties["labels"].AdditionalProperties; values == nil || values.Type != "integer" {
+		t.Errorf("Expected labels to map to integers, but got %+v", s.Properties["labels"])
+	}
+	for _, name := range []string{"Hidden", "-", "internal"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("Expected property %q to be left out", name)
+		}
+	}
+
+	expectedRequired := []string{"Untagged", "children", "id", "labels", "name", "note", "ratio", "raw"}
+	required := slices.Sorted(slices.Values(s.Required))
+	if !slices.Equal(required, expectedRequired) {
+		t.Errorf("Expected required %v, but got %v", expectedRequired, required)
+	}
+}
+
+func TestBuilder_ComponentNames(t *testing.T) {
+	b := New(Info{Title: "Test", Version: "1"})
+	b.Name = func(t reflect.Type) string { return "Renamed" }
+	b.SchemaOf(testBase{})
+	b.SchemaOf(testItem{})
+
+	schemas := b.Document().Components.Schemas
+	if _, ok := schemas["Renamed"]; !ok {
+		t.Errorf("Expected the first type to be named Renamed, but got %v", slices.Collect(maps.Keys(schemas)))
+	}
+	if _, ok := schemas["OpenapiRenamed"]; !ok {
+		t.Errorf("Expected the clashing type to be prefixed with its package, but got %v", slices.Collect(maps.Keys(schemas)))
+	}
+}
+
+func TestBuilder_Document(t *testing.T) {
+	b := New(Info{Title: "Test", Version: "1"})
+	b.AddServer("/api/v1")
+	b.AddAPIKey("apiKey", "X-API-Key", "", true)
+	b.Add("POST", "/items", Operation{
+		OperationID: "createItem",
+		Summary:     "Create an item",
+		RequestBody: &RequestBody{Required: true, Content: b.JSON(testItem{})},
+		Responses:   map[string]Response{"201": {Description: "Created", Content: b.JSON(testItem{})}},
+	})
+
+	data, err := json.Marshal(b.Document())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["openapi"] != Version {
+		t.Errorf("Expected openapi %s, but got %v", Version, doc["openapi"])
+	}
+	post := doc["paths"].(map[string]any)["/items"].(map[string]any)["post"].(map[string]any)
+	if post["operationId"] != "createItem" {
+		t.Errorf("Expected the operation under paths./items.post, but got %v", post)
+	}
+	if len(doc["security"].([]any)) != 1 {
+		t.Errorf("Expected the API key to be required, but got %v", doc["security"])
+	}
+}
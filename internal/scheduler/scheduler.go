@@ -0,0 +1,253 @@
+// Package scheduler re-runs analyses of registered URLs on cron schedules, turning the
+// analyzer into a monitor.
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+const (
+	// MaxSchedules bounds the number of registered schedules.
+	MaxSchedules = 100
+	// maxRuns is the number of past runs kept per schedule.
+	maxRuns = 20
+)
+
+// ErrTooManySchedules is returned by Add when MaxSchedules are already registered.
+var ErrTooManySchedules = errors.New("too many schedules")
+
+// RunFunc analyzes the URL of schedule for a scheduled run. The result is expected to be
+// stored by the caller, e.g. in the result store. schedule.Runs holds the earlier runs, so
+// the result can be compared with the previous one.
+type RunFunc func(ctx context.Context, schedule Schedule) (*analyzer.AnalysisResult, error)
+
+// Schedule is a URL analyzed on a cron schedule, with its most recent runs.
+type Schedule struct {
+	ID   string `json:"id" xml:"id,attr"`
+	URL  string `json:"url" xml:"url"`
+	Cron string `json:"cron" xml:"cron"`
+	// Email, if set, receives the report of every successful run.
+	Email string `json:"email,omitempty" xml:"email,omitempty"`
+	// WebhookURL, if set, is POSTed the outcome of every run.
+	WebhookURL string    `json:"webhook_url,omitempty" xml:"webhook_url,omitempty"`
+	CreatedAt  time.Time `json:"created_at" xml:"created_at"`
+	// NextRun is when the schedule fires next; zero until the scheduler is started.
+	NextRun time.Time `json:"next_run,omitzero" xml:"next_run,omitempty"`
+	// ConsecutiveFailures counts the failed runs since the last successful one.
+	ConsecutiveFailures int `json:"consecutive_failures" xml:"consecutive_failures"`
+	// Runs lists up to 20 past runs, newest first.
+	Runs []Run `json:"runs" xml:"runs>run"`
+}
+
+// Run is one execution of a schedule. Exactly one of ResultID and Error is set.
+type Run struct {
+	At       time.Time `json:"at" xml:"at,attr"`
+	Duration string    `json:"duration" xml:"duration,attr"`
+	ResultID string    `json:"result_id,omitempty" xml:"result_id,attr,omitempty"`
+	Error    string    `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+// Scheduler runs the registered schedules. It is safe for concurrent use.
+type Scheduler struct {
+	logger *slog.Logger
+	run    RunFunc
+	cron   *cron.Cron
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	schedule Schedule
+	cronID   cron.EntryID
+	// running is set while a run is in progress; a run that is due meanwhile is skipped.
+	running bool
+}
+
+// New returns a stopped scheduler that analyzes due URLs with run.
+func New(logger *slog.Logger, run RunFunc) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger:  logger,
+		run:     run,
+		cron:    cron.New(),
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: make(map[string]*entry),
+	}
+}
+
+// ParseCron validates a standard five-field cron expression ("minute hour day-of-month
+// month day-of-week") or a descriptor such as "@hourly" or "@every 30m".
+func ParseCron(spec string) error {
+	_, err := cron.ParseStandard(spec)
+	return err
+}
+
+// Start starts running schedules in the background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops the scheduler, cancels runs in progress and waits for them to return.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+}
+
+// Shutdown stops starting new runs and waits for the runs in progress to finish. If ctx
+// ends first, the remaining runs are canceled as by Stop, and ctx's error is returned once
+// they have returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	done := s.cron.Stop().Done()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Add registers schedule.URL to be analyzed on the cron schedule schedule.Cron. The ID,
+// creation time and run history are assigned by the scheduler.
+func (s *Scheduler) Add(schedule Schedule) (Schedule, error) {
+	cronSchedule, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid cron expression %q: %w", schedule.Cron, err)
+	}
+	id, err := newID()
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= MaxSchedules {
+		return Schedule{}, ErrTooManySchedules
+	}
+
+	schedule.ID, schedule.CreatedAt = id, time.Now().UTC()
+	schedule.NextRun, schedule.ConsecutiveFailures, schedule.Runs = time.Time{}, 0, nil
+	e := &entry{schedule: schedule}
+	e.cronID = s.cron.Schedule(cronSchedule, cron.FuncJob(func() { s.runSchedule(id) }))
+	s.entries[id] = e
+	s.logger.Info("Schedule added", "id", id, "url", schedule.URL, "cron", schedule.Cron)
+	return s.snapshot(e), nil
+}
+
+// Remove unregisters the schedule with the given ID and reports whether it existed. A run
+// in progress is not interrupted.
+func (s *Scheduler) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return false
+	}
+	s.cron.Remove(e.cronID)
+	delete(s.entries, id)
+	s.logger.Info("Schedule removed", "id", id, "url", e.schedule.URL)
+	return true
+}
+
+// Get returns the schedule with the given ID.
+func (s *Scheduler) Get(id string) (Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return Schedule{}, false
+	}
+	return s.snapshot(e), true
+}
+
+// List returns all schedules, oldest first.
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	list := make([]Schedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, s.snapshot(e))
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b Schedule) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return list
+}
+
+// snapshot copies the schedule of e so callers cannot race with runs. s.mu must be held.
+func (s *Scheduler) snapshot(e *entry) Schedule {
+	schedule := e.schedule
+	schedule.Runs = append([]Run{}, e.schedule.Runs...)
+	schedule.NextRun = s.cron.Entry(e.cronID).Next
+	return schedule
+}
+
+// runSchedule analyzes the URL of schedule id and records the outcome.
+func (s *Scheduler) runSchedule(id string) {
+	s.mu.Lock()
+	e, ok := s.entries[id]
+	if !ok || e.running {
+		s.mu.Unlock()
+		if ok {
+			s.logger.Warn("Skipping scheduled run, previous run still in progress", "id", id)
+		}
+		return
+	}
+	e.running = true
+	schedule := s.snapshot(e)
+	pageURL := schedule.URL
+	s.mu.Unlock()
+
+	start := time.Now()
+	result, err := s.run(s.ctx, schedule)
+	run := Run{At: start.UTC(), Duration: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		run.Error = err.Error()
+		s.logger.Warn("Scheduled analysis failed", "id", id, "url", pageURL, "error", err)
+	} else {
+		run.ResultID = result.ID
+		s.logger.Info("Scheduled analysis finished", "id", id, "url", pageURL, "result_id", result.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.running = false
+	if err != nil {
+		e.schedule.ConsecutiveFailures++
+	} else {
+		e.schedule.ConsecutiveFailures = 0
+	}
+	e.schedule.Runs = slices.Insert(e.schedule.Runs, 0, run)
+	if len(e.schedule.Runs) > maxRuns {
+		e.schedule.Runs = e.schedule.Runs[:maxRuns]
+	}
+}
+
+func newID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
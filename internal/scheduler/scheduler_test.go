@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestScheduler_AddListRemove(t *testing.T) {
+	s := New(testLogger, nil)
+
+	if _, err := s.Add(Schedule{URL: "https://example.com/", Cron: "not a cron"}); err == nil {
+		t.Error("Expected an error for an invalid cron expression, but got nil")
+	}
+
+	first, err := s.Add(Schedule{URL: "https://example.com/", Cron: "*/15 * * * *"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	second, err := s.Add(Schedule{URL: "https://example.org/", Cron: "@daily"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	list := s.List()
+	if len(list) != 2 || list[0].ID != first.ID || list[1].ID != second.ID {
+		t.Fatalf("Expected both schedules oldest first, but got %+v", list)
+	}
+
+	if !s.Remove(first.ID) {
+		t.Error("Expected removing an existing schedule to succeed")
+	}
+	if s.Remove(first.ID) {
+		t.Error("Expected removing a removed schedule to report false")
+	}
+	if _, ok := s.Get(first.ID); ok {
+		t.Error("Expected a removed schedule to be gone")
+	}
+	if len(s.List()) != 1 {
+		t.Errorf("Expected 1 schedule left, but got %d", len(s.List()))
+	}
+}
+
+func TestScheduler_NextRun(t *testing.T) {
+	s := New(testLogger, nil)
+	schedule, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@hourly"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if !schedule.NextRun.IsZero() {
+		t.Errorf("Expected no next run before Start, but got %v", schedule.NextRun)
+	}
+
+	s.Start()
+	defer s.Stop()
+	if got, _ := s.Get(schedule.ID); got.NextRun.IsZero() {
+		t.Error("Expected a next run once started, but got none")
+	}
+}
+
+func TestScheduler_MaxSchedules(t *testing.T) {
+	s := New(testLogger, nil)
+	for range MaxSchedules {
+		if _, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@hourly"}); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+	if _, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@hourly"}); !errors.Is(err, ErrTooManySchedules) {
+		t.Errorf("Expected ErrTooManySchedules, but got: %v", err)
+	}
+}
+
+func TestScheduler_RecordsRuns(t *testing.T) {
+	fail := true
+	s := New(testLogger, func(ctx context.Context, schedule Schedule) (*analyzer.AnalysisResult, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return &analyzer.AnalysisResult{ID: "result-1"}, nil
+	})
+	schedule, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@hourly"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	s.runSchedule(schedule.ID)
+	s.runSchedule(schedule.ID)
+	got, _ := s.Get(schedule.ID)
+	if got.ConsecutiveFailures != 2 || len(got.Runs) != 2 || got.Runs[0].Error != "boom" {
+		t.Errorf("Expected 2 recorded failures, but got %+v", got)
+	}
+
+	fail = false
+	s.runSchedule(schedule.ID)
+	got, _ = s.Get(schedule.ID)
+	if got.ConsecutiveFailures != 0 {
+		t.Errorf("Expected a success to reset the failure count, but got %d", got.ConsecutiveFailures)
+	}
+	if len(got.Runs) != 3 || got.Runs[0].ResultID != "result-1" || got.Runs[0].Error != "" {
+		t.Errorf("Expected the successful run first, but got %+v", got.Runs)
+	}
+
+	for range maxRuns {
+		s.runSchedule(schedule.ID)
+	}
+	if got, _ = s.Get(schedule.ID); len(got.Runs) != maxRuns {
+		t.Errorf("Expected %d runs kept, but got %d", maxRuns, len(got.Runs))
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	calls := 0
+	var s *Scheduler
+	s = New(testLogger, func(ctx context.Context, schedule Schedule) (*analyzer.AnalysisResult, error) {
+		calls++
+		if calls == 1 {
+			// A run that comes due while this one is in progress is skipped.
+			s.runSchedule(s.List()[0].ID)
+		}
+		return &analyzer.AnalysisResult{ID: "result"}, nil
+	})
+	schedule, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@hourly"})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	s.runSchedule(schedule.ID)
+
+	if calls != 1 {
+		t.Errorf("Expected 1 run, but got %d", calls)
+	}
+}
+
+func TestScheduler_ShutdownCancelsRunsAfterDeadline(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var runErr error
+	s := New(testLogger, func(ctx context.Context, schedule Schedule) (*analyzer.AnalysisResult, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		runErr = ctx.Err()
+		return nil, runErr
+	})
+	if _, err := s.Add(Schedule{URL: "https://example.com/", Cron: "@every 1s"}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	s.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, but got: %v", err)
+	}
+	if !errors.Is(runErr, context.Canceled) {
+		t.Errorf("Expected the run in progress to be canceled, but got: %v", runErr)
+	}
+}
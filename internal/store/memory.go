@@ -0,0 +1,100 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxMemoryRecords bounds the memory store; expired records are pruned first and, if that
+// is not enough, the store is cleared.
+const maxMemoryRecords = 1000
+
+// Memory is a Store that keeps records in process memory, so they are lost on restart.
+type Memory struct {
+	retention time.Duration
+
+	mu      sync.Mutex
+	records map[string]memoryRecord
+}
+
+type memoryRecord struct {
+	Record
+	expires time.Time
+}
+
+// NewMemory returns an empty memory store that keeps records for retention (0 keeps them
+// until the store is full).
+func NewMemory(retention time.Duration) *Memory {
+	return &Memory{retention: retention, records: make(map[string]memoryRecord)}
+}
+
+func (m *Memory) Save(_ context.Context, rec Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[rec.Result.ID]; !ok && len(m.records) >= maxMemoryRecords {
+		for id, r := range m.records {
+			if r.expired() {
+				delete(m.records, id)
+			}
+		}
+		if len(m.records) >= maxMemoryRecords {
+			m.records = make(map[string]memoryRecord)
+		}
+	}
+
+	r := memoryRecord{Record: rec}
+	if m.retention > 0 {
+		r.expires = time.Now().Add(m.retention)
+	}
+	m.records[rec.Result.ID] = r
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, id string) (Record, error) {
+	m.mu.Lock()
+	r, ok := m.records[id]
+	m.mu.Unlock()
+
+	if !ok || r.expired() {
+		return Record{}, ErrNotFound
+	}
+	return r.Record, nil
+}
+
+func (m *Memory) ListByURL(_ context.Context, pageURL string, limit int) ([]Record, error) {
+	m.mu.Lock()
+	var list []Record
+	for _, r := range m.records {
+		if r.URL == pageURL && !r.Custom && !r.expired() {
+			list = append(list, r.Record)
+		}
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b Record) int {
+		return cmp.Or(b.Result.AnalyzedAt.Compare(a.Result.AnalyzedAt), cmp.Compare(a.Result.ID, b.Result.ID))
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *Memory) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	delete(m.records, id)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (r memoryRecord) expired() bool {
+	return !r.expires.IsZero() && time.Now().After(r.expires)
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+// dialect captures what differs between the supported SQL databases.
+type dialect struct {
+	driver string
+	// numbered reports whether placeholders are written $1, $2, ... instead of ?.
+	numbered bool
+}
+
+var (
+	sqliteDialect   = dialect{driver: "sqlite"}
+	postgresDialect = dialect{driver: "pgx", numbered: true}
+)
+
+// The result is stored as its JSON encoding, which SchemaVersion keeps readable across
+// releases; the other columns only serve lookups. Times are Unix milliseconds so both
+// databases store and compare them the same way, and custom is 1 for custom records.
+const createTable = `CREATE TABLE IF NOT EXISTS analysis_results (
+	id TEXT PRIMARY KEY,
+	url TEXT NOT NULL,
+	analyzed_at BIGINT NOT NULL,
+	saved_at BIGINT NOT NULL,
+	result TEXT NOT NULL,
+	custom INTEGER NOT NULL DEFAULT 0
+)`
+
+// addCustomColumn upgrades a table created before records could be custom.
+const addCustomColumn = `ALTER TABLE analysis_results ADD COLUMN custom INTEGER NOT NULL DEFAULT 0`
+
+const createIndex = `CREATE INDEX IF NOT EXISTS analysis_results_url ON analysis_results (url, analyzed_at)`
+
+// SQL is a Store backed by a SQLite or PostgreSQL database.
+type SQL struct {
+	db        *sql.DB
+	dialect   dialect
+	retention time.Duration
+}
+
+func openSQL(ctx context.Context, d dialect, dsn string, retention time.Duration) (*SQL, error) {
+	db, err := sql.Open(d.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if d == sqliteDialect {
+		// SQLite allows one writer at a time; a single connection avoids "database is locked".
+		db.SetMaxOpenConns(1)
+	}
+	for _, stmt := range []string{createTable, createIndex} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if err := upgradeTable(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQL{db: db, dialect: d, retention: retention}, nil
+}
+
+// upgradeTable adds the columns missing from a table created by an earlier release.
+func upgradeTable(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `SELECT custom FROM analysis_results WHERE 1 = 0`)
+	if err == nil {
+		return rows.Close()
+	}
+	_, err = db.ExecContext(ctx, addCustomColumn)
+	return err
+}
+
+func (s *SQL) Save(ctx context.Context, rec Record) error {
+	data, err := json.Marshal(rec.Result)
+	if err != nil {
+		return err
+	}
+	custom := 0
+	if rec.Custom {
+		custom = 1
+	}
+	_, err = s.db.ExecContext(ctx, s.query(`INSERT INTO analysis_results (id, url, analyzed_at, saved_at, result, custom)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET saved_at = excluded.saved_at, result = excluded.result, custom = excluded.custom`),
+		rec.Result.ID, rec.URL, rec.Result.AnalyzedAt.UnixMilli(), time.Now().UnixMilli(), string(data), custom)
+	return err
+}
+
+func (s *SQL) Get(ctx context.Context, id string) (Record, error) {
+	row := s.db.QueryRowContext(ctx, s.query(`SELECT url, result, custom FROM analysis_results WHERE id = ? AND saved_at >= ?`),
+		id, s.savedAfter())
+	rec, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Record{}, ErrNotFound
+	}
+	return rec, err
+}
+
+func (s *SQL) ListByURL(ctx context.Context, pageURL string, limit int) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(`SELECT url, result, custom FROM analysis_results
+		WHERE url = ? AND custom = 0 AND saved_at >= ? ORDER BY analyzed_at DESC, id LIMIT ?`),
+		pageURL, s.savedAfter(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Record
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rec)
+	}
+	return list, rows.Err()
+}
+
+func (s *SQL) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.query(`DELETE FROM analysis_results WHERE id = ?`), id)
+	return err
+}
+
+func (s *SQL) Close() error {
+	return s.db.Close()
+}
+
+// savedAfter returns the oldest saved_at that has not expired.
+func (s *SQL) savedAfter() int64 {
+	if s.retention <= 0 {
+		return 0
+	}
+	return time.Now().Add(-s.retention).UnixMilli()
+}
+
+// query rewrites the ? placeholders of q for the store's dialect.
+func (s *SQL) query(q string) string {
+	if !s.dialect.numbered {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func scanRecord(row interface{ Scan(...any) error }) (Record, error) {
+	var rec Record
+	var data string
+	var custom int
+	if err := row.Scan(&rec.URL, &data, &custom); err != nil {
+		return Record{}, err
+	}
+	rec.Custom = custom != 0
+	rec.Result = new(analyzer.AnalysisResult)
+	if err := json.Unmarshal([]byte(data), rec.Result); err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"web-analyzer/pkg/analyzer"
+)
+
+func newRecord(id, pageURL string, analyzedAt time.Time) Record {
+	return Record{URL: pageURL, Result: &analyzer.AnalysisResult{
+		SchemaVersion: analyzer.SchemaVersion,
+		ID:            id,
+		Title:         "Page " + id,
+		Headings:      map[string]int{"h1": 1},
+		AnalyzedAt:    analyzedAt,
+	}}
+}
+
+// testStores returns every store that can run here. PostgreSQL is only tested when
+// ANALYZER_TEST_POSTGRES is set to a connection URL.
+func testStores(t *testing.T, retention time.Duration) map[string]Store {
+	t.Helper()
+	ctx := context.Background()
+	stores := map[string]Store{"memory": NewMemory(retention)}
+
+	sqlite, err := Open(ctx, "sqlite:"+filepath.Join(t.TempDir(), "results.db"), retention)
+	if err != nil {
+		t.Fatalf("Expected no error opening SQLite, but got: %v", err)
+	}
+	stores["sqlite"] = sqlite
+
+	if dsn := os.Getenv("ANALYZER_TEST_POSTGRES"); dsn != "" {
+		postgres, err := Open(ctx, dsn, retention)
+		if err != nil {
+			t.Fatalf("Expected no error opening PostgreSQL, but got: %v", err)
+		}
+		stores["postgres"] = postgres
+	}
+
+	for _, s := range stores {
+		t.Cleanup(func() { s.Close() })
+	}
+	return stores
+}
+
+func TestStore_SaveGetDelete(t *testing.T) {
+	for name, s := range testStores(t, time.Hour) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "save-get-" + name
+			rec := newRecord(id, "https://example.com/", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+			if err := s.Save(ctx, rec); err != nil {
+				t.Fatalf("Expected no error saving, but got: %v", err)
+			}
+			rec.Result.Title = "Updated"
+			if err := s.Save(ctx, rec); err != nil {
+				t.Fatalf("Expected no error saving again, but got: %v", err)
+			}
+
+			got, err := s.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Expected no error getting, but got: %v", err)
+			}
+			if got.URL != rec.URL || got.Result.Title != "Updated" || got.Result.Headings["h1"] != 1 {
+				t.Errorf("Expected the saved record, but got %+v (result %+v)", got, got.Result)
+			}
+			if !got.Result.AnalyzedAt.Equal(rec.Result.AnalyzedAt) {
+				t.Errorf("Expected analyzed_at %v, but got %v", rec.Result.AnalyzedAt, got.Result.AnalyzedAt)
+			}
+
+			if err := s.Delete(ctx, id); err != nil {
+				t.Fatalf("Expected no error deleting, but got: %v", err)
+			}
+			if _, err := s.Get(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound after delete, but got: %v", err)
+			}
+			if err := s.Delete(ctx, id); err != nil {
+				t.Errorf("Expected deleting a missing record to succeed, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestStore_ListByURL(t *testing.T) {
+	for name, s := range testStores(t, time.Hour) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pageURL := "https://list.example/" + name
+			base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			for _, rec := range []Record{
+				newRecord(name+"-old", pageURL, base),
+				newRecord(name+"-newest", pageURL, base.Add(2*time.Hour)),
+				newRecord(name+"-middle", pageURL, base.Add(time.Hour)),
+			} {
+				if err := s.Save(ctx, rec); err != nil {
+					t.Fatalf("Expected no error saving, but got: %v", err)
+				}
+			}
+			if err := s.Save(ctx, newRecord(name+"-other", "https://other.example/", base)); err != nil {
+				t.Fatalf("Expected no error saving, but got: %v", err)
+			}
+			custom := newRecord(name+"-custom", pageURL, base.Add(3*time.Hour))
+			custom.Custom = true
+			if err := s.Save(ctx, custom); err != nil {
+				t.Fatalf("Expected no error saving, but got: %v", err)
+			}
+
+			list, err := s.ListByURL(ctx, pageURL, 2)
+			if err != nil {
+				t.Fatalf("Expected no error listing, but got: %v", err)
+			}
+			if len(list) != 2 || list[0].Result.ID != name+"-newest" || list[1].Result.ID != name+"-middle" {
+				t.Errorf("Expected the two newest records, newest first, but got %d records", len(list))
+				for _, rec := range list {
+					t.Logf("  %s %v", rec.Result.ID, rec.Result.AnalyzedAt)
+				}
+			}
+			if got, err := s.Get(ctx, custom.Result.ID); err != nil || !got.Custom {
+				t.Errorf("Expected the custom record by its ID, but got %+v and %v", got, err)
+			}
+		})
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	for name, s := range testStores(t, time.Millisecond) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			rec := newRecord("expiring-"+name, "https://expiring.example/", time.Now())
+			if err := s.Save(ctx, rec); err != nil {
+				t.Fatalf("Expected no error saving, but got: %v", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			if _, err := s.Get(ctx, rec.Result.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for an expired record, but got: %v", err)
+			}
+			if list, _ := s.ListByURL(ctx, rec.URL, 10); len(list) != 0 {
+				t.Errorf("Expected no expired records listed, but got %d", len(list))
+			}
+		})
+	}
+}
+
+func TestOpen_UpgradesSQLiteTable(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "results.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Expected no error opening SQLite, but got: %v", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE TABLE analysis_results (id TEXT PRIMARY KEY, url TEXT NOT NULL,
+		analyzed_at BIGINT NOT NULL, saved_at BIGINT NOT NULL, result TEXT NOT NULL)`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Expected no error creating the old table, but got: %v", err)
+	}
+
+	s, err := Open(ctx, "sqlite:"+path, 0)
+	if err != nil {
+		t.Fatalf("Expected no error opening the old table, but got: %v", err)
+	}
+	defer s.Close()
+	if err := s.Save(ctx, newRecord("upgraded", "https://upgraded.example/", time.Now())); err != nil {
+		t.Fatalf("Expected no error saving, but got: %v", err)
+	}
+	if list, err := s.ListByURL(ctx, "https://upgraded.example/", 10); err != nil || len(list) != 1 {
+		t.Errorf("Expected the record listed, but got %d records and %v", len(list), err)
+	}
+}
+
+func TestOpen_UnsupportedStore(t *testing.T) {
+	if _, err := Open(context.Background(), "mysql://localhost/db", 0); err == nil {
+		t.Error("Expected an error for an unsupported store, but got nil")
+	}
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSender_Send(t *testing.T) {
+	secret := []byte("s3cret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderEvent) != "analysis.completed" {
+			t.Errorf("Expected event analysis.completed, but got %q", r.Header.Get(HeaderEvent))
+		}
+		if string(body) != `{"ok":true}` {
+			t.Errorf("Expected the JSON payload, but got %s", body)
+		}
+		expected := Sign(secret, r.Header.Get(HeaderTimestamp), body)
+		if !hmac.Equal([]byte(r.Header.Get(HeaderSignature)), []byte(expected)) {
+			t.Errorf("Expected signature %s, but got %s", expected, r.Header.Get(HeaderSignature))
+		}
+	}))
+	defer server.Close()
+
+	sender := &Sender{Client: server.Client(), Secret: secret}
+	if err := sender.Send(context.Background(), server.URL, "analysis.completed", map[string]bool{"ok": true}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestSender_Unsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sig := r.Header.Get(HeaderSignature); sig != "" {
+			t.Errorf("Expected no signature without a secret, but got %q", sig)
+		}
+	}))
+	defer server.Close()
+
+	sender := &Sender{Client: server.Client()}
+	if err := sender.Send(context.Background(), server.URL, "test", nil); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+}
+
+func TestSender_Retries(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		expectedCalls int32
+	}{
+		{name: "Server error is retried", status: http.StatusBadGateway, expectedCalls: 3},
+		{name: "Rate limit is retried", status: http.StatusTooManyRequests, expectedCalls: 3},
+		{name: "Client error is not retried", status: http.StatusBadRequest, expectedCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			sender := &Sender{Client: server.Client(), Retries: 2, Backoff: time.Millisecond}
+			if err := sender.Send(context.Background(), server.URL, "test", nil); err == nil {
+				t.Error("Expected an error, but got nil")
+			}
+			if calls.Load() != tc.expectedCalls {
+				t.Errorf("Expected %d attempts, but got %d", tc.expectedCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1700000000.{}' | openssl dgst -sha256 -hmac key
+	expected := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	if got := Sign([]byte("key"), "1700000000", []byte("{}")); got != expected {
+		t.Errorf("Expected %s, but got %s", expected, got)
+	}
+}
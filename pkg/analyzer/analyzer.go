@@ -0,0 +1,465 @@
+// Package analyzer fetches a web page and reports on its structure: HTML version, title,
+// headings, links and their reachability, login forms and security headers. It is the only
+// analysis implementation; the server in cmd and the internal packages build on it, and
+// other programs can import it to analyze pages directly with AnalyzePage.
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+func AnalyzePage(ctx context.Context, logger *slog.Logger, pageURL string, opts Options) (_ *AnalysisResult, err error) {
+	ctx, span := tracer.Start(ctx, "AnalyzePage", trace.WithAttributes(attribute.String("url.full", pageURL)))
+	defer func() { endSpan(span, err) }()
+
+	logger = logger.With(slog.String("Analyzing page url", pageURL))
+	logger.DebugContext(ctx, "Starting page analysis")
+
+	if opts.Proxy != nil {
+		ctx = withProxy(ctx, opts.Proxy)
+	}
+
+	// -- 1. Validate url ---
+	isvValid := IsValidURL(pageURL)
+	if !isvValid {
+		logger.ErrorContext(ctx, "Invalid page Url")
+		return nil, fmt.Errorf("invalid page Url")
+	}
+
+	session, err := newAuthSession(pageURL, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Invalid credentials", slog.Any("error", err))
+		return nil, err
+	}
+	if session != nil {
+		ctx = withAuthSession(ctx, session)
+	}
+
+	// --- 2. Load Web Page ---
+	fetchURL := pageURL
+	var snapshot *ArchiveSnapshot
+	if opts.Snapshot != "" {
+		snapshot, err = snapshotToAnalyze(ctx, pageURL, opts)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to find archived snapshot", slog.String("snapshot", opts.Snapshot), slog.Any("error", err))
+			return nil, err
+		}
+		fetchURL = rawSnapshotURL(snapshot)
+		logger.InfoContext(ctx, "Analyzing archived snapshot", slog.String("snapshot_url", fetchURL))
+	}
+
+	reportStage(opts.Progress, StageFetching)
+	data, err := loadWebPage(ctx, logger, fetchURL, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to load web page", slog.Any("error", err))
+		return nil, err
+	}
+	defer data.Body.Close()
+	// The checks of the response headers look at those of the page, not of the archive.
+	header := data.Header
+	if snapshot != nil {
+		header = archivedHeader(data.Header)
+	}
+
+	if data.StatusCode == http.StatusNotModified && opts.Revalidate != nil {
+		span.SetAttributes(attribute.Bool("analysis.revalidated", true))
+		result := *opts.Revalidate
+		result.ID = newAnalysisID()
+		result.AnalyzedAt = time.Now().UTC()
+		logger.InfoContext(ctx, "Reusing previous analysis of unmodified page",
+			slog.String("etag", result.ETag),
+			slog.String("last_modified", result.LastModified),
+		)
+		return &result, nil
+	}
+
+	body, stream, err := openPageBody(data, opts.StreamingThreshold, opts.MaxPageBytes)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to read page body", slog.Any("error", err))
+		return nil, err
+	}
+
+	result := &AnalysisResult{
+		SchemaVersion: SchemaVersion,
+		ID:            newAnalysisID(),
+		Headings:      make(map[string]int),
+		ETag:          header.Get("ETag"),
+		LastModified:  header.Get("Last-Modified"),
+		Redirects:     redirectsOf(data),
+		Archive:       snapshot,
+	}
+
+	baseURL, err := url.Parse(pageURL)
+	if err != nil {
+		logger.ErrorContext(ctx, "Fatal: could not parse base URL", slog.Any("error", err))
+		return nil, fmt.Errorf("could not parse base URL: %w", err)
+	}
+	result.Host, result.HostUnicode = hostForms(baseURL)
+
+	partial := newPartialReporter(opts.Partial)
+
+	// The validator checks the markup while the page is analyzed.
+	type validation struct {
+		findings []SecurityFinding
+		err      error
+	}
+	var validated chan validation
+	if opts.ValidatorURL != "" && opts.runs(CheckMarkupValidation) {
+		if stream != nil {
+			logger.InfoContext(ctx, "Page exceeds the streaming threshold, skipping markup validation")
+		} else {
+			validated = make(chan validation, 1)
+			partial.running(CheckMarkupValidation)
+			go func() {
+				findings, err := validateMarkup(ctx, opts.ValidatorURL, body, data.Header.Get("Content-Type"))
+				validated <- validation{findings, err}
+			}()
+		}
+	}
+
+	// The host's DNS records, the registration of its domain and its latest archived snapshot
+	// are looked up while the page is analyzed.
+	type dnsReport struct {
+		records *DNSRecords
+		err     error
+	}
+	var resolved chan dnsReport
+	if opts.runs(CheckDNS) {
+		resolved = make(chan dnsReport, 1)
+		partial.running(CheckDNS)
+		go func() {
+			records, err := lookupDNS(ctx, logger, baseURL.Hostname())
+			resolved <- dnsReport{records, err}
+		}()
+	}
+
+	type registrationLookup struct {
+		registration *DomainRegistration
+		err          error
+	}
+	var registered chan registrationLookup
+	if opts.RDAPURL != "" && opts.runs(CheckRegistration) {
+		registered = make(chan registrationLookup, 1)
+		partial.running(CheckRegistration)
+		go func() {
+			registration, err := lookupRegistration(ctx, opts.RDAPURL, baseURL.Hostname())
+			registered <- registrationLookup{registration, err}
+		}()
+	}
+
+	type archiveLookup struct {
+		snapshot *ArchiveSnapshot
+		err      error
+	}
+	var archived chan archiveLookup
+	if opts.WaybackURL != "" && snapshot == nil && opts.runs(CheckArchive) {
+		archived = make(chan archiveLookup, 1)
+		partial.running(CheckArchive)
+		go func() {
+			latest, err := lookupSnapshot(ctx, opts.WaybackURL, pageURL, "")
+			archived <- archiveLookup{latest, err}
+		}()
+	}
+
+	// --- 3. Run All Analyses ---
+	reportStage(opts.Progress, StageParsing)
+	var linkAnalysis LinkAnalysis
+	if stream != nil {
+		logger.InfoContext(ctx, "Page exceeds the streaming threshold, analyzing without a DOM",
+			slog.Int64("streaming_threshold_bytes", opts.StreamingThreshold),
+		)
+		span.SetAttributes(attribute.Bool("analysis.streamed", true))
+		counted := &countingReader{r: stream}
+		linkAnalysis, err = analyzeStream(ctx, logger, counted, baseURL, opts, result)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to analyze streamed document", slog.Any("error", err))
+			return nil, err
+		}
+		result.PageBytes = counted.n
+	} else {
+		result.PageBytes = int64(len(body))
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to parse HTML document", slog.Any("error", err))
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		linkAnalysis = analyzeDocument(ctx, logger, doc, baseURL, opts, result)
+	}
+
+	result.Noindex = result.Noindex || headerNoindex(header)
+	result.Links.InternalCount = len(linkAnalysis.InternalLinks)
+	result.Links.ExternalCount = len(linkAnalysis.ExternalLinks)
+	result.Links.BrokenAnchorCount = len(linkAnalysis.BrokenAnchors)
+	result.Links.SkippedCounts = linkAnalysis.SkippedLinks
+	result.Links.InternalIframeCount = len(linkAnalysis.InternalIframes)
+	result.Links.ExternalIframeCount = len(linkAnalysis.ExternalIframes)
+	result.Links.CSSResourceCount = len(linkAnalysis.CSSResources)
+	result.Links.MediaSourceCount = len(linkAnalysis.MediaSources)
+	result.Iframes = linkAnalysis.Iframes
+	if opts.runs(CheckSecurity) {
+		result.SecurityFindings = securityFindings(baseURL, header, linkAnalysis, result.ContainsLoginForm)
+	}
+	for _, check := range documentChecks {
+		if opts.runs(check) && (check != CheckSpelling || spellcheckEnabled()) {
+			partial.finished(check, result)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		logger.WarnContext(ctx, "Analysis canceled before link checks", slog.Any("error", err))
+		return nil, err
+	}
+
+	// The page and its external links are checked against the blocklists while the links are.
+	type blocklistCheck struct {
+		findings []SecurityFinding
+		err      error
+	}
+	var blocklisted chan blocklistCheck
+	if blocklistEnabled() && opts.runs(CheckBlocklist) {
+		blocklisted = make(chan blocklistCheck, 1)
+		partial.running(CheckBlocklist)
+		go func() {
+			findings, err := blocklistFindings(ctx, baseURL, linkAnalysis)
+			blocklisted <- blocklistCheck{findings, err}
+		}()
+	}
+
+	// Inaccessible Link Check
+	type linkCheck struct {
+		report linkCheckReport
+		err    error
+	}
+	var checked chan linkCheck
+	if result.Classification != nil {
+		// The links of parking pages are mostly ads, and welcome pages link to the server's
+		// documentation, neither of which says anything about the site.
+		logger.InfoContext(ctx, "Page is a stand-in, skipping link checks and scoring",
+			slog.String("classification", result.Classification.Kind),
+			slog.String("signature", result.Classification.Signature),
+		)
+	} else if opts.runs(CheckLinkStatus) {
+		checked = make(chan linkCheck, 1)
+		partial.running(CheckLinkStatus)
+		go func() {
+			report, err := validateLinkAccessibility(ctx, logger, linkAnalysis, opts)
+			checked <- linkCheck{report, err}
+		}()
+	}
+	partial.report(result)
+
+	// The slower checks go into the result as each completes, so the partial results have
+	// them as soon as they are available. Their findings are kept apart, to join those of
+	// the page in the same order whichever completes first.
+	pageFindings := result.SecurityFindings
+	var markupFindings, newDomainFindings, listedFindings []SecurityFinding
+	var linkReport linkCheckReport
+	pending := func() bool {
+		return checked != nil || validated != nil || resolved != nil || registered != nil || archived != nil || blocklisted != nil
+	}
+	for pending() {
+		var done string
+		select {
+		case c := <-checked:
+			if c.err != nil {
+				logger.WarnContext(ctx, "Analysis canceled during link checks", slog.Any("error", c.err))
+				return nil, c.err
+			}
+			linkReport, checked, done = c.report, nil, CheckLinkStatus
+			// Set here for the partial results, and again below for analyses without link checks.
+			result.Links.InaccessibleCount = len(linkReport.Inaccessible)
+			result.LinkResults = linkResults(linkAnalysis, linkReport, baseURL)
+		case v := <-validated:
+			markupFindings, validated, done = v.findings, nil, CheckMarkupValidation
+			result.addCheckError(CheckMarkupValidation, v.err)
+		case r := <-resolved:
+			resolved, done = nil, CheckDNS
+			result.DNS = r.records
+			result.Hosting = hostingIPs(baseURL.Hostname(), r.records)
+			result.addCheckError(CheckDNS, r.err)
+		case r := <-registered:
+			registered, done = nil, CheckRegistration
+			result.Registration = r.registration
+			result.addCheckError(CheckRegistration, r.err)
+			if opts.runs(CheckSecurity) {
+				newDomainFindings = registrationFindings(r.registration, result.ContainsLoginForm, time.Now())
+			}
+		case a := <-archived:
+			archived, done = nil, CheckArchive
+			result.Archive = a.snapshot
+			result.addCheckError(CheckArchive, a.err)
+		case b := <-blocklisted:
+			listedFindings, blocklisted, done = b.findings, nil, CheckBlocklist
+			result.addCheckError(CheckBlocklist, b.err)
+		}
+		result.SecurityFindings = slices.Concat(pageFindings, markupFindings, newDomainFindings, listedFindings)
+		partial.finished(done, result)
+		if pending() {
+			partial.report(result)
+		}
+	}
+	result.Links.InaccessibleCount = len(linkReport.Inaccessible)
+	result.LinkResults = linkResults(linkAnalysis, linkReport, baseURL)
+	notChecked := make(map[string]int)
+	for reason, links := range linkReport.NotChecked {
+		notChecked[reason] = len(links)
+	}
+	result.Links.NotCheckedCounts = notChecked
+
+	if opts.Checks == nil && result.Classification == nil {
+		result.Score, result.Grade = scoreResult(result)
+	}
+	result.AnalyzedAt = time.Now().UTC()
+	span.SetAttributes(attribute.Int("analysis.score", result.Score), attribute.Int("analysis.links.inaccessible", result.Links.InaccessibleCount))
+
+	// --- 4. Final Summary Log ---
+	logger.InfoContext(ctx, "Page analysis complete",
+		slog.Group("results",
+			slog.String("host", result.Host),
+			slog.String("host_unicode", result.HostUnicode),
+			slog.String("html_version", result.HTMLVersion),
+			slog.String("title", result.Title),
+			slog.Int("internal_links", result.Links.InternalCount),
+			slog.Int("external_links", result.Links.ExternalCount),
+			slog.Int("internal_iframes", result.Links.InternalIframeCount),
+			slog.Int("external_iframes", result.Links.ExternalIframeCount),
+			slog.Int("css_resources", result.Links.CSSResourceCount),
+			slog.Int("media_sources", result.Links.MediaSourceCount),
+			slog.Int("inaccessible_links", result.Links.InaccessibleCount),
+			slog.Int("broken_anchors", result.Links.BrokenAnchorCount),
+			slog.Bool("has_login_form", result.ContainsLoginForm),
+		),
+	)
+
+	return result, nil
+}
+
+// newAnalysisID returns a random, unguessable ID for a new analysis.
+func newAnalysisID() string {
+	var b [16]byte
+	// crypto/rand.Read never returns an error on supported platforms.
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// analyzeDocument runs the DOM-based checks on doc, filling result and returning the page's
+// links for validation. The checks only read the document, so they run side by side; each
+// one writes to its own part of the result.
+func analyzeDocument(ctx context.Context, logger *slog.Logger, doc *goquery.Document, baseURL *url.URL, opts Options, result *AnalysisResult) LinkAnalysis {
+	ctx, span := tracer.Start(ctx, "analyzeDocument")
+	defer span.End()
+
+	logger.DebugContext(ctx, "Beginning individual analyses")
+
+	var (
+		g            errgroup.Group
+		mu           sync.Mutex
+		linkAnalysis LinkAnalysis
+		cssResources []string
+	)
+
+	// check runs fn as part of the group, unless opts leaves the named check out. A failing
+	// check is recorded in the result rather than failing the analysis, so the other checks
+	// still produce their sections.
+	check := func(name string, fn func() error) {
+		if !opts.runs(name) {
+			return
+		}
+		g.Go(func() error {
+			if err := fn(); err != nil {
+				logger.WarnContext(ctx, "An individual analysis failed, continuing with partial results",
+					slog.String("check", name),
+					slog.Any("error", err),
+				)
+				mu.Lock()
+				result.addCheckError(name, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	// HTML Version
+	check(CheckHTMLVersion, func() (err error) {
+		result.HTMLVersion, err = findHTMLVersion(ctx, logger, doc)
+		return err
+	})
+
+	// Title, Description, ContentFingerprint, WordCount, Placeholders and Classification
+	g.Go(func() error {
+		result.Title = doc.Find("title").Text()
+		result.Description = findMetaDescription(doc)
+		texts := documentText(doc)
+		words := textWords(texts)
+		result.ContentFingerprint = contentFingerprint(words)
+		result.WordCount = len(words)
+		result.Placeholders = findPlaceholders(texts)
+		result.Classification = classifyPage(result.Title, texts)
+		result.Noindex = findMetaNoindex(doc)
+		resolveBase := documentBaseURL(ctx, logger, doc, baseURL)
+		result.Pagination = findPagination(doc, resolveBase, opts.Normalize)
+		result.Noscript = summarizeNoscript(findNoscript(doc), resolveBase, opts.Normalize)
+		result.RichResults = richResults(findStructuredData(doc))
+		result.CustomElements = findCustomElements(doc)
+		return nil
+	})
+
+	// Spellcheck
+	if spellcheckEnabled() {
+		check(CheckSpelling, func() error {
+			result.Spelling = checkSpelling(doc.Find("html").AttrOr("lang", ""), documentText(doc))
+			return nil
+		})
+	}
+
+	// Heading Counts
+	check(CheckHeadings, func() (err error) {
+		result.Headings, err = countHeadings(ctx, logger, doc)
+		return err
+	})
+
+	// Link Extraction
+	check(CheckLinks, func() (err error) {
+		linkAnalysis, err = extractLinks(ctx, logger, doc, baseURL, opts.Normalize)
+		return err
+	})
+	check(CheckCSSResources, func() (err error) {
+		cssResources, err = extractCSSResources(ctx, logger, doc, baseURL, opts.Normalize)
+		return err
+	})
+
+	// Media Inventory
+	check(CheckMedia, func() (err error) {
+		result.Media, err = extractMedia(ctx, logger, doc, baseURL, opts.Normalize)
+		return err
+	})
+
+	// Login Form Detection
+	check(CheckLoginForm, func() (err error) {
+		result.ContainsLoginForm, err = detectLoginForm(ctx, logger, doc)
+		return err
+	})
+
+	_ = g.Wait()
+
+	linkAnalysis.CSSResources = cssResources
+	if result.Media != nil {
+		linkAnalysis.MediaSources = result.Media.Sources
+	}
+	return linkAnalysis
+}
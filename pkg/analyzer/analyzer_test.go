@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalyzePage_RevalidatesUnmodifiedPage(t *testing.T) {
+	var fullResponses int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fullResponses, 1)
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "<html><head><title>Cached</title></head><body></body></html>")
+	}))
+	defer server.Close()
+
+	first, err := AnalyzePage(context.Background(), testLogger, server.URL, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if first.ETag != `"v1"` {
+		t.Fatalf("Expected the ETag to be recorded, but got %q", first.ETag)
+	}
+
+	opts := DefaultOptions()
+	opts.Revalidate = first
+	second, err := AnalyzePage(context.Background(), testLogger, server.URL, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if second.Title != "Cached" {
+		t.Errorf("Expected the previous title to be reused, but got %q", second.Title)
+	}
+	if second == first {
+		t.Error("Expected a copy of the previous result, but got the same pointer")
+	}
+	if got := atomic.LoadInt32(&fullResponses); got != 1 {
+		t.Errorf("Expected the page body to be served once, but got %d", got)
+	}
+}
+
+func TestAnalyzePage_PageTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>"+strings.Repeat("<p>filler</p>", 100)+"</body></html>")
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.MaxPageBytes = 256
+
+	if _, err := AnalyzePage(context.Background(), testLogger, server.URL, opts); !errors.Is(err, ErrPageTooLarge) {
+		t.Errorf("Expected ErrPageTooLarge, but got: %v", err)
+	}
+}
+
+func TestAnalyzePage_AllChecks(t *testing.T) {
+	const page = `<!DOCTYPE html><html><head><title>All checks</title>
+		<style>body { background: url("/bg.png"); }</style></head><body>
+		<h1>One</h1><h2>Two</h2><h2>Three</h2>
+		<a href="/about">About</a>
+		<a href="#missing">Missing</a>
+		<form><input type="text" name="user"><input type="password" name="pass"></form>
+	</body></html>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema version %q, but got %q", SchemaVersion, result.SchemaVersion)
+	}
+	if len(result.ID) != 32 {
+		t.Errorf("Expected a 32-character hex ID, but got %q", result.ID)
+	}
+	if result.HTMLVersion != "HTML5" {
+		t.Errorf("Expected HTML5, but got %q", result.HTMLVersion)
+	}
+	if result.Title != "All checks" {
+		t.Errorf("Expected title %q, but got %q", "All checks", result.Title)
+	}
+	if result.Headings["h1"] != 1 || result.Headings["h2"] != 2 {
+		t.Errorf("Expected 1 h1 and 2 h2 headings, but got %v", result.Headings)
+	}
+	if result.Links.InternalCount != 1 || result.Links.BrokenAnchorCount != 1 || result.Links.CSSResourceCount != 1 {
+		t.Errorf("Expected 1 internal link, 1 broken anchor and 1 CSS resource, but got %+v", result.Links)
+	}
+	if !result.ContainsLoginForm {
+		t.Error("Expected a login form to be detected")
+	}
+	if result.WordCount != 5 || result.PageBytes != int64(len(page)) {
+		t.Errorf("Expected 5 words and %d bytes, but got %d and %d", len(page), result.WordCount, result.PageBytes)
+	}
+
+	expectedLink := LinkResult{URL: server.URL + "/about", Type: LinkTypeInternal, Kind: LinkKindLink, AnchorText: "About", Status: LinkStatusOK, StatusCode: http.StatusOK}
+	if len(result.LinkResults) != 2 || !reflect.DeepEqual(result.LinkResults[0], expectedLink) {
+		t.Errorf("Expected link results starting with %+v, but got %+v", expectedLink, result.LinkResults)
+	}
+}
+
+func TestAnalyzePage_SelectedChecks(t *testing.T) {
+	var linkChecks int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			atomic.AddInt32(&linkChecks, 1)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Selected</title></head><body>
+			<h1>One</h1>
+			<a href="/about">About</a>
+			<form><input type="password" name="pass"></form>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Checks = []string{CheckHeadings, CheckLinks}
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result.Title != "Selected" || result.Headings["h1"] != 1 || result.Links.InternalCount != 1 {
+		t.Errorf("Expected the title and the selected checks to complete, but got %+v", result)
+	}
+	if result.HTMLVersion != "" || result.ContainsLoginForm || len(result.SecurityFindings) > 0 {
+		t.Errorf("Expected the other checks to be skipped, but got %+v", result)
+	}
+	if result.Score != 0 || result.Grade != "" {
+		t.Errorf("Expected no score for a partial analysis, but got %d %q", result.Score, result.Grade)
+	}
+	if got := atomic.LoadInt32(&linkChecks); got != 0 {
+		t.Errorf("Expected no link checks without %q, but got %d", CheckLinkStatus, got)
+	}
+}
+
+func TestAnalyzePage_RecordsPartialErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Partial</title></head><body>
+			<h1>One</h1>
+			<a href="/about">About</a>
+			<a href="http://[::1">Malformed</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if !strings.Contains(result.Errors[CheckLinks], "http://[::1") {
+		t.Errorf("Expected a %q error naming the malformed link, but got %v", CheckLinks, result.Errors)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected only the %q check to fail, but got %v", CheckLinks, result.Errors)
+	}
+	if result.Title != "Partial" || result.Headings["h1"] != 1 || result.Links.InternalCount != 1 {
+		t.Errorf("Expected the other checks to complete, but got %+v", result)
+	}
+}
+
+func TestAnalyzePage_CanceledDuringLinkChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, "<html><body>")
+			for i := 0; i < 50; i++ {
+				fmt.Fprintf(w, `<a href="/slow/%d">slow</a>`, i)
+			}
+			fmt.Fprint(w, "</body></html>")
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	opts := DefaultOptions()
+	opts.Workers = 2
+
+	start := time.Now()
+	_, err := AnalyzePage(ctx, testLogger, server.URL+"/", opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the analysis to stop promptly after cancellation, but took %v", elapsed)
+	}
+}
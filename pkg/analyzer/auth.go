@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// BasicAuth holds HTTP basic authentication credentials for the analyzed page.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// authSession carries the credentials of one authenticated analysis. Basic auth is only
+// sent to the analyzed page's own host; cookies live in a per-analysis jar, so they go
+// wherever the cookie rules allow (by default only that host, never external links) and
+// pick up any cookies the site sets along the way. A nil session means an anonymous analysis.
+type authSession struct {
+	host      string
+	basicAuth *BasicAuth
+
+	page *http.Client
+	link *http.Client
+}
+
+type authSessionContextKey struct{}
+
+// newAuthSession returns the session for analyzing pageURL with opts, or nil when opts carry
+// no credentials.
+func newAuthSession(pageURL string, opts Options) (*authSession, error) {
+	if opts.BasicAuth == nil && opts.Cookie == "" && len(opts.Cookies) == 0 {
+		return nil, nil
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	var cookies []*http.Cookie
+	if opts.Cookie != "" {
+		if cookies, err = http.ParseCookie(opts.Cookie); err != nil {
+			return nil, fmt.Errorf("invalid cookie header: %w", err)
+		}
+	}
+	for _, cookie := range opts.Cookies {
+		if err := cookie.Valid(); err != nil {
+			return nil, fmt.Errorf("invalid cookie: %w", err)
+		}
+		// Copy so the caller's cookie is not modified below.
+		cookie := *cookie
+		cookies = append(cookies, &cookie)
+	}
+	for _, cookie := range cookies {
+		// Without an explicit path the jar would scope the cookie to the page's directory.
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+	}
+	jar.SetCookies(u, cookies)
+
+	return &authSession{
+		host:      canonicalHost(u),
+		basicAuth: opts.BasicAuth,
+		page:      &http.Client{Transport: pageClient.Transport, Timeout: pageClient.Timeout, CheckRedirect: pageClient.CheckRedirect, Jar: jar},
+		link:      &http.Client{Transport: client.Transport, Timeout: client.Timeout, CheckRedirect: client.CheckRedirect, Jar: jar},
+	}, nil
+}
+
+func withAuthSession(ctx context.Context, session *authSession) context.Context {
+	return context.WithValue(ctx, authSessionContextKey{}, session)
+}
+
+func authSessionFrom(ctx context.Context) *authSession {
+	session, _ := ctx.Value(authSessionContextKey{}).(*authSession)
+	return session
+}
+
+// pageClient returns the client for fetching the analyzed page.
+func (s *authSession) pageClient() *http.Client {
+	if s == nil {
+		return pageClient
+	}
+	return s.page
+}
+
+// linkClient returns the client for link checks.
+func (s *authSession) linkClient() *http.Client {
+	if s == nil {
+		return client
+	}
+	return s.link
+}
+
+// authorize adds basic auth to req if it targets the analyzed page's host.
+func (s *authSession) authorize(req *http.Request) {
+	if s == nil || s.basicAuth == nil || canonicalHost(req.URL) != s.host {
+		return
+	}
+	req.SetBasicAuth(s.basicAuth.Username, s.basicAuth.Password)
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAnalyzePage_Authenticated(t *testing.T) {
+	var leaked int32
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+			atomic.AddInt32(&leaked, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+	externalURL := strings.Replace(external.URL, "127.0.0.1", "localhost", 1) + "/"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if consent, err := r.Cookie("consent"); err != nil || consent.Value != "yes" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			fmt.Fprintf(w, `<html><head><title>Dashboard</title></head><body>
+				<a href="/private">private</a>
+				<a href="%s">external</a>
+			</body></html>`, externalURL)
+		case "/private":
+			if session, err := r.Cookie("session"); err != nil || session.Value != "abc" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry.MaxRetries = 0
+	opts.BasicAuth = &BasicAuth{Username: "alice", Password: "secret"}
+	opts.Cookie = "consent=yes"
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result.Title != "Dashboard" {
+		t.Errorf("Expected the authenticated page to be analyzed, but got title %q", result.Title)
+	}
+	if result.Links.InaccessibleCount != 0 {
+		t.Errorf("Expected the private link to be checked with the session, but got %d inaccessible links", result.Links.InaccessibleCount)
+	}
+	if n := atomic.LoadInt32(&leaked); n != 0 {
+		t.Errorf("Expected no credentials to reach the external host, but got %d requests with them", n)
+	}
+}
+
+func TestAnalyzePage_Unauthenticated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry.MaxRetries = 0
+
+	if _, err := AnalyzePage(context.Background(), testLogger, server.URL, opts); err == nil {
+		t.Error("Expected an error for a page behind auth without credentials, but got nil")
+	}
+}
+
+func TestNewAuthSession_InvalidCookie(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Cookie = "not a cookie"
+
+	if _, err := newAuthSession("https://example.com/", opts); err == nil {
+		t.Error("Expected an error for an invalid cookie header, but got nil")
+	}
+}
+
+func TestAnalyzePage_NamedCookies(t *testing.T) {
+	var externalCookies int32
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.Cookies()) > 0 {
+			atomic.AddInt32(&externalCookies, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+	externalURL := strings.Replace(external.URL, "127.0.0.1", "localhost", 1) + "/"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title := "Variant A"
+		if bucket, err := r.Cookie("ab_bucket"); err == nil && bucket.Value == "B" {
+			title = "Variant B"
+		}
+		fmt.Fprintf(w, `<html><head><title>%s</title></head><body><a href="%s">external</a></body></html>`, title, externalURL)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Cookies = []*http.Cookie{{Name: "ab_bucket", Value: "B"}}
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/landing/page", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result.Title != "Variant B" {
+		t.Errorf("Expected the cookie to select variant B, but got title %q", result.Title)
+	}
+	if n := atomic.LoadInt32(&externalCookies); n != 0 {
+		t.Errorf("Expected no cookies on external link checks, but got %d requests with cookies", n)
+	}
+	if opts.Cookies[0].Path != "" {
+		t.Error("Expected the caller's cookie to be left untouched")
+	}
+}
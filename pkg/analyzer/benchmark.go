@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Benchmark compares a page with its competitors on the measures of an SEO benchmark.
+type Benchmark struct {
+	XMLName xml.Name `json:"-" xml:"benchmark"`
+	// Pages lists the primary page first, then the competitors in the order given.
+	Pages []BenchmarkPage `json:"pages" xml:"page"`
+	// Ranks places the primary page among the pages analyzed; nil if it could not be analyzed.
+	Ranks *BenchmarkRanks `json:"ranks,omitempty" xml:"ranks,omitempty"`
+}
+
+// BenchmarkPage is the row of one page in a Benchmark.
+type BenchmarkPage struct {
+	URL     string `json:"url" xml:"url,attr"`
+	Primary bool   `json:"primary,omitempty" xml:"primary,attr,omitempty"`
+	// ResultID identifies the page's analysis; empty if it failed.
+	ResultID string `json:"result_id,omitempty" xml:"result_id,attr,omitempty"`
+	// Error is why the page could not be analyzed, in which case its measures are zero.
+	Error     string `json:"error,omitempty" xml:"error,omitempty"`
+	Score     int    `json:"score" xml:"score"`
+	Grade     string `json:"grade" xml:"grade"`
+	WordCount int    `json:"word_count" xml:"word_count"`
+	// Headings counts the page's headings by level, its heading structure.
+	Headings    HeadingCounts `json:"headings" xml:"headings"`
+	PageBytes   int64         `json:"page_bytes" xml:"page_bytes"`
+	BrokenLinks int           `json:"broken_links" xml:"broken_links"`
+	// BrokenAnchors counts the in-page links to anchors the page does not have.
+	BrokenAnchors int `json:"broken_anchors" xml:"broken_anchors"`
+}
+
+// HeadingCounts counts headings by level.
+type HeadingCounts struct {
+	H1 int `json:"h1" xml:"h1,attr"`
+	H2 int `json:"h2" xml:"h2,attr"`
+	H3 int `json:"h3" xml:"h3,attr"`
+	H4 int `json:"h4" xml:"h4,attr"`
+	H5 int `json:"h5" xml:"h5,attr"`
+	H6 int `json:"h6" xml:"h6,attr"`
+}
+
+// BenchmarkRanks is the rank of the primary page among the pages analyzed for each measure,
+// 1 being the best. Pages that measure the same share a rank.
+type BenchmarkRanks struct {
+	// Of is the number of pages ranked.
+	Of    int `json:"of" xml:"of,attr"`
+	Score int `json:"score" xml:"score,attr"`
+	// WordCount ranks the page with the most words first.
+	WordCount int `json:"word_count" xml:"word_count,attr"`
+	// PageBytes ranks the lightest page first.
+	PageBytes int `json:"page_bytes" xml:"page_bytes,attr"`
+	// BrokenLinks ranks the page with the fewest broken links first.
+	BrokenLinks int `json:"broken_links" xml:"broken_links,attr"`
+}
+
+// NewBenchmarkPage returns the row of the page at pageURL with the analysis result.
+func NewBenchmarkPage(pageURL string, result *AnalysisResult) BenchmarkPage {
+	return BenchmarkPage{
+		URL:       pageURL,
+		ResultID:  result.ID,
+		Score:     result.Score,
+		Grade:     result.Grade,
+		WordCount: result.WordCount,
+		Headings: HeadingCounts{
+			H1: result.Headings["h1"], H2: result.Headings["h2"], H3: result.Headings["h3"],
+			H4: result.Headings["h4"], H5: result.Headings["h5"], H6: result.Headings["h6"],
+		},
+		PageBytes:     result.PageBytes,
+		BrokenLinks:   result.Links.InaccessibleCount,
+		BrokenAnchors: result.Links.BrokenAnchorCount,
+	}
+}
+
+// NewBenchmark compares primary with competitors. Pages with an Error are listed but not
+// ranked.
+func NewBenchmark(primary BenchmarkPage, competitors []BenchmarkPage) *Benchmark {
+	primary.Primary = true
+	b := &Benchmark{Pages: append([]BenchmarkPage{primary}, competitors...)}
+	if primary.Error != "" {
+		return b
+	}
+
+	var analyzed []BenchmarkPage
+	for _, page := range b.Pages {
+		if page.Error == "" {
+			analyzed = append(analyzed, page)
+		}
+	}
+	// rank counts the pages that beat the primary page on the measure.
+	rank := func(better func(page BenchmarkPage) bool) int {
+		r := 1
+		for _, page := range analyzed {
+			if better(page) {
+				r++
+			}
+		}
+		return r
+	}
+	b.Ranks = &BenchmarkRanks{
+		Of:          len(analyzed),
+		Score:       rank(func(p BenchmarkPage) bool { return p.Score > primary.Score }),
+		WordCount:   rank(func(p BenchmarkPage) bool { return p.WordCount > primary.WordCount }),
+		PageBytes:   rank(func(p BenchmarkPage) bool { return p.PageBytes < primary.PageBytes }),
+		BrokenLinks: rank(func(p BenchmarkPage) bool { return p.BrokenLinks < primary.BrokenLinks }),
+	}
+	return b
+}
+
+// benchmarkCSVHeader is the header row written by WriteBenchmarkCSV.
+var benchmarkCSVHeader = []string{"url", "primary", "score", "grade", "word_count", "h1", "h2", "h3", "h4", "h5", "h6", "page_bytes", "broken_links", "broken_anchors", "error"}
+
+// WriteBenchmarkCSV writes b to w as CSV, one row per page, for importing into spreadsheets.
+func WriteBenchmarkCSV(w io.Writer, b *Benchmark) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(benchmarkCSVHeader); err != nil {
+		return err
+	}
+
+	for _, page := range b.Pages {
+		// A failed page leaves its measures empty rather than zero.
+		record := make([]string, len(benchmarkCSVHeader))
+		record[0], record[1] = csvSafe(page.URL), strconv.FormatBool(page.Primary)
+		if page.Error != "" {
+			record[len(record)-1] = csvSafe(page.Error)
+		} else {
+			h := page.Headings
+			record[2], record[3] = strconv.Itoa(page.Score), page.Grade
+			for i, n := range []int{page.WordCount, h.H1, h.H2, h.H3, h.H4, h.H5, h.H6} {
+				record[4+i] = strconv.Itoa(n)
+			}
+			record[11] = strconv.FormatInt(page.PageBytes, 10)
+			record[12], record[13] = strconv.Itoa(page.BrokenLinks), strconv.Itoa(page.BrokenAnchors)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
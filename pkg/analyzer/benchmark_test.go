@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestNewBenchmark(t *testing.T) {
+	primary := NewBenchmarkPage("https://shop.example/", &AnalysisResult{
+		ID:        "mine",
+		Score:     80,
+		Grade:     "B",
+		WordCount: 500,
+		Headings:  map[string]int{"h1": 1, "h2": 4},
+		PageBytes: 30000,
+		Links:     LinkSummary{InaccessibleCount: 2, BrokenAnchorCount: 1},
+	})
+	competitors := []BenchmarkPage{
+		NewBenchmarkPage("https://rival.example/", &AnalysisResult{ID: "rival", Score: 90, Grade: "A", WordCount: 800, PageBytes: 30000}),
+		NewBenchmarkPage("https://other.example/", &AnalysisResult{ID: "other", Score: 80, Grade: "B", WordCount: 200, PageBytes: 90000, Links: LinkSummary{InaccessibleCount: 5}}),
+		{URL: "https://down.example/", Error: "The page took too long to respond. Try again later."},
+	}
+
+	b := NewBenchmark(primary, competitors)
+	want := BenchmarkPage{
+		URL: "https://shop.example/", Primary: true, ResultID: "mine", Score: 80, Grade: "B", WordCount: 500,
+		Headings: HeadingCounts{H1: 1, H2: 4}, PageBytes: 30000, BrokenLinks: 2, BrokenAnchors: 1,
+	}
+	if len(b.Pages) != 4 || !reflect.DeepEqual(b.Pages[0], want) || b.Pages[1].Primary {
+		t.Errorf("Expected the primary page %+v first, but got %+v", want, b.Pages)
+	}
+	wantRanks := &BenchmarkRanks{Of: 3, Score: 2, WordCount: 2, PageBytes: 1, BrokenLinks: 2}
+	if !reflect.DeepEqual(b.Ranks, wantRanks) {
+		t.Errorf("Expected ranks %+v, but got %+v", wantRanks, b.Ranks)
+	}
+
+	failed := NewBenchmark(BenchmarkPage{URL: "https://shop.example/", Error: "failed"}, competitors)
+	if failed.Ranks != nil {
+		t.Errorf("Expected no ranks without the primary page, but got %+v", failed.Ranks)
+	}
+}
+
+func TestWriteBenchmarkCSV(t *testing.T) {
+	b := NewBenchmark(
+		BenchmarkPage{URL: "https://shop.example/", Score: 80, Grade: "B", WordCount: 500, Headings: HeadingCounts{H1: 1, H2: 4}, PageBytes: 30000, BrokenLinks: 2},
+		[]BenchmarkPage{{URL: "https://down.example/", Error: "-timeout"}},
+	)
+
+	var buf bytes.Buffer
+	if err := WriteBenchmarkCSV(&buf, b); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, but got: %v", err)
+	}
+
+	expected := [][]string{
+		{"url", "primary", "score", "grade", "word_count", "h1", "h2", "h3", "h4", "h5", "h6", "page_bytes", "broken_links", "broken_anchors", "error"},
+		{"https://shop.example/", "true", "80", "B", "500", "1", "4", "0", "0", "0", "0", "30000", "2", "0", ""},
+		{"https://down.example/", "false", "", "", "", "", "", "", "", "", "", "", "", "", "'-timeout"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, but got %q", expected, records)
+	}
+}
@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxSafeBrowsingEntries is the most URLs the Safe Browsing Lookup API takes per request.
+	maxSafeBrowsingEntries = 500
+	// maxSafeBrowsingResponseBytes caps how much of a Safe Browsing answer is read.
+	maxSafeBrowsingResponseBytes = 4 << 20
+)
+
+// safeBrowsingURL is the endpoint of the Safe Browsing Lookup API (v4).
+var safeBrowsingURL = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// safeBrowsingClient queries Safe Browsing. Like the markup validator it is a service the server
+// is configured with, so it does not go through the analyses' transport.
+var safeBrowsingClient = &http.Client{Timeout: 10 * time.Second}
+
+// safeBrowsingThreatTypes are the threats URLs are looked up for.
+var safeBrowsingThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+
+// Blocklist is a list of hosts and URLs known to be malicious.
+type Blocklist struct {
+	// domains holds the listed hosts, which also cover their subdomains, and urls the listed
+	// URLs without their fragment.
+	domains map[string]bool
+	urls    map[string]bool
+}
+
+// ReadBlocklist reads a blocklist with one entry per line: a host such as "evil.example", which
+// also blocks its subdomains, or an http or https URL, which blocks that URL only. Lines of a
+// hosts file, such as "0.0.0.0 evil.example", block their hosts. Blank lines and lines starting
+// with "#" are skipped, as are the hosts a hosts file maps for itself, such as "localhost".
+func ReadBlocklist(r io.Reader) (*Blocklist, error) {
+	b := &Blocklist{domains: make(map[string]bool), urls: make(map[string]bool)}
+	if err := b.read(r); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Blocklist) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := netip.ParseAddr(fields[0]); err == nil && len(fields) > 1 {
+			for _, host := range fields[1:] {
+				if host != "localhost" && !strings.HasPrefix(host, "localhost.") && !strings.HasPrefix(host, "ip6-") && host != "broadcasthost" {
+					b.domains[blocklistHost(host)] = true
+				}
+			}
+			continue
+		}
+		entry := fields[0]
+		if lower := strings.ToLower(entry); strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+			u, err := url.Parse(entry)
+			if err != nil || u.Host == "" {
+				return fmt.Errorf("line %d: invalid URL %q", line, entry)
+			}
+			b.urls[blocklistURL(u)] = true
+			continue
+		}
+		if strings.ContainsAny(entry, "/:") {
+			return fmt.Errorf("line %d: expected a host or an http or https URL, but got %q", line, entry)
+		}
+		b.domains[blocklistHost(entry)] = true
+	}
+	return scanner.Err()
+}
+
+// LoadBlocklist reads the blocklists at paths into one.
+func LoadBlocklist(paths ...string) (*Blocklist, error) {
+	b := &Blocklist{domains: make(map[string]bool), urls: make(map[string]bool)}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = b.read(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return b, nil
+}
+
+// Len returns the number of hosts and URLs on the blocklist.
+func (b *Blocklist) Len() int {
+	return len(b.domains) + len(b.urls)
+}
+
+// Contains reports whether u, or its host or a parent domain of it, is on the blocklist.
+func (b *Blocklist) Contains(u *url.URL) bool {
+	if b.urls[blocklistURL(u)] {
+		return true
+	}
+	for host := blocklistHost(u.Hostname()); host != ""; {
+		if b.domains[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return false
+}
+
+// blocklistHost returns host as it is listed, in lower case without a trailing dot.
+func blocklistHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// blocklistURL returns u as it is listed, with a lower-case scheme and host and without its
+// fragment.
+func blocklistURL(u *url.URL) string {
+	listed := *u
+	listed.Scheme = strings.ToLower(listed.Scheme)
+	listed.Host = blocklistHost(listed.Host)
+	listed.Fragment, listed.RawFragment = "", ""
+	return listed.String()
+}
+
+// blocklist and safeBrowsingKey are set with SetBlocklist and SetSafeBrowsing.
+var (
+	blocklist       atomic.Pointer[Blocklist]
+	safeBrowsingKey atomic.Pointer[string]
+)
+
+// SetBlocklist sets the local blocklist the analyzed URLs and their external links are checked
+// against. Nil disables it.
+func SetBlocklist(b *Blocklist) {
+	blocklist.Store(b)
+}
+
+// SetSafeBrowsing enables the lookup of the analyzed URLs and their external links with the
+// Google Safe Browsing Lookup API, using apiKey. An empty key disables it.
+func SetSafeBrowsing(apiKey string) {
+	if apiKey == "" {
+		safeBrowsingKey.Store(nil)
+		return
+	}
+	safeBrowsingKey.Store(&apiKey)
+}
+
+// blocklistEnabled reports whether a blocklist or Safe Browsing is set.
+func blocklistEnabled() bool {
+	return blocklist.Load() != nil || safeBrowsingKey.Load() != nil
+}
+
+// blocklistFindings checks the page at pageURL and the external links and iframes of links
+// against the blocklist and Safe Browsing, returning a RuleBlocklistedURL finding for each URL
+// flagged. A URL on the blocklist is not looked up with Safe Browsing. The findings of the
+// blocklist are returned even if Safe Browsing fails.
+func blocklistFindings(ctx context.Context, pageURL *url.URL, links LinkAnalysis) (_ []SecurityFinding, err error) {
+	ctx, span := tracer.Start(ctx, "blocklistFindings")
+	defer func() { endSpan(span, err) }()
+
+	urls := []string{pageURL.String()}
+	seen := map[string]bool{pageURL.String(): true}
+	for _, list := range [][]string{links.ExternalLinks, links.ExternalIframes} {
+		for _, link := range list {
+			if !seen[link] {
+				seen[link] = true
+				urls = append(urls, link)
+			}
+		}
+	}
+
+	var findings []SecurityFinding
+	add := func(link, message string) {
+		subject := "Link target"
+		if link == pageURL.String() {
+			subject = "Page"
+		}
+		findings = append(findings, SecurityFinding{
+			RuleID:   RuleBlocklistedURL,
+			Severity: securityRuleByID(RuleBlocklistedURL).Severity,
+			Message:  subject + " " + message,
+			URL:      link,
+		})
+	}
+
+	var unlisted []string
+	if b := blocklist.Load(); b != nil {
+		for _, link := range urls {
+			if u, err := url.Parse(link); err == nil && b.Contains(u) {
+				add(link, "is on the blocklist")
+			} else {
+				unlisted = append(unlisted, link)
+			}
+		}
+	} else {
+		unlisted = urls
+	}
+
+	if key := safeBrowsingKey.Load(); key != nil {
+		threats, err := lookupSafeBrowsing(ctx, *key, unlisted)
+		for _, link := range unlisted {
+			if threat, ok := threats[link]; ok {
+				add(link, "is flagged by Google Safe Browsing as "+strings.ToLower(strings.ReplaceAll(threat, "_", " ")))
+			}
+		}
+		if err != nil {
+			return findings, err
+		}
+	}
+	return findings, nil
+}
+
+// safeBrowsingRequest is the body of a Safe Browsing threatMatches:find request.
+type safeBrowsingRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string            `json:"threatTypes"`
+		PlatformTypes    []string            `json:"platformTypes"`
+		ThreatEntryTypes []string            `json:"threatEntryTypes"`
+		ThreatEntries    []map[string]string `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+// lookupSafeBrowsing looks urls up with Safe Browsing, returning the threat type of each one
+// flagged. It returns the threats found so far along with the error of a failed request.
+func lookupSafeBrowsing(ctx context.Context, apiKey string, urls []string) (map[string]string, error) {
+	threats := make(map[string]string)
+	for start := 0; start < len(urls); start += maxSafeBrowsingEntries {
+		var body safeBrowsingRequest
+		body.Client.ClientID = "web-analyzer"
+		body.Client.ClientVersion = SchemaVersion
+		body.ThreatInfo.ThreatTypes = safeBrowsingThreatTypes
+		body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+		body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+		for _, link := range urls[start:min(start+maxSafeBrowsingEntries, len(urls))] {
+			body.ThreatInfo.ThreatEntries = append(body.ThreatInfo.ThreatEntries, map[string]string{"url": link})
+		}
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return threats, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, safeBrowsingURL+"?key="+url.QueryEscape(apiKey), bytes.NewReader(encoded))
+		if err != nil {
+			return threats, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", DefaultUserAgent)
+
+		resp, err := safeBrowsingClient.Do(req)
+		if err != nil {
+			// The error would include the request URL, and with it the API key.
+			if urlErr := (*url.Error)(nil); errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return threats, fmt.Errorf("Safe Browsing unreachable: %w", err)
+		}
+		var answer struct {
+			Matches []struct {
+				ThreatType string `json:"threatType"`
+				Threat     struct {
+					URL string `json:"url"`
+				} `json:"threat"`
+			} `json:"matches"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return threats, fmt.Errorf("Safe Browsing answered %s", resp.Status)
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxSafeBrowsingResponseBytes)).Decode(&answer)
+		resp.Body.Close()
+		if err != nil {
+			return threats, fmt.Errorf("invalid Safe Browsing response: %w", err)
+		}
+		for _, match := range answer.Matches {
+			if _, ok := threats[match.Threat.URL]; !ok {
+				threats[match.Threat.URL] = match.ThreatType
+			}
+		}
+	}
+	return threats, nil
+}
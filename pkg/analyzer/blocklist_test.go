@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlocklist_Contains(t *testing.T) {
+	b, err := ReadBlocklist(strings.NewReader(`# Local blocklist
+evil.example
+Phish.Example.   # trailing dot and capitals
+https://cdn.example/payload.js
+0.0.0.0 tracker.example ads.example
+127.0.0.1 localhost
+::1 ip6-localhost
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if b.Len() != 5 {
+		t.Errorf("Expected 5 entries, but got %d", b.Len())
+	}
+
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://evil.example/", true},
+		{"http://login.evil.example/account", true},
+		{"https://phish.example/x", true},
+		{"https://notevil.example/", false},
+		{"https://evil.example.org/", false},
+		{"https://CDN.example/payload.js#top", true},
+		{"https://cdn.example/other.js", false},
+		{"https://ads.example/banner", true},
+		{"http://localhost/", false},
+	}
+
+	for _, tc := range testCases {
+		u, _ := url.Parse(tc.url)
+		if got := b.Contains(u); got != tc.expected {
+			t.Errorf("Expected Contains(%s) to be %v, but got %v", tc.url, tc.expected, got)
+		}
+	}
+}
+
+func TestReadBlocklist_Invalid(t *testing.T) {
+	for _, data := range []string{"ftp://files.example/x\n", "evil.example/path\n", "https:///nohost\n"} {
+		if _, err := ReadBlocklist(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected an error for %q, but got none", data)
+		}
+	}
+}
+
+func TestBlocklistFindings(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body safeBrowsingRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, entry := range body.ThreatInfo.ThreatEntries {
+			requested = append(requested, entry["url"])
+		}
+		fmt.Fprint(w, `{"matches": [{"threatType": "SOCIAL_ENGINEERING", "platformType": "ANY_PLATFORM", "threat": {"url": "https://phish.example/login"}}]}`)
+	}))
+	defer server.Close()
+
+	previousURL := safeBrowsingURL
+	safeBrowsingURL = server.URL
+	defer func() { safeBrowsingURL = previousURL }()
+	b, _ := ReadBlocklist(strings.NewReader("evil.example\n"))
+	SetBlocklist(b)
+	defer SetBlocklist(nil)
+	SetSafeBrowsing("test-key")
+	defer SetSafeBrowsing("")
+
+	pageURL, _ := url.Parse("https://example.com/")
+	links := LinkAnalysis{
+		InternalLinks:   []string{"https://example.com/about"},
+		ExternalLinks:   []string{"https://evil.example/x", "https://phish.example/login", "https://evil.example/x"},
+		ExternalIframes: []string{"https://video.example/embed"},
+	}
+	findings, err := blocklistFindings(context.Background(), pageURL, links)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := []SecurityFinding{
+		{RuleID: RuleBlocklistedURL, Severity: SeverityError, Message: "Link target is on the blocklist", URL: "https://evil.example/x"},
+		{RuleID: RuleBlocklistedURL, Severity: SeverityError, Message: "Link target is flagged by Google Safe Browsing as social engineering", URL: "https://phish.example/login"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Expected %+v, but got %+v", want, findings)
+	}
+	if wantRequested := []string{"https://example.com/", "https://phish.example/login", "https://video.example/embed"}; !reflect.DeepEqual(requested, wantRequested) {
+		t.Errorf("Expected Safe Browsing to be asked about %v, but got %v", wantRequested, requested)
+	}
+
+	SetSafeBrowsing("wrong-key")
+	findings, err = blocklistFindings(context.Background(), pageURL, links)
+	if err == nil || strings.Contains(err.Error(), "wrong-key") {
+		t.Errorf("Expected an error without the API key, but got: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Errorf("Expected the blocklist findings despite the error, but got %+v", findings)
+	}
+}
+
+func TestAnalyzePage_Blocklisted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html><body><a href="https://evil.example/">Prize</a></body></html>`)
+	}))
+	defer server.Close()
+
+	b, _ := ReadBlocklist(strings.NewReader("evil.example\n"))
+	SetBlocklist(b)
+	defer SetBlocklist(nil)
+
+	opts := DefaultOptions()
+	opts.Checks = []string{CheckLinks, CheckBlocklist}
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := []SecurityFinding{{RuleID: RuleBlocklistedURL, Severity: SeverityError, Message: "Link target is on the blocklist", URL: "https://evil.example/"}}
+	if !reflect.DeepEqual(result.SecurityFindings, want) {
+		t.Errorf("Expected %+v, but got %+v", want, result.SecurityFindings)
+	}
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import "sync"
+
+// hostBreaker is a per-host circuit breaker for one link validation run. Once a host has
+// failed threshold link checks in a row, the circuit opens and stays open, so the remaining
+// links to that host are skipped instead of each burning through the full retry budget.
+type hostBreaker struct {
+	threshold int
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func newHostBreaker(threshold int) *hostBreaker {
+	return &hostBreaker{
+		threshold: threshold,
+		failures:  make(map[string]int),
+	}
+}
+
+// isOpen reports whether checks against host should be skipped.
+// A non-positive threshold disables the breaker.
+func (b *hostBreaker) isOpen(host string) bool {
+	if b.threshold <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures[host] >= b.threshold
+}
+
+// recordFailure counts a host-level failure (connection error or 5xx) against host.
+func (b *hostBreaker) recordFailure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[host]++
+}
+
+// recordSuccess resets the consecutive failure count of host unless its circuit is already open.
+func (b *hostBreaker) recordSuccess(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures[host] < b.threshold {
+		b.failures[host] = 0
+	}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostBreaker(t *testing.T) {
+	breaker := newHostBreaker(2)
+
+	breaker.recordFailure("a.example")
+	if breaker.isOpen("a.example") {
+		t.Fatal("Expected the circuit to stay closed below the threshold")
+	}
+
+	breaker.recordSuccess("a.example")
+	breaker.recordFailure("a.example")
+	if breaker.isOpen("a.example") {
+		t.Fatal("Expected a success to reset the consecutive failure count")
+	}
+
+	breaker.recordFailure("a.example")
+	if !breaker.isOpen("a.example") {
+		t.Fatal("Expected the circuit to open at the threshold")
+	}
+
+	breaker.recordSuccess("a.example")
+	if !breaker.isOpen("a.example") {
+		t.Error("Expected an open circuit to stay open for the rest of the run")
+	}
+	if breaker.isOpen("b.example") {
+		t.Error("Expected other hosts to be unaffected")
+	}
+}
+
+func TestHostBreaker_Disabled(t *testing.T) {
+	breaker := newHostBreaker(0)
+	for i := 0; i < 10; i++ {
+		breaker.recordFailure("a.example")
+	}
+	if breaker.isOpen("a.example") {
+		t.Error("Expected a zero threshold to disable the breaker")
+	}
+}
+
+func TestLinkAccessibilityChecker_CircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := server.URL
+	server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}
+	opts.CircuitBreakerThreshold = 2
+	state := newLinkCheckState(opts)
+
+	inaccessibleLinks := make(chan string, 5)
+	for _, path := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		linkAccessibilityChecker(context.Background(), testLogger, state, deadURL+path, inaccessibleLinks)
+	}
+	close(inaccessibleLinks)
+
+	var failed []string
+	for link := range inaccessibleLinks {
+		failed = append(failed, link)
+	}
+
+	if len(failed) != 2 {
+		t.Errorf("Expected 2 links to be checked and fail, but got %v", failed)
+	}
+	if skipped := state.notChecked[notCheckedCircuitOpen]; len(skipped) != 3 {
+		t.Errorf("Expected 3 links to be skipped by the open circuit, but got %v", skipped)
+	}
+}
+
+func TestLinkAccessibilityChecker_NotFoundDoesNotTripBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 1}
+	opts.CircuitBreakerThreshold = 1
+	state := newLinkCheckState(opts)
+
+	inaccessibleLinks := make(chan string, 3)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		linkAccessibilityChecker(context.Background(), testLogger, state, server.URL+path, inaccessibleLinks)
+	}
+
+	if len(inaccessibleLinks) != 3 {
+		t.Errorf("Expected all 3 missing pages to be checked, but got %d", len(inaccessibleLinks))
+	}
+	if skipped := state.notChecked[notCheckedCircuitOpen]; len(skipped) != 0 {
+		t.Errorf("Expected no links skipped for 404s, but got %v", skipped)
+	}
+}
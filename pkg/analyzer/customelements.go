@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// reservedElementNames are the hyphenated names of SVG and MathML elements, which cannot be
+// custom elements.
+var reservedElementNames = map[string]bool{
+	"annotation-xml": true, "color-profile": true, "font-face": true, "font-face-src": true,
+	"font-face-uri": true, "font-face-format": true, "font-face-name": true, "missing-glyph": true,
+}
+
+// customElementDefinition matches a customElements.define call with a literal name.
+var customElementDefinition = regexp.MustCompile("customElements\\s*\\.\\s*define\\s*\\(\\s*(['\"`])([a-z][a-z0-9._-]*-[a-z0-9._-]*)['\"`]")
+
+// htmlWhitespace is the whitespace of HTML, which leaves an element without content.
+const htmlWhitespace = " \t\n\f\r"
+
+// CustomElement is a custom element of a page, a web component whose content and behaviour
+// come from a script, out of reach of the rest of the analysis.
+type CustomElement struct {
+	// Name is the tag name, which contains a hyphen, e.g. "product-card".
+	Name string `json:"name" xml:"name,attr"`
+	// Count is the number of elements in the markup; 0 for elements an inline script defines
+	// but only creates from scripts.
+	Count int `json:"count" xml:"count,attr"`
+	// Empty counts the elements without content of their own in the markup, which their
+	// script renders entirely.
+	Empty int `json:"empty" xml:"empty,attr"`
+	// Defined reports whether an inline script of the page defines the element. Definitions in
+	// external scripts are not looked for.
+	Defined bool `json:"defined" xml:"defined,attr"`
+}
+
+// isCustomElementName reports whether name, a lower-case tag name, names a custom element.
+func isCustomElementName(name string) bool {
+	return strings.Contains(name, "-") && name[0] >= 'a' && name[0] <= 'z' && !reservedElementNames[name]
+}
+
+// definedCustomElements returns the names script defines as custom elements, in order.
+func definedCustomElements(script string) []string {
+	var names []string
+	for _, match := range customElementDefinition.FindAllStringSubmatch(script, -1) {
+		names = append(names, match[2])
+	}
+	return names
+}
+
+// customElements collects the custom elements of a page, in order of first appearance in the
+// markup, followed by those only defined by its scripts. The DOM path adds the elements it
+// finds, while the streaming path notes the tags and text it reads.
+type customElements struct {
+	elements []CustomElement
+	index    map[string]int
+	// open holds the custom elements open while tokenizing, and whether each has content yet.
+	open []openCustomElement
+}
+
+type openCustomElement struct {
+	name    string
+	content bool
+}
+
+// add records an element named name, empty if it has no content.
+func (c *customElements) add(name string, empty bool) {
+	i := c.lookup(name)
+	c.elements[i].Count++
+	if empty {
+		c.elements[i].Empty++
+	}
+}
+
+// define marks the elements named as defined.
+func (c *customElements) define(names []string) {
+	for _, name := range names {
+		c.elements[c.lookup(name)].Defined = true
+	}
+}
+
+func (c *customElements) lookup(name string) int {
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+	i, ok := c.index[name]
+	if !ok {
+		i = len(c.elements)
+		c.index[name] = i
+		c.elements = append(c.elements, CustomElement{Name: name})
+	}
+	return i
+}
+
+// startTag notes a start tag while tokenizing: content of the open custom elements, and a
+// custom element itself if name is one.
+func (c *customElements) startTag(name string) {
+	c.content()
+	if isCustomElementName(name) {
+		c.lookup(name)
+		c.open = append(c.open, openCustomElement{name: name})
+	}
+}
+
+// text notes text while tokenizing.
+func (c *customElements) text(data string) {
+	if strings.Trim(data, htmlWhitespace) != "" {
+		c.content()
+	}
+}
+
+func (c *customElements) content() {
+	for i := range c.open {
+		c.open[i].content = true
+	}
+}
+
+// endTag notes an end tag while tokenizing, closing the custom elements opened since the last
+// element named name, if it is open.
+func (c *customElements) endTag(name string) {
+	for i := len(c.open) - 1; i >= 0; i-- {
+		if c.open[i].name == name {
+			c.close(i)
+			return
+		}
+	}
+}
+
+// close records the open custom elements from the i-th on.
+func (c *customElements) close(i int) {
+	for j := len(c.open) - 1; j >= i; j-- {
+		c.add(c.open[j].name, !c.open[j].content)
+	}
+	c.open = c.open[:i]
+}
+
+// list closes the elements still open, marks the elements named in definitions as defined and
+// returns the custom elements found.
+func (c *customElements) list(definitions []string) []CustomElement {
+	c.close(0)
+	c.define(definitions)
+	return c.elements
+}
+
+// findCustomElements returns the custom elements of the document and those its inline scripts
+// define.
+func findCustomElements(doc *goquery.Document) []CustomElement {
+	var found customElements
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && isCustomElementName(n.Data) {
+			found.add(n.Data, !hasContent(n))
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n)
+	}
+	var definitions []string
+	doc.Find("script").Each(func(i int, s *goquery.Selection) {
+		definitions = append(definitions, definedCustomElements(s.Text())...)
+	})
+	return found.list(definitions)
+}
+
+// hasContent reports whether n has child elements or text other than whitespace.
+func hasContent(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode || (child.Type == html.TextNode && strings.Trim(child.Data, htmlWhitespace) != "") {
+			return true
+		}
+	}
+	return false
+}
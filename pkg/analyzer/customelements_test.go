@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestFindCustomElements(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<!DOCTYPE html><html><body>
+		<site-header>
+		</site-header>
+		<main><product-card sku="1"><h3>Tea</h3></product-card><product-card sku="2"> </product-card>
+		<product-card>Coffee</product-card><my-rating value="4"><template shadowrootmode="open"><b>4</b></template></my-rating></main>
+		<svg><font-face></font-face><missing-glyph></missing-glyph></svg><data-table></data-table>
+		<script type="module">
+			customElements.define("site-header", SiteHeader);
+			customElements.define('cart-drawer', class extends HTMLElement {});
+			window.customElements . define(` + "`toast-message`" + `, Toast);
+			customElements.define(name, Dynamic);
+		</script>
+	</body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	want := []CustomElement{
+		{Name: "site-header", Count: 1, Empty: 1, Defined: true},
+		{Name: "product-card", Count: 3, Empty: 1},
+		{Name: "my-rating", Count: 1},
+		{Name: "data-table", Count: 1, Empty: 1},
+		{Name: "cart-drawer", Defined: true},
+		{Name: "toast-message", Defined: true},
+	}
+	if got := findCustomElements(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestFindCustomElements_None(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<p>Plain <span>page</span></p><script>var x = 1;</script>`))
+	if got := findCustomElements(doc); got != nil {
+		t.Errorf("Expected no custom elements, but got %+v", got)
+	}
+}
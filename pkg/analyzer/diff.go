@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ResultDiff describes what changed between two analyses of the same page, e.g. before and
+// after a deploy. Lists are sorted so diffs compare stably.
+type ResultDiff struct {
+	XMLName xml.Name `json:"-" xml:"diff"`
+	FromID  string   `json:"from_id" xml:"from_id,attr"`
+	ToID    string   `json:"to_id" xml:"to_id,attr"`
+	// ScoreDelta is the new score minus the old one; positive means the page improved.
+	ScoreDelta int `json:"score_delta" xml:"score_delta"`
+	// Changes lists the summary fields whose value changed, in a fixed order.
+	Changes        []FieldChange `json:"changes,omitempty" xml:"changes>change"`
+	HeadingChanges []CountChange `json:"heading_changes,omitempty" xml:"heading_changes>heading"`
+	// NewBrokenLinks are inaccessible now but were not before, including new links.
+	NewBrokenLinks []string `json:"new_broken_links,omitempty" xml:"new_broken_links>url"`
+	// FixedLinks were inaccessible before and are still linked but now accessible.
+	FixedLinks       []string          `json:"fixed_links,omitempty" xml:"fixed_links>url"`
+	AddedLinks       []string          `json:"added_links,omitempty" xml:"added_links>url"`
+	RemovedLinks     []string          `json:"removed_links,omitempty" xml:"removed_links>url"`
+	NewFindings      []SecurityFinding `json:"new_findings,omitempty" xml:"new_findings>finding"`
+	ResolvedFindings []SecurityFinding `json:"resolved_findings,omitempty" xml:"resolved_findings>finding"`
+}
+
+// FieldChange is a summary field whose value changed, both values rendered as strings.
+type FieldChange struct {
+	Field string `json:"field" xml:"field,attr"`
+	From  string `json:"from" xml:"from"`
+	To    string `json:"to" xml:"to"`
+}
+
+// CountChange is a count, such as the number of h2 headings, that changed.
+type CountChange struct {
+	Name string `json:"name" xml:"name,attr"`
+	From int    `json:"from" xml:"from,attr"`
+	To   int    `json:"to" xml:"to,attr"`
+}
+
+// Empty reports whether the two analyses are equivalent.
+func (d *ResultDiff) Empty() bool {
+	return d.ScoreDelta == 0 && len(d.Changes) == 0 && len(d.HeadingChanges) == 0 &&
+		len(d.NewBrokenLinks) == 0 && len(d.FixedLinks) == 0 && len(d.AddedLinks) == 0 &&
+		len(d.RemovedLinks) == 0 && len(d.NewFindings) == 0 && len(d.ResolvedFindings) == 0
+}
+
+// DiffResults compares two analyses of the same page, from being the older one.
+func DiffResults(from, to *AnalysisResult) *ResultDiff {
+	d := &ResultDiff{FromID: from.ID, ToID: to.ID, ScoreDelta: to.Score - from.Score}
+
+	field := func(name, before, after string) {
+		if before != after {
+			d.Changes = append(d.Changes, FieldChange{Field: name, From: before, To: after})
+		}
+	}
+	count := func(name string, before, after int) {
+		field(name, strconv.Itoa(before), strconv.Itoa(after))
+	}
+	field("title", from.Title, to.Title)
+	field("description", from.Description, to.Description)
+	field("html_version", from.HTMLVersion, to.HTMLVersion)
+	field("grade", from.Grade, to.Grade)
+	field("contains_login_form", strconv.FormatBool(from.ContainsLoginForm), strconv.FormatBool(to.ContainsLoginForm))
+	count("internal_count", from.Links.InternalCount, to.Links.InternalCount)
+	count("external_count", from.Links.ExternalCount, to.Links.ExternalCount)
+	count("inaccessible_count", from.Links.InaccessibleCount, to.Links.InaccessibleCount)
+	count("broken_anchor_count", from.Links.BrokenAnchorCount, to.Links.BrokenAnchorCount)
+	count("css_resource_count", from.Links.CSSResourceCount, to.Links.CSSResourceCount)
+	count("media_source_count", from.Links.MediaSourceCount, to.Links.MediaSourceCount)
+
+	levels := slices.Collect(maps.Keys(from.Headings))
+	for level := range maps.Keys(to.Headings) {
+		if _, ok := from.Headings[level]; !ok {
+			levels = append(levels, level)
+		}
+	}
+	slices.Sort(levels)
+	for _, level := range levels {
+		if from.Headings[level] != to.Headings[level] {
+			d.HeadingChanges = append(d.HeadingChanges, CountChange{Name: level, From: from.Headings[level], To: to.Headings[level]})
+		}
+	}
+
+	before, after := linkStatuses(from), linkStatuses(to)
+	for _, link := range slices.Sorted(maps.Keys(after)) {
+		status, existed := before[link]
+		switch {
+		case !existed:
+			d.AddedLinks = append(d.AddedLinks, link)
+		case status == LinkStatusInaccessible && after[link] == LinkStatusOK:
+			d.FixedLinks = append(d.FixedLinks, link)
+		}
+		if after[link] == LinkStatusInaccessible && status != LinkStatusInaccessible {
+			d.NewBrokenLinks = append(d.NewBrokenLinks, link)
+		}
+	}
+	for _, link := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[link]; !ok {
+			d.RemovedLinks = append(d.RemovedLinks, link)
+		}
+	}
+
+	d.NewFindings = findingsMissingFrom(to.SecurityFindings, from.SecurityFindings)
+	d.ResolvedFindings = findingsMissingFrom(from.SecurityFindings, to.SecurityFindings)
+	return d
+}
+
+// linkStatuses maps each checked URL of r to its status. A URL found as several kinds, e.g.
+// as a link and an iframe, counts as inaccessible if any of them is.
+func linkStatuses(r *AnalysisResult) map[string]string {
+	statuses := make(map[string]string, len(r.LinkResults))
+	for _, link := range r.LinkResults {
+		if statuses[link.URL] != LinkStatusInaccessible {
+			statuses[link.URL] = link.Status
+		}
+	}
+	return statuses
+}
+
+// findingsMissingFrom returns the findings in a that have no finding with the same rule and
+// URL in b.
+func findingsMissingFrom(a, b []SecurityFinding) []SecurityFinding {
+	var missing []SecurityFinding
+	for _, finding := range a {
+		if !slices.ContainsFunc(b, func(other SecurityFinding) bool {
+			return other.RuleID == finding.RuleID && other.URL == finding.URL
+		}) {
+			missing = append(missing, finding)
+		}
+	}
+	return missing
+}
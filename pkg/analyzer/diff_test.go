@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	from := &AnalysisResult{
+		ID:          "before",
+		Title:       "Shop",
+		HTMLVersion: "HTML5",
+		Headings:    map[string]int{"h1": 1, "h2": 3},
+		Links:       LinkSummary{InternalCount: 3, InaccessibleCount: 1},
+		LinkResults: []LinkResult{
+			{URL: "https://shop.example/a", Status: LinkStatusOK},
+			{URL: "https://shop.example/b", Status: LinkStatusInaccessible},
+			{URL: "https://shop.example/old", Status: LinkStatusOK},
+		},
+		SecurityFindings: []SecurityFinding{{RuleID: RuleMissingHSTS, Severity: SeverityWarning}},
+		Score:            90,
+		Grade:            "A",
+	}
+	to := &AnalysisResult{
+		ID:          "after",
+		Title:       "Shop - Sale",
+		HTMLVersion: "HTML5",
+		Headings:    map[string]int{"h1": 1, "h3": 2},
+		Links:       LinkSummary{InternalCount: 3, InaccessibleCount: 1},
+		LinkResults: []LinkResult{
+			{URL: "https://shop.example/a", Status: LinkStatusInaccessible},
+			{URL: "https://shop.example/b", Status: LinkStatusOK},
+			{URL: "https://shop.example/new", Status: LinkStatusOK},
+		},
+		SecurityFindings: []SecurityFinding{{RuleID: RuleMissingCSP, Severity: SeverityWarning}},
+		Score:            85,
+		Grade:            "B",
+	}
+
+	d := DiffResults(from, to)
+
+	expected := &ResultDiff{
+		FromID:     "before",
+		ToID:       "after",
+		ScoreDelta: -5,
+		Changes: []FieldChange{
+			{Field: "title", From: "Shop", To: "Shop - Sale"},
+			{Field: "grade", From: "A", To: "B"},
+		},
+		HeadingChanges: []CountChange{
+			{Name: "h2", From: 3, To: 0},
+			{Name: "h3", From: 0, To: 2},
+		},
+		NewBrokenLinks:   []string{"https://shop.example/a"},
+		FixedLinks:       []string{"https://shop.example/b"},
+		AddedLinks:       []string{"https://shop.example/new"},
+		RemovedLinks:     []string{"https://shop.example/old"},
+		NewFindings:      []SecurityFinding{{RuleID: RuleMissingCSP, Severity: SeverityWarning}},
+		ResolvedFindings: []SecurityFinding{{RuleID: RuleMissingHSTS, Severity: SeverityWarning}},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("Expected diff\n%+v\nbut got\n%+v", expected, d)
+	}
+	if d.Empty() {
+		t.Error("Expected a non-empty diff")
+	}
+}
+
+func TestDiffResults_Identical(t *testing.T) {
+	result := &AnalysisResult{
+		Title:       "Same",
+		Headings:    map[string]int{"h1": 1},
+		LinkResults: []LinkResult{{URL: "https://example.com/", Status: LinkStatusOK}},
+		Score:       100,
+		Grade:       "A",
+	}
+
+	if d := DiffResults(result, result); !d.Empty() {
+		t.Errorf("Expected an empty diff, but got %+v", d)
+	}
+}
+
+func TestDiffResults_NewLinkBroken(t *testing.T) {
+	from := &AnalysisResult{}
+	to := &AnalysisResult{LinkResults: []LinkResult{{URL: "https://example.com/gone", Status: LinkStatusInaccessible}}}
+
+	d := DiffResults(from, to)
+
+	if !reflect.DeepEqual(d.NewBrokenLinks, []string{"https://example.com/gone"}) {
+		t.Errorf("Expected the new link to be reported broken, but got %v", d.NewBrokenLinks)
+	}
+	if !reflect.DeepEqual(d.AddedLinks, []string{"https://example.com/gone"}) {
+		t.Errorf("Expected the new link to be reported added, but got %v", d.AddedLinks)
+	}
+}
+
+func TestResultDiff_XML(t *testing.T) {
+	d := &ResultDiff{
+		FromID:         "a",
+		ToID:           "b",
+		ScoreDelta:     3,
+		Changes:        []FieldChange{{Field: "title", From: "Old", To: "New"}},
+		NewBrokenLinks: []string{"https://example.com/x"},
+	}
+
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).Encode(d); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	for _, want := range []string{
+		`<diff from_id="a" to_id="b">`,
+		`<score_delta>3</score_delta>`,
+		`<changes><change field="title"><from>Old</from><to>New</to></change></changes>`,
+		`<new_broken_links><url>https://example.com/x</url></new_broken_links>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected XML to contain %s, but got %s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// dnsReportTimeout bounds all the lookups of a DNS report together.
+const dnsReportTimeout = 5 * time.Second
+
+// DNSRecords is the DNS report of the analyzed host.
+type DNSRecords struct {
+	// CNAME is the canonical name the host is an alias of; empty if it is not an alias.
+	CNAME string `json:"cname,omitempty" xml:"cname,omitempty"`
+	// A and AAAA are the IPv4 and IPv6 addresses the host resolves to.
+	A    []string `json:"a,omitempty" xml:"a,omitempty"`
+	AAAA []string `json:"aaaa,omitempty" xml:"aaaa,omitempty"`
+	// MX lists the mail servers of the host, most preferred first.
+	MX []MXRecord `json:"mx,omitempty" xml:"mx,omitempty"`
+	// SPF is the host's "v=spf1" TXT record and DMARC the "v=DMARC1" TXT record of its _dmarc
+	// subdomain; empty if the host has none.
+	SPF   string `json:"spf,omitempty" xml:"spf,omitempty"`
+	DMARC string `json:"dmarc,omitempty" xml:"dmarc,omitempty"`
+}
+
+// MXRecord is a mail server of a host.
+type MXRecord struct {
+	Host       string `json:"host" xml:",chardata"`
+	Preference int    `json:"preference" xml:"preference,attr"`
+}
+
+// dnsResolver is the part of *net.Resolver the DNS report uses.
+type dnsResolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// reportResolver answers the lookups of DNS reports. They go to the server's resolver
+// directly, even for analyses through a proxy.
+var reportResolver dnsResolver = net.DefaultResolver
+
+// lookupDNS returns the DNS report of host. Names that do not exist or have no records of a
+// type are not errors; the records found are returned along with the errors of the lookups that
+// failed. It returns nil for IP addresses and for hosts without any records.
+func lookupDNS(ctx context.Context, logger *slog.Logger, host string) (_ *DNSRecords, err error) {
+	ctx, span := tracer.Start(ctx, "lookupDNS")
+	defer func() { endSpan(span, err) }()
+
+	if net.ParseIP(host) != nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsReportTimeout)
+	defer cancel()
+
+	records := &DNSRecords{}
+	var errs []error
+	failed := func(kind string, err error) bool {
+		var dnsErr *net.DNSError
+		if err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+			return false
+		}
+		logger.WarnContext(ctx, "DNS lookup failed", slog.String("type", kind), slog.Any("error", err))
+		errs = append(errs, fmt.Errorf("failed to look up %s records: %w", kind, err))
+		return true
+	}
+
+	cname, err := reportResolver.LookupCNAME(ctx, host)
+	if !failed("CNAME", err) {
+		cname = strings.TrimSuffix(cname, ".")
+		if !strings.EqualFold(cname, strings.TrimSuffix(host, ".")) {
+			records.CNAME = cname
+		}
+	}
+
+	addrs, err := reportResolver.LookupIPAddr(ctx, host)
+	if !failed("address", err) {
+		for _, addr := range addrs {
+			if addr.IP.To4() != nil {
+				records.A = append(records.A, addr.IP.String())
+			} else {
+				records.AAAA = append(records.AAAA, addr.IP.String())
+			}
+		}
+	}
+
+	mxs, err := reportResolver.LookupMX(ctx, host)
+	if !failed("MX", err) {
+		for _, mx := range mxs {
+			records.MX = append(records.MX, MXRecord{Host: strings.TrimSuffix(mx.Host, "."), Preference: int(mx.Pref)})
+		}
+		// The resolver shuffles servers of equal preference.
+		slices.SortFunc(records.MX, func(a, b MXRecord) int {
+			if a.Preference != b.Preference {
+				return a.Preference - b.Preference
+			}
+			return strings.Compare(a.Host, b.Host)
+		})
+	}
+
+	txts, err := reportResolver.LookupTXT(ctx, host)
+	if !failed("TXT", err) {
+		records.SPF = policyRecord(txts, "v=spf1")
+	}
+	txts, err = reportResolver.LookupTXT(ctx, "_dmarc."+host)
+	if !failed("DMARC", err) {
+		records.DMARC = policyRecord(txts, "v=DMARC1")
+	}
+
+	logger.DebugContext(ctx, "Finished DNS lookups",
+		slog.Int("addresses", len(records.A)+len(records.AAAA)),
+		slog.Int("mx", len(records.MX)),
+		slog.Bool("spf", records.SPF != ""),
+		slog.Bool("dmarc", records.DMARC != ""),
+	)
+
+	if records.CNAME == "" && len(records.A) == 0 && len(records.AAAA) == 0 && len(records.MX) == 0 && records.SPF == "" && records.DMARC == "" {
+		records = nil
+	}
+	return records, errors.Join(errs...)
+}
+
+// policyRecord returns the first of the TXT records txts that starts with version, such as
+// "v=spf1", or "" if none does.
+func policyRecord(txts []string, version string) string {
+	for _, txt := range txts {
+		txt = strings.TrimSpace(txt)
+		if len(txt) >= len(version) && strings.EqualFold(txt[:len(version)], version) &&
+			(len(txt) == len(version) || txt[len(version)] == ' ' || txt[len(version)] == ';') {
+			return txt
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// fakeResolver answers DNS lookups from maps keyed by name; missing names do not exist.
+type fakeResolver struct {
+	cnames map[string]string
+	addrs  map[string][]string
+	mxs    map[string][]*net.MX
+	txts   map[string][]string
+	err    error
+}
+
+func (f fakeResolver) notFound(name string) error {
+	if f.err != nil {
+		return f.err
+	}
+	return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (f fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if cname, ok := f.cnames[host]; ok {
+		return cname, nil
+	}
+	if _, ok := f.addrs[host]; ok {
+		return host + ".", nil
+	}
+	return "", f.notFound(host)
+}
+
+func (f fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	addrs, ok := f.addrs[host]
+	if !ok {
+		return nil, f.notFound(host)
+	}
+	var ips []net.IPAddr
+	for _, addr := range addrs {
+		ips = append(ips, net.IPAddr{IP: net.ParseIP(addr)})
+	}
+	return ips, nil
+}
+
+func (f fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if mxs, ok := f.mxs[name]; ok {
+		return mxs, nil
+	}
+	return nil, f.notFound(name)
+}
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if txts, ok := f.txts[name]; ok {
+		return txts, nil
+	}
+	return nil, f.notFound(name)
+}
+
+func useResolver(t *testing.T, resolver dnsResolver) {
+	previous := reportResolver
+	reportResolver = resolver
+	t.Cleanup(func() { reportResolver = previous })
+}
+
+func TestLookupDNS(t *testing.T) {
+	useResolver(t, fakeResolver{
+		cnames: map[string]string{"www.example.com": "example.cdn.net."},
+		addrs:  map[string][]string{"www.example.com": {"192.0.2.1", "2001:db8::1", "192.0.2.2"}},
+		mxs: map[string][]*net.MX{"www.example.com": {
+			{Host: "mx2.example.com.", Pref: 20}, {Host: "mx1b.example.com.", Pref: 10}, {Host: "mx1a.example.com.", Pref: 10},
+		}},
+		txts: map[string][]string{
+			"www.example.com":        {"google-site-verification=abc", "v=spf1 include:_spf.example.net ~all"},
+			"_dmarc.www.example.com": {"v=DMARC1; p=reject"},
+		},
+	})
+
+	got, err := lookupDNS(context.Background(), testLogger, "www.example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := &DNSRecords{
+		CNAME: "example.cdn.net",
+		A:     []string{"192.0.2.1", "192.0.2.2"},
+		AAAA:  []string{"2001:db8::1"},
+		MX: []MXRecord{
+			{Host: "mx1a.example.com", Preference: 10}, {Host: "mx1b.example.com", Preference: 10}, {Host: "mx2.example.com", Preference: 20},
+		},
+		SPF:   "v=spf1 include:_spf.example.net ~all",
+		DMARC: "v=DMARC1; p=reject",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestLookupDNS_AddressesOnly(t *testing.T) {
+	useResolver(t, fakeResolver{addrs: map[string][]string{"example.com": {"192.0.2.1"}}})
+
+	got, err := lookupDNS(context.Background(), testLogger, "example.com")
+	if err != nil {
+		t.Fatalf("Expected missing records not to be errors, but got: %v", err)
+	}
+	if want := (&DNSRecords{A: []string{"192.0.2.1"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestLookupDNS_NoRecords(t *testing.T) {
+	useResolver(t, fakeResolver{})
+
+	for _, host := range []string{"127.0.0.1", "::1", "missing.example"} {
+		if got, err := lookupDNS(context.Background(), testLogger, host); got != nil || err != nil {
+			t.Errorf("Expected no records for %s, but got %+v and %v", host, got, err)
+		}
+	}
+}
+
+func TestLookupDNS_Failure(t *testing.T) {
+	useResolver(t, fakeResolver{err: errors.New("server misbehaving")})
+
+	got, err := lookupDNS(context.Background(), testLogger, "example.com")
+	if got != nil {
+		t.Errorf("Expected no records, but got %+v", got)
+	}
+	if err == nil {
+		t.Error("Expected the lookup errors to be returned")
+	}
+}
+
+func TestPolicyRecord(t *testing.T) {
+	testCases := []struct {
+		txts     []string
+		version  string
+		expected string
+	}{
+		{[]string{"v=spf1 -all"}, "v=spf1", "v=spf1 -all"},
+		{[]string{" V=SPF1 mx -all "}, "v=spf1", "V=SPF1 mx -all"},
+		{[]string{"v=spf10 -all", "spf v=spf1"}, "v=spf1", ""},
+		{[]string{"v=DMARC1;p=none"}, "v=DMARC1", "v=DMARC1;p=none"},
+		{nil, "v=DMARC1", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := policyRecord(tc.txts, tc.version); got != tc.expected {
+			t.Errorf("Expected policyRecord(%q, %q) to be %q, but got %q", tc.txts, tc.version, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsCacheTTL is how long resolved addresses are reused before being looked up again.
+const dnsCacheTTL = time.Minute
+
+// dnsCache memoizes successful host lookups for the shared transport, so checking many
+// links on the same few hosts does not repeat identical DNS queries. Failures are not cached.
+type dnsCache struct {
+	ttl        time.Duration
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		ttl:        ttl,
+		lookupHost: net.DefaultResolver.LookupHost,
+		entries:    make(map[string]dnsEntry),
+	}
+}
+
+// lookup returns the addresses of host, from the cache when a fresh entry exists.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		c.hits.Add(1)
+		return entry.addrs, nil
+	}
+	c.misses.Add(1)
+
+	addrs, err := c.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return addrs, nil
+}
+
+// stats returns the number of cache hits and misses so far.
+func (c *dnsCache) stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// dialContext returns a DialContext function that resolves hostnames through the cache
+// and tries each returned address in turn. Literal IP addresses are dialed directly.
+// Every destination is vetted with checkDestination first; a host with any internal address
+// is refused outright unless the host policy allows it. The operator's proxy is exempt.
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || isOperatorProxy(addr) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		if net.ParseIP(host) != nil {
+			if err := checkDestination(host, []string{host}); err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkDestination(host, ips); err != nil {
+			return nil, err
+		}
+
+		var errs []error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCache_Lookup(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(time.Minute)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []string{"127.0.0.1"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		addrs, err := cache.lookup(context.Background(), "example.com")
+		if err != nil || len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+			t.Fatalf("lookup() = %v, %v", addrs, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("Expected 1 resolver lookup, but got %d", n)
+	}
+	if hits, misses := cache.stats(); hits != 2 || misses != 1 {
+		t.Errorf("stats() = (%d hits, %d misses), want (2, 1)", hits, misses)
+	}
+}
+
+func TestDNSCache_Expiry(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(10 * time.Millisecond)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []string{"127.0.0.1"}, nil
+	}
+
+	cache.lookup(context.Background(), "example.com")
+	time.Sleep(20 * time.Millisecond)
+	cache.lookup(context.Background(), "example.com")
+
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("Expected an expired entry to be looked up again, but got %d lookups", n)
+	}
+}
+
+func TestDNSCache_FailuresAreNotCached(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(time.Minute)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return nil, errors.New("no such host")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.lookup(context.Background(), "missing.example"); err == nil {
+			t.Fatal("Expected the lookup error to be returned")
+		}
+	}
+
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("Expected failed lookups to be retried, but got %d lookups", n)
+	}
+}
+
+func TestDNSCache_DialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var lookups int32
+	cache := newDNSCache(time.Minute)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []string{"127.0.0.1"}, nil
+	}
+
+	testClient := &http.Client{Transport: newBaseTransport(cache)}
+	testClient.Transport.(*http.Transport).DisableKeepAlives = true
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	for i := 0; i < 3; i++ {
+		resp, err := testClient.Get("http://cached.test:" + port + "/")
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("Expected 1 lookup for 3 connections, but got %d", n)
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+)
+
+// Errors returned by AnalyzePage when the page itself cannot be fetched. They are wrapped
+// together with the underlying error, so callers can match them with errors.Is.
+var (
+	// ErrDNSFailure means the page's host name could not be resolved.
+	ErrDNSFailure = errors.New("dns lookup failed")
+	// ErrTimeout means the page fetch did not complete in time.
+	ErrTimeout = errors.New("page fetch timed out")
+	// ErrTLS means the TLS handshake with the page's host failed, e.g. on an invalid certificate.
+	ErrTLS = errors.New("tls handshake failed")
+	// ErrNotHTML means the page was fetched but is not an HTML document.
+	ErrNotHTML = errors.New("page is not html")
+)
+
+// HTTPStatusError is returned when the page keeps answering with a non-success status code.
+type HTTPStatusError struct {
+	Code int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("page returned HTTP status %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// classifyFetchError wraps a failed page fetch with the matching error sentinel, leaving
+// errors it does not recognize (and cancellation by the caller) untouched.
+func classifyFetchError(err error) error {
+	var (
+		dnsErr  *net.DNSError
+		certErr *tls.CertificateVerificationError
+		authErr x509.UnknownAuthorityError
+		hostErr x509.HostnameError
+		invErr  x509.CertificateInvalidError
+		recErr  tls.RecordHeaderError
+		alert   tls.AlertError
+		netErr  net.Error
+	)
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, ErrBlockedAddress):
+		return err
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("%w: %w", ErrDNSFailure, err)
+	case errors.As(err, &certErr), errors.As(err, &authErr), errors.As(err, &hostErr),
+		errors.As(err, &invErr), errors.As(err, &recErr), errors.As(err, &alert):
+		return fmt.Errorf("%w: %w", ErrTLS, err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return err
+	}
+}
+
+// checkHTMLContentType fails with ErrNotHTML when resp declares a media type other than HTML
+// or XHTML. Responses without a Content-Type are given the benefit of the doubt.
+func checkHTMLContentType(resp *http.Response) error {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: invalid content type %q", ErrNotHTML, contentType)
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return nil
+	default:
+		return fmt.Errorf("%w: content type is %s", ErrNotHTML, mediaType)
+	}
+}
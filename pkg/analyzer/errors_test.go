@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLoadWebPage_ErrorTaxonomy(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Retry.MaxRetries = 0
+
+	t.Run("Not HTML", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"ok": true}`)
+		}))
+		defer server.Close()
+
+		_, err := loadWebPage(context.Background(), testLogger, server.URL, opts)
+		if !errors.Is(err, ErrNotHTML) {
+			t.Errorf("Expected ErrNotHTML, but got: %v", err)
+		}
+	})
+
+	t.Run("HTTP status", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := loadWebPage(context.Background(), testLogger, server.URL, opts)
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+			t.Errorf("Expected an HTTPStatusError with code %d, but got: %v", http.StatusNotFound, err)
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		_, err := loadWebPage(context.Background(), testLogger, server.URL, opts)
+		if !errors.Is(err, ErrTLS) {
+			t.Errorf("Expected ErrTLS for an untrusted certificate, but got: %v", err)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := loadWebPage(ctx, testLogger, server.URL, opts)
+		if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrTimeout wrapping context.DeadlineExceeded, but got: %v", err)
+		}
+	})
+}
+
+func TestClassifyFetchError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "DNS failure",
+			err:  &url.Error{Op: "Get", URL: "http://missing.invalid", Err: &net.DNSError{Err: "no such host", Name: "missing.invalid", IsNotFound: true}},
+			want: ErrDNSFailure,
+		},
+		{
+			name: "Canceled is left alone",
+			err:  context.Canceled,
+			want: nil,
+		},
+		{
+			name: "Blocked address is left alone",
+			err:  fmt.Errorf("dial: %w", ErrBlockedAddress),
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyFetchError(tc.err)
+			if !errors.Is(got, tc.err) {
+				t.Errorf("Expected the original error to stay matchable, but got: %v", got)
+			}
+			if tc.want == nil {
+				if got != tc.err {
+					t.Errorf("Expected the error to be returned unchanged, but got: %v", got)
+				}
+				return
+			}
+			if !errors.Is(got, tc.want) {
+				t.Errorf("Expected %v, but got: %v", tc.want, got)
+			}
+		})
+	}
+}
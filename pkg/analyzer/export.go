@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// linksCSVHeader is the header row written by WriteLinksCSV.
+var linksCSVHeader = []string{"url", "type", "kind", "anchor_text", "status", "status_code", "error"}
+
+// WriteLinksCSV writes the link results of result to w as CSV, one row per link, for
+// importing into spreadsheets.
+func WriteLinksCSV(w io.Writer, result *AnalysisResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(linksCSVHeader); err != nil {
+		return err
+	}
+
+	for _, link := range result.LinkResults {
+		statusCode := ""
+		if link.StatusCode != 0 {
+			statusCode = strconv.Itoa(link.StatusCode)
+		}
+		record := []string{link.URL, link.Type, link.Kind, link.AnchorText, link.Status, statusCode, link.Error}
+		for i, field := range record {
+			record[i] = csvSafe(field)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe keeps spreadsheets from evaluating page-controlled text such as anchor text as a
+// formula, by prefixing fields that start with a formula character with a quote.
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestWriteLinksCSV(t *testing.T) {
+	result := &AnalysisResult{
+		LinkResults: []LinkResult{
+			{URL: "https://example.com/about", Type: LinkTypeInternal, Kind: LinkKindLink, AnchorText: "About, us", Status: LinkStatusOK, StatusCode: 200},
+			{URL: "https://other.example/x", Type: LinkTypeExternal, Kind: LinkKindLink, AnchorText: "=HYPERLINK(\"evil\")", Status: LinkStatusInaccessible, StatusCode: 404, Error: "404 Not Found"},
+			{URL: "https://example.com/private", Type: LinkTypeInternal, Kind: LinkKindIframe, Status: LinkStatusNotChecked, Error: notCheckedRobots},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLinksCSV(&buf, result); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, but got: %v", err)
+	}
+
+	expected := [][]string{
+		{"url", "type", "kind", "anchor_text", "status", "status_code", "error"},
+		{"https://example.com/about", "internal", "link", "About, us", "ok", "200", ""},
+		{"https://other.example/x", "external", "link", "'=HYPERLINK(\"evil\")", "inaccessible", "404", "404 Not Found"},
+		{"https://example.com/private", "internal", "iframe", "", "not_checked", "", "robots"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Expected records %q, but got %q", expected, records)
+	}
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+const (
+	// shingleWords is the number of consecutive words hashed together as one feature of a
+	// content fingerprint.
+	shingleWords = 3
+	// textContextBytes is about how much text textContext quotes on each side of a match.
+	textContextBytes = 40
+)
+
+// invisibleTextTags are the elements whose text is not shown on the page, and so is left out
+// of its content fingerprint.
+var invisibleTextTags = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "title": true}
+
+// contentWords appends the lower-cased words of text to words.
+func contentWords(words []string, text string) []string {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		words = append(words, strings.ToLower(word))
+	}
+	return words
+}
+
+// documentWords returns the words of the visible text of doc, in document order.
+func documentWords(doc *goquery.Document) []string {
+	return textWords(documentText(doc))
+}
+
+// textWords returns the words of texts, in order.
+func textWords(texts []string) []string {
+	var words []string
+	for _, text := range texts {
+		words = contentWords(words, text)
+	}
+	return words
+}
+
+// documentText returns the visible text nodes of doc, in document order.
+func documentText(doc *goquery.Document) []string {
+	var texts []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && invisibleTextTags[n.Data] {
+			return
+		}
+		if n.Type == html.TextNode {
+			texts = append(texts, n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range doc.Nodes {
+		walk(n)
+	}
+	return texts
+}
+
+// textContext returns the text around text[start:end], cut at spaces about
+// textContextBytes away, with whitespace collapsed and an ellipsis where it was cut.
+func textContext(text string, start, end int) string {
+	from, to := 0, len(text)
+	if start > textContextBytes {
+		if i := strings.IndexAny(text[start-textContextBytes:start], " \t\n"); i >= 0 {
+			from = start - textContextBytes + i + 1
+		}
+	}
+	if len(text)-end > textContextBytes {
+		if i := strings.LastIndexAny(text[end:end+textContextBytes], " \t\n"); i >= 0 {
+			to = end + i
+		}
+	}
+	context := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		context = "…" + context
+	}
+	if to < len(text) {
+		context += "…"
+	}
+	return context
+}
+
+// contentFingerprint returns the simhash of words as 16 hex digits, or "" for a page without
+// text. Pages with similar text get fingerprints differing in few bits; see
+// FingerprintDistance.
+func contentFingerprint(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	var weights [64]int
+	for i := 0; i+shingleWords <= max(len(words), shingleWords); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+shingleWords, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", fingerprint)
+}
+
+// FingerprintDistance returns the number of bits in which the content fingerprints a and b
+// differ, from 0 for the same text to 64. It reports false if either is not a fingerprint.
+func FingerprintDistance(a, b string) (int, bool) {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil {
+		return 0, false
+	}
+	return bits.OnesCount64(x ^ y), true
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDocumentWords(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head><title>Title</title>
+		<style>p { color: red }</style><script>var x = "hidden";</script></head>
+		<body><h1>Hello, World!</h1><p>It's <b>café</b> time: 9am</p><!-- comment -->
+		<noscript>Enable scripts</noscript><template><p>Later</p></template></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	want := []string{"hello", "world", "it", "s", "café", "time", "9am"}
+	if got := documentWords(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, but got %q", want, got)
+	}
+}
+
+func TestContentFingerprint(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog while the farmer watches from the porch " +
+		"and the sun sets slowly behind the hills of the quiet green valley far away from town"
+	base := contentFingerprint(contentWords(nil, text))
+	if len(base) != 16 {
+		t.Fatalf("Expected 16 hex digits, but got %q", base)
+	}
+	if fingerprint := contentFingerprint(contentWords(nil, strings.ToUpper(text))); fingerprint != base {
+		t.Errorf("Expected case to be ignored, but got %q and %q", base, fingerprint)
+	}
+
+	near := contentFingerprint(contentWords(nil, strings.Replace(text, "lazy", "sleepy", 1)))
+	far := contentFingerprint(contentWords(nil, "Completely unrelated words about databases, indexes, "+
+		"query planners and the cost of sequential scans on very large tables in production systems"))
+	nearDistance, ok := FingerprintDistance(base, near)
+	if !ok {
+		t.Fatalf("Expected valid fingerprints, but got %q and %q", base, near)
+	}
+	farDistance, _ := FingerprintDistance(base, far)
+	if nearDistance >= farDistance {
+		t.Errorf("Expected similar text to be closer than unrelated text, but got distances %d and %d", nearDistance, farDistance)
+	}
+
+	if fingerprint := contentFingerprint(nil); fingerprint != "" {
+		t.Errorf("Expected no fingerprint without text, but got %q", fingerprint)
+	}
+	if fingerprint := contentFingerprint([]string{"one", "two"}); len(fingerprint) != 16 {
+		t.Errorf("Expected a fingerprint for text shorter than a shingle, but got %q", fingerprint)
+	}
+}
+
+func TestFingerprintDistance(t *testing.T) {
+	testCases := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"0000000000000000", "0000000000000000", 0, true},
+		{"0000000000000000", "ffffffffffffffff", 64, true},
+		{"00000000000000f0", "0000000000000010", 3, true},
+		{"", "0000000000000000", 0, false},
+		{"not hex", "0000000000000000", 0, false},
+	}
+	for _, tc := range testCases {
+		got, ok := FingerprintDistance(tc.a, tc.b)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("FingerprintDistance(%q, %q): expected %d, %t, but got %d, %t", tc.a, tc.b, tc.want, tc.wantOK, got, ok)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// DefaultUserAgent identifies the analyzer on outbound requests. Go's own default
+// ("Go-http-client/1.1") is rejected outright by many sites.
+const DefaultUserAgent = "Mozilla/5.0 (compatible; web-analyzer/1.0; +https://github.com/lalithyawiki/web-analyzer)"
+
+// ErrInvalidHeader is returned for request headers that cannot be sent over HTTP.
+var ErrInvalidHeader = errors.New("invalid request header")
+
+// ParseHeader splits a "Name: value" line into a validated header name and value.
+func ParseHeader(line string) (name, value string, err error) {
+	name, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: %q is not in \"Name: value\" form", ErrInvalidHeader, line)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if err := ValidateHeader(name, value); err != nil {
+		return "", "", err
+	}
+	return name, value, nil
+}
+
+// ValidateHeader checks that name and value form a legal HTTP request header.
+func ValidateHeader(name, value string) error {
+	if !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("%w: bad name %q", ErrInvalidHeader, name)
+	}
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return fmt.Errorf("%w: bad value for %s", ErrInvalidHeader, name)
+	}
+	return nil
+}
+
+// requestHeader returns the headers sent with the page fetch and every link check.
+// UserAgent takes precedence over a User-Agent entry in Headers.
+func (o Options) requestHeader() http.Header {
+	header := o.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if o.UserAgent != "" {
+		header.Set("User-Agent", o.UserAgent)
+	}
+	return header
+}
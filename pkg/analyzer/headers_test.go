@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	testCases := []struct {
+		line          string
+		expectedName  string
+		expectedValue string
+		expectErr     bool
+	}{
+		{"X-Api-Key: secret", "X-Api-Key", "secret", false},
+		{"  Accept-Language :  en-GB, en;q=0.8 ", "Accept-Language", "en-GB, en;q=0.8", false},
+		{"X-Empty:", "X-Empty", "", false},
+		{"no separator", "", "", true},
+		{"Bad Name: value", "", "", true},
+		{"X-Bad: line\nbreak", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			name, value, err := ParseHeader(tc.line)
+			if tc.expectErr {
+				if !errors.Is(err, ErrInvalidHeader) {
+					t.Errorf("Expected ErrInvalidHeader, but got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if name != tc.expectedName || value != tc.expectedValue {
+				t.Errorf("Expected (%q, %q), but got (%q, %q)", tc.expectedName, tc.expectedValue, name, value)
+			}
+		})
+	}
+}
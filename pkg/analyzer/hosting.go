@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// HostingIP is an address the analyzed host resolves to and the network it belongs to.
+type HostingIP struct {
+	IP string `json:"ip" xml:"ip,attr"`
+	// ASN is the number of the autonomous system announcing the address, Organization the name
+	// it is registered under and Country the ISO 3166 code of its country. They are left out
+	// without an IP database, or if the address is not in it.
+	ASN          int    `json:"asn,omitempty" xml:"asn,attr,omitempty"`
+	Organization string `json:"organization,omitempty" xml:"organization,attr,omitempty"`
+	Country      string `json:"country,omitempty" xml:"country,attr,omitempty"`
+}
+
+// IPDatabase maps IP address ranges to the autonomous system announcing them.
+type IPDatabase struct {
+	// ranges are sorted by start and do not overlap.
+	ranges []ipRange
+}
+
+type ipRange struct {
+	start, end   netip.Addr
+	asn          int
+	organization string
+	country      string
+}
+
+// ReadIPDatabase reads an IP-to-ASN database in the tab-separated format of iptoasn.com's
+// ip2asn files: the first and last address of a range, the AS number, the country code and the
+// AS description, one range per line. Ranges of AS 0, which no one announces, are skipped.
+func ReadIPDatabase(r io.Reader) (*IPDatabase, error) {
+	db := &IPDatabase{}
+	organizations := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %d: expected 5 tab-separated fields, but got %d", line, len(fields))
+		}
+		start, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		end, err := netip.ParseAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		asn, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid AS number %q", line, fields[2])
+		}
+		if asn == 0 {
+			continue
+		}
+		start, end = start.Unmap(), end.Unmap()
+		if end.Less(start) || start.Is4() != end.Is4() {
+			return nil, fmt.Errorf("line %d: invalid range %s-%s", line, start, end)
+		}
+		// The descriptions repeat for every range of an AS, so they are shared.
+		organization, ok := organizations[fields[4]]
+		if !ok {
+			organization = fields[4]
+			organizations[organization] = organization
+		}
+		country := fields[3]
+		if country == "None" {
+			country = ""
+		}
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, asn: asn, organization: organization, country: country})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(db.ranges, func(a, b ipRange) int { return a.start.Compare(b.start) })
+	for i := 1; i < len(db.ranges); i++ {
+		if !db.ranges[i-1].end.Less(db.ranges[i].start) {
+			return nil, fmt.Errorf("overlapping ranges %s-%s and %s-%s", db.ranges[i-1].start, db.ranges[i-1].end, db.ranges[i].start, db.ranges[i].end)
+		}
+	}
+	return db, nil
+}
+
+// LoadIPDatabase reads the IP database at path, decompressing it if the name ends in ".gz".
+func LoadIPDatabase(path string) (*IPDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return ReadIPDatabase(r)
+}
+
+// Len returns the number of address ranges in the database.
+func (db *IPDatabase) Len() int {
+	return len(db.ranges)
+}
+
+// lookup returns the network of addr, reporting whether the database has it.
+func (db *IPDatabase) lookup(addr netip.Addr) (HostingIP, bool) {
+	addr = addr.Unmap()
+	// The last range starting at or before addr is the only one that may hold it.
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r ipRange, addr netip.Addr) int { return r.start.Compare(addr) })
+	if !found {
+		i--
+	}
+	if i < 0 || db.ranges[i].end.Less(addr) || db.ranges[i].start.Is4() != addr.Is4() {
+		return HostingIP{}, false
+	}
+	r := db.ranges[i]
+	return HostingIP{ASN: r.asn, Organization: r.organization, Country: r.country}, true
+}
+
+var ipDatabase atomic.Pointer[IPDatabase]
+
+// SetIPDatabase sets the database the AS and country of hosting addresses are looked up in. Nil
+// leaves them out.
+func SetIPDatabase(db *IPDatabase) {
+	ipDatabase.Store(db)
+}
+
+// hostingIPs returns the addresses of the analyzed host, host itself if it is an IP address and
+// otherwise its DNS addresses, with their networks if an IP database is set.
+func hostingIPs(host string, records *DNSRecords) []HostingIP {
+	var addrs []string
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []string{addr.String()}
+	} else if records != nil {
+		addrs = append(slices.Clone(records.A), records.AAAA...)
+	}
+
+	db := ipDatabase.Load()
+	var hosting []HostingIP
+	for _, s := range addrs {
+		ip := HostingIP{IP: s}
+		if addr, err := netip.ParseAddr(s); err == nil && db != nil {
+			if network, ok := db.lookup(addr); ok {
+				network.IP = s
+				ip = network
+			}
+		}
+		hosting = append(hosting, ip)
+	}
+	return hosting
+}
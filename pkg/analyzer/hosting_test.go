@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"compress/gzip"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testIPDatabase = `1.0.0.0	1.0.0.255	13335	US	CLOUDFLARENET
+1.0.1.0	1.0.3.255	0	None	Not routed
+2001:db8::	2001:db8::ffff	64500	DE	EXAMPLE-AS
+8.8.8.0	8.8.8.255	15169	US	GOOGLE
+93.184.215.0	93.184.215.255	15133	None	EDGECAST
+`
+
+func TestReadIPDatabase(t *testing.T) {
+	db, err := ReadIPDatabase(strings.NewReader(testIPDatabase))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Expected the unrouted range to be skipped, leaving 4 ranges, but got %d", db.Len())
+	}
+
+	testCases := []struct {
+		addr     string
+		expected HostingIP
+		found    bool
+	}{
+		{"1.0.0.1", HostingIP{ASN: 13335, Organization: "CLOUDFLARENET", Country: "US"}, true},
+		{"1.0.0.255", HostingIP{ASN: 13335, Organization: "CLOUDFLARENET", Country: "US"}, true},
+		{"::ffff:8.8.8.8", HostingIP{ASN: 15169, Organization: "GOOGLE", Country: "US"}, true},
+		{"93.184.215.14", HostingIP{ASN: 15133, Organization: "EDGECAST"}, true},
+		{"2001:db8::1", HostingIP{ASN: 64500, Organization: "EXAMPLE-AS", Country: "DE"}, true},
+		{"1.0.2.1", HostingIP{}, false},
+		{"0.0.0.1", HostingIP{}, false},
+		{"255.255.255.255", HostingIP{}, false},
+		{"2001:db8::1:0", HostingIP{}, false},
+		{"::1", HostingIP{}, false},
+	}
+
+	for _, tc := range testCases {
+		got, found := db.lookup(netip.MustParseAddr(tc.addr))
+		if found != tc.found || got != tc.expected {
+			t.Errorf("Expected lookup(%s) to be %+v, %v, but got %+v, %v", tc.addr, tc.expected, tc.found, got, found)
+		}
+	}
+}
+
+func TestReadIPDatabase_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"Missing field":     "1.0.0.0\t1.0.0.255\t13335\tUS\n",
+		"Invalid address":   "1.0.0\t1.0.0.255\t13335\tUS\tX\n",
+		"Invalid AS number": "1.0.0.0\t1.0.0.255\tAS13335\tUS\tX\n",
+		"Reversed range":    "1.0.0.255\t1.0.0.0\t13335\tUS\tX\n",
+		"Mixed families":    "1.0.0.0\t2001:db8::\t13335\tUS\tX\n",
+		"Overlapping":       "1.0.0.0\t1.0.0.255\t13335\tUS\tX\n1.0.0.128\t1.0.1.255\t64500\tUS\tY\n",
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadIPDatabase(strings.NewReader(data)); err == nil {
+				t.Error("Expected an error, but got none")
+			}
+		})
+	}
+}
+
+func TestLoadIPDatabase_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip2asn-combined.tsv.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(testIPDatabase))
+	gz.Close()
+	f.Close()
+
+	db, err := LoadIPDatabase(path)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Expected 4 ranges, but got %d", db.Len())
+	}
+}
+
+func TestHostingIPs(t *testing.T) {
+	records := &DNSRecords{A: []string{"8.8.8.8", "192.0.2.1"}, AAAA: []string{"2001:db8::1"}}
+
+	if got, want := hostingIPs("example.com", records), []HostingIP{{IP: "8.8.8.8"}, {IP: "192.0.2.1"}, {IP: "2001:db8::1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only addresses without a database, but got %+v", got)
+	}
+
+	db, _ := ReadIPDatabase(strings.NewReader(testIPDatabase))
+	SetIPDatabase(db)
+	defer SetIPDatabase(nil)
+
+	want := []HostingIP{
+		{IP: "8.8.8.8", ASN: 15169, Organization: "GOOGLE", Country: "US"},
+		{IP: "192.0.2.1"},
+		{IP: "2001:db8::1", ASN: 64500, Organization: "EXAMPLE-AS", Country: "DE"},
+	}
+	if got := hostingIPs("example.com", records); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+	if got, want := hostingIPs("1.0.0.1", nil), []HostingIP{{IP: "1.0.0.1", ASN: 13335, Organization: "CLOUDFLARENET", Country: "US"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v for an IP address host, but got %+v", want, got)
+	}
+	if got := hostingIPs("missing.example", nil); got != nil {
+		t.Errorf("Expected no addresses without DNS records, but got %+v", got)
+	}
+}
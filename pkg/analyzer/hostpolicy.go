@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// hostPolicy holds the operator-configured host allowlist and denylist. Entries are either
+// hostname globs (path.Match syntax, e.g. "*.example.com") or IP addresses and CIDR ranges.
+type hostPolicy struct {
+	allowHosts []string
+	allowNets  []netip.Prefix
+	denyHosts  []string
+	denyNets   []netip.Prefix
+}
+
+// currentHostPolicy is consulted on every outbound connection; nil means no lists are configured.
+var currentHostPolicy atomic.Pointer[hostPolicy]
+
+// SetHostPolicy restricts which hosts the analyzer may connect to, both for the analyzed page
+// and for link checks. A destination matching deny is always refused. When allow is non-empty,
+// only destinations matching it are permitted, and they are exempt from the private network
+// block so internal hosts can be allowed explicitly. A destination matches by hostname glob,
+// or by IP range when every address it resolves to lies within an allowed range (for allow)
+// or any address lies within a denied range (for deny).
+func SetHostPolicy(allow, deny []string) error {
+	policy := &hostPolicy{}
+
+	var err error
+	if policy.allowHosts, policy.allowNets, err = parseHostPatterns(allow); err != nil {
+		return fmt.Errorf("invalid allowlist: %w", err)
+	}
+	if policy.denyHosts, policy.denyNets, err = parseHostPatterns(deny); err != nil {
+		return fmt.Errorf("invalid denylist: %w", err)
+	}
+
+	if policy.empty() {
+		currentHostPolicy.Store(nil)
+	} else {
+		currentHostPolicy.Store(policy)
+	}
+	return nil
+}
+
+// parseHostPatterns splits patterns into lowercase hostname globs and IP prefixes.
+func parseHostPatterns(patterns []string) (hosts []string, nets []netip.Prefix, err error) {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+
+		if strings.Contains(pattern, "/") {
+			prefix, err := netip.ParsePrefix(pattern)
+			if err != nil {
+				return nil, nil, err
+			}
+			nets = append(nets, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(pattern); err == nil {
+			nets = append(nets, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, nil, fmt.Errorf("%q: %w", pattern, err)
+		}
+		hosts = append(hosts, pattern)
+	}
+	return hosts, nets, nil
+}
+
+func (p *hostPolicy) empty() bool {
+	return len(p.allowHosts) == 0 && len(p.allowNets) == 0 && len(p.denyHosts) == 0 && len(p.denyNets) == 0
+}
+
+func (p *hostPolicy) hasAllowlist() bool {
+	return len(p.allowHosts) > 0 || len(p.allowNets) > 0
+}
+
+func (p *hostPolicy) allows(host string, addrs []netip.Addr) bool {
+	if matchesHostGlob(p.allowHosts, host) {
+		return true
+	}
+	if len(p.allowNets) == 0 || len(addrs) == 0 {
+		return false
+	}
+	for _, addr := range addrs {
+		if !prefixesContain(p.allowNets, addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *hostPolicy) denies(host string, addrs []netip.Addr) bool {
+	if matchesHostGlob(p.denyHosts, host) {
+		return true
+	}
+	for _, addr := range addrs {
+		if prefixesContain(p.denyNets, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesHostGlob(globs []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDestination returns an error wrapping ErrBlockedAddress if host, resolved to ips,
+// must not be connected to under the host policy and the private network block.
+func checkDestination(host string, ips []string) error {
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return fmt.Errorf("%w: unparseable address %q", ErrBlockedAddress, ip)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	if policy := currentHostPolicy.Load(); policy != nil {
+		if policy.denies(host, addrs) {
+			return fmt.Errorf("%w: %s is denied by the host policy", ErrBlockedAddress, host)
+		}
+		if policy.allows(host, addrs) {
+			return nil
+		}
+		if policy.hasAllowlist() {
+			return fmt.Errorf("%w: %s is not on the host allowlist", ErrBlockedAddress, host)
+		}
+	}
+
+	for _, addr := range addrs {
+		if err := checkDialAddress(addr); err != nil {
+			return fmt.Errorf("%s: %w", host, err)
+		}
+	}
+	return nil
+}
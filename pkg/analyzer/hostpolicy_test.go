@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetHostPolicy_InvalidPatterns(t *testing.T) {
+	t.Cleanup(func() { SetHostPolicy(nil, nil) })
+
+	if err := SetHostPolicy([]string{"10.0.0.0/33"}, nil); err == nil {
+		t.Error("Expected an error for an invalid CIDR, but got nil")
+	}
+	if err := SetHostPolicy(nil, []string{"[example.com"}); err == nil {
+		t.Error("Expected an error for an invalid glob, but got nil")
+	}
+}
+
+func TestCheckDestination(t *testing.T) {
+	testCases := []struct {
+		name         string
+		allow, deny  []string
+		blockPrivate bool
+		host         string
+		ips          []string
+		allowed      bool
+	}{
+		{"No policy", nil, nil, false, "example.com", []string{"93.184.216.34"}, true},
+		{"Denied by glob", nil, []string{"*.example.com"}, false, "api.example.com", []string{"93.184.216.34"}, false},
+		{"Denied by CIDR", nil, []string{"93.184.0.0/16"}, false, "example.com", []string{"1.1.1.1", "93.184.216.34"}, false},
+		{"Deny wins over allow", []string{"*.example.com"}, []string{"api.example.com"}, false, "api.example.com", []string{"93.184.216.34"}, false},
+		{"Allowed by glob", []string{"*.example.com"}, nil, false, "www.example.com", []string{"93.184.216.34"}, true},
+		{"Not on allowlist", []string{"*.example.com"}, nil, false, "example.org", []string{"93.184.216.34"}, false},
+		{"Allowed by CIDR", []string{"93.184.216.0/24"}, nil, false, "example.com", []string{"93.184.216.34"}, true},
+		{"CIDR allow needs every address", []string{"93.184.216.0/24"}, nil, false, "example.com", []string{"93.184.216.34", "1.1.1.1"}, false},
+		{"Allowlist exempts private block", []string{"intranet.corp"}, nil, true, "intranet.corp", []string{"10.0.0.5"}, true},
+		{"Private block without policy", nil, nil, true, "intranet.corp", []string{"10.0.0.5"}, false},
+		{"Private block with denylist only", nil, []string{"*.example.com"}, true, "intranet.corp", []string{"10.0.0.5"}, false},
+		{"Glob is case insensitive", []string{"*.Example.COM"}, nil, false, "WWW.example.com", []string{"93.184.216.34"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				SetHostPolicy(nil, nil)
+				SetBlockPrivateNetworks(false)
+			})
+			if err := SetHostPolicy(tc.allow, tc.deny); err != nil {
+				t.Fatalf("Expected a valid policy, but got: %v", err)
+			}
+			SetBlockPrivateNetworks(tc.blockPrivate)
+
+			err := checkDestination(tc.host, tc.ips)
+			if tc.allowed && err != nil {
+				t.Errorf("Expected %s to be allowed, but got: %v", tc.host, err)
+			}
+			if !tc.allowed && !errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("Expected %s to be blocked, but got: %v", tc.host, err)
+			}
+		})
+	}
+}
+
+func TestLoadWebPage_HostPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Cleanup(func() {
+		SetHostPolicy(nil, nil)
+		SetBlockPrivateNetworks(false)
+	})
+	SetBlockPrivateNetworks(true)
+
+	if err := SetHostPolicy([]string{"127.0.0.1"}, nil); err != nil {
+		t.Fatalf("Expected a valid policy, but got: %v", err)
+	}
+	resp, err := loadWebPage(context.Background(), testLogger, server.URL, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected an allowlisted loopback server to be fetched, but got: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := SetHostPolicy(nil, []string{"127.0.0.0/8"}); err != nil {
+		t.Fatalf("Expected a valid policy, but got: %v", err)
+	}
+	server.CloseClientConnections()
+	if _, err := loadWebPage(context.Background(), testLogger, server.URL, DefaultOptions()); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected ErrBlockedAddress for a denied range, but got: %v", err)
+	}
+}
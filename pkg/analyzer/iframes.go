@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Iframe is an iframe of the page and the attributes restricting what it may do.
+type Iframe struct {
+	URL string `json:"url" xml:"url,attr"`
+	// ThirdParty reports whether the iframe is on another host than the page.
+	ThirdParty bool `json:"third_party" xml:"third_party,attr"`
+	// Sandboxed reports whether the iframe has a sandbox attribute, and Sandbox holds its
+	// value, the restrictions lifted; empty lifts none.
+	Sandboxed bool   `json:"sandboxed" xml:"sandboxed,attr"`
+	Sandbox   string `json:"sandbox,omitempty" xml:"sandbox,attr,omitempty"`
+	// Allow is the permissions policy of the iframe, and ReferrerPolicy the referrer policy
+	// of its requests.
+	Allow          string `json:"allow,omitempty" xml:"allow,attr,omitempty"`
+	ReferrerPolicy string `json:"referrer_policy,omitempty" xml:"referrer_policy,attr,omitempty"`
+}
+
+// iframeMarkup is an iframe as written in the page, before resolving its src.
+type iframeMarkup struct {
+	src, sandbox, allow, referrerPolicy string
+	sandboxed                           bool
+}
+
+// newIframeMarkup reads the attributes of an iframe, given by attr.
+func newIframeMarkup(attr func(name string) (string, bool)) iframeMarkup {
+	src, _ := attr("src")
+	sandbox, sandboxed := attr("sandbox")
+	allow, _ := attr("allow")
+	referrerPolicy, _ := attr("referrerpolicy")
+	return iframeMarkup{src: src, sandbox: sandbox, sandboxed: sandboxed, allow: allow, referrerPolicy: referrerPolicy}
+}
+
+// findIframes returns the iframes with a src of the document, in document order.
+func findIframes(doc *goquery.Document) []iframeMarkup {
+	var frames []iframeMarkup
+	doc.Find("iframe[src]").Each(func(i int, s *goquery.Selection) {
+		frames = append(frames, newIframeMarkup(s.Attr))
+	})
+	return frames
+}
+
+// auditIframes resolves the srcs of frames, leaving out those that classifyLinks skips or
+// cannot parse.
+func auditIframes(frames []iframeMarkup, resolveBase, baseURL *url.URL, opts NormalizeOptions) []Iframe {
+	var iframes []Iframe
+	for _, frame := range frames {
+		src := strings.TrimSpace(frame.src)
+		if _, skipped := skippedLinkCategory(src); skipped || strings.HasPrefix(strings.ToLower(src), "about:") {
+			continue
+		}
+		frameURL, err := resolveLink(src, resolveBase, opts)
+		if err != nil {
+			continue
+		}
+		iframes = append(iframes, Iframe{
+			URL:            frameURL.String(),
+			ThirdParty:     !isSameHost(frameURL, baseURL),
+			Sandboxed:      frame.sandboxed,
+			Sandbox:        strings.Join(strings.Fields(frame.sandbox), " "),
+			Allow:          strings.TrimSpace(frame.allow),
+			ReferrerPolicy: strings.TrimSpace(frame.referrerPolicy),
+		})
+	}
+	return iframes
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditIframes(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/docs/")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<iframe src="widget.html"></iframe>
+		<iframe src="https://ads.example/slot" allow=" camera; microphone " referrerpolicy="origin"></iframe>
+		<iframe src="https://video.example/embed" sandbox=" allow-scripts
+			allow-same-origin "></iframe>
+		<iframe src="https://maps.example/embed" sandbox></iframe>
+		<iframe src="about:blank"></iframe><iframe src="javascript:void(0)"></iframe><iframe></iframe>
+		<noscript><iframe src="https://tags.example/ns"></iframe></noscript>
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	got := auditIframes(findIframes(doc), baseURL, baseURL, NormalizeOptions{})
+	want := []Iframe{
+		{URL: "https://example.com/docs/widget.html"},
+		{URL: "https://ads.example/slot", ThirdParty: true, Allow: "camera; microphone", ReferrerPolicy: "origin"},
+		{URL: "https://video.example/embed", ThirdParty: true, Sandboxed: true, Sandbox: "allow-scripts allow-same-origin"},
+		{URL: "https://maps.example/embed", ThirdParty: true, Sandboxed: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestAuditIframes_None(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/")
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<p>No frames</p>`))
+	if got := auditIframes(findIframes(doc), baseURL, baseURL, NormalizeOptions{}); got != nil {
+		t.Errorf("Expected no iframes, but got %+v", got)
+	}
+}
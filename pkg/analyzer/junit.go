@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// WriteJUnitXML writes result as a JUnit XML report with one test case per check, so CI
+// systems can gate on an analysis of pageURL. Violations such as inaccessible links are
+// failures; checks that could not run (see AnalysisResult.Errors) are errors.
+func WriteJUnitXML(w io.Writer, pageURL string, result *AnalysisResult) error {
+	suite := junitSuite{
+		Name:      "web-analyzer " + pageURL,
+		Timestamp: result.AnalyzedAt.Format("2006-01-02T15:04:05"),
+	}
+	className := "web-analyzer." + result.Host
+
+	addCase := func(name string, failure *junitProblem) {
+		testCase := junitTestCase{ClassName: className, Name: name}
+		if reason, ok := result.Errors[name]; ok {
+			testCase.Error = &junitProblem{Message: "check did not complete", Type: "check_error", Details: reason}
+			suite.Errors++
+		} else if failure != nil {
+			testCase.Failure = failure
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	var doctype *junitProblem
+	if result.HTMLVersion == "" || strings.HasPrefix(result.HTMLVersion, "Unknown") {
+		doctype = &junitProblem{Message: "page has no recognizable doctype", Type: "missing_doctype"}
+	}
+	addCase(CheckHTMLVersion, doctype)
+
+	var title *junitProblem
+	if strings.TrimSpace(result.Title) == "" {
+		title = &junitProblem{Message: "page has no title", Type: "missing_title"}
+	}
+	addCase("title", title)
+
+	addCase(CheckHeadings, nil)
+	addCase(CheckLinks, inaccessibleLinksProblem(result, "links", LinkKindLink, LinkKindIframe))
+	addCase(CheckCSSResources, inaccessibleLinksProblem(result, "CSS resources", LinkKindCSS))
+	addCase(CheckMedia, inaccessibleLinksProblem(result, "media sources", LinkKindMedia))
+
+	var anchors *junitProblem
+	if n := result.Links.BrokenAnchorCount; n > 0 {
+		anchors = &junitProblem{Message: fmt.Sprintf("%d broken in-page anchors", n), Type: "broken_anchors"}
+	}
+	addCase("broken_anchors", anchors)
+
+	addCase(CheckLoginForm, nil)
+
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// inaccessibleLinksProblem reports the inaccessible links of the given kinds, one per line,
+// or nil if there are none. noun names them in the failure message.
+func inaccessibleLinksProblem(result *AnalysisResult, noun string, kinds ...string) *junitProblem {
+	var lines []string
+	for _, link := range result.LinkResults {
+		if link.Status != LinkStatusInaccessible || !slices.Contains(kinds, link.Kind) {
+			continue
+		}
+		lines = append(lines, link.URL+": "+link.Error)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return &junitProblem{
+		Message: fmt.Sprintf("%d inaccessible %s", len(lines), noun),
+		Type:    "inaccessible_links",
+		Details: strings.Join(lines, "\n"),
+	}
+}
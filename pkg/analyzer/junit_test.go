@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnitXML(t *testing.T) {
+	result := &AnalysisResult{
+		Host:        "example.com",
+		HTMLVersion: "HTML5",
+		Title:       "",
+		Links:       LinkSummary{BrokenAnchorCount: 1},
+		LinkResults: []LinkResult{
+			{URL: "https://example.com/ok", Kind: LinkKindLink, Status: LinkStatusOK},
+			{URL: "https://example.com/gone", Kind: LinkKindLink, Status: LinkStatusInaccessible, Error: "404 Not Found"},
+			{URL: "https://example.com/robots", Kind: LinkKindIframe, Status: LinkStatusNotChecked, Error: notCheckedRobots},
+		},
+		Errors: map[string]string{CheckLoginForm: "context canceled"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnitXML(&buf, "https://example.com/", result); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected valid XML, but got: %v\n%s", err, buf.String())
+	}
+	if len(report.Suites) != 1 {
+		t.Fatalf("Expected one test suite, but got %d", len(report.Suites))
+	}
+	suite := report.Suites[0]
+
+	if suite.Tests != 8 || suite.Failures != 3 || suite.Errors != 1 {
+		t.Errorf("Expected 8 tests, 3 failures and 1 error, but got %d, %d and %d", suite.Tests, suite.Failures, suite.Errors)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, testCase := range suite.Cases {
+		cases[testCase.Name] = testCase
+	}
+	testCases := []struct {
+		name    string
+		failure string
+		err     bool
+	}{
+		{name: CheckHTMLVersion},
+		{name: "title", failure: "missing_title"},
+		{name: CheckHeadings},
+		{name: CheckLinks, failure: "inaccessible_links"},
+		{name: CheckCSSResources},
+		{name: "broken_anchors", failure: "broken_anchors"},
+		{name: CheckLoginForm, err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testCase, ok := cases[tc.name]
+			if !ok {
+				t.Fatalf("Expected a %q test case", tc.name)
+			}
+			if tc.failure == "" && testCase.Failure != nil {
+				t.Errorf("Expected no failure, but got %+v", testCase.Failure)
+			}
+			if tc.failure != "" && (testCase.Failure == nil || testCase.Failure.Type != tc.failure) {
+				t.Errorf("Expected a %q failure, but got %+v", tc.failure, testCase.Failure)
+			}
+			if tc.err != (testCase.Error != nil) {
+				t.Errorf("Expected error = %v, but got %+v", tc.err, testCase.Error)
+			}
+		})
+	}
+
+	if details := cases[CheckLinks].Failure.Details; !strings.Contains(details, "https://example.com/gone: 404 Not Found") || strings.Contains(details, "robots") {
+		t.Errorf("Expected the failure to list only the inaccessible link, but got %q", details)
+	}
+}
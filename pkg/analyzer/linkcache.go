@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"sync"
+	"time"
+)
+
+// maxLinkCacheEntries bounds the link status cache; expired entries are pruned first
+// and, if that is not enough, the cache is cleared.
+const maxLinkCacheEntries = 50000
+
+// linkStatusCache remembers link check outcomes across analyses, so pages sharing
+// navigation and footer links do not re-check the same URLs on every run.
+type linkStatusCache struct {
+	mu      sync.RWMutex
+	entries map[string]linkStatusEntry
+}
+
+type linkStatusEntry struct {
+	outcome linkOutcome
+	expires time.Time
+}
+
+// linkCache is shared by every analysis in the process.
+var linkCache = newLinkStatusCache()
+
+func newLinkStatusCache() *linkStatusCache {
+	return &linkStatusCache{entries: make(map[string]linkStatusEntry)}
+}
+
+// get returns the cached outcome for link, if one exists and has not expired.
+func (c *linkStatusCache) get(link string) (outcome linkOutcome, ok bool) {
+	c.mu.RLock()
+	entry, found := c.entries[link]
+	c.mu.RUnlock()
+
+	if !found || time.Now().After(entry.expires) {
+		return linkOutcome{}, false
+	}
+	return entry.outcome, true
+}
+
+// put stores the outcome for link for the given ttl.
+func (c *linkStatusCache) put(link string, outcome linkOutcome, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxLinkCacheEntries {
+		c.pruneLocked()
+	}
+	c.entries[link] = linkStatusEntry{outcome: outcome, expires: time.Now().Add(ttl)}
+}
+
+func (c *linkStatusCache) pruneLocked() {
+	now := time.Now()
+	for link, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, link)
+		}
+	}
+	if len(c.entries) >= maxLinkCacheEntries {
+		c.entries = make(map[string]linkStatusEntry)
+	}
+}
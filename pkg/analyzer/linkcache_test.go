@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLinkStatusCache(t *testing.T) {
+	cache := newLinkStatusCache()
+
+	if _, ok := cache.get("https://example.com/"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	cache.put("https://example.com/", linkOutcome{statusCode: http.StatusOK}, time.Minute)
+	cache.put("https://example.com/dead", linkOutcome{statusCode: http.StatusNotFound, err: "404 Not Found"}, time.Minute)
+
+	if outcome, ok := cache.get("https://example.com/"); !ok || !outcome.accessible() {
+		t.Errorf("get() = (%+v, %v), want an accessible outcome", outcome, ok)
+	}
+	if outcome, ok := cache.get("https://example.com/dead"); !ok || outcome.accessible() || outcome.statusCode != http.StatusNotFound {
+		t.Errorf("get() = (%+v, %v), want an inaccessible 404 outcome", outcome, ok)
+	}
+}
+
+func TestLinkStatusCache_Expiry(t *testing.T) {
+	cache := newLinkStatusCache()
+	cache.put("https://example.com/", linkOutcome{statusCode: http.StatusOK}, 10*time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.get("https://example.com/"); ok {
+		t.Error("Expected the entry to expire after its TTL")
+	}
+}
+
+func TestValidateLinkAccessibility_ReusesCachedStatus(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks: []string{server.URL + "/nav", server.URL + "/missing"},
+	}
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 1}
+	opts.LinkCacheTTL = time.Minute
+
+	for run := 1; run <= 2; run++ {
+		report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, opts)
+		if err != nil {
+			t.Fatalf("Run %d: expected no error, but got: %v", run, err)
+		}
+		if len(report.Inaccessible) != 1 || report.Inaccessible[0] != server.URL+"/missing" {
+			t.Errorf("Run %d: expected /missing to be inaccessible, but got %v", run, report.Inaccessible)
+		}
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("Expected the second run to be served from cache (2 requests total), but got %d", n)
+	}
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Values of MediaEmbed.Provider.
+const (
+	MediaProviderYouTube = "youtube"
+	MediaProviderVimeo   = "vimeo"
+	MediaProviderSpotify = "spotify"
+)
+
+// mediaEmbedHosts maps the hosts of the embedded players of video and audio services, without
+// "www.", to their provider.
+var mediaEmbedHosts = map[string]string{
+	"youtube.com":          MediaProviderYouTube,
+	"youtube-nocookie.com": MediaProviderYouTube,
+	"player.vimeo.com":     MediaProviderVimeo,
+	"open.spotify.com":     MediaProviderSpotify,
+	"embed.spotify.com":    MediaProviderSpotify,
+}
+
+// Media is the video and audio inventory of a page.
+type Media struct {
+	// Videos and Audios count the <video> and <audio> elements.
+	Videos int `json:"videos" xml:"videos,attr"`
+	Audios int `json:"audios" xml:"audios,attr"`
+	// Sources lists the files the elements play, from their src attributes and <source>
+	// children, and the posters of the videos, as absolute URLs. They are checked along with
+	// the links of the page, as LinkKindMedia link results.
+	Sources []string `json:"sources,omitempty" xml:"source,omitempty"`
+	// Embeds lists the iframes of known video and audio players.
+	Embeds []MediaEmbed `json:"embeds,omitempty" xml:"embed,omitempty"`
+}
+
+// MediaEmbed is the embedded player of a video or audio service.
+type MediaEmbed struct {
+	// Provider is one of the MediaProvider constants.
+	Provider string `json:"provider" xml:"provider,attr"`
+	URL      string `json:"url" xml:",chardata"`
+}
+
+// mediaMarkup is what the media of a page reference, before resolving.
+type mediaMarkup struct {
+	videos, audios int
+	// srcs holds the src and poster attributes of the <video> and <audio> elements and the src
+	// attributes of their <source> children, in document order.
+	srcs      []string
+	frameSrcs []string
+}
+
+// extractMedia collects the <video> and <audio> elements of the document and the iframes of
+// known players.
+func extractMedia(ctx context.Context, logger *slog.Logger, doc *goquery.Document, baseURL *url.URL, opts NormalizeOptions) (_ *Media, err error) {
+	ctx, span := tracer.Start(ctx, "extractMedia")
+	defer func() { endSpan(span, err) }()
+
+	var markup mediaMarkup
+	doc.Find("video, audio, video source[src], audio source[src], iframe[src]").Each(func(i int, s *goquery.Selection) {
+		switch goquery.NodeName(s) {
+		case "video":
+			markup.videos++
+		case "audio":
+			markup.audios++
+		case "iframe":
+			markup.frameSrcs = append(markup.frameSrcs, s.AttrOr("src", ""))
+			return
+		}
+		for _, attr := range []string{"src", "poster"} {
+			if src, ok := s.Attr(attr); ok && (attr == "src" || goquery.NodeName(s) == "video") {
+				markup.srcs = append(markup.srcs, src)
+			}
+		}
+	})
+
+	resolveBase := documentBaseURL(ctx, logger, doc, baseURL)
+	return collectMedia(ctx, logger, markup, resolveBase, opts)
+}
+
+// collectMedia resolves the sources of markup and recognizes its embedded players. It returns
+// nil if the page has no media.
+func collectMedia(ctx context.Context, logger *slog.Logger, markup mediaMarkup, resolveBase *url.URL, opts NormalizeOptions) (*Media, error) {
+	media := &Media{Videos: markup.videos, Audios: markup.audios}
+	seen := make(map[string]bool)
+	var errs []error
+
+	for _, src := range markup.srcs {
+		src = strings.TrimSpace(src)
+		if _, skipped := skippedLinkCategory(src); skipped {
+			continue
+		}
+		sourceURL, err := resolveLink(src, resolveBase, opts)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to parse media source", slog.String("src", src), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("failed to parse media source '%s': %w", src, err))
+			continue
+		}
+		if seen[sourceURL.String()] {
+			continue
+		}
+		seen[sourceURL.String()] = true
+		media.Sources = append(media.Sources, sourceURL.String())
+	}
+
+	for _, src := range markup.frameSrcs {
+		frameURL, err := resolveLink(strings.TrimSpace(src), resolveBase, opts)
+		if err != nil {
+			// extractLinks reports iframes that do not parse.
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(frameURL.Hostname()), "www.")
+		if provider, ok := mediaEmbedHosts[host]; ok {
+			media.Embeds = append(media.Embeds, MediaEmbed{Provider: provider, URL: frameURL.String()})
+		}
+	}
+
+	logger.DebugContext(ctx, "Finished extracting media",
+		slog.Int("videos", media.Videos),
+		slog.Int("audios", media.Audios),
+		slog.Int("sources", len(media.Sources)),
+		slog.Int("embeds", len(media.Embeds)),
+	)
+
+	if media.Videos == 0 && media.Audios == 0 && len(media.Embeds) == 0 {
+		media = nil
+	}
+	return media, errors.Join(errs...)
+}
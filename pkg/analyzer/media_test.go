@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractMedia(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/talks/")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<video src="intro.mp4" poster="/img/intro.jpg" controls></video>
+		<video poster="data:image/png;base64,AAAA"><source src="keynote.webm"><source src="keynote.mp4"><track src="keynote.vtt"></video>
+		<audio><source src="https://cdn.example/episode.mp3"></audio>
+		<picture><source srcset="hero.webp"><img src="hero.png"></picture><source src="stray.mp4">
+		<iframe src="https://www.youtube.com/embed/xyz"></iframe>
+		<iframe src="https://player.vimeo.com/video/1"></iframe>
+		<iframe src="https://open.spotify.com/embed/episode/2"></iframe>
+		<iframe src="https://maps.example/embed"></iframe>
+	`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	got, err := extractMedia(context.Background(), testLogger, doc, baseURL, NormalizeOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := &Media{
+		Videos: 2,
+		Audios: 1,
+		Sources: []string{
+			"https://example.com/talks/intro.mp4", "https://example.com/img/intro.jpg", "https://example.com/talks/keynote.webm",
+			"https://example.com/talks/keynote.mp4", "https://cdn.example/episode.mp3",
+		},
+		Embeds: []MediaEmbed{
+			{Provider: MediaProviderYouTube, URL: "https://www.youtube.com/embed/xyz"},
+			{Provider: MediaProviderVimeo, URL: "https://player.vimeo.com/video/1"},
+			{Provider: MediaProviderSpotify, URL: "https://open.spotify.com/embed/episode/2"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestExtractMedia_None(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/")
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<p>Text</p><iframe src="/frame"></iframe>`))
+	got, err := extractMedia(context.Background(), testLogger, doc, baseURL, NormalizeOptions{})
+	if err != nil || got != nil {
+		t.Errorf("Expected no media, but got %+v and %v", got, err)
+	}
+}
+
+func TestLinkResults_MediaSources(t *testing.T) {
+	baseURL, _ := url.Parse("https://example.com/")
+	analysis := LinkAnalysis{MediaSources: []string{"https://example.com/a.mp4", "https://cdn.example/b.mp3"}}
+	report := linkCheckReport{Inaccessible: []string{"https://cdn.example/b.mp3"}, Outcomes: map[string]linkOutcome{
+		"https://example.com/a.mp4": {statusCode: 200},
+		"https://cdn.example/b.mp3": {statusCode: 404, err: "404 Not Found"},
+	}}
+	got := linkResults(analysis, report, baseURL)
+	want := []LinkResult{
+		{URL: "https://example.com/a.mp4", Type: LinkTypeInternal, Kind: LinkKindMedia, Status: LinkStatusOK, StatusCode: 200},
+		{URL: "https://cdn.example/b.mp3", Type: LinkTypeExternal, Kind: LinkKindMedia, Status: LinkStatusInaccessible, StatusCode: 404, Error: "404 Not Found"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
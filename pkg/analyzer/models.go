@@ -0,0 +1,238 @@
+package analyzer
+
+import "time"
+
+type LinkSummary struct {
+	InternalCount     int            `json:"internal_count"`
+	ExternalCount     int            `json:"external_count"`
+	InaccessibleCount int            `json:"inaccessible_count"`
+	NotCheckedCounts  map[string]int `json:"not_checked_counts"`
+	BrokenAnchorCount int            `json:"broken_anchor_count"`
+	SkippedCounts     map[string]int `json:"skipped_counts"`
+
+	InternalIframeCount int `json:"internal_iframe_count"`
+	ExternalIframeCount int `json:"external_iframe_count"`
+
+	CSSResourceCount int `json:"css_resource_count"`
+
+	// MediaSourceCount counts the sources and posters of the page's media. Added in schema
+	// version 1.15.
+	MediaSourceCount int `json:"media_source_count"`
+}
+
+type LinkAnalysis struct {
+	InternalLinks []string
+	ExternalLinks []string
+	BrokenAnchors []string
+	SkippedLinks  map[string]int
+
+	InternalIframes []string
+	ExternalIframes []string
+
+	CSSResources []string
+
+	MediaSources []string
+
+	// Iframes lists the iframes of the page with their restricting attributes.
+	Iframes []Iframe
+
+	// AnchorTexts maps each link in InternalLinks and ExternalLinks to the text of the first
+	// anchor pointing at it that has any.
+	AnchorTexts map[string]string
+}
+
+// LinkResult is one link found on the page and the outcome of checking it.
+type LinkResult struct {
+	URL string `json:"url"`
+	// Type is LinkTypeInternal or LinkTypeExternal.
+	Type string `json:"type"`
+	// Kind is LinkKindLink, LinkKindIframe, LinkKindCSS or LinkKindMedia.
+	Kind       string `json:"kind"`
+	AnchorText string `json:"anchor_text,omitempty"`
+	// Status is LinkStatusOK, LinkStatusInaccessible or LinkStatusNotChecked.
+	Status string `json:"status"`
+	// StatusCode is the last HTTP status code received for the link, if any.
+	StatusCode int `json:"status_code,omitempty"`
+	// Error says why the link is inaccessible, or why it was not checked.
+	Error string `json:"error,omitempty"`
+	// Redirects lists the URLs the link redirected to, in order, up to the last one checked.
+	// A link redirecting in a loop ends with a URL it had already visited. Added in schema
+	// version 1.8.
+	Redirects []string `json:"redirects,omitempty"`
+}
+
+// SecurityFinding is one issue found by the security checks.
+type SecurityFinding struct {
+	// RuleID identifies the check, one of the Rule constants.
+	RuleID string `json:"rule_id" xml:"rule_id,attr"`
+	// Severity is SeverityError, SeverityWarning or SeverityNote.
+	Severity string `json:"severity" xml:"severity,attr"`
+	Message  string `json:"message" xml:",chardata"`
+	// URL is the offending resource, for findings about a specific link.
+	URL string `json:"url,omitempty" xml:"url,attr,omitempty"`
+}
+
+// Values of LinkResult.Type, Kind and Status.
+const (
+	LinkTypeInternal = "internal"
+	LinkTypeExternal = "external"
+
+	LinkKindLink   = "link"
+	LinkKindIframe = "iframe"
+	LinkKindCSS    = "css"
+	LinkKindMedia  = "media"
+
+	LinkStatusOK           = "ok"
+	LinkStatusInaccessible = "inaccessible"
+	LinkStatusNotChecked   = "not_checked"
+)
+
+// SchemaVersion is the version of the AnalysisResult JSON schema, stored in every result.
+// Within a major version the schema only changes additively: fields are added (bumping the
+// minor version) but never renamed, removed or given a different meaning or type. Anything
+// else requires a new major version.
+const SchemaVersion = "1.22"
+
+type AnalysisResult struct {
+	// SchemaVersion is the SchemaVersion the result was produced with.
+	SchemaVersion string `json:"schema_version"`
+	// ID uniquely identifies this analysis, e.g. for permalinks. A cached result keeps its ID;
+	// every new analysis, including a revalidation, gets a new one. Added in schema version 1.4.
+	ID string `json:"id"`
+
+	Host              string         `json:"host"`
+	HostUnicode       string         `json:"host_unicode"`
+	HTMLVersion       string         `json:"html_version"`
+	Title             string         `json:"title"`
+	Headings          map[string]int `json:"headings"`
+	Links             LinkSummary    `json:"links"`
+	ContainsLoginForm bool           `json:"contains_login_form"`
+	AnalyzedAt        time.Time      `json:"analyzed_at"`
+
+	// Description is the content of the page's meta description, if it has one. Added in
+	// schema version 1.5.
+	Description string `json:"description,omitempty"`
+	// ContentFingerprint is a simhash of the visible text of the page, for finding pages with
+	// nearly the same content with FingerprintDistance; empty for a page without text. Added
+	// in schema version 1.6.
+	ContentFingerprint string `json:"content_fingerprint,omitempty"`
+	// Noindex reports whether the page asks not to be indexed by search engines, with a robots
+	// meta tag or an X-Robots-Tag header. Added in schema version 1.7.
+	Noindex bool `json:"noindex,omitempty"`
+	// Redirects lists the URLs the page redirected to, in order, ending with the URL of the
+	// page analyzed. Added in schema version 1.8.
+	Redirects []string `json:"redirects,omitempty"`
+	// Pagination holds the page's rel="next" and rel="prev" links, if it has any. Added in
+	// schema version 1.9.
+	Pagination *Pagination `json:"pagination,omitempty"`
+	// RichResults lists the JSON-LD structured data items of the page of a type with a Google
+	// rich result, and whether each is eligible for it. Added in schema version 1.10.
+	RichResults []RichResult `json:"rich_results,omitempty"`
+	// Spelling lists the likely misspellings in the page's visible text, if the spellcheck is
+	// enabled with SetSpellcheck and has a dictionary for the page's language. Added in schema
+	// version 1.11.
+	Spelling *Spelling `json:"spelling,omitempty"`
+	// Placeholders lists the placeholder text left in the page's visible text, such as "lorem
+	// ipsum", "coming soon" or the sample content of a CMS. Added in schema version 1.12.
+	Placeholders []Placeholder `json:"placeholders,omitempty"`
+	// Classification marks a parking page or a web server's welcome page. Such pages are not
+	// scored and their links are not checked. Added in schema version 1.13.
+	Classification *Classification `json:"classification,omitempty"`
+	// Noscript sums up the page's <noscript> blocks, if it has any. Added in schema version
+	// 1.14.
+	Noscript *Noscript `json:"noscript,omitempty"`
+	// Media is the page's video and audio inventory, if it has any. Added in schema version
+	// 1.15.
+	Media *Media `json:"media,omitempty"`
+	// CustomElements lists the page's custom elements, the parts of it that scripts render
+	// and the rest of the analysis cannot see into. Added in schema version 1.16.
+	CustomElements []CustomElement `json:"custom_elements,omitempty"`
+	// Iframes lists the page's iframes with their sandbox, allow and referrerpolicy
+	// attributes. Added in schema version 1.17.
+	Iframes []Iframe `json:"iframes,omitempty"`
+	// DNS holds the DNS records of the analyzed host; nil for IP addresses. Added in schema
+	// version 1.18.
+	DNS *DNSRecords `json:"dns,omitempty"`
+	// Hosting lists the addresses of the analyzed host, with their AS and country when the
+	// server has an IP database. Added in schema version 1.19.
+	Hosting []HostingIP `json:"hosting,omitempty"`
+	// Registration is the registration of the host's registrable domain, if Options.RDAPURL is
+	// set and the RDAP service knows the domain. Added in schema version 1.20.
+	Registration *DomainRegistration `json:"registration,omitempty"`
+	// Archive is the latest snapshot of the page in the Wayback Machine, if Options.WaybackURL is
+	// set, or the snapshot analyzed, for Options.Snapshot. Added in schema version 1.21.
+	Archive *ArchiveSnapshot `json:"archive,omitempty"`
+	// WordCount is the number of words of the page's visible text and PageBytes the size of its
+	// HTML after decompression, for comparing pages in a benchmark. Added in schema version 1.22.
+	WordCount int   `json:"word_count,omitempty"`
+	PageBytes int64 `json:"page_bytes,omitempty"`
+
+	// ETag and LastModified are the page's cache validators, used to revalidate the result later.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// LinkResults lists every checked link with its outcome. Added in schema version 1.1.
+	LinkResults []LinkResult `json:"link_results,omitempty"`
+
+	// SecurityFindings lists the security issues found on the page, such as missing security
+	// headers or mixed content. Added in schema version 1.2.
+	SecurityFindings []SecurityFinding `json:"security_findings,omitempty"`
+
+	// Score rates the page from 0 to 100 and Grade turns it into a letter from A to F; see
+	// scoreResult for what lowers them. Both are left empty when only some checks ran or the
+	// page has a Classification. Added in schema version 1.3.
+	Score int    `json:"score"`
+	Grade string `json:"grade"`
+
+	// Errors maps the name of each check that failed (see the Check constants) to the reason,
+	// so callers can tell which parts of an otherwise successful result are incomplete.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Names of the individual checks, as used for the keys of AnalysisResult.Errors and in
+// Options.Checks.
+const (
+	CheckHTMLVersion  = "html_version"
+	CheckHeadings     = "headings"
+	CheckLinks        = "links"
+	CheckCSSResources = "css_resources"
+	CheckLoginForm    = "login_form"
+	CheckMedia        = "media"
+
+	// CheckLinkStatus checks whether the links, CSS resources and media sources found are
+	// accessible. It only selects what Options.Checks runs; without CheckLinks,
+	// CheckCSSResources and CheckMedia it has nothing to check.
+	CheckLinkStatus = "link_status"
+	// CheckSecurity looks for security findings. It only selects what Options.Checks runs.
+	CheckSecurity = "security"
+	// CheckMarkupValidation submits the page to the Nu HTML Checker of Options.ValidatorURL,
+	// adding its errors and warnings to the security findings as RuleInvalidMarkup findings,
+	// which do not lower the score. It only runs when that is set.
+	CheckMarkupValidation = "markup_validation"
+	// CheckSpelling spellchecks the visible text of the page. It only runs when SetSpellcheck
+	// has set dictionaries.
+	CheckSpelling = "spelling"
+	// CheckDNS looks up the DNS records of the analyzed host and the networks of its addresses.
+	CheckDNS = "dns"
+	// CheckRegistration looks up the registration of the host's domain with the RDAP service of
+	// Options.RDAPURL. It only runs when that is set.
+	CheckRegistration = "registration"
+	// CheckBlocklist checks the page and its external links against the blocklist and Safe
+	// Browsing, adding the URLs flagged to the security findings. It only runs when SetBlocklist
+	// or SetSafeBrowsing has set either.
+	CheckBlocklist = "blocklist"
+	// CheckArchive looks up the latest snapshot of the page in the Wayback Machine at
+	// Options.WaybackURL. It only runs when that is set.
+	CheckArchive = "archive"
+)
+
+// addCheckError records that the named check failed with err. It is a no-op for a nil err.
+func (r *AnalysisResult) addCheckError(check string, err error) {
+	if err == nil {
+		return
+	}
+	if r.Errors == nil {
+		r.Errors = make(map[string]string)
+	}
+	r.Errors[check] = err.Error()
+}
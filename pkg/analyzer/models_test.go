@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestAnalysisResult_JSONFields pins the JSON field names of schema version 1. Fields may be
+// added to this list, but removing or renaming one is a breaking change that needs a new
+// major SchemaVersion.
+func TestAnalysisResult_JSONFields(t *testing.T) {
+	if !strings.HasPrefix(SchemaVersion, "1.") {
+		t.Fatalf("Expected schema major version 1, but got %q; update this test for the new schema", SchemaVersion)
+	}
+
+	testCases := []struct {
+		name   string
+		value  any
+		fields []string
+	}{
+		{
+			name:  "AnalysisResult",
+			value: AnalysisResult{Description: "About", ContentFingerprint: "0123456789abcdef", Noindex: true, Redirects: []string{"https://example.com/"}, Pagination: &Pagination{}, RichResults: []RichResult{{}}, Spelling: &Spelling{}, Placeholders: []Placeholder{{}}, Classification: &Classification{}, Noscript: &Noscript{}, Media: &Media{}, CustomElements: []CustomElement{{}}, Iframes: []Iframe{{}}, DNS: &DNSRecords{}, Hosting: []HostingIP{{}}, Registration: &DomainRegistration{}, Archive: &ArchiveSnapshot{}, WordCount: 250, PageBytes: 4096, Errors: map[string]string{CheckLinks: "failed"}, ETag: `"v1"`, LastModified: "then", LinkResults: []LinkResult{{}}, SecurityFindings: []SecurityFinding{{}}},
+			fields: []string{
+				"analyzed_at", "archive", "classification", "contains_login_form", "content_fingerprint", "custom_elements", "description", "dns", "errors", "etag", "headings", "host", "host_unicode", "hosting",
+				"grade", "html_version", "id", "iframes", "last_modified", "link_results", "links", "media", "noindex", "noscript", "page_bytes", "pagination", "placeholders", "redirects", "registration", "rich_results", "schema_version",
+				"score", "security_findings", "spelling", "title", "word_count",
+			},
+		},
+		{
+			name:   "LinkResult",
+			value:  LinkResult{AnchorText: "About", StatusCode: 404, Error: "404 Not Found", Redirects: []string{"https://example.com/about/"}},
+			fields: []string{"anchor_text", "error", "kind", "redirects", "status", "status_code", "type", "url"},
+		},
+		{
+			name:   "SecurityFinding",
+			value:  SecurityFinding{URL: "http://example.com/style.css"},
+			fields: []string{"message", "rule_id", "severity", "url"},
+		},
+		{
+			name:  "LinkSummary",
+			value: LinkSummary{},
+			fields: []string{
+				"broken_anchor_count", "css_resource_count", "external_count", "external_iframe_count",
+				"inaccessible_count", "internal_count", "internal_iframe_count", "media_source_count", "not_checked_counts", "skipped_counts",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			var decoded map[string]json.RawMessage
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			for _, field := range tc.fields {
+				if _, ok := decoded[field]; !ok {
+					t.Errorf("Expected JSON field %q to be present, but got %s", field, data)
+				}
+			}
+			for field := range decoded {
+				if !slices.Contains(tc.fields, field) {
+					t.Errorf("Unexpected JSON field %q; add it to this test if it is a new additive field", field)
+				}
+			}
+		})
+	}
+}
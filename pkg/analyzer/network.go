@@ -0,0 +1,796 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	maxRetries     = 3
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+	backoffJitter  = 0.1
+	numWorkers     = 10
+
+	// maxRetryAfter caps how long a server-supplied Retry-After header can make us wait.
+	maxRetryAfter = 30 * time.Second
+
+	// maxDrainBytes caps how much of a link check response body is read before closing it.
+	// Small bodies are drained so the connection can be reused; larger ones are abandoned.
+	maxDrainBytes = 4 << 10
+
+	// defaultMaxPageBytes is the default cap on the analyzed page body.
+	defaultMaxPageBytes = 10 << 20
+
+	// defaultStreamingThreshold is the page size above which the tokenizer path is used.
+	defaultStreamingThreshold = 2 << 20
+)
+
+// ErrPageTooLarge is returned when the analyzed page exceeds Options.MaxPageBytes.
+var ErrPageTooLarge = errors.New("page too large")
+
+var resolverCache = newDNSCache(dnsCacheTTL)
+
+var transport = &rateLimitedTransport{base: newBaseTransport(resolverCache)}
+
+// client is used for link checks and auxiliary lookups; pageClient fetches the analyzed page.
+var (
+	client = &http.Client{
+		Timeout:       10 * time.Second,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}
+	pageClient = &http.Client{
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}
+)
+
+// SetLinkTimeout bounds each link check request, including redirects and reading the
+// response headers. A non-positive timeout removes the bound.
+func SetLinkTimeout(timeout time.Duration) {
+	client.Timeout = max(timeout, 0)
+}
+
+// NewOutboundClient returns a client that shares the analyses' transport, so requests to
+// user-supplied URLs such as webhooks are subject to the same private network block, host
+// lists, proxy and outbound rate limit.
+func NewOutboundClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// newBaseTransport clones the default transport, resolving hostnames through cache.
+func newBaseTransport(cache *dnsCache) *http.Transport {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxyForRequest
+	base.DialContext = cache.dialContext(&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	})
+	return base
+}
+
+func loadWebPage(ctx context.Context, logger *slog.Logger, pageURL string, opts Options) (_ *http.Response, err error) {
+	ctx, span := tracer.Start(ctx, "loadWebPage", trace.WithAttributes(attribute.String("url.full", pageURL)))
+	defer func() { endSpan(span, err) }()
+
+	logger = logger.With(slog.String("analyzing_page_link", pageURL))
+
+	logger.DebugContext(ctx, "Starting to load web page")
+
+	req, err := newPageRequest(ctx, pageURL, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to create page request", slog.Any("error", err))
+		return nil, err
+	}
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+
+	var data *http.Response
+
+	policy := opts.Retry
+	for i := 0; i < policy.attempts(); i++ {
+		attempt := i + 1
+		logger.DebugContext(ctx, "Attempting to fetch page", slog.Int("attempt", attempt), slog.Bool("conditional", conditional))
+
+		data, err = authSessionFrom(ctx).pageClient().Do(req)
+		span.SetAttributes(attribute.Int("http.request.resend_count", i))
+		if data != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", data.StatusCode))
+		}
+
+		if errors.Is(err, ErrBlockedAddress) {
+			logger.ErrorContext(ctx, "Page address is not allowed", slog.Any("error", err))
+			return nil, err
+		}
+
+		var redirectErr *RedirectError
+		if errors.As(err, &redirectErr) {
+			logger.ErrorContext(ctx, "Page redirects without end", slog.Any("error", err))
+			return nil, err
+		}
+
+		if err == nil && conditional && data.StatusCode == http.StatusNotModified {
+			logger.InfoContext(ctx, "Page not modified since previous analysis", slog.Int("attempt", attempt))
+			return data, nil
+		}
+
+		if err == nil && data.StatusCode >= 200 && data.StatusCode < 300 {
+			if err := checkHTMLContentType(data); err != nil {
+				data.Body.Close()
+				logger.ErrorContext(ctx, "Page is not an HTML document", slog.Any("error", err))
+				return nil, err
+			}
+			logger.InfoContext(
+				ctx,
+				"Successfully fetched page",
+				slog.Int("status_code", data.StatusCode),
+				slog.Int("attempt", attempt),
+			)
+			return data, nil
+		}
+
+		if data != nil {
+			data.Body.Close()
+		}
+
+		if i < policy.attempts()-1 {
+			backoffDuration := policy.backoff(i)
+			var statusCode int
+			if data != nil {
+				statusCode = data.StatusCode
+				backoffDuration = retryDelay(data, backoffDuration)
+			}
+
+			logger.WarnContext(
+				ctx,
+				"Fetch attempt failed, retrying...",
+				slog.Int("attempt", attempt),
+				slog.Any("error", err),
+				slog.Int("status_code", statusCode),
+				slog.Duration("backoff_duration", backoffDuration),
+			)
+			if sleepErr := sleepContext(ctx, backoffDuration); sleepErr != nil {
+				logger.WarnContext(ctx, "Page fetch canceled during backoff", slog.Any("error", sleepErr))
+				return nil, classifyFetchError(sleepErr)
+			}
+			continue
+		}
+	}
+
+	if data != nil && err == nil {
+		issue := &HTTPStatusError{Code: data.StatusCode}
+		logger.ErrorContext(
+			ctx,
+			"Failed to fetch page after all attempts",
+			slog.Int("max_retries", policy.attempts()),
+			slog.Any("last_error", issue),
+		)
+		return nil, issue
+	} else {
+		logger.ErrorContext(
+			ctx,
+			"Failed to fetch page after all attempts",
+			slog.Int("max_retries", policy.attempts()),
+			slog.Any("last_error", err),
+		)
+		return nil, classifyFetchError(err)
+	}
+}
+
+// readPageBody reads the page body into memory, failing with ErrPageTooLarge instead of
+// buffering more than limit bytes. A non-positive limit reads the whole body.
+func readPageBody(resp *http.Response, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrPageTooLarge, resp.ContentLength, limit)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: body exceeds the %d byte limit", ErrPageTooLarge, limit)
+	}
+	return body, nil
+}
+
+// openPageBody prepares the page body for analysis. Bodies up to threshold bytes are read
+// into memory; larger ones (by Content-Length, or once more than threshold bytes have been
+// read) are returned as a stream instead, still capped at limit bytes. A non-positive
+// threshold always reads the body into memory.
+func openPageBody(resp *http.Response, threshold, limit int64) ([]byte, io.Reader, error) {
+	if threshold <= 0 || (limit > 0 && limit <= threshold) {
+		body, err := readPageBody(resp, limit)
+		return body, nil, err
+	}
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, nil, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrPageTooLarge, resp.ContentLength, limit)
+	}
+	if resp.ContentLength > threshold {
+		return nil, capReader(resp.Body, limit), nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, threshold+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(head)) <= threshold {
+		return head, nil, nil
+	}
+	return nil, capReader(io.MultiReader(bytes.NewReader(head), resp.Body), limit), nil
+}
+
+// maxBytesReader fails with ErrPageTooLarge once more than limit bytes have been read, and
+// on every Read after that.
+type maxBytesReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+	err   error
+}
+
+// capReader limits r to limit bytes; a non-positive limit returns r unchanged.
+func capReader(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &maxBytesReader{r: io.LimitReader(r, limit+1), limit: limit}
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	if m.read > m.limit {
+		m.err = fmt.Errorf("%w: body exceeds the %d byte limit", ErrPageTooLarge, m.limit)
+		return n - int(m.read-m.limit), m.err
+	}
+	return n, err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newPageRequest builds the GET request for the analyzed page with the configured headers,
+// made conditional on the validators of opts.Revalidate when there are any.
+func newPageRequest(ctx context.Context, pageURL string, opts Options) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = opts.requestHeader()
+	authSessionFrom(ctx).authorize(req)
+	if previous := opts.Revalidate; previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+	return req, nil
+}
+
+// Reasons a link can be left unchecked, used as keys of linkCheckReport.NotChecked.
+const (
+	notCheckedRobots      = "robots"
+	notCheckedCircuitOpen = "circuit_open"
+	notCheckedBlocked     = "blocked"
+)
+
+// linkCheckState is shared by every worker of a single link validation run.
+type linkCheckState struct {
+	retry    RetryPolicy
+	header   http.Header
+	cacheTTL time.Duration
+	throttle *hostThrottle
+	breaker  *hostBreaker
+	robots   *robotsCache
+	progress *progressReporter
+
+	mu         sync.Mutex
+	notChecked map[string][]string
+	outcomes   map[string]linkOutcome
+}
+
+// linkOutcome is what checking a single link found: the last status code received, if any,
+// why the link counts as inaccessible, if it does, and the URLs it redirected to.
+type linkOutcome struct {
+	statusCode int
+	err        string
+	redirects  []string
+}
+
+func (o linkOutcome) accessible() bool {
+	return o.err == ""
+}
+
+func newLinkCheckState(opts Options) *linkCheckState {
+	state := &linkCheckState{
+		retry:      opts.Retry,
+		header:     opts.requestHeader(),
+		cacheTTL:   opts.LinkCacheTTL,
+		throttle:   newHostThrottle(opts.PerHostDelay, opts.PerHostConcurrency),
+		breaker:    newHostBreaker(opts.CircuitBreakerThreshold),
+		progress:   newProgressReporter(opts.Progress),
+		notChecked: make(map[string][]string),
+		outcomes:   make(map[string]linkOutcome),
+	}
+	if opts.BasicAuth != nil || opts.Cookie != "" || len(opts.Cookies) > 0 {
+		// Authenticated outcomes say nothing about what anonymous analyses would see.
+		state.cacheTTL = 0
+	}
+	if opts.RespectRobots {
+		state.robots = newRobotsCache()
+	}
+	return state
+}
+
+// skip records that link was deliberately not checked for the given reason.
+func (s *linkCheckState) skip(link, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notChecked[reason] = append(s.notChecked[reason], link)
+}
+
+// record stores the outcome of checking link for the report.
+func (s *linkCheckState) record(link string, outcome linkOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcomes[link] = outcome
+}
+
+// spanAttributes describes what checking link found, for its trace span.
+func (s *linkCheckState) spanAttributes(link string) []attribute.KeyValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	outcome, ok := s.outcomes[link]
+	if !ok {
+		return []attribute.KeyValue{attribute.Bool("link.checked", false)}
+	}
+	attrs := []attribute.KeyValue{attribute.Bool("link.checked", true), attribute.Bool("link.accessible", outcome.accessible())}
+	if outcome.statusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", outcome.statusCode))
+	}
+	return attrs
+}
+
+// cached returns the outcome of a recent check of link from the shared link cache.
+func (s *linkCheckState) cached(link string) (outcome linkOutcome, ok bool) {
+	if s.cacheTTL <= 0 {
+		return linkOutcome{}, false
+	}
+	return linkCache.get(link)
+}
+
+// remember stores the outcome of checking link in the shared link cache.
+func (s *linkCheckState) remember(link string, outcome linkOutcome) {
+	if s.cacheTTL > 0 {
+		linkCache.put(link, outcome, s.cacheTTL)
+	}
+}
+
+// linkCheckReport is the outcome of a link validation run.
+type linkCheckReport struct {
+	Inaccessible []string
+	NotChecked   map[string][]string
+	Outcomes     map[string]linkOutcome
+}
+
+func linkAccessibilityChecker(ctx context.Context, logger *slog.Logger, state *linkCheckState, url string, inaccessibleLinks chan<- string) {
+	logger = logger.With(slog.String("url", url))
+	logger.DebugContext(ctx, "Starting link check")
+
+	if state.robots != nil {
+		if !state.robots.allows(ctx, logger, url) {
+			logger.InfoContext(ctx, "Link disallowed by robots.txt, not checking")
+			state.skip(url, notCheckedRobots)
+			return
+		}
+	}
+
+	if outcome, ok := state.cached(url); ok {
+		logger.DebugContext(ctx, "Using cached link status", slog.Bool("accessible", outcome.accessible()))
+		state.record(url, outcome)
+		if !outcome.accessible() {
+			inaccessibleLinks <- url
+		}
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Could not create HTTP request", slog.Any("error", err))
+		state.record(url, linkOutcome{err: err.Error()})
+		inaccessibleLinks <- url
+		return
+	}
+	if state.header != nil {
+		req.Header = state.header.Clone()
+	}
+	authSessionFrom(ctx).authorize(req)
+	host := canonicalHost(req.URL)
+
+	// hostFailure tracks whether the last attempt failed because of the host itself
+	// (connection error or 5xx) rather than the individual link (e.g. a 404).
+	hostFailure := false
+	// lastStatus, lastErr and lastRedirects describe the last failed attempt, for the link's
+	// outcome.
+	var lastStatus int
+	var lastErr string
+	var lastRedirects []string
+
+	policy := state.retry
+	for i := 0; i < policy.attempts(); i++ {
+		attempt := i + 1
+		lastAttempt := attempt == policy.attempts()
+		backoff := policy.backoff(i)
+
+		release, err := state.throttle.acquire(ctx, host)
+		if err != nil {
+			logger.WarnContext(ctx, "Link check abandoned while waiting for host slot", slog.Any("error", err))
+			state.record(url, linkOutcome{err: err.Error()})
+			inaccessibleLinks <- url
+			return
+		}
+
+		if state.breaker.isOpen(host) {
+			release()
+			logger.InfoContext(ctx, "Circuit open for host after repeated failures, not checking", slog.String("host", host))
+			state.skip(url, notCheckedCircuitOpen)
+			return
+		}
+
+		resp, err := requestLink(ctx, logger, req)
+		release()
+
+		if errors.Is(err, ErrBlockedAddress) {
+			logger.InfoContext(ctx, "Link points to a blocked address, not checking", slog.Any("error", err))
+			state.skip(url, notCheckedBlocked)
+			return
+		}
+
+		// Following the redirects again would end the same way.
+		var redirectErr *RedirectError
+		if errors.As(err, &redirectErr) {
+			logger.WarnContext(ctx, "Link redirects without end", slog.Any("error", err))
+			hostFailure = false
+			lastStatus, lastErr, lastRedirects = 0, err.Error(), redirectErr.Redirects
+			break
+		}
+
+		if err != nil {
+			hostFailure = true
+			lastStatus, lastErr, lastRedirects = 0, err.Error(), nil
+			logger.WarnContext(ctx, "Connection error on attempt, retrying...",
+				slog.Int("attempt", attempt),
+				slog.Any("error", err),
+				slog.Duration("backoff_duration", backoff),
+			)
+			if lastAttempt {
+				break
+			}
+			if sleepErr := sleepContext(ctx, backoff); sleepErr != nil {
+				logger.WarnContext(ctx, "Link check canceled during backoff", slog.Any("error", sleepErr))
+				break
+			}
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			logger.InfoContext(ctx, "Link is accessible", slog.Int("status_code", resp.StatusCode))
+			discardBody(resp)
+			state.breaker.recordSuccess(host)
+			outcome := linkOutcome{statusCode: resp.StatusCode, redirects: redirectsOf(resp)}
+			state.remember(url, outcome)
+			state.record(url, outcome)
+			return
+		}
+
+		hostFailure = resp.StatusCode >= 500
+		lastStatus, lastErr, lastRedirects = resp.StatusCode, resp.Status, redirectsOf(resp)
+		wait := retryDelay(resp, backoff)
+		logger.WarnContext(ctx, "Received non-success status, retrying...",
+			slog.Int("attempt", attempt),
+			slog.Int("status_code", resp.StatusCode),
+			slog.String("status_text", resp.Status),
+			slog.Duration("backoff_duration", wait),
+		)
+		discardBody(resp)
+
+		if lastAttempt {
+			break
+		}
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			logger.WarnContext(ctx, "Link check canceled during backoff", slog.Any("error", sleepErr))
+			break
+		}
+	}
+
+	if hostFailure {
+		state.breaker.recordFailure(host)
+	} else {
+		state.breaker.recordSuccess(host)
+	}
+
+	outcome := linkOutcome{statusCode: lastStatus, err: lastErr, redirects: lastRedirects}
+	// A canceled analysis says nothing about the link itself, so only real failures are cached.
+	if ctx.Err() == nil {
+		state.remember(url, outcome)
+	}
+
+	logger.ErrorContext(ctx, "Link is inaccessible after all retries", slog.Int("max_retries", policy.attempts()))
+	state.record(url, outcome)
+	inaccessibleLinks <- url
+}
+
+// retryDelay returns how long to wait before retrying after resp. Rate-limited (429) and
+// unavailable (503) responses carrying a Retry-After header are honored, capped at
+// maxRetryAfter; everything else uses the caller's exponential backoff.
+func retryDelay(resp *http.Response, backoff time.Duration) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return backoff
+	}
+
+	header := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if header == "" {
+		return backoff
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+	} else {
+		return backoff
+	}
+
+	return min(max(wait, 0), maxRetryAfter)
+}
+
+// requestLink sends a HEAD request to avoid downloading the body of every checked link,
+// falling back to GET for servers that answer HEAD with 405 or 501.
+func requestLink(ctx context.Context, logger *slog.Logger, req *http.Request) (*http.Response, error) {
+	client := authSessionFrom(ctx).linkClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+		return resp, nil
+	}
+	discardBody(resp)
+
+	logger.DebugContext(ctx, "HEAD not supported, falling back to GET", slog.Int("status_code", resp.StatusCode))
+
+	getReq := req.Clone(ctx)
+	getReq.Method = http.MethodGet
+	return client.Do(getReq)
+}
+
+// discardBody reads at most maxDrainBytes of a link check response and closes it.
+// Only the status line matters for link checks, so full bodies are never downloaded.
+func discardBody(resp *http.Response) {
+	io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	resp.Body.Close()
+}
+
+func linkAccessibilityCheckWorker(ctx context.Context, logger *slog.Logger, state *linkCheckState, wg *sync.WaitGroup, jobs <-chan string, inaccessibleLinks chan<- string) {
+	defer wg.Done()
+	ctx, span := tracer.Start(ctx, "linkAccessibilityCheckWorker")
+	defer span.End()
+
+	checked := 0
+	for url := range jobs {
+		// Once the analysis is canceled, the remaining queued links are dropped unchecked.
+		if ctx.Err() != nil {
+			continue
+		}
+		linkCtx, linkSpan := tracer.Start(ctx, "linkAccessibilityChecker", trace.WithAttributes(attribute.String("url.full", url)))
+		linkAccessibilityChecker(linkCtx, logger, state, url, inaccessibleLinks)
+		linkSpan.SetAttributes(state.spanAttributes(url)...)
+		linkSpan.End()
+		state.progress.linkChecked()
+		checked++
+	}
+	span.SetAttributes(attribute.Int("links.checked", checked))
+}
+
+func validateLinkAccessibility(ctx context.Context, logger *slog.Logger, analysis LinkAnalysis, opts Options) (_ linkCheckReport, err error) {
+	ctx, span := tracer.Start(ctx, "validateLinkAccessibility")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Setting up link check process")
+
+	pageLinks := interleaveByHost(uniqueLinks(analysis.InternalLinks, analysis.ExternalLinks, analysis.InternalIframes, analysis.ExternalIframes, analysis.CSSResources, analysis.MediaSources))
+	if len(pageLinks) == 0 {
+		logger.InfoContext(ctx, "No links to check, skipping process.")
+		return linkCheckReport{}, nil
+	}
+
+	totalLinks := len(pageLinks)
+	span.SetAttributes(attribute.Int("links.total", totalLinks))
+	logger.InfoContext(ctx, "Starting to check links", slog.Int("total_links", totalLinks))
+
+	jobs := make(chan string, totalLinks)
+	inaccessibleLinks := make(chan string, totalLinks)
+
+	state := newLinkCheckState(opts)
+	state.progress.start(totalLinks)
+	var wg sync.WaitGroup
+
+	// Prevent creating unnecessary additional workers
+	workers := min(BoundedWorkers(opts.Workers), totalLinks)
+	logger.DebugContext(ctx, "Starting link check workers", slog.Int("workers", workers))
+	for w := 1; w <= workers; w++ {
+		wg.Add(1)
+		go linkAccessibilityCheckWorker(ctx, logger, state, &wg, jobs, inaccessibleLinks)
+	}
+
+	for _, link := range pageLinks {
+		jobs <- link
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(inaccessibleLinks)
+	}()
+
+	var failedLinks []string
+	for link := range inaccessibleLinks {
+		failedLinks = append(failedLinks, link)
+	}
+
+	if err := ctx.Err(); err != nil {
+		logger.WarnContext(ctx, "Link check canceled", slog.Any("error", err))
+		return linkCheckReport{}, err
+	}
+
+	logger.InfoContext(ctx, "Finished checking all links",
+		slog.Int("total_links_checked", totalLinks),
+		slog.Int("inaccessible_links_found", len(failedLinks)),
+		slog.Any("not_checked", state.notChecked),
+	)
+
+	dnsHits, dnsMisses := resolverCache.stats()
+	logger.DebugContext(ctx, "DNS cache statistics",
+		slog.Int64("hits", dnsHits),
+		slog.Int64("misses", dnsMisses),
+	)
+
+	return linkCheckReport{Inaccessible: failedLinks, NotChecked: state.notChecked, Outcomes: state.outcomes}, nil
+}
+
+// linkResults lists every link of analysis with what the link check found for it.
+func linkResults(analysis LinkAnalysis, report linkCheckReport, baseURL *url.URL) []LinkResult {
+	notChecked := make(map[string]string)
+	for reason, links := range report.NotChecked {
+		for _, link := range links {
+			notChecked[link] = reason
+		}
+	}
+
+	var results []LinkResult
+	add := func(link, linkType, kind string) {
+		result := LinkResult{URL: link, Type: linkType, Kind: kind, Status: LinkStatusNotChecked}
+		if kind == LinkKindLink {
+			result.AnchorText = analysis.AnchorTexts[link]
+		}
+		if reason, ok := notChecked[link]; ok {
+			result.Error = reason
+		} else if outcome, ok := report.Outcomes[link]; ok {
+			result.StatusCode, result.Error, result.Redirects = outcome.statusCode, outcome.err, outcome.redirects
+			result.Status = LinkStatusOK
+			if outcome.err != "" {
+				result.Status = LinkStatusInaccessible
+			}
+		}
+		results = append(results, result)
+	}
+
+	for _, link := range analysis.InternalLinks {
+		add(link, LinkTypeInternal, LinkKindLink)
+	}
+	for _, link := range analysis.ExternalLinks {
+		add(link, LinkTypeExternal, LinkKindLink)
+	}
+	for _, link := range analysis.InternalIframes {
+		add(link, LinkTypeInternal, LinkKindIframe)
+	}
+	for _, link := range analysis.ExternalIframes {
+		add(link, LinkTypeExternal, LinkKindIframe)
+	}
+	// The DOM and streaming paths collect CSS resources in different orders, so sort them.
+	for _, link := range slices.Sorted(slices.Values(analysis.CSSResources)) {
+		linkType := LinkTypeExternal
+		if u, err := url.Parse(link); err == nil && isSameHost(u, baseURL) {
+			linkType = LinkTypeInternal
+		}
+		add(link, linkType, LinkKindCSS)
+	}
+	for _, link := range analysis.MediaSources {
+		linkType := LinkTypeExternal
+		if u, err := url.Parse(link); err == nil && isSameHost(u, baseURL) {
+			linkType = LinkTypeInternal
+		}
+		add(link, linkType, LinkKindMedia)
+	}
+	return results
+}
+
+// uniqueLinks flattens the given link lists, dropping URLs that appear more than once
+// (e.g. a page linked from an anchor and embedded in an iframe) so each is checked only once.
+func uniqueLinks(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var links []string
+	for _, list := range lists {
+		for _, link := range list {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// interleaveByHost reorders links round-robin across their hosts, so the worker pool spreads
+// over many hosts instead of queueing up behind the per-host limit of the first one.
+func interleaveByHost(links []string) []string {
+	var hosts []string
+	byHost := make(map[string][]string)
+	for _, link := range links {
+		host := link
+		if u, err := url.Parse(link); err == nil {
+			host = canonicalHost(u)
+		}
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], link)
+	}
+
+	interleaved := make([]string, 0, len(links))
+	for len(interleaved) < len(links) {
+		for _, host := range hosts {
+			if queue := byHost[host]; len(queue) > 0 {
+				interleaved = append(interleaved, queue[0])
+				byHost[host] = queue[1:]
+			}
+		}
+	}
+	return interleaved
+}
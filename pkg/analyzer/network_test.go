@@ -0,0 +1,771 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testLogger = slog.New(slog.NewTextHandler(&testWriter{}, nil))
+)
+
+type testWriter struct{}
+
+func (tw *testWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func TestLoadWebPage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("Success on first attempt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "Hello, client")
+		}))
+		defer server.Close()
+
+		resp, err := loadWebPage(context.Background(), logger, server.URL, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		if resp == nil {
+			t.Fatal("Expected a response, but got nil")
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status code %d, but got %d", http.StatusOK, resp.StatusCode)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), "Hello, client") {
+			t.Errorf("Response body did not contain expected text. Got: %s", string(body))
+		}
+	})
+
+	t.Run("Honors Retry-After on 503", func(t *testing.T) {
+		var requestCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requestCount, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		start := time.Now()
+		resp, err := loadWebPage(context.Background(), logger, server.URL, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if elapsed := time.Since(start); elapsed >= initialBackoff {
+			t.Errorf("Expected Retry-After: 0 to skip the %v backoff, but took %v", initialBackoff, elapsed)
+		}
+	})
+
+	t.Run("Sends validators and accepts 304", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		opts := DefaultOptions()
+		opts.Revalidate = &AnalysisResult{ETag: `"v1"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}
+		resp, err := loadWebPage(context.Background(), logger, server.URL, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("Expected status code %d, but got %d", http.StatusNotModified, resp.StatusCode)
+		}
+	})
+
+	t.Run("Unconditional 304 is a failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotModified)
+		}))
+		defer server.Close()
+
+		opts := DefaultOptions()
+		opts.Retry.MaxRetries = 0
+		if _, err := loadWebPage(context.Background(), logger, server.URL, opts); err == nil {
+			t.Error("Expected an error for a 304 to an unconditional request, but got nil")
+		}
+	})
+}
+
+func TestLinkAccessibilityChecker_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		t.Errorf("Expected no inaccessible links, but got %s", link)
+	default:
+		// Test passes
+	}
+}
+
+func TestLinkAccessibilityChecker_FailureAfterRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		if link != server.URL {
+			t.Errorf("Expected link %s, but got %s", server.URL, link)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected to receive an inaccessible link, but got none")
+	}
+}
+
+func TestLinkAccessibilityChecker_SuccessAfterOneRetry(t *testing.T) {
+	var requestCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		t.Errorf("Expected no inaccessible links, but got %s", link)
+	default:
+		// Test passes
+	}
+
+	if atomic.LoadInt32(&requestCount) != 2 {
+		t.Errorf("Expected 2 requests, but got %d", requestCount)
+	}
+}
+
+func TestLinkAccessibilityChecker_UsesHead(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		t.Errorf("Expected no inaccessible links, but got %s", link)
+	default:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("Expected a single HEAD request, but got %v", methods)
+	}
+}
+
+func TestLinkAccessibilityChecker_FallsBackToGet(t *testing.T) {
+	for _, status := range []int{http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var mu sync.Mutex
+			var methods []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				methods = append(methods, r.Method)
+				mu.Unlock()
+				if r.Method == http.MethodHead {
+					w.WriteHeader(status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			inaccessibleLinks := make(chan string, 1)
+
+			linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+			select {
+			case link := <-inaccessibleLinks:
+				t.Errorf("Expected no inaccessible links, but got %s", link)
+			default:
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			want := []string{http.MethodHead, http.MethodGet}
+			if len(methods) != len(want) || methods[0] != want[0] || methods[1] != want[1] {
+				t.Errorf("Expected requests %v, but got %v", want, methods)
+			}
+		})
+	}
+}
+
+type countingBody struct {
+	read   int
+	closed bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	b.read += len(p)
+	return len(p), nil
+}
+
+func (b *countingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDiscardBody(t *testing.T) {
+	body := &countingBody{}
+
+	discardBody(&http.Response{Body: body})
+
+	if body.read > maxDrainBytes {
+		t.Errorf("Expected at most %d bytes to be read, but read %d", maxDrainBytes, body.read)
+	}
+	if !body.closed {
+		t.Error("Expected the body to be closed")
+	}
+}
+
+func TestLinkAccessibilityChecker_LargeGetBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		chunk := []byte(strings.Repeat("x", 32<<10))
+		for i := 0; i < 1024; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	start := time.Now()
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		t.Errorf("Expected no inaccessible links, but got %s", link)
+	default:
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the check to finish without downloading the body, but it took %v", elapsed)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff := 2 * time.Second
+
+	testCases := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "No Header", status: http.StatusTooManyRequests, want: backoff},
+		{name: "Seconds On 429", status: http.StatusTooManyRequests, retryAfter: "5", want: 5 * time.Second},
+		{name: "Seconds On 503", status: http.StatusServiceUnavailable, retryAfter: "0", want: 0},
+		{name: "Capped", status: http.StatusTooManyRequests, retryAfter: "3600", want: maxRetryAfter},
+		{name: "Date In The Past", status: http.StatusServiceUnavailable, retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0},
+		{name: "Invalid Header", status: http.StatusTooManyRequests, retryAfter: "soon", want: backoff},
+		{name: "Ignored On Other Statuses", status: http.StatusInternalServerError, retryAfter: "5", want: backoff},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			if tc.retryAfter != "" {
+				resp.Header.Set("Retry-After", tc.retryAfter)
+			}
+			if got := retryDelay(resp, backoff); got != tc.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay_FutureDate(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(10*time.Second).UTC().Format(http.TimeFormat))
+
+	got := retryDelay(resp, time.Second)
+	if got < 8*time.Second || got > 10*time.Second {
+		t.Errorf("retryDelay() = %v, want about 10s", got)
+	}
+}
+
+func TestLinkAccessibilityChecker_HonorsRetryAfter(t *testing.T) {
+	var requestCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	start := time.Now()
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		t.Errorf("Expected no inaccessible links, but got %s", link)
+	default:
+	}
+
+	if elapsed := time.Since(start); elapsed >= initialBackoff {
+		t.Errorf("Expected Retry-After: 0 to skip the %v backoff, but took %v", initialBackoff, elapsed)
+	}
+}
+
+func TestLinkAccessibilityChecker_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		if link != server.URL {
+			t.Errorf("Expected link %s, but got %s", server.URL, link)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected to receive an inaccessible link, but got none")
+	}
+}
+
+func TestSetLinkTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	previous := client.Timeout
+	SetLinkTimeout(20 * time.Millisecond)
+	defer SetLinkTimeout(previous)
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 1}
+	state := newLinkCheckState(opts)
+	inaccessibleLinks := make(chan string, 1)
+	linkAccessibilityChecker(context.Background(), testLogger, state, server.URL, inaccessibleLinks)
+
+	if len(inaccessibleLinks) != 1 {
+		t.Fatal("Expected a link slower than the timeout to be inaccessible")
+	}
+	if outcome := state.outcomes[server.URL]; !strings.Contains(outcome.err, "Timeout") {
+		t.Errorf("Expected a timeout error, but got %q", outcome.err)
+	}
+}
+
+func TestLinkAccessibilityChecker_ContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	inaccessibleLinks := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	linkAccessibilityChecker(ctx, testLogger, newLinkCheckState(DefaultOptions()), server.URL, inaccessibleLinks)
+
+	select {
+	case link := <-inaccessibleLinks:
+		if link != server.URL {
+			t.Errorf("Expected link %s, but got %s", server.URL, link)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected to receive an inaccessible link due to context cancellation, but got none")
+	}
+}
+
+func TestValidateLinkAccessibility_AllLinksAccessible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks: []string{server.URL + "/internal1", server.URL + "/internal2"},
+		ExternalLinks: []string{server.URL + "/external1"},
+	}
+
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	failedLinks := report.Inaccessible
+
+	if len(failedLinks) != 0 {
+		t.Errorf("Expected 0 failed links, but got %d: %v", len(failedLinks), failedLinks)
+	}
+}
+
+func TestValidateLinkAccessibility_SomeLinksInaccessible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks: []string{server.URL + "/ok", server.URL + "/fail"},
+		ExternalLinks: []string{server.URL + "/another-ok"},
+	}
+
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	failedLinks := report.Inaccessible
+
+	if len(failedLinks) != 1 {
+		t.Fatalf("Expected 1 failed link, but got %d", len(failedLinks))
+	}
+
+	expectedFailedLink := server.URL + "/fail"
+	if failedLinks[0] != expectedFailedLink {
+		t.Errorf("Expected failed link to be %s, but got %s", expectedFailedLink, failedLinks[0])
+	}
+}
+
+func TestValidateLinkAccessibility_IncludesIframesOnce(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks:   []string{server.URL + "/page"},
+		ExternalLinks:   []string{},
+		InternalIframes: []string{server.URL + "/embed", server.URL + "/page"},
+		ExternalIframes: []string{server.URL + "/widget"},
+	}
+
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	failedLinks := report.Inaccessible
+	if len(failedLinks) != 0 {
+		t.Errorf("Expected 0 failed links, but got %d: %v", len(failedLinks), failedLinks)
+	}
+
+	want := map[string]int{"/page": 1, "/embed": 1, "/widget": 1}
+	mu.Lock()
+	defer mu.Unlock()
+	for path, count := range want {
+		if requests[path] != count {
+			t.Errorf("Expected %d request(s) to %s, but got %d", count, path, requests[path])
+		}
+	}
+}
+
+func TestValidateLinkAccessibility_NoLinks(t *testing.T) {
+	analysis := LinkAnalysis{
+		InternalLinks: []string{},
+		ExternalLinks: []string{},
+	}
+
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	failedLinks := report.Inaccessible
+
+	if len(failedLinks) != 0 {
+		t.Errorf("Expected 0 failed links for empty input, but got %d", len(failedLinks))
+	}
+}
+
+func TestWorker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	jobs := make(chan string, 2)
+	inaccessibleLinks := make(chan string, 2)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go linkAccessibilityCheckWorker(context.Background(), testLogger, newLinkCheckState(DefaultOptions()), &wg, jobs, inaccessibleLinks)
+
+	jobs <- server.URL + "/good"
+	jobs <- server.URL + "/bad"
+	close(jobs)
+
+	wg.Wait()
+	close(inaccessibleLinks)
+
+	var failedLinks []string
+	for link := range inaccessibleLinks {
+		failedLinks = append(failedLinks, link)
+	}
+
+	if len(failedLinks) != 1 {
+		t.Fatalf("Expected 1 failed link, but got %d", len(failedLinks))
+	}
+	if failedLinks[0] != server.URL+"/bad" {
+		t.Errorf("Expected failed link to be %s, but got %s", server.URL+"/bad", failedLinks[0])
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	links := []string{
+		"https://a.example/1",
+		"https://a.example/2",
+		"https://a.example/3",
+		"https://b.example/1",
+		"https://c.example/1",
+		"https://b.example/2",
+	}
+
+	got := interleaveByHost(links)
+	want := []string{
+		"https://a.example/1",
+		"https://b.example/1",
+		"https://c.example/1",
+		"https://a.example/2",
+		"https://b.example/2",
+		"https://a.example/3",
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("interleaveByHost() = %v, want %v", got, want)
+	}
+}
+
+func TestBoundedWorkers(t *testing.T) {
+	testCases := []struct {
+		input int
+		want  int
+	}{
+		{input: -5, want: MinWorkers},
+		{input: 0, want: MinWorkers},
+		{input: 1, want: 1},
+		{input: 25, want: 25},
+		{input: 10000, want: MaxWorkers},
+	}
+
+	for _, tc := range testCases {
+		if got := BoundedWorkers(tc.input); got != tc.want {
+			t.Errorf("BoundedWorkers(%d) = %d, want %d", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestValidateLinkAccessibility_SingleWorker(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks: []string{server.URL + "/1", server.URL + "/2", server.URL + "/3", server.URL + "/4"},
+	}
+
+	opts := DefaultOptions()
+	opts.Workers = 1
+	opts.PerHostConcurrency = 0
+
+	if _, err := validateLinkAccessibility(context.Background(), testLogger, analysis, opts); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&maxInFlight); n != 1 {
+		t.Errorf("Expected a single worker to check links one at a time, but saw %d concurrent requests", n)
+	}
+}
+
+func TestReadPageBody(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		contentLength int64
+		limit         int64
+		expectTooBig  bool
+	}{
+		{"Within limit", "hello", -1, 10, false},
+		{"Exactly at limit", "0123456789", -1, 10, false},
+		{"Body over limit", "0123456789a", -1, 10, true},
+		{"Declared length over limit", "short", 1 << 30, 10, true},
+		{"No limit", strings.Repeat("x", 100), -1, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Body: io.NopCloser(strings.NewReader(tc.body)), ContentLength: tc.contentLength}
+
+			body, err := readPageBody(resp, tc.limit)
+			if tc.expectTooBig {
+				if !errors.Is(err, ErrPageTooLarge) {
+					t.Errorf("Expected ErrPageTooLarge, but got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if string(body) != tc.body {
+				t.Errorf("Expected body %q, but got %q", tc.body, body)
+			}
+		})
+	}
+}
+
+func TestCapReader_ReadsPastLimit(t *testing.T) {
+	r := capReader(strings.NewReader("0123456789abc"), 10)
+	buf := make([]byte, 4)
+	total := 0
+	for i := 0; i < 6; i++ {
+		n, err := r.Read(buf)
+		if n < 0 || n > len(buf) {
+			t.Fatalf("Expected a byte count between 0 and %d on read %d, but got %d", len(buf), i+1, n)
+		}
+		total += n
+		if total > 10 {
+			t.Fatalf("Expected at most 10 bytes, but got %d", total)
+		}
+		if i >= 3 && (n != 0 || !errors.Is(err, ErrPageTooLarge)) {
+			t.Errorf("Expected 0 bytes and ErrPageTooLarge on read %d past the limit, but got %d and %v", i+1, n, err)
+		}
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]http.Header)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Clone()
+		mu.Unlock()
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.UserAgent = "custom-agent/2.0"
+	opts.Headers = http.Header{"X-Test": {"yes"}, "User-Agent": {"overridden"}}
+
+	resp, err := loadWebPage(context.Background(), testLogger, server.URL+"/page", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	resp.Body.Close()
+
+	inaccessible := make(chan string, 1)
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(opts), server.URL+"/link", inaccessible)
+	close(inaccessible)
+
+	for _, key := range []string{"GET /page", "HEAD /link", "GET /link"} {
+		header, ok := seen[key]
+		if !ok {
+			t.Errorf("Expected a %s request, but got none", key)
+			continue
+		}
+		if got := header.Get("User-Agent"); got != "custom-agent/2.0" {
+			t.Errorf("Expected %s to send User-Agent %q, but got %q", key, "custom-agent/2.0", got)
+		}
+		if got := header.Get("X-Test"); got != "yes" {
+			t.Errorf("Expected %s to send X-Test, but got %q", key, got)
+		}
+	}
+	if opts.Headers.Get("User-Agent") != "overridden" {
+		t.Error("Expected the caller's header map to be left untouched")
+	}
+}
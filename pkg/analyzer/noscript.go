@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Noscript sums up the <noscript> blocks of a page, the fallback content that crawlers and
+// visitors without JavaScript see in place of its scripts.
+type Noscript struct {
+	// Count is the number of blocks and Bytes the size of their markup.
+	Count int `json:"count" xml:"count,attr"`
+	Bytes int `json:"bytes" xml:"bytes,attr"`
+	// Links counts the links and iframes in the blocks. They are part of the page's links
+	// and link results.
+	Links int `json:"links" xml:"links,attr"`
+	// Images lists the images in the blocks, often tracking pixels, as absolute URLs.
+	Images []string `json:"images,omitempty" xml:"image,omitempty"`
+}
+
+// noscriptContent is what the <noscript> blocks of a page hold. Browsers running scripts, and
+// so the HTML parser, take the blocks as text, so they are parsed on their own.
+type noscriptContent struct {
+	hrefs, hrefTexts, frameSrcs, imageSrcs []string
+}
+
+// findNoscript returns the markup of the <noscript> blocks of the document.
+func findNoscript(doc *goquery.Document) []string {
+	var blocks []string
+	doc.Find("noscript").Each(func(i int, s *goquery.Selection) {
+		blocks = append(blocks, s.Text())
+	})
+	return blocks
+}
+
+// parseNoscript collects the links, iframes and images of the <noscript> blocks, in order.
+func parseNoscript(blocks []string) noscriptContent {
+	var content noscriptContent
+	for _, block := range blocks {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(block))
+		if err != nil {
+			continue
+		}
+		doc.Find("a[href], iframe[src], img[src]").Each(func(i int, s *goquery.Selection) {
+			switch goquery.NodeName(s) {
+			case "a":
+				content.hrefs = append(content.hrefs, s.AttrOr("href", ""))
+				content.hrefTexts = append(content.hrefTexts, s.Text())
+			case "iframe":
+				content.frameSrcs = append(content.frameSrcs, s.AttrOr("src", ""))
+			case "img":
+				content.imageSrcs = append(content.imageSrcs, s.AttrOr("src", ""))
+			}
+		})
+	}
+	return content
+}
+
+// summarizeNoscript returns the summary of the <noscript> blocks, or nil if there are none.
+// Image srcs that do not resolve to a URL are left out.
+func summarizeNoscript(blocks []string, resolveBase *url.URL, opts NormalizeOptions) *Noscript {
+	if len(blocks) == 0 {
+		return nil
+	}
+	content := parseNoscript(blocks)
+	noscript := &Noscript{Count: len(blocks), Links: len(content.hrefs) + len(content.frameSrcs)}
+	seen := make(map[string]bool)
+	for _, block := range blocks {
+		noscript.Bytes += len(block)
+	}
+	for _, src := range content.imageSrcs {
+		src = strings.TrimSpace(src)
+		if _, skipped := skippedLinkCategory(src); skipped {
+			continue
+		}
+		image, err := resolveLink(src, resolveBase, opts)
+		if err != nil || seen[image.String()] {
+			continue
+		}
+		seen[image.String()] = true
+		noscript.Images = append(noscript.Images, image.String())
+	}
+	return noscript
+}
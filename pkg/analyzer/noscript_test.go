@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeNoscript(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/shop/")
+	blocks := []string{
+		`<img height="1" width="1" src="https://tracker.example/tr?id=1&amp;ev=PageView">`,
+		`<p>Please enable JavaScript, or use the <a href="basic">basic shop</a>.</p><img src="basic.png"><img src="data:image/gif;base64,R0lGOD"><img src="/shop/basic.png">`,
+	}
+	want := &Noscript{
+		Count:  2,
+		Bytes:  len(blocks[0]) + len(blocks[1]),
+		Links:  1,
+		Images: []string{"https://tracker.example/tr?id=1&ev=PageView", "https://example.com/shop/basic.png"},
+	}
+	if got := summarizeNoscript(blocks, pageURL, NormalizeOptions{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+	if got := summarizeNoscript(nil, pageURL, NormalizeOptions{}); got != nil {
+		t.Errorf("Expected no summary without blocks, but got %+v", got)
+	}
+}
+
+func TestParseNoscript(t *testing.T) {
+	content := parseNoscript([]string{`<a href="/a"> A </a><iframe src="/frame"></iframe>`, `<a href="/b">B</a>`})
+	if want := []string{"/a", "/b"}; !reflect.DeepEqual(content.hrefs, want) {
+		t.Errorf("Expected hrefs %q, but got %q", want, content.hrefs)
+	}
+	if want := []string{" A ", "B"}; !reflect.DeepEqual(content.hrefTexts, want) {
+		t.Errorf("Expected texts %q, but got %q", want, content.hrefTexts)
+	}
+	if want := []string{"/frame"}; !reflect.DeepEqual(content.frameSrcs, want) {
+		t.Errorf("Expected frames %q, but got %q", want, content.frameSrcs)
+	}
+}
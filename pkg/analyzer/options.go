@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Bounds applied to Options.Workers.
+const (
+	MinWorkers = 1
+	MaxWorkers = 100
+)
+
+// Options tunes how a page is analyzed. The zero value is usable, but callers should
+// start from DefaultOptions so new settings pick up sensible defaults.
+type Options struct {
+	Normalize NormalizeOptions
+	Retry     RetryPolicy
+
+	// Workers is the size of the link check worker pool, clamped to [MinWorkers, MaxWorkers].
+	Workers int
+
+	// PerHostDelay is the minimum gap between the starts of two link checks against the same host.
+	PerHostDelay time.Duration
+	// PerHostConcurrency caps in-flight link checks per host; zero or less disables the cap.
+	PerHostConcurrency int
+	// CircuitBreakerThreshold is the number of consecutive host-level failures (connection
+	// errors or 5xx) after which the remaining links to that host are skipped; zero disables it.
+	CircuitBreakerThreshold int
+
+	// LinkCacheTTL is how long link check outcomes are reused across analyses; zero disables the cache.
+	LinkCacheTTL time.Duration
+
+	// RespectRobots consults each host's robots.txt and leaves disallowed links unchecked.
+	RespectRobots bool
+
+	// MaxPageBytes caps the size of the analyzed page body; larger pages fail with ErrPageTooLarge.
+	// Zero or less disables the cap.
+	MaxPageBytes int64
+	// StreamingThreshold is the page size above which the page is analyzed in a single
+	// streaming tokenizer pass instead of through a full DOM. Zero or less always builds a DOM.
+	StreamingThreshold int64
+
+	// ValidatorURL is the address of a Nu HTML Checker, such as http://localhost:8888/, that the
+	// page is submitted to for markup validation; empty skips validation. Pages analyzed in
+	// streaming mode are not validated, as their body is not kept.
+	ValidatorURL string
+
+	// RDAPURL is the address of an RDAP service, such as https://rdap.org/, that the registration
+	// of the page's domain is looked up with; empty skips the lookup.
+	RDAPURL string
+
+	// WaybackURL is the address of the Wayback Machine's availability API, such as
+	// https://archive.org/wayback/available, that the latest archived snapshot of the page is
+	// looked up with; empty skips the lookup.
+	WaybackURL string
+	// Snapshot, if set, analyzes the snapshot of the page archived in the Wayback Machine at
+	// WaybackURL instead of the live page: SnapshotLatest for the latest one, or a timestamp such
+	// as "2019" or "20190615120000" for the one closest to it. The result is that of the page's
+	// URL, with the response headers the archive recorded, but its links are checked live.
+	Snapshot string
+
+	// UserAgent is sent with the page fetch and link checks; empty falls back to Go's default.
+	UserAgent string
+	// Headers are extra request headers sent with the page fetch and link checks.
+	Headers http.Header
+
+	// BasicAuth is sent with the page fetch and with link checks to the page's own host.
+	BasicAuth *BasicAuth
+	// Cookie is a Cookie header value ("name=value; other=value") for the page's host. It seeds
+	// a per-analysis cookie jar that also keeps any cookies the site sets during the analysis.
+	Cookie string
+	// Cookies are named cookies for the page's host, e.g. to pick a consent state or an A/B
+	// bucket. Unless they set a Domain, they are never sent to other hosts.
+	Cookies []*http.Cookie
+
+	// Proxy routes this analysis through the given HTTP, HTTPS or SOCKS5 proxy instead of the
+	// server-wide one. Unlike the server-wide proxy it is subject to the destination checks.
+	Proxy *url.URL
+
+	// Revalidate is a previous result for the same page. When it carries validators the page
+	// is fetched conditionally, and a 304 Not Modified answer reuses it instead of re-analyzing.
+	Revalidate *AnalysisResult
+
+	// Checks, if not nil, limits the analysis to the named checks (the Check constants), so
+	// callers that need only part of the result skip the work for the rest; the title is always
+	// read. The sections of other checks are left empty, and so are the score and grade, which
+	// need every check. On pages analyzed in streaming mode every parser runs regardless.
+	Checks []string
+
+	// Progress, if set, is called as the analysis moves through its stages and after each
+	// link check. Calls never overlap, but they come from the analysis' goroutines, so it
+	// should return quickly. It is left out when options are encoded as JSON.
+	Progress func(Progress) `json:"-"`
+
+	// Partial, if set, is called with the result so far once the page is parsed and its
+	// document checks are done, and again as each of the slower checks, such as the link
+	// checks and lookups, completes; not for the last one, whose result AnalyzePage returns.
+	// Calls never overlap. It is left out when options are encoded as JSON.
+	Partial func(PartialResult) `json:"-"`
+}
+
+// DefaultOptions returns the options used when the caller has no specific requirements.
+func DefaultOptions() Options {
+	return Options{
+		Normalize: NormalizeOptions{
+			StripTrailingSlash: false,
+			StripUTMParams:     false,
+		},
+		Retry: RetryPolicy{
+			MaxRetries:     maxRetries,
+			InitialBackoff: initialBackoff,
+			MaxBackoff:     maxBackoff,
+			Jitter:         backoffJitter,
+		},
+		Workers:                 numWorkers,
+		PerHostDelay:            0,
+		PerHostConcurrency:      2,
+		CircuitBreakerThreshold: 3,
+		LinkCacheTTL:            0,
+		RespectRobots:           false,
+		MaxPageBytes:            defaultMaxPageBytes,
+		StreamingThreshold:      defaultStreamingThreshold,
+		UserAgent:               DefaultUserAgent,
+	}
+}
+
+// runs reports whether the analysis runs the named check.
+func (o Options) runs(check string) bool {
+	return o.Checks == nil || slices.Contains(o.Checks, check)
+}
+
+// BoundedWorkers clamps a requested worker pool size to [MinWorkers, MaxWorkers].
+func BoundedWorkers(n int) int {
+	return min(max(n, MinWorkers), MaxWorkers)
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Pagination holds the links of a page to its neighbours in a paginated series, declared
+// with rel="next" and rel="prev" on <link> or <a> elements.
+type Pagination struct {
+	Next string `json:"next,omitempty" xml:"next,omitempty"`
+	Prev string `json:"prev,omitempty" xml:"prev,omitempty"`
+}
+
+// paginationHrefs collects the hrefs of the first rel="next" and rel="prev" links of a page.
+type paginationHrefs struct {
+	next, prev       string
+	hasNext, hasPrev bool
+}
+
+// add records href if rel, the rel attribute of a <link> or <a>, marks it as the first next
+// or previous page. "previous" is accepted for "prev".
+func (h *paginationHrefs) add(rel, href string) {
+	if strings.TrimSpace(href) == "" {
+		return
+	}
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		switch {
+		case token == "next" && !h.hasNext:
+			h.next, h.hasNext = href, true
+		case (token == "prev" || token == "previous") && !h.hasPrev:
+			h.prev, h.hasPrev = href, true
+		}
+	}
+}
+
+// resolve returns the pagination of the page, with the hrefs resolved like its links, or nil
+// if it declares none.
+func (h paginationHrefs) resolve(resolveBase *url.URL, opts NormalizeOptions) *Pagination {
+	var pagination Pagination
+	if u, err := resolveLink(strings.TrimSpace(h.next), resolveBase, opts); h.hasNext && err == nil {
+		pagination.Next = u.String()
+	}
+	if u, err := resolveLink(strings.TrimSpace(h.prev), resolveBase, opts); h.hasPrev && err == nil {
+		pagination.Prev = u.String()
+	}
+	if pagination == (Pagination{}) {
+		return nil
+	}
+	return &pagination
+}
+
+// findPagination returns the rel="next" and rel="prev" links of the document, or nil if it
+// has none.
+func findPagination(doc *goquery.Document, resolveBase *url.URL, opts NormalizeOptions) *Pagination {
+	var hrefs paginationHrefs
+	doc.Find("[rel][href]").Each(func(i int, s *goquery.Selection) {
+		if name := goquery.NodeName(s); name == "link" || name == "a" {
+			hrefs.add(s.AttrOr("rel", ""), s.AttrOr("href", ""))
+		}
+	})
+	return hrefs.resolve(resolveBase, opts)
+}
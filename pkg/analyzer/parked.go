@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Values of Classification.Kind.
+const (
+	// ClassificationParked is a registrar or domain marketplace parking page.
+	ClassificationParked = "parked"
+	// ClassificationServerDefault is the welcome page of a freshly installed web server.
+	ClassificationServerDefault = "server_default"
+)
+
+// Classification marks a page that is a stand-in rather than a site, so its analysis says
+// little about the site that will replace it.
+type Classification struct {
+	// Kind is one of the Classification constants.
+	Kind string `json:"kind" xml:"kind,attr"`
+	// Signature names what the page was recognized as, e.g. "Sedo" or "nginx".
+	Signature string `json:"signature" xml:"signature,attr"`
+}
+
+// pageSignatures recognize parking and server welcome pages by their title and visible text,
+// lower-cased with their whitespace collapsed. The first match wins, so the specific
+// signatures come before the generic ones.
+var pageSignatures = []struct {
+	kind      string
+	signature string
+	pattern   *regexp.Regexp
+}{
+	{ClassificationParked, "GoDaddy", regexp.MustCompile(`parked free,? courtesy of godaddy|this web page is parked free`)},
+	{ClassificationParked, "Sedo", regexp.MustCompile(`sedo domain parking|this domain (?:name )?(?:may be|is) for sale.{0,80}\bsedo\b`)},
+	{ClassificationParked, "Dan.com", regexp.MustCompile(`\bdan\.com\b.{0,200}(?:buy|for sale)|(?:buy|for sale).{0,200}\bdan\.com\b`)},
+	{ClassificationParked, "HugeDomains", regexp.MustCompile(`hugedomains\.com|\bhugedomains\b.{0,120}for sale`)},
+	{ClassificationParked, "Afternic", regexp.MustCompile(`\bafternic\b`)},
+	{ClassificationParked, "Namecheap", regexp.MustCompile(`this domain is registered at namecheap|parked (?:free )?by namecheap`)},
+	{ClassificationParked, "Bodis", regexp.MustCompile(`\bbodis\b`)},
+	{ClassificationParked, "ParkingCrew", regexp.MustCompile(`\bparkingcrew\b`)},
+	{ClassificationParked, "parking page", regexp.MustCompile(`this domain (?:name )?(?:has been|is) (?:registered and )?parked|` +
+		`(?:this|the) domain (?:name )?(?:[a-z0-9.-]+ )?(?:may be|is) for sale|buy this domain|inquire about this domain`)},
+
+	{ClassificationServerDefault, "Apache", regexp.MustCompile(`apache2 (?:ubuntu|debian) default page|test page for the apache http server|` +
+		`^it works!$|^it works! this is the default web page for this server`)},
+	{ClassificationServerDefault, "nginx", regexp.MustCompile(`welcome to nginx!|test page for the nginx http server`)},
+	{ClassificationServerDefault, "IIS", regexp.MustCompile(`^iis(?:\d+| windows(?: server)?)$`)},
+	{ClassificationServerDefault, "LiteSpeed", regexp.MustCompile(`congratulations! your litespeed web server is working|litespeed web server default page`)},
+	{ClassificationServerDefault, "Caddy", regexp.MustCompile(`caddy works!|caddy web server default page`)},
+	{ClassificationServerDefault, "Tomcat", regexp.MustCompile(`you've successfully installed tomcat`)},
+	{ClassificationServerDefault, "web server test page", regexp.MustCompile(`^http server test page\b|this page is used to test the proper operation of the (?:apache )?http server`)},
+}
+
+// classifyPage recognizes parking and server welcome pages by their title and texts, the
+// visible text nodes. It returns nil for other pages.
+func classifyPage(title string, texts []string) *Classification {
+	text := strings.ToLower(strings.Join(strings.Fields(title+" "+strings.Join(texts, " ")), " "))
+	for _, s := range pageSignatures {
+		if s.pattern.MatchString(text) {
+			return &Classification{Kind: s.kind, Signature: s.signature}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClassifyPage(t *testing.T) {
+	testCases := []struct {
+		name  string
+		title string
+		texts []string
+		want  *Classification
+	}{
+		{"GoDaddy parking", "example.com", []string{"This Web page is parked ", "FREE", ", courtesy of GoDaddy."}, &Classification{ClassificationParked, "GoDaddy"}},
+		{"Sedo listing", "example.com", []string{"This domain may be for sale!", " Buy now at Sedo."}, &Classification{ClassificationParked, "Sedo"}},
+		{"Generic parking", "", []string{"The domain example.com is for sale. Inquire about this domain."}, &Classification{ClassificationParked, "parking page"}},
+		{"Apache It works", "", []string{"It works!"}, &Classification{ClassificationServerDefault, "Apache"}},
+		{"Ubuntu Apache", "Apache2 Ubuntu Default Page: It works", []string{"Apache2 Ubuntu Default Page"}, &Classification{ClassificationServerDefault, "Apache"}},
+		{"nginx", "Welcome to nginx!", []string{"Welcome to nginx!", "If you see this page, the nginx web server is successfully installed"}, &Classification{ClassificationServerDefault, "nginx"}},
+		{"IIS", "IIS Windows Server", nil, &Classification{ClassificationServerDefault, "IIS"}},
+		{"Tomcat", "Apache Tomcat", []string{"If you're seeing this, you've successfully installed Tomcat. Congratulations!"}, &Classification{ClassificationServerDefault, "Tomcat"}},
+		{"Ordinary page", "It works! A guide", []string{"How our product works, and the IIS Windows Server setup it needs."}, nil},
+		{"Registrar", "Domains", []string{"Search for your next domain and buy it today."}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyPage(tc.title, tc.texts); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %+v, but got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzePage_ParkedPage(t *testing.T) {
+	var linkChecks int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			atomic.AddInt32(&linkChecks, 1)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>example.com</title></head><body>
+			<p>This domain is parked. <a href="/buy">Buy this domain</a></p><a href="/ad">Related searches</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if want := (&Classification{ClassificationParked, "parking page"}); !reflect.DeepEqual(result.Classification, want) {
+		t.Errorf("Expected classification %+v, but got %+v", want, result.Classification)
+	}
+	if result.Score != 0 || result.Grade != "" {
+		t.Errorf("Expected no score for a parked page, but got %d %q", result.Score, result.Grade)
+	}
+	if result.Links.InternalCount != 2 {
+		t.Errorf("Expected 2 internal links, but got %d", result.Links.InternalCount)
+	}
+	if got := atomic.LoadInt32(&linkChecks); got != 0 {
+		t.Errorf("Expected no link checks on a parked page, but got %d", got)
+	}
+}
@@ -0,0 +1,576 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// cssURLPattern matches url(...) references in CSS, with the target quoted or bare.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+
+// skippedLinkSchemes are href schemes that do not point to a fetchable page.
+var skippedLinkSchemes = []string{"mailto", "tel", "javascript", "data"}
+
+func findHTMLVersion(ctx context.Context, logger *slog.Logger, doc *goquery.Document) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "findHTMLVersion")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Starting to determine HTML version")
+
+	var version string
+	var found bool
+
+	doc.Each(func(i int, s *goquery.Selection) {
+		if found {
+			return
+		}
+		for _, node := range s.Nodes {
+			if node.FirstChild != nil && node.FirstChild.Type == html.DoctypeNode {
+				doctype := node.FirstChild
+				logger.DebugContext(ctx, "Found doctype node", slog.String("data", doctype.Data))
+
+				if doctype.Data == "html" && len(doctype.Attr) == 0 {
+					version = "HTML5"
+					found = true
+					return
+				}
+
+				for _, attr := range doctype.Attr {
+					if attr.Key == "public" {
+						val := strings.ToLower(attr.Val)
+						logger.DebugContext(ctx, "Found public identifier", slog.String("value", val))
+						if strings.Contains(val, "xhtml 1.0") {
+							version = "XHTML 1.0"
+						} else if strings.Contains(val, "html 4.01") {
+							version = "HTML 4.01"
+						} else {
+							version = "Unknown (Pre-HTML5)"
+						}
+						found = true
+						return
+					}
+				}
+			}
+		}
+	})
+
+	if version == "" {
+		version = "Unknown or No Doctype"
+		logger.WarnContext(ctx, "Could not determine HTML version", slog.String("result", version))
+	} else {
+		logger.InfoContext(ctx, "Successfully determined HTML version", slog.String("version", version))
+	}
+
+	return version, nil
+}
+
+func countHeadings(ctx context.Context, logger *slog.Logger, doc *goquery.Document) (_ map[string]int, err error) {
+	ctx, span := tracer.Start(ctx, "countHeadings")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Starting to count headings")
+
+	headings := make(map[string]int)
+	headingLevels := []string{"h1", "h2", "h3", "h4", "h5", "h6"}
+
+	for _, tag := range headingLevels {
+		count := doc.Find(tag).Length()
+		if count > 0 {
+			logger.DebugContext(
+				ctx,
+				"Found heading tag",
+				slog.String("tag", tag),
+				slog.Int("count", count),
+			)
+			headings[tag] = count
+		}
+	}
+
+	logger.InfoContext(
+		ctx,
+		"Successfully counted all headings",
+		slog.Any("heading_counts", headings),
+	)
+
+	return headings, nil
+}
+
+func extractLinks(ctx context.Context, logger *slog.Logger, doc *goquery.Document, baseURL *url.URL, opts NormalizeOptions) (_ LinkAnalysis, err error) {
+	ctx, span := tracer.Start(ctx, "extractLinks")
+	defer func() { endSpan(span, err) }()
+
+	logger = logger.With(slog.String("analyzing_page_link", baseURL.String()))
+	logger.DebugContext(ctx, "Starting to extract links")
+
+	var hrefs, hrefTexts, frameSrcs []string
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		hrefs = append(hrefs, s.AttrOr("href", ""))
+		hrefTexts = append(hrefTexts, s.Text())
+	})
+	doc.Find("iframe[src]").Each(func(i int, s *goquery.Selection) {
+		frameSrcs = append(frameSrcs, s.AttrOr("src", ""))
+	})
+	noscript := parseNoscript(findNoscript(doc))
+	hrefs = append(hrefs, noscript.hrefs...)
+	hrefTexts = append(hrefTexts, noscript.hrefTexts...)
+	frameSrcs = append(frameSrcs, noscript.frameSrcs...)
+
+	hasTarget := func(fragment string) bool { return hasAnchorTarget(doc, fragment) }
+	resolveBase := documentBaseURL(ctx, logger, doc, baseURL)
+
+	analysis, err := classifyLinks(ctx, logger, hrefs, hrefTexts, frameSrcs, hasTarget, resolveBase, baseURL, opts)
+	analysis.Iframes = auditIframes(findIframes(doc), resolveBase, baseURL, opts)
+	return analysis, err
+}
+
+// classifyLinks sorts raw link hrefs and iframe srcs into the categories of a LinkAnalysis.
+// hrefTexts holds the text of the anchor each href came from, if known. hasTarget reports
+// whether an in-page fragment exists in the document.
+func classifyLinks(ctx context.Context, logger *slog.Logger, hrefs, hrefTexts, frameSrcs []string, hasTarget func(fragment string) bool, resolveBase, baseURL *url.URL, opts NormalizeOptions) (LinkAnalysis, error) {
+	result := LinkAnalysis{
+		InternalLinks: []string{},
+		ExternalLinks: []string{},
+		BrokenAnchors: []string{},
+		SkippedLinks:  make(map[string]int),
+
+		InternalIframes: []string{},
+		ExternalIframes: []string{},
+
+		AnchorTexts: make(map[string]string),
+	}
+
+	var errs []error
+
+	seen := make(map[string]bool)
+
+	for i, href := range hrefs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		href = strings.TrimSpace(href)
+
+		if strings.HasPrefix(href, "#") {
+			fragment := strings.TrimPrefix(href, "#")
+			if !hasTarget(fragment) {
+				logger.DebugContext(ctx, "Found broken in-page anchor", slog.String("href", href))
+				result.BrokenAnchors = append(result.BrokenAnchors, href)
+			}
+			continue
+		}
+
+		if category, skipped := skippedLinkCategory(href); skipped {
+			logger.DebugContext(ctx, "Skipping non-navigational link", slog.String("href", href), slog.String("category", category))
+			result.SkippedLinks[category]++
+			continue
+		}
+
+		absoluteLink, err := resolveLink(href, resolveBase, opts)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to parse link href", slog.String("href", href), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("failed to parse href '%s': %w", href, err))
+			continue
+		}
+
+		if i < len(hrefTexts) && result.AnchorTexts[absoluteLink.String()] == "" {
+			if text := strings.Join(strings.Fields(hrefTexts[i]), " "); text != "" {
+				result.AnchorTexts[absoluteLink.String()] = text
+			}
+		}
+
+		if seen[absoluteLink.String()] {
+			logger.DebugContext(ctx, "Skipping duplicate link", slog.String("link", absoluteLink.String()))
+			continue
+		}
+		seen[absoluteLink.String()] = true
+
+		if isSameHost(absoluteLink, baseURL) {
+			logger.DebugContext(ctx, "Found internal link", slog.String("link", absoluteLink.String()))
+			result.InternalLinks = append(result.InternalLinks, absoluteLink.String())
+		} else {
+			logger.DebugContext(ctx, "Found external link", slog.String("link", absoluteLink.String()))
+			result.ExternalLinks = append(result.ExternalLinks, absoluteLink.String())
+		}
+	}
+
+	seenFrames := make(map[string]bool)
+
+	for _, src := range frameSrcs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		src = strings.TrimSpace(src)
+
+		if _, skipped := skippedLinkCategory(src); skipped || strings.HasPrefix(strings.ToLower(src), "about:") {
+			logger.DebugContext(ctx, "Skipping non-fetchable iframe source", slog.String("src", src))
+			continue
+		}
+
+		frameURL, err := resolveLink(src, resolveBase, opts)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to parse iframe src", slog.String("src", src), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("failed to parse iframe src '%s': %w", src, err))
+			continue
+		}
+
+		if seenFrames[frameURL.String()] {
+			continue
+		}
+		seenFrames[frameURL.String()] = true
+
+		if isSameHost(frameURL, baseURL) {
+			logger.DebugContext(ctx, "Found internal iframe", slog.String("src", frameURL.String()))
+			result.InternalIframes = append(result.InternalIframes, frameURL.String())
+		} else {
+			logger.DebugContext(ctx, "Found external iframe", slog.String("src", frameURL.String()))
+			result.ExternalIframes = append(result.ExternalIframes, frameURL.String())
+		}
+	}
+
+	logger.InfoContext(ctx, "Finished extracting links",
+		slog.Int("internal_links_found", len(result.InternalLinks)),
+		slog.Int("external_links_found", len(result.ExternalLinks)),
+		slog.Int("internal_iframes_found", len(result.InternalIframes)),
+		slog.Int("external_iframes_found", len(result.ExternalIframes)),
+		slog.Int("broken_anchors_found", len(result.BrokenAnchors)),
+		slog.Any("skipped_links", result.SkippedLinks),
+		slog.Int("parsing_errors", len(errs)),
+	)
+
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+
+	return result, nil
+}
+
+// extractCSSResources collects the resources referenced through url(...) in inline style
+// attributes and <style> blocks, such as background images and web fonts.
+func extractCSSResources(ctx context.Context, logger *slog.Logger, doc *goquery.Document, baseURL *url.URL, opts NormalizeOptions) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "extractCSSResources")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Starting to extract CSS resources")
+
+	var stylesheets []string
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		stylesheets = append(stylesheets, s.AttrOr("style", ""))
+	})
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		stylesheets = append(stylesheets, s.Text())
+	})
+
+	resolveBase := documentBaseURL(ctx, logger, doc, baseURL)
+	return collectCSSResources(ctx, logger, stylesheets, resolveBase, baseURL, opts)
+}
+
+// collectCSSResources resolves the url(...) references found in the given CSS sources.
+func collectCSSResources(ctx context.Context, logger *slog.Logger, stylesheets []string, resolveBase, baseURL *url.URL, opts NormalizeOptions) ([]string, error) {
+	resources := []string{}
+	seen := make(map[string]bool)
+	var errs []error
+
+	for _, css := range stylesheets {
+		if err := ctx.Err(); err != nil {
+			return resources, err
+		}
+		for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+			ref := strings.TrimSpace(match[1] + match[2] + match[3])
+
+			if _, skipped := skippedLinkCategory(ref); skipped || strings.HasPrefix(ref, "#") {
+				logger.DebugContext(ctx, "Skipping non-fetchable CSS reference", slog.String("ref", ref))
+				continue
+			}
+
+			resourceURL, err := resolveLink(ref, resolveBase, opts)
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to parse CSS url reference", slog.String("ref", ref), slog.Any("error", err))
+				errs = append(errs, fmt.Errorf("failed to parse CSS url '%s': %w", ref, err))
+				continue
+			}
+
+			if seen[resourceURL.String()] {
+				continue
+			}
+			seen[resourceURL.String()] = true
+
+			logger.DebugContext(ctx, "Found CSS resource", slog.String("url", resourceURL.String()))
+			resources = append(resources, resourceURL.String())
+		}
+	}
+
+	logger.InfoContext(ctx, "Finished extracting CSS resources",
+		slog.Int("css_resources_found", len(resources)),
+		slog.Int("parsing_errors", len(errs)),
+	)
+
+	if len(errs) > 0 {
+		return resources, errors.Join(errs...)
+	}
+
+	return resources, nil
+}
+
+// resolveLink turns an href into a normalized absolute URL. Relative references, including
+// protocol-relative ones ("//host/path"), resolve against resolveBase as browsers do.
+func resolveLink(href string, resolveBase *url.URL, opts NormalizeOptions) (*url.URL, error) {
+	linkURL, err := url.Parse(href)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeURL(resolveBase.ResolveReference(linkURL), opts), nil
+}
+
+// documentBaseURL returns the URL relative links should be resolved against, honoring the
+// first <base href> element in the document and falling back to the page URL.
+func documentBaseURL(ctx context.Context, logger *slog.Logger, doc *goquery.Document, pageURL *url.URL) *url.URL {
+	baseHref, exists := doc.Find("base[href]").First().Attr("href")
+	if !exists {
+		return pageURL
+	}
+	return resolveBaseHref(ctx, logger, baseHref, pageURL)
+}
+
+// resolveBaseHref resolves the href of a <base> element against the page URL, falling back
+// to the page URL if it cannot be parsed.
+func resolveBaseHref(ctx context.Context, logger *slog.Logger, baseHref string, pageURL *url.URL) *url.URL {
+	baseHref = strings.TrimSpace(baseHref)
+	parsed, err := url.Parse(baseHref)
+	if err != nil {
+		logger.WarnContext(ctx, "Ignoring unparsable base href", slog.String("href", baseHref), slog.Any("error", err))
+		return pageURL
+	}
+
+	resolved := pageURL.ResolveReference(parsed)
+	logger.DebugContext(ctx, "Using document base URL", slog.String("base_url", resolved.String()))
+	return resolved
+}
+
+// skippedLinkCategory reports whether an href should be excluded from link analysis and,
+// if so, the category it is counted under (its scheme, or "empty" for blank hrefs).
+func skippedLinkCategory(href string) (string, bool) {
+	if href == "" {
+		return "empty", true
+	}
+
+	scheme, _, found := strings.Cut(href, ":")
+	if !found {
+		return "", false
+	}
+
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+	for _, skipped := range skippedLinkSchemes {
+		if scheme == skipped {
+			return scheme, true
+		}
+	}
+
+	return "", false
+}
+
+// hasAnchorTarget reports whether an in-page fragment resolves to an element in the document.
+// An empty fragment and "top" always refer to the top of the page.
+func hasAnchorTarget(doc *goquery.Document, fragment string) bool {
+	fragment, implicit := anchorFragment(fragment)
+	if implicit {
+		return true
+	}
+
+	found := false
+	doc.Find("[id], a[name]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if s.AttrOr("id", "") == fragment || (goquery.NodeName(s) == "a" && s.AttrOr("name", "") == fragment) {
+			found = true
+			return false
+		}
+		return true
+	})
+
+	return found
+}
+
+// anchorFragment percent-decodes an in-page fragment and reports whether it targets the top
+// of the page implicitly, without needing a matching element.
+func anchorFragment(fragment string) (string, bool) {
+	if decoded, err := url.PathUnescape(fragment); err == nil {
+		fragment = decoded
+	}
+	return fragment, fragment == "" || strings.EqualFold(fragment, "top")
+}
+
+// findMetaDescription returns the trimmed content of the first meta description in the
+// document, or "" if it has none.
+func findMetaDescription(doc *goquery.Document) string {
+	description := ""
+	doc.Find("meta[name]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if isMetaDescription(s.AttrOr("name", "")) {
+			description = strings.TrimSpace(s.AttrOr("content", ""))
+			return false
+		}
+		return true
+	})
+	return description
+}
+
+// isMetaDescription reports whether name, the name attribute of a meta element, marks it as
+// the page description.
+func isMetaDescription(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), "description")
+}
+
+// findMetaNoindex reports whether a robots meta element of the document forbids indexing it.
+func findMetaNoindex(doc *goquery.Document) bool {
+	noindex := false
+	doc.Find("meta[name]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		noindex = isMetaNoindex(s.AttrOr("name", ""), s.AttrOr("content", ""))
+		return !noindex
+	})
+	return noindex
+}
+
+// isMetaNoindex reports whether a meta element with the given name and content attributes
+// forbids indexing the page: a robots meta tag, for all crawlers or for web-analyzer, with
+// noindex or none among its directives.
+func isMetaNoindex(name, content string) bool {
+	name = strings.TrimSpace(name)
+	if !strings.EqualFold(name, "robots") && !strings.EqualFold(name, robotsUserAgent) {
+		return false
+	}
+	return hasNoindex(content)
+}
+
+// hasNoindex reports whether a comma-separated list of robots directives includes noindex or
+// none.
+func hasNoindex(directives string) bool {
+	for _, directive := range strings.Split(directives, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == "noindex" || directive == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+func detectLoginForm(ctx context.Context, logger *slog.Logger, doc *goquery.Document) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "detectLoginForm")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Starting login form detection")
+	var isLoginForm bool
+
+	doc.Find("form").EachWithBreak(func(i int, formSelection *goquery.Selection) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		formLogger := logger.With(slog.Int("form_index", i)) // Create a logger specific to this form
+		formLogger.DebugContext(ctx, "Analyzing form")
+
+		// Criterion 1: A password field MUST exist.
+		if formSelection.Find("input[type='password']").Length() == 0 {
+			formLogger.DebugContext(ctx, "Skipping form: no password field found")
+			return true
+		}
+
+		// Criterion 2: Check for a username/email field.
+		hasUserIdentifierField := false
+		formSelection.Find("input").Each(func(j int, inputSelection *goquery.Selection) {
+			// Check for various attributes that indicate a user identifier field
+			if isUserIdentifierInput(
+				inputSelection.AttrOr("type", ""),
+				inputSelection.AttrOr("name", ""),
+				inputSelection.AttrOr("id", ""),
+				inputSelection.AttrOr("placeholder", ""),
+			) {
+				hasUserIdentifierField = true
+			}
+		})
+
+		// Criterion 3: Check for a submit button with "log in" or "sign in" text.
+		hasLoginButton := false
+		formSelection.Find("button, input[type='submit']").Each(func(k int, btnSelection *goquery.Selection) {
+			if isLoginButtonText(btnSelection.Text() + btnSelection.AttrOr("value", "")) {
+				hasLoginButton = true
+			}
+		})
+
+		formLogger.DebugContext(ctx, "Form analysis criteria",
+			slog.Bool("has_user_identifier", hasUserIdentifierField),
+			slog.Bool("has_login_button", hasLoginButton),
+		)
+
+		if hasUserIdentifierField || hasLoginButton {
+			isLoginForm = true
+			formLogger.DebugContext(ctx, "Confirmed as login form")
+			return false
+		}
+
+		return true
+	})
+
+	// Fallback for pages without a <form> tag (e.g., logins handled by JavaScript)
+	if !isLoginForm {
+		logger.DebugContext(ctx, "No traditional login form found, running fallback check")
+		hasPasswordInput := doc.Find("input[type='password']").Length() > 0
+		hasEmailInput := doc.Find("input[type='email']").Length() > 0
+		hasTextInput := doc.Find("input[id*='user'], input[id*='login'], input[name*='user'], input[name*='login']").Length() > 0
+
+		logger.DebugContext(ctx, "Fallback analysis criteria",
+			slog.Bool("has_password_input", hasPasswordInput),
+			slog.Bool("has_email_input", hasEmailInput),
+			slog.Bool("has_text_input_heuristic", hasTextInput),
+		)
+
+		if hasPasswordInput && (hasEmailInput || hasTextInput) {
+			logger.DebugContext(ctx, "Confirmed as login form via fallback")
+			isLoginForm = true
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	logger.InfoContext(ctx, "Login form detection finished", slog.Bool("login_form_found", isLoginForm))
+	return isLoginForm, nil
+}
+
+// isUserIdentifierInput reports whether an input with the given attributes looks like a
+// username or email field.
+func isUserIdentifierInput(inputType, name, id, placeholder string) bool {
+	name, id, placeholder = strings.ToLower(name), strings.ToLower(id), strings.ToLower(placeholder)
+	return inputType == "email" ||
+		strings.Contains(name, "user") || strings.Contains(id, "user") || strings.Contains(placeholder, "user") ||
+		strings.Contains(name, "email") || strings.Contains(id, "email") || strings.Contains(placeholder, "email")
+}
+
+// isLoginButtonText reports whether a button's text or value reads like a login action.
+func isLoginButtonText(text string) bool {
+	text = strings.ToLower(text)
+	return strings.Contains(text, "log in") || strings.Contains(text, "sign in")
+}
+
+// IsValidURL reports whether toTest is a URL AnalyzePage accepts: an absolute http or https
+// URL whose host has a dot.
+func IsValidURL(toTest string) bool {
+	u, err := url.Parse(toTest)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	if u.Host == "" || !strings.Contains(u.Host, ".") {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,951 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestFindHTMLVersion(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "HTML5",
+			htmlContent: `<!DOCTYPE html><html><head></head><body></body></html>`,
+			wantVersion: "HTML5",
+			wantErr:     false,
+		},
+		{
+			name:        "XHTML 1.0 Transitional",
+			htmlContent: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd"><html><head></head><body></body></html>`,
+			wantVersion: "XHTML 1.0",
+			wantErr:     false,
+		},
+		{
+			name:        "HTML 4.01 Strict",
+			htmlContent: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html><head></head><body></body></html>`,
+			wantVersion: "HTML 4.01",
+			wantErr:     false,
+		},
+		{
+			name:        "Unknown Pre-HTML5",
+			htmlContent: `<!DOCTYPE HTML PUBLIC "-//IETF//DTD HTML 2.0//EN"><html><head></head><body></body></html>`,
+			wantVersion: "Unknown (Pre-HTML5)",
+			wantErr:     false,
+		},
+		{
+			name:        "No Doctype",
+			htmlContent: `<html><head></head><body><h1>Hello</h1></body></html>`,
+			wantVersion: "Unknown or No Doctype",
+			wantErr:     false,
+		},
+		{
+			name:        "Empty Document",
+			htmlContent: ``,
+			wantVersion: "Unknown or No Doctype",
+			wantErr:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			version, err := findHTMLVersion(ctx, logger, doc)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("findHTMLVersion() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if version != tc.wantVersion {
+				t.Errorf("findHTMLVersion() got = %v, want %v", version, tc.wantVersion)
+			}
+		})
+	}
+}
+
+func TestCountHeadings(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		wantCounts  map[string]int
+		wantErr     bool
+	}{
+		{
+			name:        "All Heading Levels",
+			htmlContent: `<html><body><h1>H1</h1><h2>H2</h2><h3>H3</h3><h4>H4</h4><h5>H5</h5><h6>H6</h6><h2>Another H2</h2></body></html>`,
+			wantCounts:  map[string]int{"h1": 1, "h2": 2, "h3": 1, "h4": 1, "h5": 1, "h6": 1},
+			wantErr:     false,
+		},
+		{
+			name:        "No Headings",
+			htmlContent: `<html><body><p>Just a paragraph.</p></body></html>`,
+			wantCounts:  map[string]int{},
+			wantErr:     false,
+		},
+		{
+			name:        "Only Some Heading Levels",
+			htmlContent: `<html><body><h1>H1</h1><h3>H3</h3><h3>H3</h3></body></html>`,
+			wantCounts:  map[string]int{"h1": 1, "h3": 2},
+			wantErr:     false,
+		},
+		{
+			name:        "Empty Document",
+			htmlContent: ``,
+			wantCounts:  map[string]int{},
+			wantErr:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			counts, err := countHeadings(ctx, logger, doc)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("countHeadings() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(counts, tc.wantCounts) {
+				t.Errorf("countHeadings() got = %v, want %v", counts, tc.wantCounts)
+			}
+		})
+	}
+}
+
+func TestFindMetaDescription(t *testing.T) {
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        string
+	}{
+		{
+			name:        "Description",
+			htmlContent: `<html><head><meta charset="utf-8"><meta name="description" content="  About us  "></head></html>`,
+			want:        "About us",
+		},
+		{
+			name:        "First Of Several, Any Case",
+			htmlContent: `<html><head><meta name="DESCRIPTION" content="First"><meta name="description" content="Second"></head></html>`,
+			want:        "First",
+		},
+		{
+			name:        "Other Meta Only",
+			htmlContent: `<html><head><meta name="keywords" content="a, b"><meta property="og:description" content="OG"></head></html>`,
+			want:        "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := findMetaDescription(doc); got != tc.want {
+				t.Errorf("findMetaDescription() got = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindMetaNoindex(t *testing.T) {
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        bool
+	}{
+		{"Noindex", `<html><head><meta name="robots" content="noindex, nofollow"></head></html>`, true},
+		{"None, Any Case", `<html><head><meta name="Robots" content=" NONE "></head></html>`, true},
+		{"For web-analyzer", `<html><head><meta name="web-analyzer" content="noindex"></head></html>`, true},
+		{"After Another Meta", `<html><head><meta name="description" content="x"><meta name="robots" content="noindex"></head></html>`, true},
+		{"Index", `<html><head><meta name="robots" content="index, follow"></head></html>`, false},
+		{"For Another Crawler", `<html><head><meta name="googlebot" content="noindex"></head></html>`, false},
+		{"No Robots Meta", `<html><head><title>x</title></head></html>`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := findMetaNoindex(doc); got != tc.want {
+				t.Errorf("findMetaNoindex() got = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindPagination(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/blog/?page=2")
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        *Pagination
+	}{
+		{
+			name:        "Link Elements",
+			htmlContent: `<html><head><link rel="prev" href="?page=1"><link rel="next" href="?page=3"></head></html>`,
+			want:        &Pagination{Next: "https://example.com/blog/?page=3", Prev: "https://example.com/blog/?page=1"},
+		},
+		{
+			name:        "Anchors, Any Case",
+			htmlContent: `<html><body><a rel="Previous" href="/blog/">Back</a><a rel="nofollow NEXT" href="/blog/?page=3">More</a></body></html>`,
+			want:        &Pagination{Next: "https://example.com/blog/?page=3", Prev: "https://example.com/blog/"},
+		},
+		{
+			name:        "First Link Wins",
+			htmlContent: `<html><head><link rel="next" href="?page=3"></head><body><a rel="next" href="?page=9">Last</a></body></html>`,
+			want:        &Pagination{Next: "https://example.com/blog/?page=3"},
+		},
+		{
+			name:        "Against the Base Element",
+			htmlContent: `<html><head><base href="/archive/"><link rel="next" href="2"></head></html>`,
+			want:        &Pagination{Next: "https://example.com/archive/2"},
+		},
+		{
+			name:        "Other Rels and Empty Hrefs",
+			htmlContent: `<html><head><link rel="stylesheet" href="main.css"><link rel="next" href=""></head><body><div rel="next" href="x"></div></body></html>`,
+			want:        nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			base := documentBaseURL(context.Background(), newTestLogger(), doc, pageURL)
+			if got := findPagination(doc, base, NormalizeOptions{}); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("findPagination() got = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/path/")
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		wantResult  LinkAnalysis
+		wantErr     bool
+	}{
+		{
+			name: "Mix of Internal and External Links",
+			htmlContent: `
+                <a href="/about">About</a>
+                <a href="https://google.com">Google</a>
+                <a href="contact.html">Contact</a>
+                <a href="https://sub.example.com/page">Subdomain (External)</a>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{"https://example.com/about", "https://example.com/path/contact.html"},
+				ExternalLinks: []string{"https://google.com/", "https://sub.example.com/page"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Skipped Links",
+			htmlContent: `
+                <a href="#">Fragment</a>
+                <a href="mailto:test@example.com">Mail</a>
+                <a href="tel:+123456789">Tel</a>
+                <a href="  ">Whitespace</a>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{},
+				ExternalLinks: []string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Absolute Internal Links",
+			htmlContent: `
+                <a href="https://example.com/another-page">Another Page</a>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{"https://example.com/another-page"},
+				ExternalLinks: []string{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid Href",
+			htmlContent: `
+                <a href="http://a b.com/">Invalid</a>
+                <a href="/good-link">Good</a>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{"https://example.com/good-link"},
+				ExternalLinks: []string{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Equivalent Links Are Deduplicated",
+			htmlContent: `
+                <a href="/about">About</a>
+                <a href="https://EXAMPLE.com:443/about#team">About Team</a>
+                <a href="/team/../about">About Again</a>
+                <a href="https://google.com">Google</a>
+                <a href="https://google.com/">Google Slash</a>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{"https://example.com/about"},
+				ExternalLinks: []string{"https://google.com/"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Links Inside Noscript",
+			htmlContent: `
+                <noscript><a href="/no-js">Plain version</a><iframe src="https://tags.example/ns"></iframe></noscript>
+            `,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{"https://example.com/no-js"},
+				ExternalLinks: []string{},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Empty Document",
+			htmlContent: ``,
+			wantResult: LinkAnalysis{
+				InternalLinks: []string{},
+				ExternalLinks: []string{},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("extractLinks() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if len(result.InternalLinks) != len(tc.wantResult.InternalLinks) ||
+				len(result.ExternalLinks) != len(tc.wantResult.ExternalLinks) {
+				t.Errorf("extractLinks() result length mismatch. Got internal %d, external %d. Want internal %d, external %d",
+					len(result.InternalLinks), len(result.ExternalLinks), len(tc.wantResult.InternalLinks), len(tc.wantResult.ExternalLinks))
+			}
+		})
+	}
+}
+
+func TestExtractLinks_ProtocolRelative(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+
+	testCases := []struct {
+		name         string
+		pageURL      string
+		htmlContent  string
+		wantInternal []string
+		wantExternal []string
+	}{
+		{
+			name:    "HTTPS Page",
+			pageURL: "https://example.com/path/",
+			htmlContent: `
+                <a href="//example.com/about">About</a>
+                <a href="//cdn.example.org/lib.js">CDN</a>
+            `,
+			wantInternal: []string{"https://example.com/about"},
+			wantExternal: []string{"https://cdn.example.org/lib.js"},
+		},
+		{
+			name:    "HTTP Page",
+			pageURL: "http://example.com/",
+			htmlContent: `
+                <a href="//example.com/about">About</a>
+                <a href="//other.com/">Other</a>
+            `,
+			wantInternal: []string{"http://example.com/about"},
+			wantExternal: []string{"http://other.com/"},
+		},
+		{
+			name:    "Host Case Differs",
+			pageURL: "https://example.com/",
+			htmlContent: `
+                <a href="//EXAMPLE.com/contact">Contact</a>
+            `,
+			wantInternal: []string{"https://example.com/contact"},
+			wantExternal: []string{},
+		},
+		{
+			name:    "Internationalized Domain Names",
+			pageURL: "https://münchen.de/",
+			htmlContent: `
+                <a href="https://xn--mnchen-3ya.de/rathaus">Punycode</a>
+                <a href="//bücher.example/">Other IDN</a>
+            `,
+			wantInternal: []string{"https://xn--mnchen-3ya.de/rathaus"},
+			wantExternal: []string{"https://xn--bcher-kva.example/"},
+		},
+		{
+			name:    "Base Scheme Wins Over Page Scheme",
+			pageURL: "https://example.com/",
+			htmlContent: `
+                <head><base href="http://example.com/docs/"></head>
+                <a href="//example.com/secure">Secure</a>
+            `,
+			wantInternal: []string{"http://example.com/secure"},
+			wantExternal: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL, _ := url.Parse(tc.pageURL)
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+			if err != nil {
+				t.Fatalf("extractLinks() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(result.InternalLinks, tc.wantInternal) {
+				t.Errorf("extractLinks() internal links = %v, want %v", result.InternalLinks, tc.wantInternal)
+			}
+			if !reflect.DeepEqual(result.ExternalLinks, tc.wantExternal) {
+				t.Errorf("extractLinks() external links = %v, want %v", result.ExternalLinks, tc.wantExternal)
+			}
+		})
+	}
+}
+
+func TestExtractLinks_Iframes(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/page/")
+
+	htmlContent := `
+        <iframe src="/embed/map"></iframe>
+        <iframe src="widget.html"></iframe>
+        <iframe src="https://www.youtube.com/embed/abc123"></iframe>
+        <iframe src="//player.vimeo.com/video/42"></iframe>
+        <iframe src="https://www.youtube.com/embed/abc123#t=10"></iframe>
+        <iframe src="about:blank"></iframe>
+        <iframe src="javascript:false"></iframe>
+        <iframe src=""></iframe>
+        <iframe srcdoc="<p>inline</p>"></iframe>
+    `
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+	if err != nil {
+		t.Fatalf("extractLinks() unexpected error = %v", err)
+	}
+
+	wantInternal := []string{"https://example.com/embed/map", "https://example.com/page/widget.html"}
+	wantExternal := []string{"https://www.youtube.com/embed/abc123", "https://player.vimeo.com/video/42"}
+
+	if !reflect.DeepEqual(result.InternalIframes, wantInternal) {
+		t.Errorf("extractLinks() internal iframes = %v, want %v", result.InternalIframes, wantInternal)
+	}
+	if !reflect.DeepEqual(result.ExternalIframes, wantExternal) {
+		t.Errorf("extractLinks() external iframes = %v, want %v", result.ExternalIframes, wantExternal)
+	}
+	if len(result.InternalLinks) != 0 || len(result.ExternalLinks) != 0 {
+		t.Errorf("extractLinks() iframes should not be counted as links, got internal %v, external %v",
+			result.InternalLinks, result.ExternalLinks)
+	}
+	if len(result.SkippedLinks) != 0 {
+		t.Errorf("extractLinks() iframes should not be counted as skipped links, got %v", result.SkippedLinks)
+	}
+}
+
+func TestExtractLinks_BaseHref(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/blog/post.html")
+
+	testCases := []struct {
+		name         string
+		htmlContent  string
+		wantInternal []string
+		wantExternal []string
+	}{
+		{
+			name: "No Base Element",
+			htmlContent: `
+                <a href="next.html">Next</a>
+            `,
+			wantInternal: []string{"https://example.com/blog/next.html"},
+			wantExternal: []string{},
+		},
+		{
+			name: "Relative Base Path",
+			htmlContent: `
+                <head><base href="/docs/"></head>
+                <a href="intro.html">Intro</a>
+                <a href="/root.html">Root</a>
+            `,
+			wantInternal: []string{"https://example.com/docs/intro.html", "https://example.com/root.html"},
+			wantExternal: []string{},
+		},
+		{
+			name: "Absolute Base On Another Host",
+			htmlContent: `
+                <head><base href="https://cdn.example.org/assets/"></head>
+                <a href="guide.html">Guide</a>
+                <a href="https://example.com/home">Home</a>
+            `,
+			wantInternal: []string{"https://example.com/home"},
+			wantExternal: []string{"https://cdn.example.org/assets/guide.html"},
+		},
+		{
+			name: "Only First Base Element Counts",
+			htmlContent: `
+                <head><base href="/first/"><base href="/second/"></head>
+                <a href="page.html">Page</a>
+            `,
+			wantInternal: []string{"https://example.com/first/page.html"},
+			wantExternal: []string{},
+		},
+		{
+			name: "Base Without Href Is Ignored",
+			htmlContent: `
+                <head><base target="_blank"></head>
+                <a href="next.html">Next</a>
+            `,
+			wantInternal: []string{"https://example.com/blog/next.html"},
+			wantExternal: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+			if err != nil {
+				t.Fatalf("extractLinks() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(result.InternalLinks, tc.wantInternal) {
+				t.Errorf("extractLinks() internal links = %v, want %v", result.InternalLinks, tc.wantInternal)
+			}
+			if !reflect.DeepEqual(result.ExternalLinks, tc.wantExternal) {
+				t.Errorf("extractLinks() external links = %v, want %v", result.ExternalLinks, tc.wantExternal)
+			}
+		})
+	}
+}
+
+func TestExtractLinks_BrokenAnchors(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/")
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		wantBroken  []string
+	}{
+		{
+			name: "Anchor Matches Element ID",
+			htmlContent: `
+                <a href="#section">Section</a>
+                <h2 id="section">Section</h2>
+            `,
+			wantBroken: []string{},
+		},
+		{
+			name: "Anchor Matches Named Anchor",
+			htmlContent: `
+                <a href="#legacy">Legacy</a>
+                <a name="legacy"></a>
+            `,
+			wantBroken: []string{},
+		},
+		{
+			name: "Top Of Page Fragments",
+			htmlContent: `
+                <a href="#">Top</a>
+                <a href="#top">Top</a>
+            `,
+			wantBroken: []string{},
+		},
+		{
+			name: "Percent Encoded Fragment",
+			htmlContent: `
+                <a href="#caf%C3%A9">Cafe</a>
+                <div id="café"></div>
+            `,
+			wantBroken: []string{},
+		},
+		{
+			name: "Dead Fragments",
+			htmlContent: `
+                <a href="#missing">Missing</a>
+                <a href="#Section">Wrong Case</a>
+                <div id="section"></div>
+                <div name="other"></div>
+                <a href="#other">Name On Non-Anchor</a>
+            `,
+			wantBroken: []string{"#missing", "#Section", "#other"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+			if err != nil {
+				t.Fatalf("extractLinks() unexpected error = %v", err)
+			}
+
+			if !reflect.DeepEqual(result.BrokenAnchors, tc.wantBroken) {
+				t.Errorf("extractLinks() broken anchors = %v, want %v", result.BrokenAnchors, tc.wantBroken)
+			}
+			if len(result.InternalLinks) != 0 || len(result.ExternalLinks) != 0 {
+				t.Errorf("extractLinks() should not treat fragments as page links, got internal %v, external %v",
+					result.InternalLinks, result.ExternalLinks)
+			}
+		})
+	}
+}
+
+func TestExtractLinks_SkippedLinkCategories(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/")
+
+	htmlContent := `
+        <a href="mailto:test@example.com">Mail</a>
+        <a href="MAILTO:other@example.com">Mail Upper</a>
+        <a href="tel:+123456789">Tel</a>
+        <a href="javascript:void(0)">JS</a>
+        <a href="javascript:openMenu()">JS Menu</a>
+        <a href="data:text/plain;base64,SGVsbG8=">Data</a>
+        <a href="  ">Whitespace</a>
+        <a href="/kept">Kept</a>
+    `
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	result, err := extractLinks(ctx, logger, doc, baseURL, NormalizeOptions{})
+	if err != nil {
+		t.Fatalf("extractLinks() unexpected error = %v", err)
+	}
+
+	want := map[string]int{"mailto": 2, "tel": 1, "javascript": 2, "data": 1, "empty": 1}
+	if !reflect.DeepEqual(result.SkippedLinks, want) {
+		t.Errorf("extractLinks() skipped links = %v, want %v", result.SkippedLinks, want)
+	}
+	if len(result.InternalLinks) != 1 || len(result.ExternalLinks) != 0 {
+		t.Errorf("extractLinks() expected only the /kept link, got internal %v, external %v",
+			result.InternalLinks, result.ExternalLinks)
+	}
+}
+
+func TestExtractCSSResources(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+	baseURL, _ := url.Parse("https://example.com/blog/")
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name: "Inline Style Attributes",
+			htmlContent: `
+                <div style="background-image: url('/img/hero.jpg')"></div>
+                <section style="background: #fff url(bg.png) no-repeat"></section>
+                <span style='background:url("https://cdn.example.org/dot.gif")'></span>
+            `,
+			want: []string{
+				"https://example.com/img/hero.jpg",
+				"https://example.com/blog/bg.png",
+				"https://cdn.example.org/dot.gif",
+			},
+		},
+		{
+			name: "Style Blocks",
+			htmlContent: `
+                <style>
+                    body { background: url( "/img/body.png" ); }
+                    @font-face { font-family: X; src: url(//fonts.example.net/x.woff2) format("woff2"); }
+                    .dup { background: url(/img/body.png); }
+                </style>
+            `,
+			want: []string{
+				"https://example.com/img/body.png",
+				"https://fonts.example.net/x.woff2",
+			},
+		},
+		{
+			name: "Non-Fetchable References Are Skipped",
+			htmlContent: `
+                <div style="background: url(data:image/png;base64,iVBORw0KGgo=)"></div>
+                <svg><rect style="fill: url(#gradient)"></rect></svg>
+                <div style="background: url()"></div>
+            `,
+			want: []string{},
+		},
+		{
+			name: "Invalid Reference",
+			htmlContent: `
+                <div style="background: url('http://a b.com/x.png')"></div>
+                <div style="background: url(/ok.png)"></div>
+            `,
+			want:    []string{"https://example.com/ok.png"},
+			wantErr: true,
+		},
+		{
+			name:        "Empty Document",
+			htmlContent: ``,
+			want:        []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			got, err := extractCSSResources(ctx, logger, doc, baseURL, NormalizeOptions{})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("extractCSSResources() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("extractCSSResources() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDetectLoginForm(t *testing.T) {
+	ctx := context.Background()
+	logger := newTestLogger()
+
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name: "Standard Login Form",
+			htmlContent: `
+                <form>
+                    <input type="email" name="username">
+                    <input type="password" name="password">
+                    <button type="submit">Log In</button>
+                </form>
+            `,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Sign In Button",
+			htmlContent: `
+                <form>
+                    <input type="text" id="user-id">
+                    <input type="password" id="pass">
+                    <input type="submit" value="Sign In">
+                </form>
+            `,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "No Login Button but has User Identifier",
+			htmlContent: `
+                <form>
+                    <input type="text" placeholder="Enter your email">
+                    <input type="password" name="pword">
+                    <button type="submit">Go</button>
+                </form>
+            `,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "No Password Field",
+			htmlContent: `
+                <form>
+                    <input type="email" name="email">
+                    <button type="submit">Subscribe</button>
+                </form>
+            `,
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "Search Form - Not a Login Form",
+			htmlContent: `
+                <form action="/search">
+                    <input type="search" name="q">
+                    <button type="submit">Search</button>
+                </form>
+            `,
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "Fallback JS-based Login - No Form Tag",
+			htmlContent: `
+                <div>
+                    <input type="email" id="email-field">
+                    <input type="password" id="password-field">
+                    <div class="button">Log In</div>
+                </div>
+            `,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Fallback with user name heuristic",
+			htmlContent: `
+                <input type="text" name="login_user">
+                <input type="password" name="login_pass">
+            `,
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "No Form Elements",
+			htmlContent: `
+                <h1>Welcome</h1>
+                <p>Please log in on the other page.</p>
+            `,
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:        "Empty Document",
+			htmlContent: ``,
+			want:        false,
+			wantErr:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			got, err := detectLoginForm(ctx, logger, doc)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("detectLoginForm() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if got != tc.want {
+				t.Errorf("detectLoginForm() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid HTTPS URL", input: "https://www.google.com", expected: true},
+		{name: "Valid HTTP URL", input: "http://example.org", expected: true},
+		{name: "Valid URL with path and query", input: "https://subdomain.example.co.uk/path?query=123", expected: true},
+		{name: "Valid URL with port", input: "http://test.com:8080/resource", expected: true},
+
+		{name: "Invalid Scheme (ftp)", input: "ftp://example.com", expected: false},
+		{name: "Invalid Scheme (mailto)", input: "mailto:user@example.com", expected: false},
+		{name: "Missing Scheme", input: "www.google.com", expected: false},
+		{name: "Just a host", input: "google.com", expected: false},
+		{name: "Host without a dot", input: "http://localhost", expected: false},
+		{name: "Malformed string", input: "this is not a url", expected: false},
+		{name: "Empty string", input: "", expected: false},
+		{name: "Scheme only", input: "https://", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := IsValidURL(tc.input)
+			if actual != tc.expected {
+				t.Errorf("For input '%s', expected %v, but got %v", tc.input, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClassifyLinks_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	baseURL, _ := url.Parse("https://example.com/")
+	_, err := classifyLinks(ctx, newTestLogger(), []string{"/a", "/b"}, nil, nil, func(string) bool { return true }, baseURL, baseURL, NormalizeOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+}
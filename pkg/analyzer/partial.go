@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"maps"
+	"slices"
+)
+
+// Statuses of a check in PartialResult.Checks.
+const (
+	CheckStatusRunning = "running"
+	CheckStatusDone    = "done"
+	CheckStatusFailed  = "failed"
+)
+
+// documentChecks are the checks that read the parsed page, and so complete together before
+// the link checks and lookups do.
+var documentChecks = []string{CheckHTMLVersion, CheckHeadings, CheckLinks, CheckCSSResources, CheckLoginForm, CheckMedia, CheckSpelling, CheckSecurity}
+
+// PartialResult is the result of an analysis still in progress, as passed to Options.Partial.
+type PartialResult struct {
+	// Result holds what the completed checks found, with the title, description and the rest
+	// of what is read along with them. Score and Grade are only set once the analysis
+	// completes, and the link counts and results once the link checks do.
+	Result *AnalysisResult
+	// Checks maps each check of the analysis to its status, one of the CheckStatus constants.
+	Checks map[string]string
+}
+
+// partialReporter passes the result to Options.Partial each time a stage of the analysis
+// completes, along with the status of every check. It is only used from the goroutine of
+// AnalyzePage. A nil reporter discards the updates.
+type partialReporter struct {
+	fn     func(PartialResult)
+	checks map[string]string
+}
+
+func newPartialReporter(fn func(PartialResult)) *partialReporter {
+	if fn == nil {
+		return nil
+	}
+	return &partialReporter{fn: fn, checks: make(map[string]string)}
+}
+
+// running marks check as started.
+func (p *partialReporter) running(check string) {
+	if p != nil {
+		p.checks[check] = CheckStatusRunning
+	}
+}
+
+// finished marks check as done, or as failed if result has an error for it.
+func (p *partialReporter) finished(check string, result *AnalysisResult) {
+	if p == nil {
+		return
+	}
+	if _, failed := result.Errors[check]; failed {
+		p.checks[check] = CheckStatusFailed
+	} else {
+		p.checks[check] = CheckStatusDone
+	}
+}
+
+// report passes a copy of result, which the analysis goes on filling, to the callback.
+func (p *partialReporter) report(result *AnalysisResult) {
+	if p == nil {
+		return
+	}
+	copied := *result
+	copied.Errors = maps.Clone(result.Errors)
+	copied.SecurityFindings = slices.Clone(result.SecurityFindings)
+	p.fn(PartialResult{Result: &copied, Checks: maps.Clone(p.checks)})
+}
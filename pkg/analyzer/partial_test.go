@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAnalyzePage_PartialResults(t *testing.T) {
+	// The link check waits until the first partial result is in, so it must come before.
+	parsed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-parsed
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Staged</title></head><body><h1>Hi</h1><a href="/slow">Slow</a></body></html>`)
+	}))
+	defer server.Close()
+
+	var partials []PartialResult
+	var once sync.Once
+	opts := DefaultOptions()
+	opts.Partial = func(p PartialResult) {
+		partials = append(partials, p)
+		once.Do(func() { close(parsed) })
+	}
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(partials) == 0 {
+		t.Fatal("Expected a partial result before the link checks completed, but got none")
+	}
+
+	p := partials[0]
+	if p.Result.Title != "Staged" || p.Result.HTMLVersion != "HTML5" || p.Result.Headings["h1"] != 1 {
+		t.Errorf("Expected the document checks in the partial result, but got %+v", p.Result)
+	}
+	if p.Result.LinkResults != nil || p.Result.Score != 0 {
+		t.Errorf("Expected no link results or score before the link checks, but got %+v and %d", p.Result.LinkResults, p.Result.Score)
+	}
+	want := map[string]string{
+		CheckHTMLVersion: CheckStatusDone, CheckHeadings: CheckStatusDone, CheckLinks: CheckStatusDone,
+		CheckCSSResources: CheckStatusDone, CheckLoginForm: CheckStatusDone, CheckMedia: CheckStatusDone,
+		CheckSecurity: CheckStatusDone, CheckDNS: CheckStatusRunning, CheckLinkStatus: CheckStatusRunning,
+	}
+	if !reflect.DeepEqual(p.Checks, want) {
+		t.Errorf("Expected check statuses %v, but got %v", want, p.Checks)
+	}
+
+	if len(result.LinkResults) != 1 || result.Score == 0 {
+		t.Errorf("Expected the complete result with its link results and score, but got %+v", result)
+	}
+	if p.Result == result {
+		t.Error("Expected the partial result to be a copy")
+	}
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxPlaceholders caps the placeholders reported for a page.
+const maxPlaceholders = 50
+
+// Values of Placeholder.Kind.
+const (
+	PlaceholderLoremIpsum = "lorem_ipsum"
+	PlaceholderTODO       = "todo"
+	PlaceholderComingSoon = "coming_soon"
+	PlaceholderCMSDefault = "cms_default"
+)
+
+// placeholderPatterns match the placeholder text of each kind. TODO and FIXME only match in
+// capitals, as "todo" is a common word in Spanish and Portuguese.
+var placeholderPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{PlaceholderLoremIpsum, regexp.MustCompile(`(?i)\blorem\s+ipsum\b|\bdolor\s+sit\s+amet\b`)},
+	{PlaceholderTODO, regexp.MustCompile(`\b(?:TODO|FIXME)\b`)},
+	{PlaceholderComingSoon, regexp.MustCompile(`(?i)\bcoming\s+soon\b|\bunder\s+construction\b`)},
+	{PlaceholderCMSDefault, regexp.MustCompile(`(?i)\bjust\s+another\s+wordpress\s+site\b|` +
+		`\bwelcome\s+to\s+wordpress\.\s+this\s+is\s+your\s+first\s+post\b|` +
+		`\bthis\s+is\s+an\s+example\s+page\b|\ba\s+wordpress\s+commenter\b|\bhello\s+world!|\bsample\s+page\b|` +
+		`\bwelcome\s+to\s+(?:your\s+new\s+)?(?:drupal|joomla!?)|` +
+		`\byour\s+(?:site|store|company|business)\s+(?:name|title)\s+here\b|` +
+		`\b(?:insert|add|enter)\s+(?:your\s+)?(?:text|content)\s+here\b|\bclick\s+here\s+to\s+edit\b`)},
+}
+
+// Placeholder is placeholder text left in the visible text of a page, such as "lorem ipsum"
+// or the sample content of a CMS.
+type Placeholder struct {
+	// Kind is one of the Placeholder constants.
+	Kind string `json:"kind" xml:"kind,attr"`
+	// Text is the placeholder as it first appears.
+	Text string `json:"text" xml:"text,attr"`
+	// Count is how many times it appears, in any case.
+	Count int `json:"count" xml:"count,attr"`
+	// Context is the text around its first appearance.
+	Context string `json:"context" xml:",chardata"`
+}
+
+// findPlaceholders returns the placeholder text in texts, the visible text nodes of a page,
+// in order of first appearance. The texts are read as one, so placeholders may span inline
+// elements.
+func findPlaceholders(texts []string) []Placeholder {
+	text := strings.Join(texts, " ")
+	var placeholders []Placeholder
+	var starts []int
+	found := make(map[string]int)
+	for _, p := range placeholderPatterns {
+		for _, loc := range p.pattern.FindAllStringIndex(text, -1) {
+			match := strings.Join(strings.Fields(text[loc[0]:loc[1]]), " ")
+			key := p.kind + "\x00" + strings.ToLower(match)
+			if i, ok := found[key]; ok {
+				placeholders[i].Count++
+				continue
+			}
+			found[key] = len(placeholders)
+			placeholders = append(placeholders, Placeholder{Kind: p.kind, Text: match, Count: 1, Context: textContext(text, loc[0], loc[1])})
+			starts = append(starts, loc[0])
+		}
+	}
+	if len(placeholders) == 0 {
+		return nil
+	}
+	order := make([]int, len(placeholders))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(starts[a], starts[b]) })
+	sorted := make([]Placeholder, 0, min(len(order), maxPlaceholders))
+	for _, i := range order[:min(len(order), maxPlaceholders)] {
+		sorted = append(sorted, placeholders[i])
+	}
+	return sorted
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	texts := []string{"Our new shop is ", "coming soon", ". Lorem\n  ipsum dolor sit amet. ", "LOREM IPSUM again.",
+		" Todo el mundo. TODO: pricing. Hello world! ", "Just another WordPress site"}
+	got := findPlaceholders(texts)
+	want := []Placeholder{
+		{Kind: PlaceholderComingSoon, Text: "coming soon", Count: 1},
+		{Kind: PlaceholderLoremIpsum, Text: "Lorem ipsum", Count: 2},
+		{Kind: PlaceholderLoremIpsum, Text: "dolor sit amet", Count: 1},
+		{Kind: PlaceholderTODO, Text: "TODO", Count: 1},
+		{Kind: PlaceholderCMSDefault, Text: "Hello world!", Count: 1},
+		{Kind: PlaceholderCMSDefault, Text: "Just another WordPress site", Count: 1},
+	}
+	for i := range got {
+		if got[i].Context == "" {
+			t.Errorf("Expected a context for %q", got[i].Text)
+		}
+		got[i].Context = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestFindPlaceholders_None(t *testing.T) {
+	if got := findPlaceholders([]string{"A finished page, with todo lists and a world of content."}); got != nil {
+		t.Errorf("Expected no placeholders, but got %+v", got)
+	}
+}
+
+func TestFindPlaceholders_Repeated(t *testing.T) {
+	var texts []string
+	for i := range 60 {
+		texts = append(texts, fmt.Sprintf("Your site name here %d. Insert text here %d.", i, i))
+	}
+	got := findPlaceholders(texts)
+	if len(got) != 2 || got[0].Count != 60 || got[1].Count != 60 {
+		t.Errorf("Expected 2 placeholders appearing 60 times, but got %+v", got)
+	}
+}
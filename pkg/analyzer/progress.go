@@ -0,0 +1,65 @@
+package analyzer
+
+import "sync"
+
+// Stages of an analysis, in the order they run, as reported in Progress.Stage.
+const (
+	StageFetching      = "fetching"
+	StageParsing       = "parsing"
+	StageCheckingLinks = "checking_links"
+)
+
+// Progress reports how far an analysis has got. LinksChecked and LinksTotal are only set in
+// StageCheckingLinks, which is skipped for pages without links.
+type Progress struct {
+	Stage        string
+	LinksChecked int
+	LinksTotal   int
+}
+
+// progressReporter passes the progress of the link checks to Options.Progress one update at
+// a time, so the callback needs no locking of its own although every worker reports to it.
+// A nil reporter discards the updates.
+type progressReporter struct {
+	fn func(Progress)
+
+	mu      sync.Mutex
+	checked int
+	total   int
+}
+
+func newProgressReporter(fn func(Progress)) *progressReporter {
+	if fn == nil {
+		return nil
+	}
+	return &progressReporter{fn: fn}
+}
+
+// start reports that total links are about to be checked.
+func (p *progressReporter) start(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+	p.fn(Progress{Stage: StageCheckingLinks, LinksTotal: total})
+}
+
+// linkChecked reports that one more link has been checked.
+func (p *progressReporter) linkChecked() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checked++
+	p.fn(Progress{Stage: StageCheckingLinks, LinksChecked: p.checked, LinksTotal: p.total})
+}
+
+// reportStage calls fn, if set, with the start of stage.
+func reportStage(fn func(Progress), stage string) {
+	if fn != nil {
+		fn(Progress{Stage: stage})
+	}
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnalyzePage_ReportsProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fmt.Fprint(w, `<html><body><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a></body></html>`)
+	}))
+	defer server.Close()
+
+	var updates []Progress
+	opts := DefaultOptions()
+	opts.Workers = 3
+	opts.Progress = func(p Progress) { updates = append(updates, p) }
+
+	if _, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expected := []Progress{
+		{Stage: StageFetching},
+		{Stage: StageParsing},
+		{Stage: StageCheckingLinks, LinksTotal: 3},
+		{Stage: StageCheckingLinks, LinksChecked: 1, LinksTotal: 3},
+		{Stage: StageCheckingLinks, LinksChecked: 2, LinksTotal: 3},
+		{Stage: StageCheckingLinks, LinksChecked: 3, LinksTotal: 3},
+	}
+	if len(updates) != len(expected) {
+		t.Fatalf("Expected %d updates, but got %+v", len(expected), updates)
+	}
+	for i := range expected {
+		if updates[i] != expected[i] {
+			t.Errorf("Expected update %d to be %+v, but got %+v", i, expected[i], updates[i])
+		}
+	}
+}
+
+func TestProgressReporter_Nil(t *testing.T) {
+	p := newProgressReporter(nil)
+	if p != nil {
+		t.Fatalf("Expected no reporter without a callback, but got %+v", p)
+	}
+	// Updates to a nil reporter are dropped.
+	p.start(2)
+	p.linkChecked()
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultProxy is the operator-configured proxy for every outbound request. When it is nil,
+// the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+var defaultProxy atomic.Pointer[url.URL]
+
+// proxyDefaultPorts are used when a proxy URL does not name a port.
+var proxyDefaultPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"socks5":  "1080",
+	"socks5h": "1080",
+}
+
+// ParseProxyURL validates a proxy URL. HTTP, HTTPS and SOCKS5 proxies are supported.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := proxyDefaultPorts[u.Scheme]; !ok || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: must be an http, https, socks5 or socks5h URL with a host", u.Redacted())
+	}
+	return u, nil
+}
+
+// SetProxy routes all outbound requests through the proxy at rawURL. An empty rawURL falls
+// back to the proxy environment variables.
+func SetProxy(rawURL string) error {
+	if rawURL == "" {
+		defaultProxy.Store(nil)
+		return nil
+	}
+
+	u, err := ParseProxyURL(rawURL)
+	if err != nil {
+		return err
+	}
+	defaultProxy.Store(u)
+	return nil
+}
+
+type proxyContextKey struct{}
+
+// withProxy makes requests carrying ctx go through proxy instead of the default one.
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// proxyForRequest is the shared transport's Proxy function. A per-analysis proxy set with
+// withProxy wins over the operator's proxy, which wins over the environment. Proxied
+// targets are never dialed by us, so they are vetted against the destination checks here.
+func proxyForRequest(req *http.Request) (*url.URL, error) {
+	proxy, _ := req.Context().Value(proxyContextKey{}).(*url.URL)
+	if proxy == nil {
+		proxy = defaultProxy.Load()
+	}
+	if proxy == nil {
+		var err error
+		if proxy, err = http.ProxyFromEnvironment(req); err != nil || proxy == nil {
+			return proxy, err
+		}
+	}
+
+	if err := checkProxiedTarget(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return proxy, nil
+}
+
+// checkProxiedTarget resolves host locally and applies checkDestination to it, but only
+// when a host policy or the private network block is active.
+func checkProxiedTarget(ctx context.Context, host string) error {
+	if currentHostPolicy.Load() == nil && !blockPrivateNetworks.Load() {
+		return nil
+	}
+	if net.ParseIP(host) != nil {
+		return checkDestination(host, []string{host})
+	}
+
+	ips, err := resolverCache.lookup(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: could not resolve %s to vet it: %v", ErrBlockedAddress, host, err)
+	}
+	return checkDestination(host, ips)
+}
+
+// envProxyAddrs returns the addresses of the proxies configured through the environment.
+var envProxyAddrs = sync.OnceValue(func() []string {
+	var addrs []string
+	for _, target := range []string{"http://example.com", "https://example.com"} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if proxy, err := http.ProxyFromEnvironment(req); err == nil && proxy != nil {
+			addrs = append(addrs, proxyAddr(proxy))
+		}
+	}
+	return addrs
+})
+
+// isOperatorProxy reports whether addr (host:port) is the operator's own proxy, which may
+// live on a private network and is therefore exempt from the destination checks.
+// Per-analysis proxies get no such exemption.
+func isOperatorProxy(addr string) bool {
+	addr = strings.ToLower(addr)
+	if proxy := defaultProxy.Load(); proxy != nil {
+		return proxyAddr(proxy) == addr
+	}
+	for _, envAddr := range envProxyAddrs() {
+		if envAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func proxyAddr(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		port = proxyDefaultPorts[strings.ToLower(proxy.Scheme)]
+	}
+	return strings.ToLower(net.JoinHostPort(proxy.Hostname(), port))
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// outboundLimiter is a process-wide token bucket shared by every outbound request the
+// analyzer makes (page fetches, link checks and robots.txt lookups). It is unlimited
+// until SetOutboundRateLimit is called.
+var outboundLimiter = rate.NewLimiter(rate.Inf, 1)
+
+// SetOutboundRateLimit caps all outbound requests to requestsPerSecond, allowing bursts of
+// up to burst requests. A non-positive rate removes the limit.
+func SetOutboundRateLimit(requestsPerSecond float64, burst int) {
+	if requestsPerSecond <= 0 {
+		outboundLimiter.SetLimit(rate.Inf)
+		return
+	}
+
+	outboundLimiter.SetBurst(max(burst, 1))
+	outboundLimiter.SetLimit(rate.Limit(requestsPerSecond))
+}
+
+// rateLimitedTransport waits for an outboundLimiter token before every round trip,
+// including each hop of a redirect chain.
+type rateLimitedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := outboundLimiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
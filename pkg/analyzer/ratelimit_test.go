@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetOutboundRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	SetOutboundRateLimit(20, 1)
+	defer SetOutboundRateLimit(0, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The first request uses the burst token; the remaining four wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected 5 requests at 20 req/s to take at least 180ms, but took %v", elapsed)
+	}
+}
+
+func TestSetOutboundRateLimit_Unlimited(t *testing.T) {
+	SetOutboundRateLimit(1, 1)
+	SetOutboundRateLimit(0, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected unlimited requests to be fast, but took %v", elapsed)
+	}
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// maxRDAPResponseBytes caps how much of an RDAP answer is read.
+	maxRDAPResponseBytes = 1 << 20
+	// rdapCacheTTL is how long the registration of a domain is reused, so the pages of a crawl
+	// do not each query the RDAP service.
+	rdapCacheTTL = 6 * time.Hour
+	// maxRDAPCacheEntries bounds the cache; expired entries are dropped once it is reached.
+	maxRDAPCacheEntries = 10000
+)
+
+// rdapClient queries the RDAP service. Like the markup validator it is a service the server is
+// configured with, so it does not go through the analyses' transport. It follows the redirects
+// of bootstrap services such as rdap.org to the registries' servers.
+var rdapClient = &http.Client{Timeout: 10 * time.Second}
+
+// DomainRegistration is the registration of the registrable domain of the analyzed host, as
+// published over RDAP.
+type DomainRegistration struct {
+	// Domain is the registrable domain, e.g. "example.co.uk" for "www.example.co.uk".
+	Domain string `json:"domain" xml:"domain,attr"`
+	// Registered and Expires are when the domain was registered and when the registration
+	// expires; nil if the registry does not publish them.
+	Registered *time.Time `json:"registered,omitempty" xml:"registered,omitempty"`
+	Expires    *time.Time `json:"expires,omitempty" xml:"expires,omitempty"`
+}
+
+// rdapDomain is the part of an RDAP domain object the registration is read from.
+type rdapDomain struct {
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+}
+
+type rdapEntry struct {
+	registration *DomainRegistration
+	expires      time.Time
+}
+
+var rdapCache = struct {
+	sync.Mutex
+	entries map[string]rdapEntry
+}{entries: make(map[string]rdapEntry)}
+
+// registrableDomain returns the domain host is registered under, reporting false for IP
+// addresses and hosts that are public suffixes or not under one, such as "localhost".
+func registrableDomain(host string) (string, bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if _, err := netip.ParseAddr(host); err == nil {
+		return "", false
+	}
+	if _, icann := publicsuffix.PublicSuffix(host); !icann {
+		return "", false
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	return domain, err == nil
+}
+
+// lookupRegistration returns the registration of the registrable domain of host from the RDAP
+// service at rdapURL, such as https://rdap.org/. It returns nil without an error for hosts
+// without a registrable domain and domains the service does not know.
+func lookupRegistration(ctx context.Context, rdapURL, host string) (_ *DomainRegistration, err error) {
+	ctx, span := tracer.Start(ctx, "lookupRegistration")
+	defer func() { endSpan(span, err) }()
+
+	domain, ok := registrableDomain(host)
+	if !ok {
+		return nil, nil
+	}
+	key := rdapURL + " " + domain
+	rdapCache.Lock()
+	entry, cached := rdapCache.entries[key]
+	rdapCache.Unlock()
+	if cached && time.Now().Before(entry.expires) {
+		return entry.registration, nil
+	}
+
+	registration, err := queryRDAP(ctx, rdapURL, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rdapCache.Lock()
+	if len(rdapCache.entries) >= maxRDAPCacheEntries {
+		now := time.Now()
+		for key, entry := range rdapCache.entries {
+			if !now.Before(entry.expires) {
+				delete(rdapCache.entries, key)
+			}
+		}
+	}
+	if len(rdapCache.entries) < maxRDAPCacheEntries {
+		rdapCache.entries[key] = rdapEntry{registration: registration, expires: time.Now().Add(rdapCacheTTL)}
+	}
+	rdapCache.Unlock()
+	return registration, nil
+}
+
+// queryRDAP asks the RDAP service at rdapURL for the registration of domain.
+func queryRDAP(ctx context.Context, rdapURL, domain string) (*DomainRegistration, error) {
+	base, err := url.Parse(rdapURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RDAP URL: %w", err)
+	}
+	u := base.JoinPath("domain", domain)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	resp, err := rdapClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RDAP service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RDAP service answered %s", resp.Status)
+	}
+	var answer rdapDomain
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRDAPResponseBytes)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid RDAP response: %w", err)
+	}
+
+	registration := &DomainRegistration{Domain: domain}
+	for _, event := range answer.Events {
+		if event.Date.IsZero() {
+			continue
+		}
+		date := event.Date.UTC()
+		switch event.Action {
+		case "registration":
+			registration.Registered = &date
+		case "expiration":
+			registration.Expires = &date
+		}
+	}
+	return registration, nil
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+		ok       bool
+	}{
+		{"www.example.com", "example.com", true},
+		{"Shop.Example.CO.UK.", "example.co.uk", true},
+		{"example.com", "example.com", true},
+		{"co.uk", "", false},
+		{"localhost", "", false},
+		{"site.internal", "", false},
+		{"192.0.2.1", "", false},
+		{"2001:db8::1", "", false},
+	}
+
+	for _, tc := range testCases {
+		got, ok := registrableDomain(tc.host)
+		if got != tc.expected || ok != tc.ok {
+			t.Errorf("Expected registrableDomain(%q) to be %q, %v, but got %q, %v", tc.host, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestLookupRegistration(t *testing.T) {
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		switch r.URL.Path {
+		case "/rdap/domain/example.com":
+			w.Header().Set("Content-Type", "application/rdap+json")
+			fmt.Fprint(w, `{"objectClassName": "domain", "ldhName": "EXAMPLE.COM", "events": [
+				{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+				{"eventAction": "expiration", "eventDate": "2026-08-13T04:00:00+02:00"},
+				{"eventAction": "last update of RDAP database", "eventDate": "2025-01-01T00:00:00Z"}
+			]}`)
+		case "/rdap/domain/broken.com":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	rdapURL := server.URL + "/rdap/"
+
+	got, err := lookupRegistration(context.Background(), rdapURL, "www.example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	registered := time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 8, 13, 2, 0, 0, 0, time.UTC)
+	if got == nil || got.Domain != "example.com" || got.Registered == nil || !got.Registered.Equal(registered) || got.Expires == nil || !got.Expires.Equal(expires) {
+		t.Errorf("Expected example.com registered %v and expiring %v, but got %+v", registered, expires, got)
+	}
+
+	if _, err := lookupRegistration(context.Background(), rdapURL, "example.com"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Errorf("Expected the registration to be cached for the whole domain, but got %d queries", n)
+	}
+
+	if got, err := lookupRegistration(context.Background(), rdapURL, "unknown.com"); got != nil || err != nil {
+		t.Errorf("Expected no registration for an unknown domain, but got %+v and %v", got, err)
+	}
+	if got, err := lookupRegistration(context.Background(), rdapURL, "127.0.0.1"); got != nil || err != nil {
+		t.Errorf("Expected no registration for an IP address, but got %+v and %v", got, err)
+	}
+	if _, err := lookupRegistration(context.Background(), rdapURL, "broken.com"); err == nil {
+		t.Error("Expected an error when the RDAP service fails")
+	}
+}
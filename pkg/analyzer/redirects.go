@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxRedirects is the most redirects a request follows, as with net/http's default policy.
+const maxRedirects = 10
+
+var (
+	// ErrRedirectLoop means a request was redirected back to a URL it had already visited.
+	ErrRedirectLoop = errors.New("redirect loop")
+	// ErrTooManyRedirects means a request was still being redirected after maxRedirects.
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// RedirectError is returned when a request stops following redirects. It wraps
+// ErrRedirectLoop or ErrTooManyRedirects.
+type RedirectError struct {
+	// Redirects lists the URLs the request was redirected to, in order, up to the one it
+	// stopped at.
+	Redirects []string
+	err       error
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("%v: %s", e.err, strings.Join(e.Redirects, " -> "))
+}
+
+func (e *RedirectError) Unwrap() error {
+	return e.err
+}
+
+// checkRedirect is the redirect policy of the analyzer's clients: it stops a request going
+// round in a loop as soon as it revisits a URL, rather than after maxRedirects.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	var redirects []string
+	for _, previous := range via[1:] {
+		redirects = append(redirects, previous.URL.String())
+	}
+	redirects = append(redirects, req.URL.String())
+
+	if slices.ContainsFunc(via, func(previous *http.Request) bool { return previous.URL.String() == req.URL.String() }) {
+		return &RedirectError{Redirects: redirects, err: ErrRedirectLoop}
+	}
+	if len(via) >= maxRedirects {
+		return &RedirectError{Redirects: redirects, err: ErrTooManyRedirects}
+	}
+	return nil
+}
+
+// redirectsOf returns the URLs the request of resp was redirected to, in order and ending
+// with the URL of resp itself, or nil if it was not redirected.
+func redirectsOf(resp *http.Response) []string {
+	var redirects []string
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirects = append(redirects, req.URL.String())
+	}
+	slices.Reverse(redirects)
+	return redirects
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAnalyzePage_Redirects(t *testing.T) {
+	var loopRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/older", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/page", http.StatusFound) })
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><a href="/moved">Moved</a><a href="/loop-a">Loop</a><a href="/ok">OK</a></body></html>`)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/ok", http.StatusMovedPermanently) })
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/loop-a", func(w http.ResponseWriter, r *http.Request) {
+		loopRequests.Add(1)
+		http.Redirect(w, r, "/loop-b", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-b", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/loop-a", http.StatusFound) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.LinkCacheTTL = 0
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/old", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if want := []string{server.URL + "/older", server.URL + "/page"}; !reflect.DeepEqual(result.Redirects, want) {
+		t.Errorf("Expected page redirects %q, but got %q", want, result.Redirects)
+	}
+
+	links := make(map[string]LinkResult)
+	for _, link := range result.LinkResults {
+		links[link.URL] = link
+	}
+	if moved := links[server.URL+"/moved"]; moved.Status != LinkStatusOK || !reflect.DeepEqual(moved.Redirects, []string{server.URL + "/ok"}) {
+		t.Errorf("Expected /moved to redirect to /ok, but got %+v", moved)
+	}
+	if ok := links[server.URL+"/ok"]; ok.Redirects != nil {
+		t.Errorf("Expected no redirects for /ok, but got %q", ok.Redirects)
+	}
+	loop := links[server.URL+"/loop-a"]
+	if want := []string{server.URL + "/loop-b", server.URL + "/loop-a"}; loop.Status != LinkStatusInaccessible || !reflect.DeepEqual(loop.Redirects, want) {
+		t.Errorf("Expected /loop-a to loop through %q, but got %+v", want, loop)
+	}
+	// The loop is stopped before /loop-a is requested again, and not retried.
+	if n := loopRequests.Load(); n != 1 {
+		t.Errorf("Expected /loop-a to be requested once, but it was requested %d times", n)
+	}
+
+	_, err = AnalyzePage(context.Background(), testLogger, server.URL+"/loop-a", opts)
+	var redirectErr *RedirectError
+	if !errors.Is(err, ErrRedirectLoop) || !errors.As(err, &redirectErr) || len(redirectErr.Redirects) != 2 {
+		t.Errorf("Expected a redirect loop error, but got %v", err)
+	}
+}
+
+func TestCheckRedirect_TooMany(t *testing.T) {
+	var hops atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, fmt.Sprintf("/%d", hops.Add(1)), http.StatusFound)
+	}))
+	defer server.Close()
+
+	_, err := pageClient.Get(server.URL)
+	var redirectErr *RedirectError
+	if !errors.Is(err, ErrTooManyRedirects) || !errors.As(err, &redirectErr) || len(redirectErr.Redirects) != maxRedirects {
+		t.Errorf("Expected to stop after %d redirects, but got %v", maxRedirects, err)
+	}
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxResultCacheEntries bounds the result cache; expired entries are pruned first
+// and, if that is not enough, the cache is cleared.
+const maxResultCacheEntries = 1000
+
+// ResultCache keeps complete analysis results by page URL for a fixed TTL, so popular
+// URLs are not re-analyzed on every request. It is safe for concurrent use.
+type ResultCache struct {
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]resultCacheEntry
+}
+
+type resultCacheEntry struct {
+	result  *AnalysisResult
+	expires time.Time
+}
+
+// NewResultCache returns a cache that keeps results for ttl. A non-positive ttl
+// returns a cache that never stores anything.
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		ttl:     ttl,
+		entries: make(map[string]resultCacheEntry),
+	}
+}
+
+// Get returns the cached result for pageURL if a fresh one exists.
+func (c *ResultCache) Get(pageURL string) (*AnalysisResult, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[resultCacheKey(pageURL)]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// Stale returns the last result stored for pageURL even if it has expired, so it can be
+// revalidated with the origin instead of re-analyzed from scratch.
+func (c *ResultCache) Stale(pageURL string) (*AnalysisResult, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[resultCacheKey(pageURL)]
+	if !ok {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// Put stores result as the latest analysis of pageURL.
+func (c *ResultCache) Put(pageURL string, result *AnalysisResult) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxResultCacheEntries {
+		now := time.Now()
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxResultCacheEntries {
+			c.entries = make(map[string]resultCacheEntry)
+		}
+	}
+
+	c.entries[resultCacheKey(pageURL)] = resultCacheEntry{result: result, expires: time.Now().Add(c.ttl)}
+}
+
+// resultCacheKey normalizes pageURL so trivially different spellings share an entry.
+func resultCacheKey(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	return normalizeURL(u, NormalizeOptions{}).String()
+}
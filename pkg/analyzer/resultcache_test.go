@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	cache := NewResultCache(time.Minute)
+	result := &AnalysisResult{Title: "Example"}
+
+	if _, ok := cache.Get("https://example.com/"); ok {
+		t.Fatal("Expected a miss on an empty cache")
+	}
+
+	cache.Put("https://example.com/", result)
+
+	got, ok := cache.Get("HTTPS://Example.com:443/#top")
+	if !ok || got != result {
+		t.Errorf("Expected an equivalent URL to hit the cached result, got (%v, %v)", got, ok)
+	}
+	if _, ok := cache.Get("https://example.com/other"); ok {
+		t.Error("Expected a different URL to miss")
+	}
+}
+
+func TestResultCache_Expiry(t *testing.T) {
+	cache := NewResultCache(10 * time.Millisecond)
+	cache.Put("https://example.com/", &AnalysisResult{})
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.Get("https://example.com/"); ok {
+		t.Error("Expected the result to expire after its TTL")
+	}
+}
+
+func TestResultCache_Disabled(t *testing.T) {
+	cache := NewResultCache(0)
+	cache.Put("https://example.com/", &AnalysisResult{})
+
+	if _, ok := cache.Get("https://example.com/"); ok {
+		t.Error("Expected a zero TTL cache to store nothing")
+	}
+}
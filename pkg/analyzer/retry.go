@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how often and how patiently failed requests are retried.
+type RetryPolicy struct {
+	// MaxRetries is the total number of attempts made for a request.
+	MaxRetries int
+	// InitialBackoff is the wait after the first failed attempt; it doubles on each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff; zero or less leaves it uncapped.
+	MaxBackoff time.Duration
+	// Jitter randomizes each backoff by up to this fraction (0.2 means ±20%) so that
+	// concurrent workers do not retry in lockstep.
+	Jitter float64
+}
+
+// attempts returns the number of attempts to make, always at least one.
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxRetries, 1)
+}
+
+// backoff returns the wait before the attempt that follows the given failed one (0-based).
+func (p RetryPolicy) backoff(failedAttempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 0; i < failedAttempt && wait < math.MaxInt64/2; i++ {
+		wait *= 2
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && wait > 0 {
+		spread := float64(wait) * p.Jitter
+		wait += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+
+	return max(wait, 0)
+}
+
+// sleepContext waits for d, returning early with the context's error if it is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	testCases := []struct {
+		failedAttempt int
+		want          time.Duration
+	}{
+		{failedAttempt: 0, want: time.Second},
+		{failedAttempt: 1, want: 2 * time.Second},
+		{failedAttempt: 2, want: 4 * time.Second},
+		{failedAttempt: 3, want: 5 * time.Second},
+		{failedAttempt: 70, want: 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		if got := policy.backoff(tc.failedAttempt); got != tc.want {
+			t.Errorf("backoff(%d) = %v, want %v", tc.failedAttempt, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(0)
+		if got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("backoff(0) = %v, want within ±50%% of 1s", got)
+		}
+	}
+}
+
+func TestRetryPolicy_Attempts(t *testing.T) {
+	if got := (RetryPolicy{MaxRetries: 0}).attempts(); got != 1 {
+		t.Errorf("attempts() with MaxRetries 0 = %d, want 1", got)
+	}
+	if got := (RetryPolicy{MaxRetries: 4}).attempts(); got != 4 {
+		t.Errorf("attempts() with MaxRetries 4 = %d, want 4", got)
+	}
+}
+
+func TestSleepContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := sleepContext(ctx, time.Minute)
+
+	if err == nil {
+		t.Error("Expected an error when the context is canceled")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected sleep to stop promptly, but took %v", elapsed)
+	}
+}
+
+func TestLinkAccessibilityChecker_CustomRetryPolicy(t *testing.T) {
+	var requestCount int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 5, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	inaccessibleLinks := make(chan string, 1)
+	linkAccessibilityChecker(context.Background(), testLogger, newLinkCheckState(opts), server.URL, inaccessibleLinks)
+
+	select {
+	case <-inaccessibleLinks:
+	default:
+		t.Error("Expected the link to be reported as inaccessible")
+	}
+	if n := atomic.LoadInt32(&requestCount); n != 5 {
+		t.Errorf("Expected 5 attempts, but got %d", n)
+	}
+}
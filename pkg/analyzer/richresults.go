@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Values of RichResult.Enhancement: the Google rich results a page can be eligible for.
+const (
+	RichResultArticle = "article"
+	RichResultFAQ     = "faq"
+	RichResultHowTo   = "how_to"
+	RichResultProduct = "product_snippet"
+)
+
+// richResultTypes maps the schema.org types checked for rich results to their enhancement.
+var richResultTypes = map[string]string{
+	"Article":     RichResultArticle,
+	"NewsArticle": RichResultArticle,
+	"BlogPosting": RichResultArticle,
+	"FAQPage":     RichResultFAQ,
+	"HowTo":       RichResultHowTo,
+	"Product":     RichResultProduct,
+}
+
+// RichResult is an item of JSON-LD structured data on the page of a type with a Google rich
+// result, and whether it has the properties that rich result needs.
+type RichResult struct {
+	// Type is the schema.org type of the item, e.g. "Product" or "NewsArticle".
+	Type string `json:"type" xml:"type,attr"`
+	// Enhancement is the rich result the type is for, one of the RichResult constants.
+	Enhancement string `json:"enhancement" xml:"enhancement,attr"`
+	// Eligible reports whether the item has every required property.
+	Eligible bool `json:"eligible" xml:"eligible,attr"`
+	// Missing lists the required properties the item lacks, as dotted paths such as
+	// "mainEntity.acceptedAnswer.text". Alternatives are joined with "|".
+	Missing []string `json:"missing,omitempty" xml:"missing,omitempty"`
+}
+
+// isJSONLD reports whether scriptType, the type attribute of a <script>, marks JSON-LD.
+func isJSONLD(scriptType string) bool {
+	mediaType, _, _ := strings.Cut(scriptType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "application/ld+json")
+}
+
+// findStructuredData returns the contents of the JSON-LD scripts of the document.
+func findStructuredData(doc *goquery.Document) []string {
+	var blocks []string
+	doc.Find("script[type]").Each(func(i int, s *goquery.Selection) {
+		if isJSONLD(s.AttrOr("type", "")) {
+			blocks = append(blocks, s.Text())
+		}
+	})
+	return blocks
+}
+
+// richResults checks the items of the JSON-LD blocks for the rich results of their types, in
+// document order. Blocks that are not valid JSON are skipped, as search engines do.
+func richResults(blocks []string) []RichResult {
+	var results []RichResult
+	for _, block := range blocks {
+		var data any
+		if err := json.Unmarshal([]byte(block), &data); err != nil {
+			continue
+		}
+		for _, item := range structuredDataItems(data) {
+			if result, ok := checkRichResult(item); ok {
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
+
+// structuredDataItems returns the top-level items of a JSON-LD block: the block itself, the
+// elements of a top-level array and the items of an @graph.
+func structuredDataItems(data any) []map[string]any {
+	var items []map[string]any
+	switch v := data.(type) {
+	case []any:
+		for _, element := range v {
+			items = append(items, structuredDataItems(element)...)
+		}
+	case map[string]any:
+		if _, ok := v["@type"]; ok {
+			items = append(items, v)
+		}
+		if graph, ok := v["@graph"]; ok {
+			items = append(items, structuredDataItems(graph)...)
+		}
+	}
+	return items
+}
+
+// checkRichResult checks item for the rich result of its type, reporting false if no type of
+// the item has one.
+func checkRichResult(item map[string]any) (RichResult, bool) {
+	var result RichResult
+	for _, t := range stringValues(item["@type"]) {
+		// Types may be given as full IRIs, such as "https://schema.org/Product".
+		t = t[strings.LastIndexAny(t, "/:")+1:]
+		if enhancement, ok := richResultTypes[t]; ok {
+			result = RichResult{Type: t, Enhancement: enhancement}
+			break
+		}
+	}
+	if result.Type == "" {
+		return RichResult{}, false
+	}
+
+	var missing []string
+	require := func(path string, value any) {
+		if !hasValue(value) && !slices.Contains(missing, path) {
+			missing = append(missing, path)
+		}
+	}
+	switch result.Enhancement {
+	case RichResultArticle:
+		for _, property := range []string{"headline", "image", "datePublished", "author"} {
+			require(property, item[property])
+		}
+	case RichResultFAQ:
+		require("mainEntity", item["mainEntity"])
+		for _, question := range objectValues(item["mainEntity"]) {
+			require("mainEntity.name", question["name"])
+			require("mainEntity.acceptedAnswer", question["acceptedAnswer"])
+			for _, answer := range objectValues(question["acceptedAnswer"]) {
+				require("mainEntity.acceptedAnswer.text", answer["text"])
+			}
+		}
+	case RichResultHowTo:
+		require("name", item["name"])
+		require("step", item["step"])
+	case RichResultProduct:
+		require("name", item["name"])
+		if !hasValue(item["offers"]) && !hasValue(item["review"]) && !hasValue(item["aggregateRating"]) {
+			missing = append(missing, "offers|review|aggregateRating")
+		}
+	}
+	result.Eligible = len(missing) == 0
+	result.Missing = missing
+	return result, true
+}
+
+// hasValue reports whether a JSON-LD property is set to something other than null, blank
+// text or an empty list or object.
+func hasValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []any:
+		return slices.ContainsFunc(v, hasValue)
+	case map[string]any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// stringValues returns the strings of a JSON-LD value that is a string or a list of them.
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var values []string
+		for _, element := range v {
+			if s, ok := element.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// objectValues returns the objects of a JSON-LD value that is an object or a list of them.
+func objectValues(value any) []map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		var values []map[string]any
+		for _, element := range v {
+			if object, ok := element.(map[string]any); ok {
+				values = append(values, object)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestRichResults(t *testing.T) {
+	testCases := []struct {
+		name        string
+		htmlContent string
+		want        []RichResult
+	}{
+		{
+			name: "Eligible Product and Article",
+			htmlContent: `<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Product", "name": "Kettle", "offers": {"price": "20"}}</script>
+				<script type="Application/LD+JSON">{"@type": ["CreativeWork", "NewsArticle"], "headline": "News", "image": ["a.png"], "datePublished": "2024-01-01", "author": {"name": "Ann"}}</script>`,
+			want: []RichResult{
+				{Type: "Product", Enhancement: RichResultProduct, Eligible: true},
+				{Type: "NewsArticle", Enhancement: RichResultArticle, Eligible: true},
+			},
+		},
+		{
+			name: "Missing Properties",
+			htmlContent: `<script type="application/ld+json">[
+				{"@type": "https://schema.org/Product", "name": " "},
+				{"@type": "HowTo", "name": "Brew", "step": []},
+				{"@type": "BlogPosting", "headline": "Post"}
+			]</script>`,
+			want: []RichResult{
+				{Type: "Product", Enhancement: RichResultProduct, Missing: []string{"name", "offers|review|aggregateRating"}},
+				{Type: "HowTo", Enhancement: RichResultHowTo, Missing: []string{"step"}},
+				{Type: "BlogPosting", Enhancement: RichResultArticle, Missing: []string{"image", "datePublished", "author"}},
+			},
+		},
+		{
+			name: "FAQ in a Graph",
+			htmlContent: `<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+				{"@type": "WebPage", "name": "Help"},
+				{"@type": "FAQPage", "mainEntity": [
+					{"@type": "Question", "name": "Why?", "acceptedAnswer": {"text": "Because."}},
+					{"@type": "Question", "name": "How?", "acceptedAnswer": {"@type": "Answer"}},
+					{"@type": "Question", "acceptedAnswer": {"text": ""}}
+				]}
+			]}</script>`,
+			want: []RichResult{
+				{Type: "FAQPage", Enhancement: RichResultFAQ, Missing: []string{"mainEntity.acceptedAnswer.text", "mainEntity.name"}},
+			},
+		},
+		{
+			name: "Invalid JSON and Other Scripts",
+			htmlContent: `<script type="application/ld+json">{"@type": "Product",</script>
+				<script>{"@type": "Product", "name": "x", "offers": {}}</script>
+				<script type="application/ld+json">{"@type": "Organization", "name": "Acme"}</script>`,
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := richResults(findStructuredData(doc)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("richResults() got = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsUserAgent is the product token matched against robots.txt User-agent lines.
+	robotsUserAgent = "web-analyzer"
+	// maxRobotsBytes caps how much of a robots.txt file is read, as recommended by RFC 9309.
+	maxRobotsBytes = 500 << 10
+)
+
+// robotsRules holds the Allow/Disallow rules of the robots.txt group that applies to us,
+// and the Crawl-delay it asks for.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	matcher *regexp.Regexp
+}
+
+// allows reports whether the rules permit fetching u. The longest matching pattern wins,
+// and Allow wins over Disallow when both match with the same length.
+func (r *robotsRules) allows(u *url.URL) bool {
+	if r == nil {
+		return true
+	}
+
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+
+	allowed, matchedLength := true, -1
+	for _, rule := range r.rules {
+		if !rule.matcher.MatchString(target) {
+			continue
+		}
+		if len(rule.pattern) > matchedLength || (len(rule.pattern) == matchedLength && rule.allow) {
+			allowed, matchedLength = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// parseRobots extracts the rules for userAgent from a robots.txt body, falling back to the
+// "*" group when no group names the agent explicitly.
+func parseRobots(body io.Reader, userAgent string) *robotsRules {
+	userAgent = strings.ToLower(userAgent)
+
+	var specific, wildcard []robotsRule
+	var specificDelay, wildcardDelay time.Duration
+	hasSpecificDelay := false
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			// A blank agent names no crawler, and would otherwise match every user agent.
+			if value != "" {
+				groupAgents = append(groupAgents, strings.ToLower(value))
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value, matcher: robotsPattern(value)}
+			for _, agent := range groupAgents {
+				if agent == "*" {
+					wildcard = append(wildcard, rule)
+				} else if strings.Contains(userAgent, agent) {
+					specific = append(specific, rule)
+				}
+			}
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			delay := time.Duration(seconds * float64(time.Second))
+			for _, agent := range groupAgents {
+				if agent == "*" {
+					wildcardDelay = delay
+				} else if strings.Contains(userAgent, agent) {
+					specificDelay, hasSpecificDelay = delay, true
+				}
+			}
+		}
+	}
+
+	delay := wildcardDelay
+	if hasSpecificDelay {
+		delay = specificDelay
+	}
+	if len(specific) > 0 {
+		return &robotsRules{rules: specific, crawlDelay: delay}
+	}
+	return &robotsRules{rules: wildcard, crawlDelay: delay}
+}
+
+// robotsPattern compiles a robots.txt path pattern, where "*" matches any sequence and a
+// trailing "$" anchors the end of the URL.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsCache fetches each host's robots.txt at most once per link validation run.
+type robotsCache struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	once  sync.Once
+	rules *robotsRules
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{hosts: make(map[string]*robotsEntry)}
+}
+
+// allows reports whether the robots.txt of the link's host permits fetching it.
+// Links that cannot be parsed are allowed and left for the link check to report.
+func (c *robotsCache) allows(ctx context.Context, logger *slog.Logger, link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+
+	origin := strings.ToLower(u.Scheme) + "://" + canonicalHost(u)
+
+	c.mu.Lock()
+	entry, ok := c.hosts[origin]
+	if !ok {
+		entry = &robotsEntry{}
+		c.hosts[origin] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.rules = fetchRobots(ctx, logger, origin)
+	})
+
+	return entry.rules.allows(u)
+}
+
+// Robots is what a site's robots.txt asks of web-analyzer. A nil *Robots allows everything
+// without delay.
+type Robots struct {
+	rules *robotsRules
+}
+
+// FetchRobots downloads the robots.txt of the origin of pageURL through the proxy of opts,
+// if any. Missing or unreachable files impose no restrictions.
+func FetchRobots(ctx context.Context, logger *slog.Logger, pageURL string, opts Options) *Robots {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	if opts.Proxy != nil {
+		ctx = withProxy(ctx, opts.Proxy)
+	}
+	return &Robots{rules: fetchRobots(ctx, logger, strings.ToLower(u.Scheme)+"://"+canonicalHost(u))}
+}
+
+// Allows reports whether robots.txt permits fetching pageURL. URLs that cannot be parsed
+// are allowed.
+func (r *Robots) Allows(pageURL string) bool {
+	u, err := url.Parse(pageURL)
+	if r == nil || err != nil {
+		return true
+	}
+	return r.rules.allows(u)
+}
+
+// CrawlDelay is the time robots.txt asks to leave between requests, if any.
+func (r *Robots) CrawlDelay() time.Duration {
+	if r == nil || r.rules == nil {
+		return 0
+	}
+	return r.rules.crawlDelay
+}
+
+// robotsTagDirectives are the X-Robots-Tag directives taking a value after a colon, which
+// must not be mistaken for the name of the crawler the rest of the header applies to.
+var robotsTagDirectives = map[string]bool{"unavailable_after": true, "max-snippet": true, "max-image-preview": true, "max-video-preview": true}
+
+// headerNoindex reports whether an X-Robots-Tag header of a response forbids indexing the
+// page. Headers naming another crawler, as in "googlebot: noindex", are ignored.
+func headerNoindex(header http.Header) bool {
+	for _, value := range header.Values("X-Robots-Tag") {
+		if agent, directives, ok := strings.Cut(value, ":"); ok && !strings.Contains(agent, ",") {
+			agent = strings.ToLower(strings.TrimSpace(agent))
+			if !robotsTagDirectives[agent] {
+				if agent != robotsUserAgent {
+					continue
+				}
+				value = directives
+			}
+		}
+		if hasNoindex(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRobots downloads and parses origin's robots.txt. Missing or unreachable files
+// impose no restrictions, so a broken robots.txt never hides links from the report.
+func fetchRobots(ctx context.Context, logger *slog.Logger, origin string) *robotsRules {
+	robotsURL := origin + "/robots.txt"
+	logger = logger.With(slog.String("robots_url", robotsURL))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		logger.WarnContext(ctx, "Could not create robots.txt request", slog.Any("error", err))
+		return nil
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to fetch robots.txt, allowing all", slog.Any("error", err))
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.DebugContext(ctx, "No usable robots.txt, allowing all", slog.Int("status_code", resp.StatusCode))
+		return nil
+	}
+
+	rules := parseRobots(io.LimitReader(resp.Body, maxRobotsBytes), robotsUserAgent)
+	logger.DebugContext(ctx, "Loaded robots.txt", slog.Int("rules", len(rules.rules)))
+	return rules
+}
@@ -0,0 +1,222 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	robotsTxt := `
+# Example robots.txt
+User-agent: *
+Disallow: /private/
+Disallow: /*.pdf$
+Allow: /private/public-page
+
+User-agent: web-analyzer
+User-agent: other-bot
+Disallow: /no-analyzers
+`
+
+	testCases := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "Specific Group Wins", userAgent: "web-analyzer", path: "/private/secret", want: true},
+		{name: "Specific Group Disallow", userAgent: "web-analyzer", path: "/no-analyzers/x", want: false},
+		{name: "Wildcard Group Disallow", userAgent: "some-crawler", path: "/private/secret", want: false},
+		{name: "Longest Match Allow", userAgent: "some-crawler", path: "/private/public-page", want: true},
+		{name: "End Anchored Wildcard", userAgent: "some-crawler", path: "/docs/guide.pdf", want: false},
+		{name: "End Anchor Not Matched", userAgent: "some-crawler", path: "/docs/guide.pdf?download=1", want: true},
+		{name: "Unmatched Path", userAgent: "some-crawler", path: "/about", want: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(robotsTxt), tc.userAgent)
+			u, _ := url.Parse("https://example.com" + tc.path)
+			if got := rules.allows(u); got != tc.want {
+				t.Errorf("allows(%q) for %q = %v, want %v", tc.path, tc.userAgent, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRobots_BlankAgentIsNoSpecificGroup(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow: /private\n\nUser-agent:\nDisallow: /\n"), robotsUserAgent)
+	u, _ := url.Parse("https://example.com/public")
+	if !rules.allows(u) {
+		t.Error("Expected a blank User-agent group not to apply to web-analyzer")
+	}
+}
+
+func TestParseRobots_EmptyDisallowAllowsAll(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), robotsUserAgent)
+	u, _ := url.Parse("https://example.com/anything")
+	if !rules.allows(u) {
+		t.Error("Expected an empty Disallow to allow everything")
+	}
+}
+
+func TestParseRobots_CrawlDelay(t *testing.T) {
+	testCases := []struct {
+		name      string
+		robotsTxt string
+		want      time.Duration
+	}{
+		{name: "Wildcard Group", robotsTxt: "User-agent: *\nCrawl-delay: 2\nDisallow: /x\n", want: 2 * time.Second},
+		{name: "Fractional Seconds", robotsTxt: "User-agent: *\nCrawl-delay: 0.5\n", want: 500 * time.Millisecond},
+		{name: "Specific Group Wins", robotsTxt: "User-agent: *\nCrawl-delay: 10\n\nUser-agent: web-analyzer\nCrawl-delay: 1\n", want: time.Second},
+		{name: "Other Agent Only", robotsTxt: "User-agent: other-bot\nCrawl-delay: 5\n", want: 0},
+		{name: "Blank Agent", robotsTxt: "User-agent: *\nCrawl-delay: 2\n\nUser-agent:\nCrawl-delay: 9\n", want: 2 * time.Second},
+		{name: "Invalid Value", robotsTxt: "User-agent: *\nCrawl-delay: soon\n", want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseRobots(strings.NewReader(tc.robotsTxt), robotsUserAgent).crawlDelay; got != tc.want {
+				t.Errorf("Expected a crawl delay of %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.UserAgent() != DefaultUserAgent {
+			t.Errorf("Expected robots.txt to be fetched as %q, but got %q", DefaultUserAgent, r.UserAgent())
+		}
+		w.Write([]byte("User-agent: *\nDisallow: /private\nCrawl-delay: 3\n"))
+	}))
+	defer server.Close()
+
+	robots := FetchRobots(context.Background(), testLogger, server.URL+"/some/page", DefaultOptions())
+	if robots.Allows(server.URL+"/private/page") || !robots.Allows(server.URL+"/public") {
+		t.Error("Expected /private to be disallowed and /public allowed")
+	}
+	if robots.CrawlDelay() != 3*time.Second {
+		t.Errorf("Expected a crawl delay of 3s, but got %v", robots.CrawlDelay())
+	}
+
+	var none *Robots
+	if !none.Allows(server.URL+"/private") || none.CrawlDelay() != 0 {
+		t.Error("Expected a nil Robots to allow everything without delay")
+	}
+}
+
+func TestRobotsCache_FetchesOncePerHost(t *testing.T) {
+	var robotsRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsRequests, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cache := newRobotsCache()
+	ctx := context.Background()
+
+	if cache.allows(ctx, testLogger, server.URL+"/blocked/page") {
+		t.Error("Expected /blocked/page to be disallowed")
+	}
+	if !cache.allows(ctx, testLogger, server.URL+"/open") {
+		t.Error("Expected /open to be allowed")
+	}
+	if n := atomic.LoadInt32(&robotsRequests); n != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, but got %d requests", n)
+	}
+}
+
+func TestRobotsCache_MissingRobotsAllowsAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	if !newRobotsCache().allows(context.Background(), testLogger, server.URL+"/page") {
+		t.Error("Expected a missing robots.txt to allow everything")
+	}
+}
+
+func TestValidateLinkAccessibility_RespectRobots(t *testing.T) {
+	var blockedRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+		case "/blocked":
+			atomic.AddInt32(&blockedRequests, 1)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	analysis := LinkAnalysis{
+		InternalLinks: []string{server.URL + "/ok", server.URL + "/blocked"},
+	}
+
+	opts := DefaultOptions()
+	opts.RespectRobots = true
+
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if len(report.Inaccessible) != 0 {
+		t.Errorf("Expected no inaccessible links, but got %v", report.Inaccessible)
+	}
+	if got := report.NotChecked[notCheckedRobots]; len(got) != 1 || got[0] != server.URL+"/blocked" {
+		t.Errorf("Expected /blocked to be reported as not checked, but got %v", report.NotChecked)
+	}
+	if n := atomic.LoadInt32(&blockedRequests); n != 0 {
+		t.Errorf("Expected no requests to the disallowed URL, but got %d", n)
+	}
+}
+
+func TestHeaderNoindex(t *testing.T) {
+	testCases := []struct {
+		name   string
+		values []string
+		want   bool
+	}{
+		{"None", nil, false},
+		{"Noindex", []string{"noindex"}, true},
+		{"Among Others", []string{"nofollow", "noarchive, NoIndex"}, true},
+		{"None Directive", []string{"none"}, true},
+		{"For web-analyzer", []string{"web-analyzer: noindex"}, true},
+		{"For Another Crawler", []string{"googlebot: noindex"}, false},
+		{"Directive With Value", []string{"unavailable_after: 2030-01-01, noindex"}, true},
+		{"Index", []string{"max-snippet: 20", "index"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, value := range tc.values {
+				header.Add("X-Robots-Tag", value)
+			}
+			if got := headerNoindex(header); got != tc.want {
+				t.Errorf("Expected %t for %q, but got %t", tc.want, tc.values, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	result := &AnalysisResult{
+		SecurityFindings: []SecurityFinding{
+			{RuleID: RuleMissingCSP, Severity: SeverityWarning, Message: "Content-Security-Policy header is missing"},
+			{RuleID: RuleMixedContent, Severity: SeverityError, Message: "Resource is loaded over plain HTTP", URL: "http://cdn.example/bg.png"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, "https://example.com/", result); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Expected valid JSON, but got: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Expected a single SARIF 2.1.0 run, but got version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != len(securityRules) {
+		t.Errorf("Expected %d rules, but got %d", len(securityRules), len(run.Tool.Driver.Rules))
+	}
+
+	testCases := []struct {
+		ruleID   string
+		level    string
+		location string
+	}{
+		{RuleMissingCSP, SeverityWarning, "https://example.com/"},
+		{RuleMixedContent, SeverityError, "http://cdn.example/bg.png"},
+	}
+	if len(run.Results) != len(testCases) {
+		t.Fatalf("Expected %d results, but got %d", len(testCases), len(run.Results))
+	}
+	for i, tc := range testCases {
+		got := run.Results[i]
+		if got.RuleID != tc.ruleID || got.Level != tc.level || got.Locations[0].PhysicalLocation.ArtifactLocation.URI != tc.location {
+			t.Errorf("Expected result %d to be %s/%s at %s, but got %+v", i, tc.ruleID, tc.level, tc.location, got)
+		}
+	}
+}
+
+func TestWriteSARIF_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, "https://example.com/", &AnalysisResult{}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	// SARIF requires results to be an array, even when empty.
+	if !bytes.Contains(buf.Bytes(), []byte(`"results": []`)) {
+		t.Errorf("Expected an empty results array, but got %s", buf.String())
+	}
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import "strings"
+
+// Penalties subtracted from a perfect score of 100 by scoreResult, each capped so a single
+// kind of problem cannot dominate the score.
+const (
+	inaccessibleLinkPenalty    = 5
+	maxInaccessibleLinkPenalty = 40
+	brokenAnchorPenalty        = 2
+	maxBrokenAnchorPenalty     = 10
+	maxSecurityPenalty         = 30
+	missingBasicsPenalty       = 5
+	checkErrorPenalty          = 5
+)
+
+// securityPenalties is the penalty per security finding, by severity.
+var securityPenalties = map[string]int{
+	SeverityError:   10,
+	SeverityWarning: 5,
+	SeverityNote:    1,
+}
+
+// scoreResult rates a result from 0 to 100 and grades it from A to F. Inaccessible links,
+// broken anchors, security findings, a missing title or doctype and incomplete checks all
+// lower the score.
+func scoreResult(r *AnalysisResult) (int, string) {
+	score := 100
+	score -= min(r.Links.InaccessibleCount*inaccessibleLinkPenalty, maxInaccessibleLinkPenalty)
+	score -= min(r.Links.BrokenAnchorCount*brokenAnchorPenalty, maxBrokenAnchorPenalty)
+
+	security := 0
+	for _, finding := range r.SecurityFindings {
+		// Few pages validate cleanly, so markup findings are reported without lowering the score.
+		if finding.RuleID == RuleInvalidMarkup {
+			continue
+		}
+		security += securityPenalties[finding.Severity]
+	}
+	score -= min(security, maxSecurityPenalty)
+
+	if strings.TrimSpace(r.Title) == "" {
+		score -= missingBasicsPenalty
+	}
+	if r.HTMLVersion == "" || strings.HasPrefix(r.HTMLVersion, "Unknown") {
+		score -= missingBasicsPenalty
+	}
+	score -= len(r.Errors) * checkErrorPenalty
+
+	score = max(score, 0)
+	return score, gradeFor(score)
+}
+
+func gradeFor(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
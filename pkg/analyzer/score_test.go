@@ -0,0 +1,64 @@
+package analyzer
+
+import "testing"
+
+func TestScoreResult(t *testing.T) {
+	testCases := []struct {
+		name          string
+		result        AnalysisResult
+		expectedScore int
+		expectedGrade string
+	}{
+		{
+			name:          "Clean page",
+			result:        AnalysisResult{Title: "Home", HTMLVersion: "HTML5"},
+			expectedScore: 100,
+			expectedGrade: "A",
+		},
+		{
+			name:          "Inaccessible links and broken anchors",
+			result:        AnalysisResult{Title: "Home", HTMLVersion: "HTML5", Links: LinkSummary{InaccessibleCount: 2, BrokenAnchorCount: 1}},
+			expectedScore: 88,
+			expectedGrade: "B",
+		},
+		{
+			name:          "Penalties are capped",
+			result:        AnalysisResult{Title: "Home", HTMLVersion: "HTML5", Links: LinkSummary{InaccessibleCount: 100}},
+			expectedScore: 60,
+			expectedGrade: "D",
+		},
+		{
+			name: "Security findings",
+			result: AnalysisResult{Title: "Home", HTMLVersion: "HTML5", SecurityFindings: []SecurityFinding{
+				{Severity: SeverityError}, {Severity: SeverityWarning}, {Severity: SeverityNote},
+			}},
+			expectedScore: 84,
+			expectedGrade: "B",
+		},
+		{
+			name:          "Missing basics and failed checks",
+			result:        AnalysisResult{HTMLVersion: "Unknown or No Doctype", Errors: map[string]string{CheckLinks: "failed"}},
+			expectedScore: 85,
+			expectedGrade: "B",
+		},
+		{
+			name: "Score never goes below zero",
+			result: AnalysisResult{
+				Links:            LinkSummary{InaccessibleCount: 100, BrokenAnchorCount: 100},
+				SecurityFindings: []SecurityFinding{{Severity: SeverityError}, {Severity: SeverityError}, {Severity: SeverityError}},
+				Errors:           map[string]string{CheckLinks: "a", CheckHeadings: "b", CheckLoginForm: "c", CheckCSSResources: "d", CheckHTMLVersion: "e"},
+			},
+			expectedScore: 0,
+			expectedGrade: "F",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, grade := scoreResult(&tc.result)
+			if score != tc.expectedScore || grade != tc.expectedGrade {
+				t.Errorf("Expected score %d (%s), but got %d (%s)", tc.expectedScore, tc.expectedGrade, score, grade)
+			}
+		})
+	}
+}
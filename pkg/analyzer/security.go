@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// newDomainAge is the age under which a domain counts as newly registered.
+const newDomainAge = 30 * 24 * time.Hour
+
+// Rule IDs of the security checks, as used in SecurityFinding.RuleID and SARIF reports.
+const (
+	RuleMissingHSTS               = "missing-hsts"
+	RuleMissingCSP                = "missing-csp"
+	RuleMissingContentTypeOptions = "missing-x-content-type-options"
+	RuleMissingFrameProtection    = "missing-clickjacking-protection"
+	RuleMixedContent              = "mixed-content"
+	RuleInsecureLoginForm         = "insecure-login-form"
+	RuleUnsandboxedIframe         = "unsandboxed-iframe"
+	// RuleInvalidMarkup findings come from the Nu HTML Checker of Options.ValidatorURL, with
+	// the severity it gives each message.
+	RuleInvalidMarkup = "invalid-markup"
+	// RuleNewDomainLoginForm needs the registration of the page's domain from the RDAP service
+	// of Options.RDAPURL.
+	RuleNewDomainLoginForm = "new-domain-login-form"
+	// RuleBlocklistedURL findings come from the blocklist of SetBlocklist and from Google Safe
+	// Browsing, once SetSafeBrowsing has set an API key.
+	RuleBlocklistedURL = "blocklisted-url"
+)
+
+// Severities of a SecurityFinding, matching the SARIF result levels.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// securityRule describes one security check.
+type securityRule struct {
+	ID          string
+	Severity    string
+	Description string
+}
+
+// securityRules lists every security check in the order findings are reported.
+var securityRules = []securityRule{
+	{RuleMissingHSTS, SeverityWarning, "HTTPS page does not send a Strict-Transport-Security header, so browsers may be downgraded to HTTP."},
+	{RuleMissingCSP, SeverityWarning, "Page does not send a Content-Security-Policy header to restrict where scripts and other content load from."},
+	{RuleMissingContentTypeOptions, SeverityNote, "Page does not send X-Content-Type-Options: nosniff, so browsers may guess content types."},
+	{RuleMissingFrameProtection, SeverityWarning, "Page sets neither X-Frame-Options nor a CSP frame-ancestors directive, so it can be framed for clickjacking."},
+	{RuleMixedContent, SeverityError, "HTTPS page loads a resource over plain HTTP."},
+	{RuleInsecureLoginForm, SeverityError, "Login form is served over plain HTTP, exposing credentials to the network."},
+	{RuleUnsandboxedIframe, SeverityNote, "Page embeds a third-party iframe without a sandbox attribute, so it may run scripts, submit forms and navigate the page."},
+	{RuleInvalidMarkup, SeverityWarning, "Markup does not validate against the HTML standard, according to the Nu HTML Checker."},
+	{RuleBlocklistedURL, SeverityError, "Page or one of its external links is on a blocklist of malicious sites, or flagged by Google Safe Browsing."},
+	{RuleNewDomainLoginForm, SeverityWarning, "Page with a login form is on a domain registered less than 30 days ago, a common trait of phishing pages."},
+}
+
+func securityRuleByID(id string) securityRule {
+	for _, rule := range securityRules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return securityRule{ID: id, Severity: SeverityWarning}
+}
+
+// securityFindings runs the security checks against the page at pageURL, using its response
+// header and the links found on it.
+func securityFindings(pageURL *url.URL, header http.Header, links LinkAnalysis, containsLoginForm bool) []SecurityFinding {
+	var findings []SecurityFinding
+	add := func(ruleID, message, link string) {
+		findings = append(findings, SecurityFinding{
+			RuleID:   ruleID,
+			Severity: securityRuleByID(ruleID).Severity,
+			Message:  message,
+			URL:      link,
+		})
+	}
+
+	secure := strings.EqualFold(pageURL.Scheme, "https")
+	csp := header.Get("Content-Security-Policy")
+
+	if secure && header.Get("Strict-Transport-Security") == "" {
+		add(RuleMissingHSTS, "Strict-Transport-Security header is missing", "")
+	}
+	if csp == "" {
+		add(RuleMissingCSP, "Content-Security-Policy header is missing", "")
+	}
+	if !strings.EqualFold(strings.TrimSpace(header.Get("X-Content-Type-Options")), "nosniff") {
+		add(RuleMissingContentTypeOptions, "X-Content-Type-Options: nosniff header is missing", "")
+	}
+	if header.Get("X-Frame-Options") == "" && !strings.Contains(strings.ToLower(csp), "frame-ancestors") {
+		add(RuleMissingFrameProtection, "Neither X-Frame-Options nor CSP frame-ancestors is set", "")
+	}
+
+	if secure {
+		// Plain links are navigations, not mixed content; embedded frames and styles are.
+		for _, list := range [][]string{links.InternalIframes, links.ExternalIframes, links.CSSResources} {
+			for _, link := range list {
+				if strings.HasPrefix(strings.ToLower(link), "http://") {
+					add(RuleMixedContent, "Resource is loaded over plain HTTP", link)
+				}
+			}
+		}
+	} else if containsLoginForm {
+		add(RuleInsecureLoginForm, "Page with a login form is served over plain HTTP", "")
+	}
+
+	unsandboxed := make(map[string]bool)
+	for _, iframe := range links.Iframes {
+		if iframe.ThirdParty && !iframe.Sandboxed && !unsandboxed[iframe.URL] {
+			unsandboxed[iframe.URL] = true
+			add(RuleUnsandboxedIframe, "Third-party iframe has no sandbox attribute", iframe.URL)
+		}
+	}
+
+	return findings
+}
+
+// registrationFindings runs the security checks that need the registration of the page's
+// domain, as of now.
+func registrationFindings(registration *DomainRegistration, containsLoginForm bool, now time.Time) []SecurityFinding {
+	if registration == nil || registration.Registered == nil || !containsLoginForm {
+		return nil
+	}
+	age := now.Sub(*registration.Registered)
+	if age >= newDomainAge {
+		return nil
+	}
+	return []SecurityFinding{{
+		RuleID:   RuleNewDomainLoginForm,
+		Severity: securityRuleByID(RuleNewDomainLoginForm).Severity,
+		Message:  fmt.Sprintf("Page with a login form is on %s, registered %d days ago", registration.Domain, int(max(age, 0).Hours()/24)),
+	}}
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSecurityFindings(t *testing.T) {
+	secureHeaders := http.Header{
+		"Strict-Transport-Security": {"max-age=31536000"},
+		"Content-Security-Policy":   {"default-src 'self'; frame-ancestors 'none'"},
+		"X-Content-Type-Options":    {"nosniff"},
+	}
+
+	testCases := []struct {
+		name      string
+		pageURL   string
+		header    http.Header
+		links     LinkAnalysis
+		loginForm bool
+		expected  []string
+	}{
+		{
+			name:     "Hardened HTTPS page",
+			pageURL:  "https://example.com/",
+			header:   secureHeaders,
+			expected: nil,
+		},
+		{
+			name:    "HTTPS page without security headers",
+			pageURL: "https://example.com/",
+			header:  http.Header{},
+			expected: []string{
+				RuleMissingHSTS, RuleMissingCSP, RuleMissingContentTypeOptions, RuleMissingFrameProtection,
+			},
+		},
+		{
+			name:    "X-Frame-Options instead of frame-ancestors",
+			pageURL: "https://example.com/",
+			header: http.Header{
+				"Strict-Transport-Security": {"max-age=31536000"},
+				"Content-Security-Policy":   {"default-src 'self'"},
+				"X-Content-Type-Options":    {"nosniff"},
+				"X-Frame-Options":           {"DENY"},
+			},
+			expected: nil,
+		},
+		{
+			name:    "Mixed content from iframes and styles, not links",
+			pageURL: "https://example.com/",
+			header:  secureHeaders,
+			links: LinkAnalysis{
+				InternalLinks:   []string{"http://example.com/plain-link"},
+				ExternalIframes: []string{"http://video.example/embed", "https://video.example/secure"},
+				CSSResources:    []string{"http://cdn.example/bg.png"},
+			},
+			expected: []string{RuleMixedContent, RuleMixedContent},
+		},
+		{
+			name:      "Login form over HTTP",
+			pageURL:   "http://example.com/login",
+			header:    secureHeaders,
+			loginForm: true,
+			expected:  []string{RuleInsecureLoginForm},
+		},
+		{
+			name:    "Third-party iframes without sandbox",
+			pageURL: "https://example.com/",
+			header:  secureHeaders,
+			links: LinkAnalysis{
+				Iframes: []Iframe{
+					{URL: "https://example.com/frame"},
+					{URL: "https://ads.example/slot", ThirdParty: true},
+					{URL: "https://ads.example/slot", ThirdParty: true},
+					{URL: "https://video.example/embed", ThirdParty: true, Sandboxed: true},
+				},
+			},
+			expected: []string{RuleUnsandboxedIframe},
+		},
+		{
+			name:      "Login form over HTTPS",
+			pageURL:   "https://example.com/login",
+			header:    secureHeaders,
+			loginForm: true,
+			expected:  nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pageURL, _ := url.Parse(tc.pageURL)
+			findings := securityFindings(pageURL, tc.header, tc.links, tc.loginForm)
+
+			var rules []string
+			for _, finding := range findings {
+				rules = append(rules, finding.RuleID)
+				if finding.Severity != securityRuleByID(finding.RuleID).Severity {
+					t.Errorf("Expected severity %q for %s, but got %q", securityRuleByID(finding.RuleID).Severity, finding.RuleID, finding.Severity)
+				}
+			}
+			if !reflect.DeepEqual(rules, tc.expected) {
+				t.Errorf("Expected findings %v, but got %v", tc.expected, rules)
+			}
+		})
+	}
+}
+
+func TestRegistrationFindings(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+
+	testCases := []struct {
+		name         string
+		registration *DomainRegistration
+		loginForm    bool
+		expected     int
+	}{
+		{"New domain with login form", &DomainRegistration{Domain: "examp1e.com", Registered: at(now.AddDate(0, 0, -3))}, true, 1},
+		{"New domain without login form", &DomainRegistration{Domain: "examp1e.com", Registered: at(now.AddDate(0, 0, -3))}, false, 0},
+		{"Old domain with login form", &DomainRegistration{Domain: "example.com", Registered: at(now.AddDate(-10, 0, 0))}, true, 0},
+		{"Unpublished registration date", &DomainRegistration{Domain: "example.com"}, true, 0},
+		{"No registration", nil, true, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings := registrationFindings(tc.registration, tc.loginForm, now)
+			if len(findings) != tc.expected {
+				t.Fatalf("Expected %d findings, but got %+v", tc.expected, findings)
+			}
+			if tc.expected == 1 {
+				want := SecurityFinding{RuleID: RuleNewDomainLoginForm, Severity: SeverityWarning, Message: "Page with a login form is on examp1e.com, registered 3 days ago"}
+				if findings[0] != want {
+					t.Errorf("Expected %+v, but got %+v", want, findings[0])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxSitemapBytes caps each sitemap read, uncompressed, at the protocol's 50 MB limit.
+	maxSitemapBytes = 50 << 20
+	// maxSitemapFiles bounds the sitemaps read for one call, the sitemap index included.
+	maxSitemapFiles = 50
+)
+
+// ErrNotSitemap means the document fetched is not a sitemap or sitemap index.
+var ErrNotSitemap = errors.New("not a sitemap")
+
+// sitemapDocument is a sitemap (urlset) or a sitemap index; only the locations are read.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// SitemapURLs fetches the sitemap at sitemapURL with the headers, proxy and credentials of
+// opts and returns the http and https page URLs it lists, in order and each once. Sitemap
+// indexes are followed, and gzipped sitemaps uncompressed. It stops once limit URLs are
+// found; a non-positive limit reads every sitemap, up to maxSitemapFiles of them.
+func SitemapURLs(ctx context.Context, logger *slog.Logger, sitemapURL string, limit int, opts Options) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "SitemapURLs", trace.WithAttributes(attribute.String("url.full", sitemapURL)))
+	defer func() { endSpan(span, err) }()
+
+	if !IsValidURL(sitemapURL) {
+		return nil, fmt.Errorf("invalid sitemap Url")
+	}
+	if opts.Proxy != nil {
+		ctx = withProxy(ctx, opts.Proxy)
+	}
+	session, err := newAuthSession(sitemapURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		ctx = withAuthSession(ctx, session)
+	}
+	opts.Revalidate = nil
+
+	var pages []string
+	seenPages, seenSitemaps := make(map[string]bool), map[string]bool{sitemapURL: true}
+	pending := []string{sitemapURL}
+	for files := 0; len(pending) > 0 && files < maxSitemapFiles && (limit <= 0 || len(pages) < limit); files++ {
+		current := pending[0]
+		pending = pending[1:]
+		doc, err := fetchSitemap(ctx, logger, current, opts)
+		if err != nil {
+			// A broken sitemap of an index only loses its own pages.
+			if current != sitemapURL {
+				logger.WarnContext(ctx, "Skipping unreadable sitemap", slog.String("sitemap_url", current), slog.Any("error", err))
+				continue
+			}
+			return nil, err
+		}
+		for _, child := range doc.Sitemaps {
+			if loc := strings.TrimSpace(child.Loc); isHTTPURL(loc) && !seenSitemaps[loc] {
+				seenSitemaps[loc] = true
+				pending = append(pending, loc)
+			}
+		}
+		for _, page := range doc.URLs {
+			loc := strings.TrimSpace(page.Loc)
+			if !isHTTPURL(loc) || seenPages[loc] {
+				continue
+			}
+			seenPages[loc] = true
+			pages = append(pages, loc)
+			if limit > 0 && len(pages) == limit {
+				break
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("sitemap.urls", len(pages)))
+	logger.InfoContext(ctx, "Read sitemap", slog.String("sitemap_url", sitemapURL), slog.Int("urls", len(pages)), slog.Int("sitemaps", len(seenSitemaps)))
+	return pages, nil
+}
+
+// fetchSitemap downloads and decodes the sitemap or sitemap index at sitemapURL.
+func fetchSitemap(ctx context.Context, logger *slog.Logger, sitemapURL string, opts Options) (*sitemapDocument, error) {
+	req, err := newPageRequest(ctx, sitemapURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := authSessionFrom(ctx).pageClient().Do(req)
+	if err != nil {
+		return nil, classifyFetchError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &HTTPStatusError{Code: resp.StatusCode}
+	}
+	logger.DebugContext(ctx, "Fetched sitemap", slog.String("sitemap_url", sitemapURL))
+
+	// Sitemaps served as .gz files arrive compressed whatever their Content-Encoding.
+	body := bufio.NewReader(resp.Body)
+	r := capReader(body, maxSitemapBytes)
+	if magic, _ := body.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotSitemap, err)
+		}
+		defer gz.Close()
+		r = capReader(gz, maxSitemapBytes)
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, ErrPageTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrNotSitemap, err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("%w: root element is <%s>", ErrNotSitemap, doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
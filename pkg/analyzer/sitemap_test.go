@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSitemapURLs(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+				<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+					<sitemap><loc>%[1]s/pages.xml</loc></sitemap>
+					<sitemap><loc> %[1]s/posts.xml.gz </loc></sitemap>
+					<sitemap><loc>%[1]s/missing.xml</loc></sitemap>
+				</sitemapindex>`, server.URL)
+		case "/pages.xml":
+			fmt.Fprint(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>https://example.com/</loc><lastmod>2024-01-01</lastmod></url>
+				<url><loc>https://example.com/about</loc></url>
+				<url><loc>ftp://example.com/file</loc></url>
+			</urlset>`)
+		case "/posts.xml.gz":
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			fmt.Fprint(gz, `<urlset><url><loc>https://example.com/about</loc></url><url><loc>https://example.com/post</loc></url></urlset>`)
+			gz.Close()
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(buf.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	urls, err := SitemapURLs(context.Background(), testLogger, server.URL+"/sitemap.xml", 0, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := []string{"https://example.com/", "https://example.com/about", "https://example.com/post"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("Expected %q, but got %q", want, urls)
+	}
+
+	urls, err = SitemapURLs(context.Background(), testLogger, server.URL+"/sitemap.xml", 2, DefaultOptions())
+	if err != nil || len(urls) != 2 {
+		t.Errorf("Expected the first 2 URLs, but got %q and error %v", urls, err)
+	}
+}
+
+func TestSitemapURLs_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page.html":
+			fmt.Fprint(w, `<!DOCTYPE html><html><body>Not a sitemap</body></html>`)
+		case "/feed.xml":
+			fmt.Fprint(w, `<rss><channel></channel></rss>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var statusErr *HTTPStatusError
+	if _, err := SitemapURLs(context.Background(), testLogger, server.URL+"/missing.xml", 0, DefaultOptions()); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("Expected an HTTP 404 error, but got %v", err)
+	}
+	for _, path := range []string{"/page.html", "/feed.xml"} {
+		if _, err := SitemapURLs(context.Background(), testLogger, server.URL+path, 0, DefaultOptions()); !errors.Is(err, ErrNotSitemap) {
+			t.Errorf("Expected ErrNotSitemap for %s, but got %v", path, err)
+		}
+	}
+}
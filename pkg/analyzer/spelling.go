@@ -0,0 +1,237 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMisspellings caps the misspellings reported for a page.
+const maxMisspellings = 100
+
+// Dictionary is a list of the correctly spelled words of a language, matched regardless of
+// case.
+type Dictionary struct {
+	words map[string]bool
+}
+
+// ReadDictionary reads a word list with one word per line. Blank lines and lines starting
+// with "#" are skipped. Hunspell .dic files are accepted too: the word count on the first line
+// is skipped and affix flags after a "/" are dropped, but the affix rules are not applied, so
+// the file should list every form of a word.
+func ReadDictionary(r io.Reader) (*Dictionary, error) {
+	d := &Dictionary{words: make(map[string]bool)}
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimSpace(scanner.Text())
+		if _, err := strconv.Atoi(line); first && err == nil {
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		word, _, _ := strings.Cut(line, "/")
+		d.words[spellingKey(word)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Contains reports whether word is in the dictionary, also accepting the possessive of a word
+// that is.
+func (d *Dictionary) Contains(word string) bool {
+	key := spellingKey(word)
+	if d.words[key] {
+		return true
+	}
+	base, ok := strings.CutSuffix(key, "'s")
+	return ok && d.words[base]
+}
+
+// LoadDictionaries reads the dictionaries in dir, one per file, keyed by the file name without
+// its extension in lower case, e.g. "en" for en.txt or "en-gb" for en-GB.dic. Files for the same
+// language are merged.
+func LoadDictionaries(dir string) (map[string]*Dictionary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	dicts := make(map[string]*Dictionary)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		d, err := ReadDictionary(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		lang := strings.ToLower(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+		if existing, ok := dicts[lang]; ok {
+			for word := range d.words {
+				existing.words[word] = true
+			}
+			continue
+		}
+		dicts[lang] = d
+	}
+	if len(dicts) == 0 {
+		return nil, fmt.Errorf("no dictionaries in %s", dir)
+	}
+	return dicts, nil
+}
+
+// spellchecker holds the dictionaries set with SetSpellcheck.
+type spellchecker struct {
+	dicts           map[string]*Dictionary
+	defaultLanguage string
+}
+
+var spellcheck atomic.Pointer[spellchecker]
+
+// SetSpellcheck enables the spellcheck of the visible text of pages with dicts, keyed by
+// lower-case language tag as returned by LoadDictionaries. A page is checked in the language
+// of its lang attribute, or its primary language ("en" for "en-US"), and pages without one in
+// defaultLanguage. Pages in other languages are not checked. Nil dicts disables the spellcheck.
+func SetSpellcheck(dicts map[string]*Dictionary, defaultLanguage string) {
+	if dicts == nil {
+		spellcheck.Store(nil)
+		return
+	}
+	spellcheck.Store(&spellchecker{dicts: dicts, defaultLanguage: strings.ToLower(defaultLanguage)})
+}
+
+// spellcheckEnabled reports whether SetSpellcheck has set any dictionaries.
+func spellcheckEnabled() bool {
+	return spellcheck.Load() != nil
+}
+
+// Spelling is the outcome of the spellcheck of a page.
+type Spelling struct {
+	// Language is the language of the dictionary the page was checked with.
+	Language string `json:"language" xml:"language,attr"`
+	// Misspellings lists the words missing from the dictionary, in order of first appearance,
+	// up to 100.
+	Misspellings []Misspelling `json:"misspellings" xml:"misspelling"`
+}
+
+// Misspelling is a word of a page that is not in the dictionary.
+type Misspelling struct {
+	Word string `json:"word" xml:"word,attr"`
+	// Count is how many times the word appears, in any case.
+	Count int `json:"count" xml:"count,attr"`
+	// Context is the text around its first appearance.
+	Context string `json:"context" xml:",chardata"`
+}
+
+// checkSpelling checks the words of texts, the visible text nodes of a page whose lang
+// attribute is lang, against the dictionary of its language. The texts are read as one, so
+// sentences and contexts run across inline elements. It returns nil if the spellcheck is
+// disabled or there is no dictionary for the language.
+//
+// Words with digits or capitals after their first letter, such as acronyms and product names,
+// are skipped, and so are capitalized words other than at the start of a sentence, which are
+// mostly names.
+func checkSpelling(lang string, texts []string) *Spelling {
+	checker := spellcheck.Load()
+	if checker == nil {
+		return nil
+	}
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		lang = checker.defaultLanguage
+	}
+	primary, _, _ := strings.Cut(lang, "-")
+	dict, ok := checker.dicts[lang]
+	if !ok {
+		lang, dict, ok = primary, checker.dicts[primary], checker.dicts[primary] != nil
+	}
+	if !ok {
+		return nil
+	}
+
+	spelling := &Spelling{Language: lang, Misspellings: []Misspelling{}}
+	found := make(map[string]int)
+	text := strings.Join(texts, " ")
+	spellingWords(text, func(word string, start, end int, sentenceStart bool) {
+		if !shouldSpellcheck(word, sentenceStart) || dict.Contains(word) {
+			return
+		}
+		key := spellingKey(word)
+		if i, ok := found[key]; ok {
+			spelling.Misspellings[i].Count++
+			return
+		}
+		if len(spelling.Misspellings) == maxMisspellings {
+			return
+		}
+		found[key] = len(spelling.Misspellings)
+		spelling.Misspellings = append(spelling.Misspellings, Misspelling{Word: word, Count: 1, Context: textContext(text, start, end)})
+	})
+	return spelling
+}
+
+// spellingWords calls fn with each word of text and its byte offsets, and whether it starts a
+// sentence. Words are runs of letters, possibly joined by apostrophes; hyphenated words are
+// checked part by part.
+func spellingWords(text string, fn func(word string, start, end int, sentenceStart bool)) {
+	sentenceStart := true
+	start := -1
+	for i, r := range text {
+		isApostrophe := (r == '\'' || r == '’') && start >= 0
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || isApostrophe {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			emitSpellingWord(text, start, i, sentenceStart, fn)
+			sentenceStart, start = false, -1
+		}
+		if r == '.' || r == '!' || r == '?' {
+			sentenceStart = true
+		}
+	}
+	if start >= 0 {
+		emitSpellingWord(text, start, len(text), sentenceStart, fn)
+	}
+}
+
+// emitSpellingWord passes text[start:end] to fn without trailing apostrophes.
+func emitSpellingWord(text string, start, end int, sentenceStart bool, fn func(string, int, int, bool)) {
+	word := strings.TrimRight(text[start:end], "'’")
+	fn(word, start, start+len(word), sentenceStart)
+}
+
+// shouldSpellcheck reports whether word is checked at all.
+func shouldSpellcheck(word string, sentenceStart bool) bool {
+	if utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	for i, r := range word {
+		if unicode.IsDigit(r) || (i > 0 && unicode.IsUpper(r)) {
+			return false
+		}
+	}
+	first, _ := utf8.DecodeRuneInString(word)
+	return sentenceStart || !unicode.IsUpper(first)
+}
+
+// spellingKey is the form of a word that dictionaries are matched on.
+func spellingKey(word string) string {
+	return strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDictionary(t *testing.T) {
+	d, err := ReadDictionary(strings.NewReader("3\n# comment\nColour/S\ndon't\n\nrun\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	for word, want := range map[string]bool{"colour": true, "COLOUR": true, "don’t": true, "run's": true, "3": false, "colours": false, "S": false} {
+		if got := d.Contains(word); got != want {
+			t.Errorf("Contains(%q) got = %t, want %t", word, got, want)
+		}
+	}
+}
+
+func TestLoadDictionaries(t *testing.T) {
+	dir := t.TempDir()
+	for name, words := range map[string]string{"en.txt": "one\n", "EN.dic": "1\ntwo/X\n", "de-AT.txt": "eins\n", ".hidden": "x\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(words), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dicts, err := LoadDictionaries(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if len(dicts) != 2 || !dicts["en"].Contains("one") || !dicts["en"].Contains("two") || !dicts["de-at"].Contains("eins") {
+		t.Errorf("Expected merged en and de-at dictionaries, but got %v", dicts)
+	}
+	if _, err := LoadDictionaries(t.TempDir()); err == nil {
+		t.Error("Expected an error for a directory without dictionaries")
+	}
+}
+
+func TestCheckSpelling(t *testing.T) {
+	en, _ := ReadDictionary(strings.NewReader("the\nquick\nbrown\nfox\njumps\nover\nlazy\ndog\nit\nis\nvery\nand\n"))
+	SetSpellcheck(map[string]*Dictionary{"en": en}, "en")
+	defer SetSpellcheck(nil, "")
+
+	texts := []string{"The quikc brown fox jumps over the ", "lazy", " dog. Quikc!\n  Teh fox is very NASA and Alice and v2 and "}
+	want := &Spelling{Language: "en", Misspellings: []Misspelling{
+		{Word: "quikc", Count: 2, Context: "The quikc brown fox jumps over the lazy dog.…"},
+		{Word: "Teh", Count: 1, Context: "…jumps over the lazy dog. Quikc! Teh fox is very NASA and Alice and v2 and"},
+	}}
+	if got := checkSpelling("en-US", texts); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+	if got := checkSpelling("", texts); got == nil || got.Language != "en" {
+		t.Errorf("Expected pages without a language to be checked in English, but got %+v", got)
+	}
+	if got := checkSpelling("fr", texts); got != nil {
+		t.Errorf("Expected no spellcheck without a French dictionary, but got %+v", got)
+	}
+}
+
+func TestAnalyzePage_SpellingStreamingMatchesDOM(t *testing.T) {
+	en, _ := ReadDictionary(strings.NewReader("hello\nworld\n"))
+	SetSpellcheck(map[string]*Dictionary{"en": en}, "")
+	defer SetSpellcheck(nil, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html lang="en"><head><title>Helo</title><script>wrold()</script></head>
+			<body><p>Hello <b>wrold</b>, hello world.</p></body></html>`)
+	}))
+	defer server.Close()
+
+	domOpts, streamOpts := DefaultOptions(), DefaultOptions()
+	domOpts.StreamingThreshold, streamOpts.StreamingThreshold = 0, 16
+	dom, err := AnalyzePage(context.Background(), testLogger, server.URL, domOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	streamed, err := AnalyzePage(context.Background(), testLogger, server.URL, streamOpts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if dom.Spelling == nil || len(dom.Spelling.Misspellings) != 1 || dom.Spelling.Misspellings[0].Word != "wrold" {
+		t.Errorf("Expected only the visible misspelling, but got %+v", dom.Spelling)
+	}
+	if !reflect.DeepEqual(dom.Spelling, streamed.Spelling) {
+		t.Errorf("Expected the streamed spellcheck %+v to match the DOM's %+v", streamed.Spelling, dom.Spelling)
+	}
+}
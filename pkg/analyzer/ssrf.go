@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync/atomic"
+)
+
+// ErrBlockedAddress is returned when an outbound connection would reach a private, loopback,
+// link-local or otherwise internal address while private networks are blocked.
+var ErrBlockedAddress = errors.New("destination address is not allowed")
+
+// blockPrivateNetworks makes the shared transport refuse to connect to internal addresses.
+// It is off until SetBlockPrivateNetworks is called.
+var blockPrivateNetworks atomic.Bool
+
+// blockedPrefixes are internal ranges not covered by the netip.Addr classification methods.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, includes some cloud metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, can embed any IPv4 address
+}
+
+// SetBlockPrivateNetworks controls whether page fetches, link checks and robots.txt lookups
+// may connect to private, loopback, link-local and cloud metadata addresses. The check runs
+// when connecting, so it also covers redirects and hostnames that resolve to internal addresses.
+func SetBlockPrivateNetworks(block bool) {
+	blockPrivateNetworks.Store(block)
+}
+
+// checkDialAddress returns an error wrapping ErrBlockedAddress if addr must not be dialed.
+func checkDialAddress(addr netip.Addr) error {
+	if blockPrivateNetworks.Load() && isInternalAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// isInternalAddr reports whether addr belongs to a range that is never a public web server.
+func isInternalAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsInternalAddr(t *testing.T) {
+	testCases := []struct {
+		addr     string
+		internal bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			if got := isInternalAddr(netip.MustParseAddr(tc.addr)); got != tc.internal {
+				t.Errorf("Expected isInternalAddr(%s) to be %v, but got %v", tc.addr, tc.internal, got)
+			}
+		})
+	}
+}
+
+func TestCheckDialAddress(t *testing.T) {
+	t.Cleanup(func() { SetBlockPrivateNetworks(false) })
+
+	if err := checkDialAddress(netip.MustParseAddr("127.0.0.1")); err != nil {
+		t.Errorf("Expected loopback to be allowed by default, but got: %v", err)
+	}
+
+	SetBlockPrivateNetworks(true)
+	if err := checkDialAddress(netip.MustParseAddr("127.0.0.1")); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected ErrBlockedAddress for loopback, but got: %v", err)
+	}
+	if err := checkDialAddress(netip.MustParseAddr("93.184.216.34")); err != nil {
+		t.Errorf("Expected a public address to be allowed, but got: %v", err)
+	}
+}
+
+func TestDNSCache_DialRefusesInternalAddresses(t *testing.T) {
+	t.Cleanup(func() { SetBlockPrivateNetworks(false) })
+	SetBlockPrivateNetworks(true)
+
+	cache := newDNSCache(time.Minute)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"93.184.216.34", "10.0.0.1"}, nil
+	}
+
+	dial := cache.dialContext(nil)
+	if _, err := dial(context.Background(), "tcp", "rebind.example:80"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Expected a host with an internal address to be refused, but got: %v", err)
+	}
+}
+
+func TestLoadWebPage_BlockedAddress(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	t.Cleanup(func() { SetBlockPrivateNetworks(false) })
+	SetBlockPrivateNetworks(true)
+
+	start := time.Now()
+	_, err := loadWebPage(context.Background(), testLogger, server.URL, DefaultOptions())
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Expected ErrBlockedAddress, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= initialBackoff {
+		t.Errorf("Expected a blocked address not to be retried, but took %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("Expected no requests to reach the server, but got %d", n)
+	}
+}
+
+func TestValidateLinkAccessibility_BlockedAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Cleanup(func() { SetBlockPrivateNetworks(false) })
+	SetBlockPrivateNetworks(true)
+
+	analysis := LinkAnalysis{InternalLinks: []string{server.URL + "/admin"}}
+	report, err := validateLinkAccessibility(context.Background(), testLogger, analysis, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if len(report.Inaccessible) != 0 {
+		t.Errorf("Expected no inaccessible links, but got %v", report.Inaccessible)
+	}
+	if got := report.NotChecked[notCheckedBlocked]; len(got) != 1 {
+		t.Errorf("Expected the link to be reported as blocked, but got %v", report.NotChecked)
+	}
+}
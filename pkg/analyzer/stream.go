@@ -0,0 +1,391 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// streamCancelCheckInterval is how many tokens are read between context cancellation checks.
+const streamCancelCheckInterval = 1024
+
+// streamedPage is what a single tokenizer pass collects from a page. Links and styles are
+// kept raw and classified afterwards with the same helpers as the DOM path.
+type streamedPage struct {
+	doctype           string
+	title             strings.Builder
+	description       string
+	hasDescription    bool
+	texts             []string
+	lang              string
+	noindex           bool
+	pagination        paginationHrefs
+	structuredData    []string
+	noscripts         []string
+	media             mediaMarkup
+	customElements    customElements
+	headings          map[string]int
+	containsLoginForm bool
+
+	hrefs       []string
+	hrefTexts   []string
+	frameSrcs   []string
+	iframes     []iframeMarkup
+	stylesheets []string
+	baseHref    string
+	hasBase     bool
+	anchors     map[string]bool
+
+	// inMedia counts the open <video> and <audio> elements, and definitions lists the custom
+	// elements the inline scripts define.
+	inMedia     int
+	definitions []string
+}
+
+// loginFormState tracks the login form signals seen so far, per form and for the whole page.
+type loginFormState struct {
+	inForm, inButton                       bool
+	formPassword, formUser, formButton     bool
+	buttonText                             strings.Builder
+	pagePassword, pageEmail, pageLoginText bool
+}
+
+// tokenizePage reads r once with html.Tokenizer, collecting everything AnalyzePage needs
+// without building a DOM, so memory stays proportional to the collected data rather than
+// to the document.
+func tokenizePage(ctx context.Context, r io.Reader) (_ *streamedPage, err error) {
+	ctx, span := tracer.Start(ctx, "tokenizePage")
+	defer func() { endSpan(span, err) }()
+
+	page := &streamedPage{
+		headings: make(map[string]int),
+		anchors:  make(map[string]bool),
+	}
+	var login loginFormState
+	var inTitle, inStyle, inStructuredData, inNoscript, inScript bool
+	// invisible counts the open elements whose text is left out of the content fingerprint.
+	var invisible int
+	// anchorText collects the text of the open <a href>, the last entry of page.hrefs.
+	var inAnchor bool
+	var anchorText strings.Builder
+
+	z := html.NewTokenizer(r)
+	for tokens := 1; ; tokens++ {
+		if tokens%streamCancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			break
+		}
+
+		token := z.Token()
+		switch tt {
+		case html.DoctypeToken:
+			if page.doctype == "" {
+				page.doctype = token.Data
+			}
+
+		case html.TextToken:
+			page.customElements.text(token.Data)
+			if inScript {
+				page.definitions = append(page.definitions, definedCustomElements(token.Data)...)
+			}
+			if invisible == 0 {
+				page.texts = append(page.texts, token.Data)
+			}
+			if inAnchor {
+				anchorText.WriteString(token.Data)
+			}
+			switch {
+			case inTitle:
+				page.title.WriteString(token.Data)
+			case inStyle:
+				page.stylesheets = append(page.stylesheets, token.Data)
+			case inStructuredData:
+				page.structuredData[len(page.structuredData)-1] += token.Data
+			case inNoscript:
+				page.noscripts[len(page.noscripts)-1] += token.Data
+			case login.inButton:
+				login.buttonText.WriteString(token.Data)
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if token.Data == "a" && inAnchor {
+				// Anchors cannot nest, so a new one implicitly closes the open one.
+				page.hrefTexts[len(page.hrefTexts)-1] = anchorText.String()
+				inAnchor = false
+			}
+			page.customElements.startTag(token.Data)
+			hrefs, blocks, noscripts := len(page.hrefs), len(page.structuredData), len(page.noscripts)
+			page.startTag(token, &login)
+			if tt == html.StartTagToken && len(page.hrefs) > hrefs {
+				inAnchor = true
+				anchorText.Reset()
+			}
+			inStructuredData = inStructuredData || (tt == html.StartTagToken && len(page.structuredData) > blocks)
+			inNoscript = inNoscript || (tt == html.StartTagToken && len(page.noscripts) > noscripts)
+			if tt == html.StartTagToken && (token.Data == "video" || token.Data == "audio") {
+				page.inMedia++
+			}
+			if tt == html.StartTagToken && invisibleTextTags[token.Data] {
+				invisible++
+			}
+			if tt == html.StartTagToken {
+				inTitle = inTitle || token.Data == "title"
+				inStyle = inStyle || token.Data == "style"
+				inScript = inScript || token.Data == "script"
+			}
+
+		case html.EndTagToken:
+			page.customElements.endTag(token.Data)
+			if invisible > 0 && invisibleTextTags[token.Data] {
+				invisible--
+			}
+			switch token.Data {
+			case "video", "audio":
+				page.inMedia = max(page.inMedia-1, 0)
+			case "a":
+				if inAnchor {
+					page.hrefTexts[len(page.hrefTexts)-1] = anchorText.String()
+					inAnchor = false
+				}
+			case "title":
+				inTitle = false
+			case "style":
+				inStyle = false
+			case "script":
+				inStructuredData, inScript = false, false
+			case "noscript":
+				inNoscript = false
+			case "button":
+				login.endButton()
+			case "form":
+				page.containsLoginForm = login.endForm() || page.containsLoginForm
+			}
+		}
+	}
+
+	if inAnchor {
+		page.hrefTexts[len(page.hrefTexts)-1] = anchorText.String()
+	}
+	page.containsLoginForm = login.endForm() || page.containsLoginForm ||
+		(login.pagePassword && (login.pageEmail || login.pageLoginText))
+	return page, nil
+}
+
+// startTag records the signals carried by a start or self-closing tag.
+func (p *streamedPage) startTag(token html.Token, login *loginFormState) {
+	attrs := make(map[string]string, len(token.Attr))
+	for _, attr := range token.Attr {
+		if _, dup := attrs[attr.Key]; !dup {
+			attrs[attr.Key] = attr.Val
+		}
+	}
+
+	if id, ok := attrs["id"]; ok {
+		p.anchors[id] = true
+	}
+	if style, ok := attrs["style"]; ok {
+		p.stylesheets = append(p.stylesheets, style)
+	}
+
+	switch token.Data {
+	case "html":
+		if p.lang == "" {
+			p.lang = attrs["lang"]
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		p.headings[token.Data]++
+	case "a":
+		if href, ok := attrs["href"]; ok {
+			p.hrefs = append(p.hrefs, href)
+			p.hrefTexts = append(p.hrefTexts, "")
+			p.pagination.add(attrs["rel"], href)
+		}
+		if name, ok := attrs["name"]; ok {
+			p.anchors[name] = true
+		}
+	case "link":
+		if href, ok := attrs["href"]; ok {
+			p.pagination.add(attrs["rel"], href)
+		}
+	case "script":
+		if isJSONLD(attrs["type"]) {
+			p.structuredData = append(p.structuredData, "")
+		}
+	case "noscript":
+		p.noscripts = append(p.noscripts, "")
+	case "video":
+		p.media.videos++
+		p.mediaSrc(attrs, "src", "poster")
+	case "audio":
+		p.media.audios++
+		p.mediaSrc(attrs, "src")
+	case "source":
+		if p.inMedia > 0 {
+			p.mediaSrc(attrs, "src")
+		}
+	case "iframe":
+		if src, ok := attrs["src"]; ok {
+			p.frameSrcs = append(p.frameSrcs, src)
+			p.iframes = append(p.iframes, newIframeMarkup(func(name string) (string, bool) {
+				value, ok := attrs[name]
+				return value, ok
+			}))
+		}
+	case "meta":
+		if isMetaDescription(attrs["name"]) && !p.hasDescription {
+			p.description, p.hasDescription = strings.TrimSpace(attrs["content"]), true
+		}
+		p.noindex = p.noindex || isMetaNoindex(attrs["name"], attrs["content"])
+	case "base":
+		if href, ok := attrs["href"]; ok && !p.hasBase {
+			p.baseHref, p.hasBase = href, true
+		}
+	case "form":
+		login.endForm()
+		login.inForm = true
+	case "button":
+		login.inButton = true
+		login.buttonText.Reset()
+		login.buttonText.WriteString(attrs["value"])
+	case "input":
+		login.input(attrs)
+	}
+}
+
+// mediaSrc records the named attributes of a media element that it has.
+func (p *streamedPage) mediaSrc(attrs map[string]string, names ...string) {
+	for _, name := range names {
+		if src, ok := attrs[name]; ok {
+			p.media.srcs = append(p.media.srcs, src)
+		}
+	}
+}
+
+func (l *loginFormState) input(attrs map[string]string) {
+	inputType := attrs["type"]
+	id, name := attrs["id"], attrs["name"]
+
+	// Page-wide fallback signals, for logins built without a <form>.
+	l.pagePassword = l.pagePassword || inputType == "password"
+	l.pageEmail = l.pageEmail || inputType == "email"
+	l.pageLoginText = l.pageLoginText ||
+		strings.Contains(id, "user") || strings.Contains(id, "login") ||
+		strings.Contains(name, "user") || strings.Contains(name, "login")
+
+	if !l.inForm {
+		return
+	}
+	l.formPassword = l.formPassword || inputType == "password"
+	l.formUser = l.formUser || isUserIdentifierInput(inputType, name, id, attrs["placeholder"])
+	l.formButton = l.formButton || (inputType == "submit" && isLoginButtonText(attrs["value"]))
+}
+
+func (l *loginFormState) endButton() {
+	if l.inButton && l.inForm && isLoginButtonText(l.buttonText.String()) {
+		l.formButton = true
+	}
+	l.inButton = false
+}
+
+// endForm closes the current form, if any, and reports whether it was a login form.
+func (l *loginFormState) endForm() bool {
+	isLogin := l.inForm && l.formPassword && (l.formUser || l.formButton)
+	l.inForm, l.formPassword, l.formUser, l.formButton = false, false, false, false
+	return isLogin
+}
+
+// analyzeStream runs the page checks over r in a single tokenizer pass, filling result and
+// returning the page's links for validation.
+func analyzeStream(ctx context.Context, logger *slog.Logger, r io.Reader, baseURL *url.URL, opts Options, result *AnalysisResult) (_ LinkAnalysis, err error) {
+	ctx, span := tracer.Start(ctx, "analyzeStream")
+	defer func() { endSpan(span, err) }()
+
+	logger.DebugContext(ctx, "Beginning streaming analysis")
+
+	page, err := tokenizePage(ctx, r)
+	if err != nil {
+		return LinkAnalysis{}, fmt.Errorf("failed to tokenize document: %w", err)
+	}
+
+	// Parsing just the doctype lets findHTMLVersion classify it exactly as on the DOM path.
+	doctypeDoc, err := html.Parse(strings.NewReader(doctypeMarkup(page.doctype)))
+	if err != nil {
+		return LinkAnalysis{}, fmt.Errorf("failed to parse doctype: %w", err)
+	}
+	result.HTMLVersion, err = findHTMLVersion(ctx, logger, goquery.NewDocumentFromNode(doctypeDoc))
+	result.addCheckError(CheckHTMLVersion, err)
+
+	result.Title = page.title.String()
+	result.Description = page.description
+	words := textWords(page.texts)
+	result.ContentFingerprint = contentFingerprint(words)
+	result.WordCount = len(words)
+	result.Placeholders = findPlaceholders(page.texts)
+	result.Classification = classifyPage(result.Title, page.texts)
+	result.Spelling = checkSpelling(page.lang, page.texts)
+	result.Noindex = page.noindex
+	result.RichResults = richResults(page.structuredData)
+	result.CustomElements = page.customElements.list(page.definitions)
+	result.Headings = page.headings
+	result.ContainsLoginForm = page.containsLoginForm
+
+	resolveBase := baseURL
+	if page.hasBase {
+		resolveBase = resolveBaseHref(ctx, logger, page.baseHref, baseURL)
+	}
+	result.Pagination = page.pagination.resolve(resolveBase, opts.Normalize)
+	result.Noscript = summarizeNoscript(page.noscripts, resolveBase, opts.Normalize)
+
+	hasTarget := func(fragment string) bool {
+		fragment, implicit := anchorFragment(fragment)
+		return implicit || page.anchors[fragment]
+	}
+
+	noscript := parseNoscript(page.noscripts)
+	hrefs := append(page.hrefs, noscript.hrefs...)
+	hrefTexts := append(page.hrefTexts, noscript.hrefTexts...)
+	frameSrcs := append(page.frameSrcs, noscript.frameSrcs...)
+	links, err := classifyLinks(ctx, logger, hrefs, hrefTexts, frameSrcs, hasTarget, resolveBase, baseURL, opts.Normalize)
+	result.addCheckError(CheckLinks, err)
+	links.Iframes = auditIframes(page.iframes, resolveBase, baseURL, opts.Normalize)
+	links.CSSResources, err = collectCSSResources(ctx, logger, page.stylesheets, resolveBase, baseURL, opts.Normalize)
+	result.addCheckError(CheckCSSResources, err)
+	page.media.frameSrcs = page.frameSrcs
+	result.Media, err = collectMedia(ctx, logger, page.media, resolveBase, opts.Normalize)
+	result.addCheckError(CheckMedia, err)
+	if result.Media != nil {
+		links.MediaSources = result.Media.Sources
+	}
+
+	for check, reason := range result.Errors {
+		logger.WarnContext(ctx, "An individual analysis failed, continuing with partial results",
+			slog.String("check", check),
+			slog.String("error", reason),
+		)
+	}
+
+	return links, nil
+}
+
+func doctypeMarkup(doctype string) string {
+	if doctype == "" {
+		return ""
+	}
+	return "<!DOCTYPE " + doctype + ">"
+}
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzePage_StreamingMatchesDOM(t *testing.T) {
+	testCases := []struct {
+		name string
+		html string
+	}{
+		{
+			name: "HTML5 page with everything",
+			html: `<!DOCTYPE html><html><head><title>Stream &amp; DOM</title><base href="/docs/">
+				<meta name="Description" content=" First description "><meta name="description" content="Second">
+				<link rel="stylesheet" href="/main.css"><link rel="Next" href="?page=2"><a rel="prev nofollow" href="/">Prev</a>
+				<meta name="viewport" content="width=device-width"><meta name="robots" content="NOINDEX, follow">
+				<style>.hero { background: url('img/hero.png') }</style></head><body>
+				<script>var hidden = "not text";</script><script type="application/ld+json; charset=utf-8">{"@type": "Product", "name": "A &amp; <b>B</b>"}</script><noscript><p>Enable scripts &amp; <a href="/no-js">reload</a></p><img src="/pixel.gif?a=1&amp;b=2"><iframe src="https://tags.example/ns"></iframe></noscript>
+				<template><p>Later</p></template><p>Some &amp; visible <b>text</b>, in a paragraph. <i>Lorem</i> ipsum, TODO.</p>
+				<h1 id="top-heading">One</h1><h2>Two</h2><h2>Three</h2><h6>Six</h6>
+				<a href="guide">Guide</a><a href="/about">About</a><a href="https://other.example/x">Out</a>
+				<a href="#top-heading">ok</a><a href="#nowhere">broken</a><a name="named"></a><a href="#named">named</a>
+				<a href="mailto:a@b.c">mail</a><a href="">empty</a>
+				<iframe src="https://video.example/embed" sandbox=" allow-scripts  allow-popups" allow="fullscreen" referrerpolicy="no-referrer"></iframe><iframe src="https://www.youtube-nocookie.com/embed/abc"></iframe>
+				<video src="/clip.mp4" poster="poster.jpg"><source src="/clip.webm" type="video/webm"><track src="subs.vtt"></video>
+				<picture><source srcset="a.webp"><img src="a.png"></picture><audio><source src="https://cdn.example/song.mp3"></audio><iframe src="about:blank"></iframe>
+				<div style="background-image: url(/bg.jpg)"></div><site-header> </site-header><product-card><h3>Tea</h3></product-card><product-card></product-card>
+				<svg><font-face></font-face></svg><script>customElements.define("site-header", SiteHeader); customElements.define('cart-drawer', CartDrawer)</script>
+				<form><input type="email" name="login"><input type="password"><button>Sign in</button></form>
+			</body></html>`,
+		},
+		{
+			name: "XHTML doctype without login",
+			html: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">
+				<html><head><title>Old</title></head><body><h3>Three</h3><form><input type="password"></form></body></html>`,
+		},
+		{
+			name: "No doctype and formless login",
+			html: `<html><body><input id="username"><input type="password"><a href="/x">x</a></body></html>`,
+		},
+		{
+			name: "Server welcome page",
+			html: `<!DOCTYPE html><html><head><title>Welcome to nginx!</title></head><body><h1>Welcome to nginx!</h1>
+				<p>For online documentation please refer to <a href="http://nginx.org/">nginx.org</a>.</p></body></html>`,
+		},
+		{
+			name: "Login button as submit input",
+			html: `<!DOCTYPE html><form><input type="password" name="p"><input type="submit" value="Log in"></form>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/" {
+					fmt.Fprint(w, tc.html)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			domOpts := DefaultOptions()
+			domOpts.StreamingThreshold = 0
+			domOpts.Retry.MaxRetries = 0
+			streamOpts := domOpts
+			streamOpts.StreamingThreshold = 16
+
+			dom, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", domOpts)
+			if err != nil {
+				t.Fatalf("Expected no error on the DOM path, but got: %v", err)
+			}
+			streamed, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", streamOpts)
+			if err != nil {
+				t.Fatalf("Expected no error on the streaming path, but got: %v", err)
+			}
+
+			if dom.ID == streamed.ID {
+				t.Errorf("Expected each analysis to get its own ID, but both got %q", dom.ID)
+			}
+			dom.ID, streamed.ID = "", ""
+			dom.AnalyzedAt, streamed.AnalyzedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(dom, streamed) {
+				t.Errorf("Expected identical results\nDOM:       %+v\nstreaming: %+v", dom, streamed)
+			}
+		})
+	}
+}
+
+func TestOpenPageBody(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		contentLength int64
+		threshold     int64
+		limit         int64
+		expectStream  bool
+		expectTooBig  bool
+	}{
+		{"Small body is buffered", "small", -1, 10, 100, false, false},
+		{"Large body is streamed", strings.Repeat("x", 50), -1, 10, 100, true, false},
+		{"Declared large body is streamed", strings.Repeat("x", 50), 50, 10, 100, true, false},
+		{"Streaming disabled", strings.Repeat("x", 50), -1, 0, 100, false, false},
+		{"Streamed body over limit", strings.Repeat("x", 150), -1, 10, 100, true, true},
+		{"Declared length over limit", "x", 1000, 10, 100, false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Body: io.NopCloser(strings.NewReader(tc.body)), ContentLength: tc.contentLength}
+
+			body, stream, err := openPageBody(resp, tc.threshold, tc.limit)
+			if err == nil && (stream != nil) != tc.expectStream {
+				t.Errorf("Expected streaming to be %v, but got %v", tc.expectStream, stream != nil)
+			}
+			if stream != nil {
+				body, err = io.ReadAll(stream)
+			}
+
+			if tc.expectTooBig {
+				if !errors.Is(err, ErrPageTooLarge) {
+					t.Errorf("Expected ErrPageTooLarge, but got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if !bytes.Equal(body, []byte(tc.body)) {
+				t.Errorf("Expected the whole body to be returned, but got %d of %d bytes", len(body), len(tc.body))
+			}
+		})
+	}
+}
+
+func TestTokenizePage_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	page := strings.Repeat("<p>filler</p>", 2*streamCancelCheckInterval)
+	if _, err := tokenizePage(ctx, strings.NewReader(page)); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, but got: %v", err)
+	}
+}
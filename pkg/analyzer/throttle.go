@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostThrottle limits how hard a single link validation run hits any one host: at most
+// concurrency requests in flight per host, with request starts spaced at least delay apart.
+type hostThrottle struct {
+	delay       time.Duration
+	concurrency int
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	inFlight chan struct{}
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+func newHostThrottle(delay time.Duration, concurrency int) *hostThrottle {
+	return &hostThrottle{
+		delay:       delay,
+		concurrency: concurrency,
+		hosts:       make(map[string]*hostSlot),
+	}
+}
+
+// acquire blocks until a request to host may start and returns the function that releases
+// its concurrency slot. A non-positive concurrency means requests are never capped.
+func (t *hostThrottle) acquire(ctx context.Context, host string) (release func(), err error) {
+	slot := t.slot(host)
+
+	if slot.inFlight != nil {
+		select {
+		case slot.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release = func() {
+		if slot.inFlight != nil {
+			<-slot.inFlight
+		}
+	}
+
+	if t.delay <= 0 {
+		return release, nil
+	}
+
+	slot.mu.Lock()
+	now := time.Now()
+	start := now
+	if slot.nextStart.After(now) {
+		start = slot.nextStart
+	}
+	slot.nextStart = start.Add(t.delay)
+	slot.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+func (t *hostThrottle) slot(host string) *hostSlot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	slot, ok := t.hosts[host]
+	if !ok {
+		slot = &hostSlot{}
+		if t.concurrency > 0 {
+			slot.inFlight = make(chan struct{}, t.concurrency)
+		}
+		t.hosts[host] = slot
+	}
+	return slot
+}
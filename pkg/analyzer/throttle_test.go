@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostThrottle_ConcurrencyCap(t *testing.T) {
+	throttle := newHostThrottle(0, 2)
+
+	var inFlight, maxInFlight int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := throttle.acquire(context.Background(), "example.com")
+			if err != nil {
+				t.Errorf("acquire() unexpected error = %v", err)
+				return
+			}
+			defer release()
+
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, but saw %d", maxInFlight)
+	}
+}
+
+func TestHostThrottle_HostsAreIndependent(t *testing.T) {
+	throttle := newHostThrottle(time.Hour, 1)
+
+	releaseA, err := throttle.acquire(context.Background(), "a.example")
+	if err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+	defer releaseA()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	releaseB, err := throttle.acquire(ctx, "b.example")
+	if err != nil {
+		t.Fatalf("Expected another host to be unaffected, but got %v", err)
+	}
+	releaseB()
+}
+
+func TestHostThrottle_Delay(t *testing.T) {
+	delay := 50 * time.Millisecond
+	throttle := newHostThrottle(delay, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := throttle.acquire(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("acquire() unexpected error = %v", err)
+		}
+		release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 2*delay {
+		t.Errorf("Expected three requests to span at least %v, but took %v", 2*delay, elapsed)
+	}
+}
+
+func TestHostThrottle_ContextCancellation(t *testing.T) {
+	throttle := newHostThrottle(0, 1)
+
+	release, err := throttle.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := throttle.acquire(ctx, "example.com"); err == nil {
+		t.Error("Expected an error while the only slot is held and the context expires")
+	}
+}
@@ -0,0 +1,21 @@
+package analyzer
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the analysis spans. It uses the global tracer provider, so spans are only
+// recorded once the program installs one (see cmd's -otlp-endpoint); until then they are
+// no-ops.
+var tracer = otel.Tracer("web-analyzer/pkg/analyzer")
+
+// endSpan marks span as failed if err is set and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans installs a global tracer provider recording every span for the duration of
+// the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestAnalyzePage_Spans(t *testing.T) {
+	recorder := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Traced</title></head><body>
+			<h1>One</h1><a href="/about">About</a><a href="/missing">Missing</a>
+		</body></html>`)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 1}
+	if _, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+
+	expectedCounts := map[string]int{
+		"AnalyzePage":                  1,
+		"loadWebPage":                  1,
+		"analyzeDocument":              1,
+		"findHTMLVersion":              1,
+		"countHeadings":                1,
+		"extractLinks":                 1,
+		"extractCSSResources":          1,
+		"detectLoginForm":              1,
+		"validateLinkAccessibility":    1,
+		"linkAccessibilityCheckWorker": 2,
+		"linkAccessibilityChecker":     2,
+	}
+	for name, count := range expectedCounts {
+		if len(spans[name]) != count {
+			t.Errorf("Expected %d %s spans, but got %d", count, name, len(spans[name]))
+		}
+	}
+	if t.Failed() {
+		return
+	}
+
+	root := spans["AnalyzePage"][0].SpanContext()
+	for _, name := range []string{"loadWebPage", "analyzeDocument", "validateLinkAccessibility"} {
+		if parent := spans[name][0].Parent(); parent.SpanID() != root.SpanID() {
+			t.Errorf("Expected %s to be a child of AnalyzePage, but its parent is %s", name, parent.SpanID())
+		}
+	}
+
+	accessible := make(map[string]bool)
+	for _, span := range spans["linkAccessibilityChecker"] {
+		attrs := attribute.NewSet(span.Attributes()...)
+		url, _ := attrs.Value("url.full")
+		ok, _ := attrs.Value("link.accessible")
+		accessible[url.AsString()] = ok.AsBool()
+	}
+	if !accessible[server.URL+"/about"] || accessible[server.URL+"/missing"] {
+		t.Errorf("Expected /about to be accessible and /missing not, but got %v", accessible)
+	}
+}
+
+func TestAnalyzePage_SpanRecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Retry = RetryPolicy{MaxRetries: 1}
+	if _, err := AnalyzePage(context.Background(), testLogger, server.URL, opts); err == nil {
+		t.Fatal("Expected an error, but got nil")
+	}
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "AnalyzePage" && span.Name() != "loadWebPage" {
+			t.Errorf("Expected no span after the failed page load, but got %s", span.Name())
+			continue
+		}
+		if span.Status().Code != codes.Error {
+			t.Errorf("Expected %s to be marked as failed, but got status %v", span.Name(), span.Status())
+		}
+	}
+}
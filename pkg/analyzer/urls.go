@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// isSameHost reports whether two URLs point at the same host once both are reduced
+// to their canonical form, so case and Unicode/punycode spellings compare equal.
+func isSameHost(a, b *url.URL) bool {
+	return canonicalHost(a) == canonicalHost(b)
+}
+
+// canonicalHost returns the lowercase ASCII (punycode) form of a URL's host, keeping any port.
+func canonicalHost(u *url.URL) string {
+	return joinHostPort(asciiHostname(u), u.Port())
+}
+
+// asciiHostname returns the lowercase punycode form of a URL's hostname.
+// Hostnames that cannot be converted fall back to their lowercase spelling.
+func asciiHostname(u *url.URL) string {
+	hostname := u.Hostname()
+	ascii, err := idna.Lookup.ToASCII(hostname)
+	if err != nil {
+		return strings.ToLower(hostname)
+	}
+	return ascii
+}
+
+// NormalizeOptions controls the optional, lossy steps of URL normalization.
+type NormalizeOptions struct {
+	// StripTrailingSlash removes a trailing "/" from non-root paths.
+	StripTrailingSlash bool
+	// StripUTMParams removes utm_* campaign tracking query parameters.
+	StripUTMParams bool
+}
+
+// normalizeURL returns a copy of u in a canonical form so equivalent URLs compare equal:
+// lowercase scheme and host (punycode for IDNs), no default port, no dot segments,
+// no fragment, plus the optional steps enabled in opts.
+func normalizeURL(u *url.URL, opts NormalizeOptions) *url.URL {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(u.Scheme)
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+
+	port := u.Port()
+	if port == defaultPorts[normalized.Scheme] {
+		port = ""
+	}
+	normalized.Host = joinHostPort(asciiHostname(u), port)
+
+	if normalized.Path == "" && normalized.Host != "" {
+		normalized.Path = "/"
+		normalized.RawPath = ""
+	} else if hasDotSegments(normalized.Path) {
+		resolved := normalized.ResolveReference(&url.URL{Path: normalized.Path, RawPath: normalized.RawPath})
+		normalized.Path, normalized.RawPath = resolved.Path, resolved.RawPath
+	}
+
+	if opts.StripTrailingSlash && len(normalized.Path) > 1 && strings.HasSuffix(normalized.Path, "/") {
+		normalized.Path = strings.TrimRight(normalized.Path, "/")
+		if normalized.Path == "" {
+			normalized.Path = "/"
+		}
+		normalized.RawPath = ""
+	}
+
+	if opts.StripUTMParams && normalized.RawQuery != "" {
+		query := normalized.Query()
+		stripped := false
+		for key := range query {
+			if strings.HasPrefix(strings.ToLower(key), "utm_") {
+				query.Del(key)
+				stripped = true
+			}
+		}
+		if stripped {
+			normalized.RawQuery = query.Encode()
+		}
+	}
+
+	return &normalized
+}
+
+// defaultPorts maps schemes to the port that is implied when none is given.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+func hasDotSegments(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// hostForms returns the punycode and Unicode spellings of a URL's hostname for display.
+func hostForms(u *url.URL) (ascii string, unicode string) {
+	ascii = asciiHostname(u)
+
+	unicode, err := idna.Lookup.ToUnicode(ascii)
+	if err != nil {
+		unicode = ascii
+	}
+
+	return ascii, unicode
+}
+
+func joinHostPort(hostname, port string) string {
+	if port == "" {
+		if strings.Contains(hostname, ":") {
+			return "[" + hostname + "]"
+		}
+		return hostname
+	}
+	return net.JoinHostPort(hostname, port)
+}
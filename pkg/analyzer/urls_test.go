@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestIsSameHost(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "Identical Hosts", a: "https://example.com/a", b: "https://example.com/b", want: true},
+		{name: "Case Differs", a: "https://EXAMPLE.com/", b: "https://example.com/", want: true},
+		{name: "Unicode And Punycode", a: "https://münchen.de/", b: "https://xn--mnchen-3ya.de/", want: true},
+		{name: "Unicode Case Differs", a: "https://MÜNCHEN.de/", b: "https://münchen.de/", want: true},
+		{name: "Different Ports", a: "https://example.com:8443/", b: "https://example.com/", want: false},
+		{name: "Subdomain", a: "https://sub.example.com/", b: "https://example.com/", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, _ := url.Parse(tc.a)
+			b, _ := url.Parse(tc.b)
+			if got := isSameHost(a, b); got != tc.want {
+				t.Errorf("isSameHost(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		opts  NormalizeOptions
+		want  string
+	}{
+		{name: "Lowercases Scheme And Host", input: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "Strips Default HTTPS Port", input: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "Strips Default HTTP Port", input: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "Keeps Non-Default Port", input: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "Adds Root Path", input: "https://example.com", want: "https://example.com/"},
+		{name: "Resolves Dot Segments", input: "https://example.com/a/./b/../c", want: "https://example.com/a/c"},
+		{name: "Drops Fragment", input: "https://example.com/a#section", want: "https://example.com/a"},
+		{name: "Converts IDN To Punycode", input: "https://Bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{name: "Keeps Trailing Slash By Default", input: "https://example.com/a/", want: "https://example.com/a/"},
+		{
+			name:  "Strips Trailing Slash When Enabled",
+			input: "https://example.com/a/",
+			opts:  NormalizeOptions{StripTrailingSlash: true},
+			want:  "https://example.com/a",
+		},
+		{
+			name:  "Keeps Root Slash When Stripping",
+			input: "https://example.com/",
+			opts:  NormalizeOptions{StripTrailingSlash: true},
+			want:  "https://example.com/",
+		},
+		{name: "Keeps UTM Params By Default", input: "https://example.com/?utm_source=x", want: "https://example.com/?utm_source=x"},
+		{
+			name:  "Strips UTM Params When Enabled",
+			input: "https://example.com/p?utm_source=news&id=7&UTM_Medium=email",
+			opts:  NormalizeOptions{StripUTMParams: true},
+			want:  "https://example.com/p?id=7",
+		},
+		{
+			name:  "Leaves Query Without UTM Params Untouched",
+			input: "https://example.com/p?b=2&a=1",
+			opts:  NormalizeOptions{StripUTMParams: true},
+			want:  "https://example.com/p?b=2&a=1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.input)
+			if err != nil {
+				t.Fatalf("Failed to parse URL: %v", err)
+			}
+			if got := normalizeURL(u, tc.opts).String(); got != tc.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHostForms(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		wantASCII   string
+		wantUnicode string
+	}{
+		{name: "ASCII Host", input: "https://example.com/", wantASCII: "example.com", wantUnicode: "example.com"},
+		{name: "Unicode Input", input: "https://münchen.de/", wantASCII: "xn--mnchen-3ya.de", wantUnicode: "münchen.de"},
+		{name: "Punycode Input", input: "https://xn--mnchen-3ya.de:8080/", wantASCII: "xn--mnchen-3ya.de", wantUnicode: "münchen.de"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, _ := url.Parse(tc.input)
+			ascii, unicode := hostForms(u)
+			if ascii != tc.wantASCII || unicode != tc.wantUnicode {
+				t.Errorf("hostForms(%q) = (%q, %q), want (%q, %q)", tc.input, ascii, unicode, tc.wantASCII, tc.wantUnicode)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxValidatorMessages caps the findings taken from the validator, so a badly broken page
+	// does not bury the other findings.
+	maxValidatorMessages = 50
+	// maxValidatorResponseBytes caps how much of the validator's answer is read.
+	maxValidatorResponseBytes = 4 << 20
+)
+
+// validatorClient submits pages to the Nu HTML Checker. The checker is a service the server is
+// configured with rather than a user-supplied URL, so it does not go through the analyses'
+// transport and its private network block.
+var validatorClient = &http.Client{Timeout: 30 * time.Second}
+
+// nuMessage is a message of the Nu HTML Checker's JSON output.
+type nuMessage struct {
+	Type       string `json:"type"`
+	SubType    string `json:"subType"`
+	Message    string `json:"message"`
+	LastLine   int    `json:"lastLine"`
+	LastColumn int    `json:"lastColumn"`
+}
+
+// validateMarkup submits body, served with contentType, to the Nu HTML Checker at
+// validatorURL and returns its errors and warnings as RuleInvalidMarkup findings, in the
+// order the checker reports them. Informational messages are left out.
+func validateMarkup(ctx context.Context, validatorURL string, body []byte, contentType string) ([]SecurityFinding, error) {
+	u, err := url.Parse(validatorURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("out", "json")
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		contentType = "text/html; charset=utf-8"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	resp, err := validatorClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("validator unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("validator answered %s", resp.Status)
+	}
+	var output struct {
+		Messages []nuMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxValidatorResponseBytes)).Decode(&output); err != nil {
+		return nil, fmt.Errorf("invalid validator response: %w", err)
+	}
+
+	var findings []SecurityFinding
+	for _, m := range output.Messages {
+		var severity string
+		switch {
+		case m.Type == "non-document-error":
+			return nil, fmt.Errorf("validator could not check the page: %s", m.Message)
+		case m.Type == "error":
+			severity = SeverityError
+		case m.Type == "info" && m.SubType == "warning":
+			severity = SeverityWarning
+		default:
+			continue
+		}
+		if len(findings) == maxValidatorMessages {
+			break
+		}
+		message := m.Message
+		if m.LastLine > 0 {
+			message = fmt.Sprintf("Line %d, column %d: %s", m.LastLine, m.LastColumn, m.Message)
+		}
+		findings = append(findings, SecurityFinding{RuleID: RuleInvalidMarkup, Severity: severity, Message: message})
+	}
+	return findings, nil
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateMarkup(t *testing.T) {
+	validator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.URL.Query().Get("out") != "json" || r.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Errorf("Expected a POST of HTML asking for JSON, but got %s %s with %q", r.Method, r.URL, r.Header.Get("Content-Type"))
+		}
+		switch string(body) {
+		case "<p>bad":
+			fmt.Fprint(w, `{"messages": [
+				{"type": "error", "message": "Start tag seen without seeing a doctype first.", "lastLine": 1, "lastColumn": 3},
+				{"type": "info", "message": "Just so you know."},
+				{"type": "info", "subType": "warning", "message": "Consider adding a lang attribute."}
+			]}`)
+		case "<p>broken validator":
+			fmt.Fprint(w, `{"messages": [{"type": "non-document-error", "subType": "io", "message": "HTTP resource not retrievable."}]}`)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer validator.Close()
+
+	testCases := []struct {
+		name        string
+		body        string
+		contentType string
+		want        []SecurityFinding
+		wantErr     string
+	}{
+		{
+			name:        "Errors and Warnings",
+			body:        "<p>bad",
+			contentType: "text/plain",
+			want: []SecurityFinding{
+				{RuleID: RuleInvalidMarkup, Severity: SeverityError, Message: "Line 1, column 3: Start tag seen without seeing a doctype first."},
+				{RuleID: RuleInvalidMarkup, Severity: SeverityWarning, Message: "Consider adding a lang attribute."},
+			},
+		},
+		{name: "Non-Document Error", body: "<p>broken validator", contentType: "text/html; charset=utf-8", wantErr: "HTTP resource not retrievable"},
+		{name: "Validator Down", body: "<p>other", wantErr: "503"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := validateMarkup(context.Background(), validator.URL, []byte(tc.body), tc.contentType)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Expected an error containing %q, but got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected findings %+v, but got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzePage_MarkupValidation(t *testing.T) {
+	validator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"messages": [{"type": "error", "message": "Stray end tag “div”.", "lastLine": 1, "lastColumn": 40}]}`)
+	}))
+	defer validator.Close()
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><title>Page</title></div>`)
+	}))
+	defer page.Close()
+
+	opts := DefaultOptions()
+	without, err := AnalyzePage(context.Background(), testLogger, page.URL, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	opts.ValidatorURL = validator.URL
+	with, err := AnalyzePage(context.Background(), testLogger, page.URL, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if len(with.SecurityFindings) != len(without.SecurityFindings)+1 || with.SecurityFindings[len(with.SecurityFindings)-1].RuleID != RuleInvalidMarkup {
+		t.Errorf("Expected the validator's error to be added to the findings, but got %+v", with.SecurityFindings)
+	}
+	if with.Score != without.Score {
+		t.Errorf("Expected markup findings not to change the score %d, but got %d", without.Score, with.Score)
+	}
+
+	validator.Close()
+	down, err := AnalyzePage(context.Background(), testLogger, page.URL, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if _, ok := down.Errors[CheckMarkupValidation]; !ok {
+		t.Errorf("Expected a %q error with the validator down, but got %v", CheckMarkupValidation, down.Errors)
+	}
+}
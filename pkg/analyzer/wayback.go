@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWaybackResponseBytes caps how much of an availability answer is read.
+	maxWaybackResponseBytes = 1 << 20
+	// waybackTimestampLayout is the layout of the timestamps of the Wayback Machine.
+	waybackTimestampLayout = "20060102150405"
+	// archivedHeaderPrefix prefixes the original response headers of an archived page.
+	archivedHeaderPrefix = "X-Archive-Orig-"
+)
+
+// SnapshotLatest is the Options.Snapshot value that analyzes the latest archived snapshot.
+const SnapshotLatest = "latest"
+
+// snapshotTimestamp matches the Options.Snapshot values that name a point in time, from a year
+// to a second.
+var snapshotTimestamp = regexp.MustCompile(`^\d{4}(?:\d{2}){0,5}$`)
+
+// ErrNoSnapshot is returned by AnalyzePage when Options.Snapshot asks for an archived snapshot
+// of a page that the Wayback Machine does not have.
+var ErrNoSnapshot = errors.New("no archived snapshot")
+
+// waybackClient queries the Wayback Machine's availability API. Like the markup validator it is
+// a service the server is configured with, so it does not go through the analyses' transport.
+var waybackClient = &http.Client{Timeout: 10 * time.Second}
+
+// ArchiveSnapshot is a snapshot of the analyzed page in the Wayback Machine.
+type ArchiveSnapshot struct {
+	// URL is the snapshot's page in the Wayback Machine.
+	URL string `json:"url" xml:"url,attr"`
+	// Timestamp is when the snapshot was taken.
+	Timestamp time.Time `json:"timestamp" xml:"timestamp,attr"`
+	// Analyzed reports whether the result is the analysis of the snapshot, as asked for with
+	// Options.Snapshot, rather than of the live page, which is then the latest snapshot.
+	Analyzed bool `json:"analyzed,omitempty" xml:"analyzed,attr,omitempty"`
+}
+
+// ValidSnapshot reports whether snapshot is a valid Options.Snapshot value: empty,
+// SnapshotLatest or a timestamp of 4 to 14 digits such as "2019" or "20190615120000".
+func ValidSnapshot(snapshot string) bool {
+	return snapshot == "" || snapshot == SnapshotLatest || snapshotTimestamp.MatchString(snapshot)
+}
+
+// lookupSnapshot asks the availability API at waybackURL, such as
+// https://archive.org/wayback/available, for the snapshot of pageURL closest to timestamp, or
+// the latest one if timestamp is empty. It returns nil if there is none.
+func lookupSnapshot(ctx context.Context, waybackURL, pageURL, timestamp string) (_ *ArchiveSnapshot, err error) {
+	ctx, span := tracer.Start(ctx, "lookupSnapshot")
+	defer func() { endSpan(span, err) }()
+
+	u, err := url.Parse(waybackURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Wayback Machine URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("url", pageURL)
+	if timestamp != "" {
+		query.Set("timestamp", timestamp)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	resp, err := waybackClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Wayback Machine unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Wayback Machine answered %s", resp.Status)
+	}
+	var answer struct {
+		ArchivedSnapshots struct {
+			Closest *struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+				Timestamp string `json:"timestamp"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxWaybackResponseBytes)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid Wayback Machine response: %w", err)
+	}
+
+	closest := answer.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return nil, nil
+	}
+	taken, err := time.Parse(waybackTimestampLayout, closest.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Wayback Machine timestamp %q", closest.Timestamp)
+	}
+	return &ArchiveSnapshot{URL: closest.URL, Timestamp: taken}, nil
+}
+
+// snapshotToAnalyze returns the snapshot of pageURL that opts.Snapshot asks to analyze, failing
+// with ErrNoSnapshot if the Wayback Machine does not have one.
+func snapshotToAnalyze(ctx context.Context, pageURL string, opts Options) (*ArchiveSnapshot, error) {
+	if opts.WaybackURL == "" {
+		return nil, errors.New("analyzing archived snapshots needs a Wayback Machine URL")
+	}
+	if !ValidSnapshot(opts.Snapshot) {
+		return nil, fmt.Errorf("invalid snapshot %q, expected %q or a timestamp such as 20190615", opts.Snapshot, SnapshotLatest)
+	}
+	timestamp := opts.Snapshot
+	if timestamp == SnapshotLatest {
+		timestamp = ""
+	}
+	snapshot, err := lookupSnapshot(ctx, opts.WaybackURL, pageURL, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrNoSnapshot
+	}
+	snapshot.Analyzed = true
+	return snapshot, nil
+}
+
+// rawSnapshotURL returns the address of the archived page of snapshot as it was served, without
+// the Wayback Machine's toolbar and rewritten links.
+func rawSnapshotURL(snapshot *ArchiveSnapshot) string {
+	timestamp := "/" + snapshot.Timestamp.Format(waybackTimestampLayout) + "/"
+	return strings.Replace(snapshot.URL, timestamp, strings.TrimSuffix(timestamp, "/")+"id_/", 1)
+}
+
+// archivedHeader returns the original response header of an archived page, which the Wayback
+// Machine serves with the X-Archive-Orig- prefix.
+func archivedHeader(header http.Header) http.Header {
+	original := make(http.Header)
+	for name, values := range header {
+		if trimmed, ok := strings.CutPrefix(name, archivedHeaderPrefix); ok && trimmed != "" {
+			original[http.CanonicalHeaderKey(trimmed)] = values
+		}
+	}
+	return original
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newWaybackServer serves an availability API at /available with a snapshot of every URL from
+// 15 June 2019, except those of unarchived.example, and the raw snapshots themselves.
+func newWaybackServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/available":
+			page := r.URL.Query().Get("url")
+			if page == "http://unarchived.example/" {
+				fmt.Fprint(w, `{"url": "unarchived.example", "archived_snapshots": {}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"url": %q, "archived_snapshots": {"closest": {"status": "200", "available": true, "url": "%s/web/20190615120000/%s", "timestamp": "20190615120000"}}}`, page, server.URL, page)
+		case "/web/20190615120000id_/http://archived.example/":
+			w.Header().Set("X-Archive-Orig-X-Frame-Options", "DENY")
+			w.Header().Set("X-Archive-Orig-Content-Security-Policy", "default-src 'self'")
+			w.Header().Set("X-Archive-Orig-X-Content-Type-Options", "nosniff")
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Back in 2019</title></head><body><h1>Old</h1></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookupSnapshot(t *testing.T) {
+	server := newWaybackServer(t)
+
+	got, err := lookupSnapshot(context.Background(), server.URL+"/available", "http://archived.example/", "")
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	want := &ArchiveSnapshot{URL: server.URL + "/web/20190615120000/http://archived.example/", Timestamp: time.Date(2019, 6, 15, 12, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, but got %+v", want, got)
+	}
+	if raw, wantRaw := rawSnapshotURL(got), server.URL+"/web/20190615120000id_/http://archived.example/"; raw != wantRaw {
+		t.Errorf("Expected the raw snapshot at %s, but got %s", wantRaw, raw)
+	}
+
+	if got, err := lookupSnapshot(context.Background(), server.URL+"/available", "http://unarchived.example/", ""); got != nil || err != nil {
+		t.Errorf("Expected no snapshot, but got %+v and %v", got, err)
+	}
+	if _, err := lookupSnapshot(context.Background(), server.URL+"/missing", "http://archived.example/", ""); err == nil {
+		t.Error("Expected an error when the availability API fails")
+	}
+}
+
+func TestValidSnapshot(t *testing.T) {
+	for snapshot, expected := range map[string]bool{
+		"": true, "latest": true, "2019": true, "201906": true, "20190615120000": true,
+		"19": false, "20190": false, "201906151200001": false, "2019-06-15": false, "oldest": false,
+	} {
+		if got := ValidSnapshot(snapshot); got != expected {
+			t.Errorf("Expected ValidSnapshot(%q) to be %v, but got %v", snapshot, expected, got)
+		}
+	}
+}
+
+func TestArchivedHeader(t *testing.T) {
+	header := http.Header{
+		"X-Archive-Orig-Strict-Transport-Security": {"max-age=31536000"},
+		"X-Archive-Orig-Etag":                      {`"v1"`},
+		"Content-Security-Policy":                  {"default-src 'none'"},
+		"X-Archive-Orig-":                          {"empty"},
+	}
+	want := http.Header{"Strict-Transport-Security": {"max-age=31536000"}, "Etag": {`"v1"`}}
+	if got := archivedHeader(header); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, but got %v", want, got)
+	}
+}
+
+func TestAnalyzePage_Snapshot(t *testing.T) {
+	server := newWaybackServer(t)
+
+	opts := DefaultOptions()
+	opts.Retry.MaxRetries = 0
+	opts.Checks = []string{CheckHeadings, CheckSecurity}
+	opts.WaybackURL = server.URL + "/available"
+	opts.Snapshot = SnapshotLatest
+
+	result, err := AnalyzePage(context.Background(), testLogger, "http://archived.example/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if result.Title != "Back in 2019" || result.Headings["h1"] != 1 {
+		t.Errorf("Expected the snapshot to be analyzed, but got title %q and headings %v", result.Title, result.Headings)
+	}
+	if result.Archive == nil || !result.Archive.Analyzed || result.Archive.Timestamp.Year() != 2019 {
+		t.Errorf("Expected the analyzed snapshot to be reported, but got %+v", result.Archive)
+	}
+	if result.Host != "archived.example" {
+		t.Errorf("Expected the result to be of the page's host, but got %q", result.Host)
+	}
+	if len(result.SecurityFindings) != 0 {
+		t.Errorf("Expected the archived headers to be checked, but got %+v", result.SecurityFindings)
+	}
+
+	if _, err := AnalyzePage(context.Background(), testLogger, "http://unarchived.example/", opts); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Expected ErrNoSnapshot, but got: %v", err)
+	}
+	opts.WaybackURL = ""
+	if _, err := AnalyzePage(context.Background(), testLogger, "http://archived.example/", opts); err == nil {
+		t.Error("Expected an error without a Wayback Machine URL")
+	}
+}
+
+func TestAnalyzePage_LatestSnapshot(t *testing.T) {
+	wayback := newWaybackServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Live</title></head></html>`)
+	}))
+	defer server.Close()
+
+	opts := DefaultOptions()
+	opts.Checks = []string{CheckArchive}
+	opts.WaybackURL = wayback.URL + "/available"
+
+	result, err := AnalyzePage(context.Background(), testLogger, server.URL+"/", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+	if result.Title != "Live" || result.Archive == nil || result.Archive.Analyzed {
+		t.Errorf("Expected the live page with its latest snapshot, but got title %q and %+v", result.Title, result.Archive)
+	}
+}
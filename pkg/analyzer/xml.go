@@ -0,0 +1,246 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"maps"
+	"slices"
+	"time"
+)
+
+// xmlResult is the XML representation of an AnalysisResult. encoding/xml cannot encode maps,
+// so they become lists of elements sorted by key, keeping the output stable. List containers
+// are always present, even when empty. Element names
+// follow the JSON field names, and the same additive-only rule applies within a SchemaVersion.
+type xmlResult struct {
+	SchemaVersion      string              `xml:"schema_version,attr"`
+	ID                 string              `xml:"id,attr"`
+	Host               string              `xml:"host"`
+	HostUnicode        string              `xml:"host_unicode"`
+	HTMLVersion        string              `xml:"html_version"`
+	Title              string              `xml:"title"`
+	Headings           []xmlCount          `xml:"headings>heading"`
+	Links              xmlLinkSummary      `xml:"links"`
+	ContainsLoginForm  bool                `xml:"contains_login_form"`
+	AnalyzedAt         time.Time           `xml:"analyzed_at"`
+	Description        string              `xml:"description,omitempty"`
+	ContentFingerprint string              `xml:"content_fingerprint,omitempty"`
+	Noindex            bool                `xml:"noindex,omitempty"`
+	Redirects          *xmlURLs            `xml:"redirects,omitempty"`
+	Pagination         *Pagination         `xml:"pagination,omitempty"`
+	RichResults        *xmlRichResults     `xml:"rich_results,omitempty"`
+	Spelling           *Spelling           `xml:"spelling,omitempty"`
+	Placeholders       *xmlPlaceholders    `xml:"placeholders,omitempty"`
+	Classification     *Classification     `xml:"classification,omitempty"`
+	Noscript           *Noscript           `xml:"noscript,omitempty"`
+	Media              *Media              `xml:"media,omitempty"`
+	CustomElements     *xmlCustomElements  `xml:"custom_elements,omitempty"`
+	Iframes            *xmlIframes         `xml:"iframes,omitempty"`
+	DNS                *DNSRecords         `xml:"dns,omitempty"`
+	Hosting            *xmlHosting         `xml:"hosting,omitempty"`
+	Registration       *DomainRegistration `xml:"registration,omitempty"`
+	Archive            *ArchiveSnapshot    `xml:"archive,omitempty"`
+	WordCount          int                 `xml:"word_count,omitempty"`
+	PageBytes          int64               `xml:"page_bytes,omitempty"`
+	ETag               string              `xml:"etag,omitempty"`
+	LastModified       string              `xml:"last_modified,omitempty"`
+	LinkResults        []xmlLinkResult     `xml:"link_results>link"`
+	SecurityFindings   []xmlFinding        `xml:"security_findings>finding"`
+	Score              int                 `xml:"score"`
+	Grade              string              `xml:"grade"`
+	Errors             []xmlCheckError     `xml:"errors>error"`
+}
+
+type xmlLinkSummary struct {
+	InternalCount       int        `xml:"internal_count"`
+	ExternalCount       int        `xml:"external_count"`
+	InaccessibleCount   int        `xml:"inaccessible_count"`
+	NotCheckedCounts    []xmlCount `xml:"not_checked_counts>reason"`
+	BrokenAnchorCount   int        `xml:"broken_anchor_count"`
+	SkippedCounts       []xmlCount `xml:"skipped_counts>category"`
+	InternalIframeCount int        `xml:"internal_iframe_count"`
+	ExternalIframeCount int        `xml:"external_iframe_count"`
+	CSSResourceCount    int        `xml:"css_resource_count"`
+	MediaSourceCount    int        `xml:"media_source_count"`
+}
+
+type xmlCount struct {
+	Name  string `xml:"name,attr"`
+	Count int    `xml:"count,attr"`
+}
+
+type xmlLinkResult struct {
+	URL        string   `xml:"url,attr"`
+	Type       string   `xml:"type,attr"`
+	Kind       string   `xml:"kind,attr"`
+	Status     string   `xml:"status,attr"`
+	StatusCode int      `xml:"status_code,attr,omitempty"`
+	AnchorText string   `xml:"anchor_text,omitempty"`
+	Error      string   `xml:"error,omitempty"`
+	Redirects  *xmlURLs `xml:"redirects,omitempty"`
+}
+
+// xmlURLs is a list of URLs that is left out of the XML when empty.
+type xmlURLs struct {
+	URLs []string `xml:"url"`
+}
+
+// xmlRichResults is a list of rich results that is left out of the XML when empty.
+type xmlRichResults struct {
+	RichResults []RichResult `xml:"rich_result"`
+}
+
+// xmlCustomElements is a list of custom elements that is left out of the XML when empty.
+type xmlCustomElements struct {
+	CustomElements []CustomElement `xml:"custom_element"`
+}
+
+// xmlHosting is a list of hosting addresses that is left out of the XML when empty.
+type xmlHosting struct {
+	IPs []HostingIP `xml:"address"`
+}
+
+// xmlIframes is a list of iframes that is left out of the XML when empty.
+type xmlIframes struct {
+	Iframes []Iframe `xml:"iframe"`
+}
+
+// xmlPlaceholders is a list of placeholders that is left out of the XML when empty.
+type xmlPlaceholders struct {
+	Placeholders []Placeholder `xml:"placeholder"`
+}
+
+type xmlFinding struct {
+	RuleID   string `xml:"rule_id,attr"`
+	Severity string `xml:"severity,attr"`
+	URL      string `xml:"url,attr,omitempty"`
+	Message  string `xml:",chardata"`
+}
+
+type xmlCheckError struct {
+	Check  string `xml:"check,attr"`
+	Reason string `xml:",chardata"`
+}
+
+// MarshalXML encodes the result in its stable XML representation.
+func (r AnalysisResult) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	x := xmlResult{
+		SchemaVersion:      r.SchemaVersion,
+		ID:                 r.ID,
+		Host:               r.Host,
+		HostUnicode:        r.HostUnicode,
+		HTMLVersion:        r.HTMLVersion,
+		Title:              r.Title,
+		Description:        r.Description,
+		ContentFingerprint: r.ContentFingerprint,
+		Noindex:            r.Noindex,
+		Redirects:          xmlURLList(r.Redirects),
+		Pagination:         r.Pagination,
+		RichResults:        xmlRichResultList(r.RichResults),
+		Spelling:           r.Spelling,
+		Placeholders:       xmlPlaceholderList(r.Placeholders),
+		Classification:     r.Classification,
+		Noscript:           r.Noscript,
+		Media:              r.Media,
+		CustomElements:     xmlCustomElementList(r.CustomElements),
+		Iframes:            xmlIframeList(r.Iframes),
+		DNS:                r.DNS,
+		Hosting:            xmlHostingList(r.Hosting),
+		Registration:       r.Registration,
+		Archive:            r.Archive,
+		WordCount:          r.WordCount,
+		PageBytes:          r.PageBytes,
+		Headings:           xmlCounts(r.Headings),
+		Links: xmlLinkSummary{
+			InternalCount:       r.Links.InternalCount,
+			ExternalCount:       r.Links.ExternalCount,
+			InaccessibleCount:   r.Links.InaccessibleCount,
+			NotCheckedCounts:    xmlCounts(r.Links.NotCheckedCounts),
+			BrokenAnchorCount:   r.Links.BrokenAnchorCount,
+			SkippedCounts:       xmlCounts(r.Links.SkippedCounts),
+			InternalIframeCount: r.Links.InternalIframeCount,
+			ExternalIframeCount: r.Links.ExternalIframeCount,
+			CSSResourceCount:    r.Links.CSSResourceCount,
+			MediaSourceCount:    r.Links.MediaSourceCount,
+		},
+		ContainsLoginForm: r.ContainsLoginForm,
+		AnalyzedAt:        r.AnalyzedAt,
+		ETag:              r.ETag,
+		LastModified:      r.LastModified,
+		Score:             r.Score,
+		Grade:             r.Grade,
+	}
+	for _, link := range r.LinkResults {
+		x.LinkResults = append(x.LinkResults, xmlLinkResult{
+			URL:        link.URL,
+			Type:       link.Type,
+			Kind:       link.Kind,
+			Status:     link.Status,
+			StatusCode: link.StatusCode,
+			AnchorText: link.AnchorText,
+			Error:      link.Error,
+			Redirects:  xmlURLList(link.Redirects),
+		})
+	}
+	for _, finding := range r.SecurityFindings {
+		x.SecurityFindings = append(x.SecurityFindings, xmlFinding{
+			RuleID:   finding.RuleID,
+			Severity: finding.Severity,
+			URL:      finding.URL,
+			Message:  finding.Message,
+		})
+	}
+	for _, check := range slices.Sorted(maps.Keys(r.Errors)) {
+		x.Errors = append(x.Errors, xmlCheckError{Check: check, Reason: r.Errors[check]})
+	}
+	return e.EncodeElement(x, start)
+}
+
+func xmlURLList(urls []string) *xmlURLs {
+	if len(urls) == 0 {
+		return nil
+	}
+	return &xmlURLs{URLs: urls}
+}
+
+func xmlRichResultList(results []RichResult) *xmlRichResults {
+	if len(results) == 0 {
+		return nil
+	}
+	return &xmlRichResults{RichResults: results}
+}
+
+func xmlPlaceholderList(placeholders []Placeholder) *xmlPlaceholders {
+	if len(placeholders) == 0 {
+		return nil
+	}
+	return &xmlPlaceholders{Placeholders: placeholders}
+}
+
+func xmlCustomElementList(elements []CustomElement) *xmlCustomElements {
+	if len(elements) == 0 {
+		return nil
+	}
+	return &xmlCustomElements{CustomElements: elements}
+}
+
+func xmlHostingList(ips []HostingIP) *xmlHosting {
+	if len(ips) == 0 {
+		return nil
+	}
+	return &xmlHosting{IPs: ips}
+}
+
+func xmlIframeList(iframes []Iframe) *xmlIframes {
+	if len(iframes) == 0 {
+		return nil
+	}
+	return &xmlIframes{Iframes: iframes}
+}
+
+func xmlCounts(counts map[string]int) []xmlCount {
+	var list []xmlCount
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		list = append(list, xmlCount{Name: name, Count: counts[name]})
+	}
+	return list
+}
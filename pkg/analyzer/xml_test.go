@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestAnalysisResult_MarshalXML(t *testing.T) {
+	result := &AnalysisResult{
+		SchemaVersion: SchemaVersion,
+		ID:            "0123abcd",
+		Host:          "example.com",
+		HostUnicode:   "example.com",
+		HTMLVersion:   "HTML5",
+		Title:         "Fish & Chips",
+		Headings:      map[string]int{"h2": 3, "h1": 1},
+		Links: LinkSummary{
+			InternalCount:    1,
+			NotCheckedCounts: map[string]int{},
+			SkippedCounts:    map[string]int{"mailto": 2},
+		},
+		AnalyzedAt:  time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+		LinkResults: []LinkResult{{URL: "https://example.com/a", Type: LinkTypeInternal, Kind: LinkKindLink, AnchorText: "A", Status: LinkStatusOK, StatusCode: 200}},
+		SecurityFindings: []SecurityFinding{
+			{RuleID: RuleMixedContent, Severity: SeverityError, Message: "Resource is loaded over plain HTTP", URL: "http://cdn.example/a.png"},
+		},
+		Score:  94,
+		Grade:  "A",
+		Errors: map[string]string{CheckLoginForm: "canceled"},
+	}
+
+	data, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"response"`
+		Result  *AnalysisResult `xml:"result"`
+	}{Result: result})
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expected := `<response><result schema_version="` + SchemaVersion + `" id="0123abcd">` +
+		`<host>example.com</host><host_unicode>example.com</host_unicode><html_version>HTML5</html_version>` +
+		`<title>Fish &amp; Chips</title>` +
+		`<headings><heading name="h1" count="1"></heading><heading name="h2" count="3"></heading></headings>` +
+		`<links><internal_count>1</internal_count><external_count>0</external_count><inaccessible_count>0</inaccessible_count>` +
+		`<not_checked_counts></not_checked_counts><broken_anchor_count>0</broken_anchor_count>` +
+		`<skipped_counts><category name="mailto" count="2"></category></skipped_counts>` +
+		`<internal_iframe_count>0</internal_iframe_count><external_iframe_count>0</external_iframe_count>` +
+		`<css_resource_count>0</css_resource_count><media_source_count>0</media_source_count></links>` +
+		`<contains_login_form>false</contains_login_form><analyzed_at>2025-01-02T15:04:05Z</analyzed_at>` +
+		`<link_results><link url="https://example.com/a" type="internal" kind="link" status="ok" status_code="200"><anchor_text>A</anchor_text></link></link_results>` +
+		`<security_findings><finding rule_id="mixed-content" severity="error" url="http://cdn.example/a.png">Resource is loaded over plain HTTP</finding></security_findings>` +
+		`<score>94</score><grade>A</grade>` +
+		`<errors><error check="login_form">canceled</error></errors>` +
+		`</result></response>`
+	if string(data) != expected {
+		t.Errorf("Expected XML\n%s\nbut got\n%s", expected, data)
+	}
+}
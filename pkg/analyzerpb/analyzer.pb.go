@@ -0,0 +1,1075 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: webanalyzer/v1/analyzer.proto
+
+package analyzerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Stage int32
+
+const (
+	Stage_STAGE_UNSPECIFIED Stage = 0
+	Stage_STAGE_FETCHING    Stage = 1
+	Stage_STAGE_PARSING     Stage = 2
+	// Skipped for pages without links.
+	Stage_STAGE_CHECKING_LINKS Stage = 3
+)
+
+// Enum value maps for Stage.
+var (
+	Stage_name = map[int32]string{
+		0: "STAGE_UNSPECIFIED",
+		1: "STAGE_FETCHING",
+		2: "STAGE_PARSING",
+		3: "STAGE_CHECKING_LINKS",
+	}
+	Stage_value = map[string]int32{
+		"STAGE_UNSPECIFIED":    0,
+		"STAGE_FETCHING":       1,
+		"STAGE_PARSING":        2,
+		"STAGE_CHECKING_LINKS": 3,
+	}
+)
+
+func (x Stage) Enum() *Stage {
+	p := new(Stage)
+	*p = x
+	return p
+}
+
+func (x Stage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Stage) Descriptor() protoreflect.EnumDescriptor {
+	return file_webanalyzer_v1_analyzer_proto_enumTypes[0].Descriptor()
+}
+
+func (Stage) Type() protoreflect.EnumType {
+	return &file_webanalyzer_v1_analyzer_proto_enumTypes[0]
+}
+
+func (x Stage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Stage.Descriptor instead.
+func (Stage) EnumDescriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{0}
+}
+
+type AnalyzeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Size of the link check worker pool; zero uses the server's setting.
+	Workers int32 `protobuf:"varint,2,opt,name=workers,proto3" json:"workers,omitempty"`
+	// Skip any cached result.
+	Refresh   bool              `protobuf:"varint,3,opt,name=refresh,proto3" json:"refresh,omitempty"`
+	UserAgent string            `protobuf:"bytes,4,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	Headers   map[string]string `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Proxy     string            `protobuf:"bytes,6,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	BasicAuth *BasicAuth        `protobuf:"bytes,7,opt,name=basic_auth,json=basicAuth,proto3" json:"basic_auth,omitempty"`
+	// Cookie header value ("name=value; other=value") for the page's host.
+	Cookie        string            `protobuf:"bytes,8,opt,name=cookie,proto3" json:"cookie,omitempty"`
+	Cookies       map[string]string `protobuf:"bytes,9,rep,name=cookies,proto3" json:"cookies,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRequest) Reset() {
+	*x = AnalyzeRequest{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRequest) ProtoMessage() {}
+
+func (x *AnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AnalyzeRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *AnalyzeRequest) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *AnalyzeRequest) GetRefresh() bool {
+	if x != nil {
+		return x.Refresh
+	}
+	return false
+}
+
+func (x *AnalyzeRequest) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *AnalyzeRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *AnalyzeRequest) GetProxy() string {
+	if x != nil {
+		return x.Proxy
+	}
+	return ""
+}
+
+func (x *AnalyzeRequest) GetBasicAuth() *BasicAuth {
+	if x != nil {
+		return x.BasicAuth
+	}
+	return nil
+}
+
+func (x *AnalyzeRequest) GetCookie() string {
+	if x != nil {
+		return x.Cookie
+	}
+	return ""
+}
+
+func (x *AnalyzeRequest) GetCookies() map[string]string {
+	if x != nil {
+		return x.Cookies
+	}
+	return nil
+}
+
+type BasicAuth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BasicAuth) Reset() {
+	*x = BasicAuth{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BasicAuth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BasicAuth) ProtoMessage() {}
+
+func (x *BasicAuth) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BasicAuth.ProtoReflect.Descriptor instead.
+func (*BasicAuth) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BasicAuth) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *BasicAuth) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type AnalyzeResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Result *AnalysisResult        `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// Whether the result was served from the server's result cache.
+	Cached        bool `protobuf:"varint,2,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeResponse) Reset() {
+	*x = AnalyzeResponse{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeResponse) ProtoMessage() {}
+
+func (x *AnalyzeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeResponse) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AnalyzeResponse) GetResult() *AnalysisResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type AnalyzeEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*AnalyzeEvent_Progress
+	//	*AnalyzeEvent_Result
+	Event         isAnalyzeEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeEvent) Reset() {
+	*x = AnalyzeEvent{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeEvent) ProtoMessage() {}
+
+func (x *AnalyzeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeEvent.ProtoReflect.Descriptor instead.
+func (*AnalyzeEvent) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnalyzeEvent) GetEvent() isAnalyzeEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *AnalyzeEvent) GetProgress() *Progress {
+	if x != nil {
+		if x, ok := x.Event.(*AnalyzeEvent_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *AnalyzeEvent) GetResult() *AnalyzeResponse {
+	if x != nil {
+		if x, ok := x.Event.(*AnalyzeEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isAnalyzeEvent_Event interface {
+	isAnalyzeEvent_Event()
+}
+
+type AnalyzeEvent_Progress struct {
+	Progress *Progress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type AnalyzeEvent_Result struct {
+	// The last event of the stream.
+	Result *AnalyzeResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*AnalyzeEvent_Progress) isAnalyzeEvent_Event() {}
+
+func (*AnalyzeEvent_Result) isAnalyzeEvent_Event() {}
+
+type Progress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stage Stage                  `protobuf:"varint,1,opt,name=stage,proto3,enum=webanalyzer.v1.Stage" json:"stage,omitempty"`
+	// Set in STAGE_CHECKING_LINKS only.
+	LinksChecked  int32 `protobuf:"varint,2,opt,name=links_checked,json=linksChecked,proto3" json:"links_checked,omitempty"`
+	LinksTotal    int32 `protobuf:"varint,3,opt,name=links_total,json=linksTotal,proto3" json:"links_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Progress) GetStage() Stage {
+	if x != nil {
+		return x.Stage
+	}
+	return Stage_STAGE_UNSPECIFIED
+}
+
+func (x *Progress) GetLinksChecked() int32 {
+	if x != nil {
+		return x.LinksChecked
+	}
+	return 0
+}
+
+func (x *Progress) GetLinksTotal() int32 {
+	if x != nil {
+		return x.LinksTotal
+	}
+	return 0
+}
+
+type AnalysisResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion     string                 `protobuf:"bytes,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Id                string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Host              string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	HostUnicode       string                 `protobuf:"bytes,4,opt,name=host_unicode,json=hostUnicode,proto3" json:"host_unicode,omitempty"`
+	HtmlVersion       string                 `protobuf:"bytes,5,opt,name=html_version,json=htmlVersion,proto3" json:"html_version,omitempty"`
+	Title             string                 `protobuf:"bytes,6,opt,name=title,proto3" json:"title,omitempty"`
+	Headings          map[string]int32       `protobuf:"bytes,7,rep,name=headings,proto3" json:"headings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Links             *LinkSummary           `protobuf:"bytes,8,opt,name=links,proto3" json:"links,omitempty"`
+	ContainsLoginForm bool                   `protobuf:"varint,9,opt,name=contains_login_form,json=containsLoginForm,proto3" json:"contains_login_form,omitempty"`
+	AnalyzedAt        *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=analyzed_at,json=analyzedAt,proto3" json:"analyzed_at,omitempty"`
+	Etag              string                 `protobuf:"bytes,11,opt,name=etag,proto3" json:"etag,omitempty"`
+	LastModified      string                 `protobuf:"bytes,12,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"`
+	LinkResults       []*LinkResult          `protobuf:"bytes,13,rep,name=link_results,json=linkResults,proto3" json:"link_results,omitempty"`
+	SecurityFindings  []*SecurityFinding     `protobuf:"bytes,14,rep,name=security_findings,json=securityFindings,proto3" json:"security_findings,omitempty"`
+	Score             int32                  `protobuf:"varint,15,opt,name=score,proto3" json:"score,omitempty"`
+	Grade             string                 `protobuf:"bytes,16,opt,name=grade,proto3" json:"grade,omitempty"`
+	// The checks that failed, by name, and why.
+	Errors        map[string]string `protobuf:"bytes,17,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalysisResult) Reset() {
+	*x = AnalysisResult{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalysisResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalysisResult) ProtoMessage() {}
+
+func (x *AnalysisResult) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalysisResult.ProtoReflect.Descriptor instead.
+func (*AnalysisResult) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AnalysisResult) GetSchemaVersion() string {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetHostUnicode() string {
+	if x != nil {
+		return x.HostUnicode
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetHtmlVersion() string {
+	if x != nil {
+		return x.HtmlVersion
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetHeadings() map[string]int32 {
+	if x != nil {
+		return x.Headings
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetLinks() *LinkSummary {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetContainsLoginForm() bool {
+	if x != nil {
+		return x.ContainsLoginForm
+	}
+	return false
+}
+
+func (x *AnalysisResult) GetAnalyzedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AnalyzedAt
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetLastModified() string {
+	if x != nil {
+		return x.LastModified
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetLinkResults() []*LinkResult {
+	if x != nil {
+		return x.LinkResults
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetSecurityFindings() []*SecurityFinding {
+	if x != nil {
+		return x.SecurityFindings
+	}
+	return nil
+}
+
+func (x *AnalysisResult) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *AnalysisResult) GetGrade() string {
+	if x != nil {
+		return x.Grade
+	}
+	return ""
+}
+
+func (x *AnalysisResult) GetErrors() map[string]string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type LinkSummary struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	InternalCount       int32                  `protobuf:"varint,1,opt,name=internal_count,json=internalCount,proto3" json:"internal_count,omitempty"`
+	ExternalCount       int32                  `protobuf:"varint,2,opt,name=external_count,json=externalCount,proto3" json:"external_count,omitempty"`
+	InaccessibleCount   int32                  `protobuf:"varint,3,opt,name=inaccessible_count,json=inaccessibleCount,proto3" json:"inaccessible_count,omitempty"`
+	NotCheckedCounts    map[string]int32       `protobuf:"bytes,4,rep,name=not_checked_counts,json=notCheckedCounts,proto3" json:"not_checked_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	BrokenAnchorCount   int32                  `protobuf:"varint,5,opt,name=broken_anchor_count,json=brokenAnchorCount,proto3" json:"broken_anchor_count,omitempty"`
+	SkippedCounts       map[string]int32       `protobuf:"bytes,6,rep,name=skipped_counts,json=skippedCounts,proto3" json:"skipped_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	InternalIframeCount int32                  `protobuf:"varint,7,opt,name=internal_iframe_count,json=internalIframeCount,proto3" json:"internal_iframe_count,omitempty"`
+	ExternalIframeCount int32                  `protobuf:"varint,8,opt,name=external_iframe_count,json=externalIframeCount,proto3" json:"external_iframe_count,omitempty"`
+	CssResourceCount    int32                  `protobuf:"varint,9,opt,name=css_resource_count,json=cssResourceCount,proto3" json:"css_resource_count,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *LinkSummary) Reset() {
+	*x = LinkSummary{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkSummary) ProtoMessage() {}
+
+func (x *LinkSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkSummary.ProtoReflect.Descriptor instead.
+func (*LinkSummary) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LinkSummary) GetInternalCount() int32 {
+	if x != nil {
+		return x.InternalCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetExternalCount() int32 {
+	if x != nil {
+		return x.ExternalCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetInaccessibleCount() int32 {
+	if x != nil {
+		return x.InaccessibleCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetNotCheckedCounts() map[string]int32 {
+	if x != nil {
+		return x.NotCheckedCounts
+	}
+	return nil
+}
+
+func (x *LinkSummary) GetBrokenAnchorCount() int32 {
+	if x != nil {
+		return x.BrokenAnchorCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetSkippedCounts() map[string]int32 {
+	if x != nil {
+		return x.SkippedCounts
+	}
+	return nil
+}
+
+func (x *LinkSummary) GetInternalIframeCount() int32 {
+	if x != nil {
+		return x.InternalIframeCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetExternalIframeCount() int32 {
+	if x != nil {
+		return x.ExternalIframeCount
+	}
+	return 0
+}
+
+func (x *LinkSummary) GetCssResourceCount() int32 {
+	if x != nil {
+		return x.CssResourceCount
+	}
+	return 0
+}
+
+type LinkResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// internal or external.
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// link, iframe or css.
+	Kind       string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	AnchorText string `protobuf:"bytes,4,opt,name=anchor_text,json=anchorText,proto3" json:"anchor_text,omitempty"`
+	// ok, inaccessible or not_checked.
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	StatusCode    int32  `protobuf:"varint,6,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LinkResult) Reset() {
+	*x = LinkResult{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkResult) ProtoMessage() {}
+
+func (x *LinkResult) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkResult.ProtoReflect.Descriptor instead.
+func (*LinkResult) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LinkResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *LinkResult) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LinkResult) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *LinkResult) GetAnchorText() string {
+	if x != nil {
+		return x.AnchorText
+	}
+	return ""
+}
+
+func (x *LinkResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LinkResult) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *LinkResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SecurityFinding struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	RuleId string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	// error, warning or note.
+	Severity      string `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Url           string `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecurityFinding) Reset() {
+	*x = SecurityFinding{}
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityFinding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityFinding) ProtoMessage() {}
+
+func (x *SecurityFinding) ProtoReflect() protoreflect.Message {
+	mi := &file_webanalyzer_v1_analyzer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityFinding.ProtoReflect.Descriptor instead.
+func (*SecurityFinding) Descriptor() ([]byte, []int) {
+	return file_webanalyzer_v1_analyzer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SecurityFinding) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *SecurityFinding) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *SecurityFinding) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SecurityFinding) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+var File_webanalyzer_v1_analyzer_proto protoreflect.FileDescriptor
+
+const file_webanalyzer_v1_analyzer_proto_rawDesc = "" +
+	"\n" +
+	"\x1dwebanalyzer/v1/analyzer.proto\x12\x0ewebanalyzer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe3\x03\n" +
+	"\x0eAnalyzeRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x18\n" +
+	"\aworkers\x18\x02 \x01(\x05R\aworkers\x12\x18\n" +
+	"\arefresh\x18\x03 \x01(\bR\arefresh\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x04 \x01(\tR\tuserAgent\x12E\n" +
+	"\aheaders\x18\x05 \x03(\v2+.webanalyzer.v1.AnalyzeRequest.HeadersEntryR\aheaders\x12\x14\n" +
+	"\x05proxy\x18\x06 \x01(\tR\x05proxy\x128\n" +
+	"\n" +
+	"basic_auth\x18\a \x01(\v2\x19.webanalyzer.v1.BasicAuthR\tbasicAuth\x12\x16\n" +
+	"\x06cookie\x18\b \x01(\tR\x06cookie\x12E\n" +
+	"\acookies\x18\t \x03(\v2+.webanalyzer.v1.AnalyzeRequest.CookiesEntryR\acookies\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a:\n" +
+	"\fCookiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\tBasicAuth\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"a\n" +
+	"\x0fAnalyzeResponse\x126\n" +
+	"\x06result\x18\x01 \x01(\v2\x1e.webanalyzer.v1.AnalysisResultR\x06result\x12\x16\n" +
+	"\x06cached\x18\x02 \x01(\bR\x06cached\"\x8a\x01\n" +
+	"\fAnalyzeEvent\x126\n" +
+	"\bprogress\x18\x01 \x01(\v2\x18.webanalyzer.v1.ProgressH\x00R\bprogress\x129\n" +
+	"\x06result\x18\x02 \x01(\v2\x1f.webanalyzer.v1.AnalyzeResponseH\x00R\x06resultB\a\n" +
+	"\x05event\"}\n" +
+	"\bProgress\x12+\n" +
+	"\x05stage\x18\x01 \x01(\x0e2\x15.webanalyzer.v1.StageR\x05stage\x12#\n" +
+	"\rlinks_checked\x18\x02 \x01(\x05R\flinksChecked\x12\x1f\n" +
+	"\vlinks_total\x18\x03 \x01(\x05R\n" +
+	"linksTotal\"\xcf\x06\n" +
+	"\x0eAnalysisResult\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\tR\rschemaVersion\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12!\n" +
+	"\fhost_unicode\x18\x04 \x01(\tR\vhostUnicode\x12!\n" +
+	"\fhtml_version\x18\x05 \x01(\tR\vhtmlVersion\x12\x14\n" +
+	"\x05title\x18\x06 \x01(\tR\x05title\x12H\n" +
+	"\bheadings\x18\a \x03(\v2,.webanalyzer.v1.AnalysisResult.HeadingsEntryR\bheadings\x121\n" +
+	"\x05links\x18\b \x01(\v2\x1b.webanalyzer.v1.LinkSummaryR\x05links\x12.\n" +
+	"\x13contains_login_form\x18\t \x01(\bR\x11containsLoginForm\x12;\n" +
+	"\vanalyzed_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"analyzedAt\x12\x12\n" +
+	"\x04etag\x18\v \x01(\tR\x04etag\x12#\n" +
+	"\rlast_modified\x18\f \x01(\tR\flastModified\x12=\n" +
+	"\flink_results\x18\r \x03(\v2\x1a.webanalyzer.v1.LinkResultR\vlinkResults\x12L\n" +
+	"\x11security_findings\x18\x0e \x03(\v2\x1f.webanalyzer.v1.SecurityFindingR\x10securityFindings\x12\x14\n" +
+	"\x05score\x18\x0f \x01(\x05R\x05score\x12\x14\n" +
+	"\x05grade\x18\x10 \x01(\tR\x05grade\x12B\n" +
+	"\x06errors\x18\x11 \x03(\v2*.webanalyzer.v1.AnalysisResult.ErrorsEntryR\x06errors\x1a;\n" +
+	"\rHeadingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a9\n" +
+	"\vErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8f\x05\n" +
+	"\vLinkSummary\x12%\n" +
+	"\x0einternal_count\x18\x01 \x01(\x05R\rinternalCount\x12%\n" +
+	"\x0eexternal_count\x18\x02 \x01(\x05R\rexternalCount\x12-\n" +
+	"\x12inaccessible_count\x18\x03 \x01(\x05R\x11inaccessibleCount\x12_\n" +
+	"\x12not_checked_counts\x18\x04 \x03(\v21.webanalyzer.v1.LinkSummary.NotCheckedCountsEntryR\x10notCheckedCounts\x12.\n" +
+	"\x13broken_anchor_count\x18\x05 \x01(\x05R\x11brokenAnchorCount\x12U\n" +
+	"\x0eskipped_counts\x18\x06 \x03(\v2..webanalyzer.v1.LinkSummary.SkippedCountsEntryR\rskippedCounts\x122\n" +
+	"\x15internal_iframe_count\x18\a \x01(\x05R\x13internalIframeCount\x122\n" +
+	"\x15external_iframe_count\x18\b \x01(\x05R\x13externalIframeCount\x12,\n" +
+	"\x12css_resource_count\x18\t \x01(\x05R\x10cssResourceCount\x1aC\n" +
+	"\x15NotCheckedCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a@\n" +
+	"\x12SkippedCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xb6\x01\n" +
+	"\n" +
+	"LinkResult\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x1f\n" +
+	"\vanchor_text\x18\x04 \x01(\tR\n" +
+	"anchorText\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1f\n" +
+	"\vstatus_code\x18\x06 \x01(\x05R\n" +
+	"statusCode\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"r\n" +
+	"\x0fSecurityFinding\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url*_\n" +
+	"\x05Stage\x12\x15\n" +
+	"\x11STAGE_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eSTAGE_FETCHING\x10\x01\x12\x11\n" +
+	"\rSTAGE_PARSING\x10\x02\x12\x18\n" +
+	"\x14STAGE_CHECKING_LINKS\x10\x032\xae\x01\n" +
+	"\x0fAnalyzerService\x12J\n" +
+	"\aAnalyze\x12\x1e.webanalyzer.v1.AnalyzeRequest\x1a\x1f.webanalyzer.v1.AnalyzeResponse\x12O\n" +
+	"\rAnalyzeStream\x12\x1e.webanalyzer.v1.AnalyzeRequest\x1a\x1c.webanalyzer.v1.AnalyzeEvent0\x01B\x1dZ\x1bweb-analyzer/pkg/analyzerpbb\x06proto3"
+
+var (
+	file_webanalyzer_v1_analyzer_proto_rawDescOnce sync.Once
+	file_webanalyzer_v1_analyzer_proto_rawDescData []byte
+)
+
+func file_webanalyzer_v1_analyzer_proto_rawDescGZIP() []byte {
+	file_webanalyzer_v1_analyzer_proto_rawDescOnce.Do(func() {
+		file_webanalyzer_v1_analyzer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_webanalyzer_v1_analyzer_proto_rawDesc), len(file_webanalyzer_v1_analyzer_proto_rawDesc)))
+	})
+	return file_webanalyzer_v1_analyzer_proto_rawDescData
+}
+
+var file_webanalyzer_v1_analyzer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_webanalyzer_v1_analyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_webanalyzer_v1_analyzer_proto_goTypes = []any{
+	(Stage)(0),                    // 0: webanalyzer.v1.Stage
+	(*AnalyzeRequest)(nil),        // 1: webanalyzer.v1.AnalyzeRequest
+	(*BasicAuth)(nil),             // 2: webanalyzer.v1.BasicAuth
+	(*AnalyzeResponse)(nil),       // 3: webanalyzer.v1.AnalyzeResponse
+	(*AnalyzeEvent)(nil),          // 4: webanalyzer.v1.AnalyzeEvent
+	(*Progress)(nil),              // 5: webanalyzer.v1.Progress
+	(*AnalysisResult)(nil),        // 6: webanalyzer.v1.AnalysisResult
+	(*LinkSummary)(nil),           // 7: webanalyzer.v1.LinkSummary
+	(*LinkResult)(nil),            // 8: webanalyzer.v1.LinkResult
+	(*SecurityFinding)(nil),       // 9: webanalyzer.v1.SecurityFinding
+	nil,                           // 10: webanalyzer.v1.AnalyzeRequest.HeadersEntry
+	nil,                           // 11: webanalyzer.v1.AnalyzeRequest.CookiesEntry
+	nil,                           // 12: webanalyzer.v1.AnalysisResult.HeadingsEntry
+	nil,                           // 13: webanalyzer.v1.AnalysisResult.ErrorsEntry
+	nil,                           // 14: webanalyzer.v1.LinkSummary.NotCheckedCountsEntry
+	nil,                           // 15: webanalyzer.v1.LinkSummary.SkippedCountsEntry
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_webanalyzer_v1_analyzer_proto_depIdxs = []int32{
+	10, // 0: webanalyzer.v1.AnalyzeRequest.headers:type_name -> webanalyzer.v1.AnalyzeRequest.HeadersEntry
+	2,  // 1: webanalyzer.v1.AnalyzeRequest.basic_auth:type_name -> webanalyzer.v1.BasicAuth
+	11, // 2: webanalyzer.v1.AnalyzeRequest.cookies:type_name -> webanalyzer.v1.AnalyzeRequest.CookiesEntry
+	6,  // 3: webanalyzer.v1.AnalyzeResponse.result:type_name -> webanalyzer.v1.AnalysisResult
+	5,  // 4: webanalyzer.v1.AnalyzeEvent.progress:type_name -> webanalyzer.v1.Progress
+	3,  // 5: webanalyzer.v1.AnalyzeEvent.result:type_name -> webanalyzer.v1.AnalyzeResponse
+	0,  // 6: webanalyzer.v1.Progress.stage:type_name -> webanalyzer.v1.Stage
+	12, // 7: webanalyzer.v1.AnalysisResult.headings:type_name -> webanalyzer.v1.AnalysisResult.HeadingsEntry
+	7,  // 8: webanalyzer.v1.AnalysisResult.links:type_name -> webanalyzer.v1.LinkSummary
+	16, // 9: webanalyzer.v1.AnalysisResult.analyzed_at:type_name -> google.protobuf.Timestamp
+	8,  // 10: webanalyzer.v1.AnalysisResult.link_results:type_name -> webanalyzer.v1.LinkResult
+	9,  // 11: webanalyzer.v1.AnalysisResult.security_findings:type_name -> webanalyzer.v1.SecurityFinding
+	13, // 12: webanalyzer.v1.AnalysisResult.errors:type_name -> webanalyzer.v1.AnalysisResult.ErrorsEntry
+	14, // 13: webanalyzer.v1.LinkSummary.not_checked_counts:type_name -> webanalyzer.v1.LinkSummary.NotCheckedCountsEntry
+	15, // 14: webanalyzer.v1.LinkSummary.skipped_counts:type_name -> webanalyzer.v1.LinkSummary.SkippedCountsEntry
+	1,  // 15: webanalyzer.v1.AnalyzerService.Analyze:input_type -> webanalyzer.v1.AnalyzeRequest
+	1,  // 16: webanalyzer.v1.AnalyzerService.AnalyzeStream:input_type -> webanalyzer.v1.AnalyzeRequest
+	3,  // 17: webanalyzer.v1.AnalyzerService.Analyze:output_type -> webanalyzer.v1.AnalyzeResponse
+	4,  // 18: webanalyzer.v1.AnalyzerService.AnalyzeStream:output_type -> webanalyzer.v1.AnalyzeEvent
+	17, // [17:19] is the sub-list for method output_type
+	15, // [15:17] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_webanalyzer_v1_analyzer_proto_init() }
+func file_webanalyzer_v1_analyzer_proto_init() {
+	if File_webanalyzer_v1_analyzer_proto != nil {
+		return
+	}
+	file_webanalyzer_v1_analyzer_proto_msgTypes[3].OneofWrappers = []any{
+		(*AnalyzeEvent_Progress)(nil),
+		(*AnalyzeEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_webanalyzer_v1_analyzer_proto_rawDesc), len(file_webanalyzer_v1_analyzer_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_webanalyzer_v1_analyzer_proto_goTypes,
+		DependencyIndexes: file_webanalyzer_v1_analyzer_proto_depIdxs,
+		EnumInfos:         file_webanalyzer_v1_analyzer_proto_enumTypes,
+		MessageInfos:      file_webanalyzer_v1_analyzer_proto_msgTypes,
+	}.Build()
+	File_webanalyzer_v1_analyzer_proto = out.File
+	file_webanalyzer_v1_analyzer_proto_goTypes = nil
+	file_webanalyzer_v1_analyzer_proto_depIdxs = nil
+}